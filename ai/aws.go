@@ -0,0 +1,369 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// awsCredentials are the keys requests to AWS are signed with
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time // zero for credentials that do not expire
+}
+
+// awsCredentialRefreshWindow is how long before they expire temporary
+// credentials are fetched again
+const awsCredentialRefreshWindow = 5 * time.Minute
+
+// awsMetadataTimeout bounds requests to the container and EC2 credential endpoints
+const awsMetadataTimeout = 2 * time.Second
+
+// awsCredentialChain looks up credentials the way the AWS SDKs do, from the
+// first source that has them: the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+// environment variables, the shared credentials file, the ECS or EKS
+// container credentials endpoint and finally EC2 instance metadata.
+// Temporary credentials are cached until shortly before they expire.
+type awsCredentialChain struct {
+	// httpClient talks to the link-local metadata endpoints, which must not
+	// go through the configured proxy
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	cached *awsCredentials
+}
+
+// newAWSCredentialChain creates a credential chain
+func newAWSCredentialChain() *awsCredentialChain {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	return &awsCredentialChain{httpClient: &http.Client{Transport: transport, Timeout: awsMetadataTimeout}}
+}
+
+// retrieve returns credentials from the first source in the chain that has them
+func (c *awsCredentialChain) retrieve(ctx context.Context) (awsCredentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && (c.cached.Expires.IsZero() || time.Until(c.cached.Expires) > awsCredentialRefreshWindow) {
+		return *c.cached, nil
+	}
+
+	sources := []func(context.Context) (*awsCredentials, error){
+		c.fromEnvironment,
+		c.fromSharedFile,
+		c.fromContainer,
+		c.fromInstanceMetadata,
+	}
+	for _, source := range sources {
+		creds, err := source(ctx)
+		if err != nil {
+			return awsCredentials{}, err
+		}
+		if creds != nil {
+			c.cached = creds
+			return *creds, nil
+		}
+	}
+	return awsCredentials{}, errors.New("no AWS credentials found in the environment, shared credentials file, container or instance metadata")
+}
+
+// fromEnvironment reads credentials from the standard environment variables
+func (c *awsCredentialChain) fromEnvironment(context.Context) (*awsCredentials, error) {
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, nil
+	}
+	return &awsCredentials{AccessKeyID: accessKey, SecretAccessKey: secretKey, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+}
+
+// fromSharedFile reads the AWS_PROFILE (or default) profile of the shared
+// credentials file, ~/.aws/credentials unless AWS_SHARED_CREDENTIALS_FILE is set
+func (c *awsCredentialChain) fromSharedFile(context.Context) (*awsCredentials, error) {
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read AWS credentials file: %w", err)
+	}
+	defer file.Close()
+
+	var creds awsCredentials
+	inProfile := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inProfile = strings.TrimSpace(line[1:len(line)-1]) == profile
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !inProfile || !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read AWS credentials file: %w", err)
+	}
+
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, nil
+	}
+	return &creds, nil
+}
+
+// awsMetadataCredentials is the credential document served by the container
+// and EC2 instance metadata endpoints
+type awsMetadataCredentials struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	Token           string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+// fromContainer fetches the task or pod role credentials that ECS and EKS Pod
+// Identity expose through the AWS_CONTAINER_CREDENTIALS_* variables
+func (c *awsCredentialChain) fromContainer(ctx context.Context) (*awsCredentials, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relative := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+		endpoint = "http://169.254.170.2" + relative
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid container credentials endpoint: %w", err)
+	}
+	token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if tokenFile := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read container authorization token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	creds, err := c.fetchMetadataCredentials(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// fromInstanceMetadata fetches the instance profile credentials of an EC2
+// instance using IMDSv2. Not running on EC2 is not an error.
+func (c *awsCredentialChain) fromInstanceMetadata(ctx context.Context) (*awsCredentials, error) {
+	if strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		return nil, nil
+	}
+	const metadataURL = "http://169.254.169.254/latest"
+
+	tokenReq, err := http.NewRequestWithContext(ctx, http.MethodPut, metadataURL+"/api/token", nil)
+	if err != nil {
+		return nil, nil
+	}
+	tokenReq.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+	token, err := c.getMetadata(tokenReq)
+	if err != nil {
+		return nil, nil // not on EC2
+	}
+
+	roleReq, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL+"/meta-data/iam/security-credentials/", nil)
+	if err != nil {
+		return nil, nil
+	}
+	roleReq.Header.Set("X-aws-ec2-metadata-token", token)
+	roles, err := c.getMetadata(roleReq)
+	if err != nil {
+		return nil, nil // no instance profile attached
+	}
+	role, _, _ := strings.Cut(strings.TrimSpace(roles), "\n")
+
+	credsReq, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL+"/meta-data/iam/security-credentials/"+role, nil)
+	if err != nil {
+		return nil, nil
+	}
+	credsReq.Header.Set("X-aws-ec2-metadata-token", token)
+	creds, err := c.fetchMetadataCredentials(credsReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance profile credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// getMetadata returns the body of a successful metadata request
+func (c *awsCredentialChain) getMetadata(req *http.Request) (string, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata endpoint returned status %d", resp.StatusCode)
+	}
+	return string(body), nil
+}
+
+// fetchMetadataCredentials requests and decodes a credential document
+func (c *awsCredentialChain) fetchMetadataCredentials(req *http.Request) (*awsCredentials, error) {
+	body, err := c.getMetadata(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc awsMetadataCredentials
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+	if doc.AccessKeyID == "" || doc.SecretAccessKey == "" {
+		return nil, errors.New("credentials response has no access key")
+	}
+	return &awsCredentials{
+		AccessKeyID:     doc.AccessKeyID,
+		SecretAccessKey: doc.SecretAccessKey,
+		SessionToken:    doc.Token,
+		Expires:         doc.Expiration,
+	}, nil
+}
+
+// signAWSRequest signs req for service in region with AWS Signature Version
+// 4. body must be the request body, which is hashed into the signature.
+func signAWSRequest(req *http.Request, body []byte, creds awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	// Canonical headers: host plus every header set on the request, lowercased and sorted
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsURIEncode(path, false), // every service but S3 encodes the escaped path again
+		canonicalQueryString(req),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQueryString returns the query of req with keys and values encoded
+// and sorted as Signature Version 4 requires
+func canonicalQueryString(req *http.Request) string {
+	var pairs []string
+	for key, values := range req.URL.Query() {
+		for _, value := range values {
+			pairs = append(pairs, awsURIEncode(key, true)+"="+awsURIEncode(value, true))
+		}
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent-encodes every byte of s except unreserved characters,
+// and slashes unless encodeSlash is set
+func awsURIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~", c) >= 0 || c == '/' && !encodeSlash {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data under key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
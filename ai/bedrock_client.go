@@ -0,0 +1,196 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
+)
+
+// defaultBedrockTimeout is the request timeout used when none is configured
+const defaultBedrockTimeout = 60 * time.Second
+
+// DefaultBedrockModelID is the Claude model invoked on Bedrock when none is configured
+const DefaultBedrockModelID = "anthropic.claude-3-5-sonnet-20240620-v1:0"
+
+// bedrockAnthropicVersion is the Messages API version Bedrock expects for Claude models
+const bedrockAnthropicVersion = "bedrock-2023-05-31"
+
+// BedrockClient implements the Client interface for Claude models on Amazon
+// Bedrock. Requests are signed with AWS Signature Version 4 using credentials
+// from the standard AWS credential chain.
+type BedrockClient struct {
+	region      string
+	modelID     string
+	httpClient  *http.Client
+	timeout     time.Duration
+	logger      internal.LoggerInterface
+	baseURL     string
+	credentials *awsCredentialChain
+	params      GenerationParams
+
+	// claude builds prompts and parses responses, which Bedrock shares with the Claude API
+	claude *ClaudeClient
+}
+
+// NewBedrockClient creates a new Bedrock client for region. modelID defaults
+// to DefaultBedrockModelID. A nil httpClient creates one with timeout.
+func NewBedrockClient(region, modelID string, timeout time.Duration, httpClient *http.Client, logger internal.LoggerInterface) *BedrockClient {
+	if modelID == "" {
+		modelID = DefaultBedrockModelID
+	}
+	if timeout <= 0 {
+		timeout = defaultBedrockTimeout
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: timeout}
+	}
+
+	return &BedrockClient{
+		region:      region,
+		modelID:     modelID,
+		baseURL:     fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", region),
+		httpClient:  httpClient,
+		timeout:     timeout,
+		logger:      logger,
+		credentials: newAWSCredentialChain(),
+		claude:      &ClaudeClient{model: modelID, logger: logger, errorPrompts: internal.DefaultErrorPrompts},
+	}
+}
+
+// GenerateFix implements the Client interface for Bedrock
+func (b *BedrockClient) GenerateFix(ctx context.Context, request FixRequest) (*FixResponse, error) {
+	// Scrub secrets that slipped past capture-time redaction
+	request = redactRequest(request)
+
+	// Add timeout to context if not already present
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+
+	// Bedrock takes the Claude Messages API body, with the model in the URL
+	bedrockReq := bedrockRequest{
+		AnthropicVersion: bedrockAnthropicVersion,
+		MaxTokens:        b.params.MaxTokensOr(2000),
+		Temperature:      b.params.Temperature,
+		TopP:             b.params.TopP,
+		System:           b.claude.getClaudeSystemPrompt(),
+		Messages: []claudeMessage{
+			{
+				Role:    "user",
+				Content: b.claude.generateClaudePrompt(request),
+			},
+		},
+	}
+
+	// Make API call
+	response, err := b.invokeModel(ctx, bedrockReq)
+	if err != nil {
+		return nil, fmt.Errorf("Bedrock API call failed: %w", err)
+	}
+
+	// Parse response
+	fixResponse, err := b.claude.parseClaudeResponse(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Bedrock response: %w", err)
+	}
+
+	// Set provider info
+	fixResponse.Provider = "bedrock"
+	fixResponse.UsedMCP = request.MCPContext != nil
+	fixResponse.Model = b.modelID
+	fixResponse.Usage = &TokenUsage{
+		PromptTokens:     response.Usage.InputTokens,
+		CompletionTokens: response.Usage.OutputTokens,
+		TotalTokens:      response.Usage.InputTokens + response.Usage.OutputTokens,
+	}
+
+	if b.logger != nil {
+		b.logger.Debug("Bedrock generated fix with confidence %.2f", fixResponse.Confidence)
+	}
+
+	return fixResponse, nil
+}
+
+// SetGenerationParams overrides the sampling parameters of requests. Claude on
+// Bedrock uses its own temperature and top_p defaults unless they are set.
+func (b *BedrockClient) SetGenerationParams(params GenerationParams) {
+	b.params = params
+}
+
+// SetErrorPrompts replaces the guidance added to prompts for each error
+// category, as returned by Config.GetErrorPrompts
+func (b *BedrockClient) SetErrorPrompts(prompts map[string]string) {
+	b.claude.SetErrorPrompts(prompts)
+}
+
+// GetProviderName returns the provider name
+func (b *BedrockClient) GetProviderName() string {
+	return "bedrock"
+}
+
+// ValidateConfiguration validates the Bedrock client configuration. AWS
+// credentials are looked up on the first request.
+func (b *BedrockClient) ValidateConfiguration() error {
+	if b.region == "" {
+		return fmt.Errorf("Bedrock region is required")
+	}
+	if b.modelID == "" {
+		return fmt.Errorf("Bedrock model ID is required")
+	}
+	return nil
+}
+
+// invokeModel calls the Bedrock InvokeModel API
+func (b *BedrockClient) invokeModel(ctx context.Context, request bedrockRequest) (*claudeResponse, error) {
+	creds, err := b.credentials.retrieve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Model IDs contain colons, which must reach Bedrock escaped
+	endpoint := strings.TrimRight(b.baseURL, "/") + "/model/" + awsURIEncode(b.modelID, true) + "/invoke"
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	signAWSRequest(httpReq, reqBody, creds, b.region, "bedrock", time.Now())
+
+	resp, err := b.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("Bedrock API returned status %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return nil, fmt.Errorf("Bedrock API returned status %d", resp.StatusCode)
+	}
+
+	var bedrockResp claudeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&bedrockResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &bedrockResp, nil
+}
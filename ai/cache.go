@@ -0,0 +1,118 @@
+package ai
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Patterns stripped from errors and stack traces so that recurrences of the
+// same panic produce the same cache key
+var (
+	cacheHexPattern       = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+	cacheGoroutinePattern = regexp.MustCompile(`goroutine \d+`)
+	cacheNumberPattern    = regexp.MustCompile(`\d+`)
+)
+
+// fixCache is a fixed-size LRU cache of fix responses keyed by panic fingerprint
+type fixCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front is most recently used
+	entries  map[string]*list.Element
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// fixCacheEntry is the value stored in each list element
+type fixCacheEntry struct {
+	key      string
+	response FixResponse
+}
+
+// newFixCache creates a cache holding up to capacity responses, or nil when capacity is not positive
+func newFixCache(capacity int) *fixCache {
+	if capacity <= 0 {
+		return nil
+	}
+	return &fixCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element, capacity),
+	}
+}
+
+// fixCacheKey hashes the normalized error, stack trace and source code of a request
+func fixCacheKey(request FixRequest) string {
+	h := sha256.New()
+	h.Write([]byte(normalizeForCache(request.Error)))
+	h.Write([]byte{0})
+	h.Write([]byte(normalizeStackForCache(request.StackTrace)))
+	h.Write([]byte{0})
+	h.Write([]byte(request.SourceCode))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeForCache removes addresses and numbers that vary between occurrences
+func normalizeForCache(msg string) string {
+	msg = cacheHexPattern.ReplaceAllString(msg, "0x?")
+	msg = cacheNumberPattern.ReplaceAllString(msg, "N")
+	return strings.TrimSpace(msg)
+}
+
+// normalizeStackForCache removes goroutine IDs and addresses but keeps line
+// numbers, since they identify where in the source the panic happened
+func normalizeStackForCache(stack string) string {
+	stack = cacheGoroutinePattern.ReplaceAllString(stack, "goroutine N")
+	stack = cacheHexPattern.ReplaceAllString(stack, "0x?")
+	return strings.TrimSpace(stack)
+}
+
+// get returns a copy of the cached response for key, marking it as recently used
+func (c *fixCache) get(key string) (*FixResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	c.order.MoveToFront(element)
+	response := element.Value.(*fixCacheEntry).response
+	return &response, true
+}
+
+// put stores a copy of response under key, evicting the least recently used entry when full
+func (c *fixCache) put(key string, response *FixResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*fixCacheEntry).response = *response
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&fixCacheEntry{key: key, response: *response})
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*fixCacheEntry).key)
+	}
+}
+
+// len returns the number of cached responses
+func (c *fixCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -5,44 +5,91 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ajeet-kumar1087/go-code-healer/internal"
 )
 
+// defaultClaudeTimeout is the request timeout used when none is configured
+const defaultClaudeTimeout = 60 * time.Second
+
 // ClaudeClient implements the Client interface for Anthropic's Claude API
 type ClaudeClient struct {
 	apiKey     string
 	model      string
 	httpClient *http.Client
+	timeout    time.Duration
 	logger     internal.LoggerInterface
 	baseURL    string
+	stream     bool // read responses as server-sent events
+	params     GenerationParams
+
+	errorPrompts map[string]string // guidance by error category
 }
 
-// NewClaudeClient creates a new Claude client
-func NewClaudeClient(apiKey, model string, logger internal.LoggerInterface) *ClaudeClient {
+// NewClaudeClient creates a new Claude client. A nil httpClient creates one
+// with timeout; a client passed in should not be shared with other AI
+// clients, since SetStreaming changes its timeout.
+func NewClaudeClient(apiKey, model string, timeout time.Duration, httpClient *http.Client, logger internal.LoggerInterface) *ClaudeClient {
 	if model == "" {
 		model = "claude-3-sonnet-20240229"
 	}
+	if timeout <= 0 {
+		timeout = defaultClaudeTimeout
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: timeout}
+	}
 
 	return &ClaudeClient{
-		apiKey:  apiKey,
-		model:   model,
-		baseURL: "https://api.anthropic.com/v1/messages",
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
-		},
-		logger: logger,
+		apiKey:     apiKey,
+		model:      model,
+		baseURL:    "https://api.anthropic.com/v1/messages",
+		httpClient: httpClient,
+		timeout:    timeout,
+		logger:     logger,
+
+		errorPrompts: internal.DefaultErrorPrompts,
+	}
+}
+
+// SetGenerationParams overrides the sampling parameters of requests. Claude
+// uses its own temperature and top_p defaults unless they are set.
+func (c *ClaudeClient) SetGenerationParams(params GenerationParams) {
+	c.params = params
+}
+
+// SetErrorPrompts replaces the guidance added to prompts for each error
+// category, as returned by Config.GetErrorPrompts
+func (c *ClaudeClient) SetErrorPrompts(prompts map[string]string) {
+	c.errorPrompts = prompts
+}
+
+// SetStreaming switches the client to streamed responses. While streaming,
+// the client timeout applies to the gap between chunks instead of to the
+// whole response; the context deadline still bounds the whole call.
+func (c *ClaudeClient) SetStreaming(enabled bool) {
+	c.stream = enabled
+	if enabled {
+		c.httpClient.Timeout = 0
+	} else {
+		c.httpClient.Timeout = c.timeout
 	}
 }
 
 // GenerateFix implements the Client interface for Claude
 func (c *ClaudeClient) GenerateFix(ctx context.Context, request FixRequest) (*FixResponse, error) {
+	// Scrub secrets that slipped past capture-time redaction
+	request = redactRequest(request)
+
 	// Add timeout to context if not already present
 	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, 60*time.Second)
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
 		defer cancel()
 	}
 
@@ -52,9 +99,11 @@ func (c *ClaudeClient) GenerateFix(ctx context.Context, request FixRequest) (*Fi
 
 	// Create Claude API request
 	claudeReq := claudeRequest{
-		Model:     c.model,
-		MaxTokens: 2000,
-		System:    systemPrompt,
+		Model:       c.model,
+		MaxTokens:   c.params.MaxTokensOr(2000),
+		Temperature: c.params.Temperature,
+		TopP:        c.params.TopP,
+		System:      systemPrompt,
 		Messages: []claudeMessage{
 			{
 				Role:    "user",
@@ -78,6 +127,12 @@ func (c *ClaudeClient) GenerateFix(ctx context.Context, request FixRequest) (*Fi
 	// Set provider info
 	fixResponse.Provider = "claude"
 	fixResponse.UsedMCP = request.MCPContext != nil
+	fixResponse.Model = c.model
+	fixResponse.Usage = &TokenUsage{
+		PromptTokens:     response.Usage.InputTokens,
+		CompletionTokens: response.Usage.OutputTokens,
+		TotalTokens:      response.Usage.InputTokens + response.Usage.OutputTokens,
+	}
 
 	if c.logger != nil {
 		c.logger.Debug("Claude generated fix with confidence %.2f", fixResponse.Confidence)
@@ -153,12 +208,18 @@ func (c *ClaudeClient) generateClaudePrompt(request FixRequest) string {
 		}
 	}
 
+	prompt += errorGuidance(request, c.errorPrompts)
+
 	prompt += "Please provide a JSON response with the following structure:\n"
 	prompt += "{\n"
-	prompt += "  \"proposed_fix\": \"// Your corrected Go code here\",\n"
+	prompt += "  \"proposed_fix\": \"// Replacement Go code here\",\n"
+	prompt += "  \"patch_format\": \"line_range\",\n"
+	prompt += "  \"start_line\": 42,\n"
+	prompt += "  \"end_line\": 45,\n"
 	prompt += "  \"explanation\": \"Detailed explanation of the fix and why it works\",\n"
 	prompt += "  \"confidence\": 0.85\n"
 	prompt += "}\n\n"
+	prompt += patchFormatInstructions + "\n\n"
 	prompt += "Focus on providing a minimal, targeted fix that addresses the root cause while following Go best practices."
 
 	return prompt
@@ -184,6 +245,7 @@ func (c *ClaudeClient) getClaudeSystemPrompt() string {
 
 // makeClaudeAPICall makes an HTTP request to Claude API
 func (c *ClaudeClient) makeClaudeAPICall(ctx context.Context, request claudeRequest) (*claudeResponse, error) {
+	request.Stream = c.stream
 	reqBody, err := json.Marshal(request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -209,6 +271,10 @@ func (c *ClaudeClient) makeClaudeAPICall(ctx context.Context, request claudeRequ
 		return nil, fmt.Errorf("Claude API returned status %d", resp.StatusCode)
 	}
 
+	if c.stream {
+		return c.readClaudeStream(ctx, resp.Body)
+	}
+
 	var claudeResp claudeResponse
 	if err := json.NewDecoder(resp.Body).Decode(&claudeResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
@@ -217,6 +283,51 @@ func (c *ClaudeClient) makeClaudeAPICall(ctx context.Context, request claudeRequ
 	return &claudeResp, nil
 }
 
+// readClaudeStream assembles a streamed message into a regular response so it
+// can be parsed the same way
+func (c *ClaudeClient) readClaudeStream(ctx context.Context, body io.ReadCloser) (*claudeResponse, error) {
+	var text strings.Builder
+	var usage claudeUsage
+
+	err := readSSE(ctx, body, c.timeout, func(_, data string) error {
+		var event claudeStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("failed to decode stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			usage.InputTokens = event.Message.Usage.InputTokens
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" {
+				text.WriteString(event.Delta.Text)
+			}
+		case "message_delta":
+			usage.OutputTokens = event.Usage.OutputTokens
+		case "message_stop":
+			return errStreamDone
+		case "error":
+			if event.Error != nil {
+				return fmt.Errorf("Claude API error: %s (type: %s)", event.Error.Message, event.Error.Type)
+			}
+			return fmt.Errorf("Claude API error")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.logger != nil {
+		c.logger.Debug("Claude stream finished (%d characters)", text.Len())
+	}
+
+	return &claudeResponse{
+		Content: []claudeContent{{Type: "text", Text: text.String()}},
+		Usage:   usage,
+	}, nil
+}
+
 // parseClaudeResponse parses Claude API response into FixResponse
 func (c *ClaudeClient) parseClaudeResponse(response *claudeResponse) (*FixResponse, error) {
 	if len(response.Content) == 0 {
@@ -231,6 +342,7 @@ func (c *ClaudeClient) parseClaudeResponse(response *claudeResponse) (*FixRespon
 		ProposedFix string  `json:"proposed_fix"`
 		Explanation string  `json:"explanation"`
 		Confidence  float64 `json:"confidence"`
+		fixPatch
 	}
 
 	if err := json.Unmarshal([]byte(text), &jsonResponse); err != nil {
@@ -250,10 +362,13 @@ func (c *ClaudeClient) parseClaudeResponse(response *claudeResponse) (*FixRespon
 		jsonResponse.Confidence = 1
 	}
 
-	return &FixResponse{
-		ProposedFix: jsonResponse.ProposedFix,
+	fixResponse := &FixResponse{
+		ProposedFix: trimProposedFix(jsonResponse.ProposedFix),
 		Explanation: jsonResponse.Explanation,
 		Confidence:  jsonResponse.Confidence,
 		IsValid:     jsonResponse.ProposedFix != "",
-	}, nil
+	}
+	jsonResponse.fixPatch.apply(fixResponse)
+
+	return fixResponse, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/ajeet-kumar1087/go-code-healer/internal"
@@ -22,14 +23,44 @@ type FixRequest struct {
 	Metadata   map[string]string `json:"metadata,omitempty"`
 }
 
+// redactRequest defensively scrubs built-in secret patterns from the free-text
+// fields of a request. SourceCode is left intact since the fix must match it.
+func redactRequest(request FixRequest) FixRequest {
+	redactor := internal.DefaultRedactor()
+	request.Error = redactor.Redact(request.Error)
+	request.StackTrace = redactor.Redact(request.StackTrace)
+	request.Context = redactor.Redact(request.Context)
+	return request
+}
+
 // FixResponse represents the AI's response with a proposed fix
 type FixResponse struct {
 	ProposedFix string  `json:"proposed_fix"`
 	Explanation string  `json:"explanation"`
 	Confidence  float64 `json:"confidence"`
 	IsValid     bool    `json:"is_valid"`
-	Provider    string  `json:"provider"` // which AI provider generated this fix
-	UsedMCP     bool    `json:"used_mcp"` // whether MCP context was used
+	Provider    string  `json:"provider"`             // which AI provider generated this fix
+	UsedMCP     bool    `json:"used_mcp"`             // whether MCP context was used
+	FromCache   bool    `json:"from_cache,omitempty"` // returned from the fix cache without calling a provider
+
+	// ConsultedProviders lists the providers asked for this fix, in order
+	ConsultedProviders []string `json:"consulted_providers,omitempty"`
+
+	// How ProposedFix should be merged into the source file
+	PatchFormat string `json:"patch_format,omitempty"` // "full_file", "unified_diff" or "line_range"
+	StartLine   int    `json:"start_line,omitempty"`   // first replaced line for "line_range"
+	EndLine     int    `json:"end_line,omitempty"`     // last replaced line for "line_range"
+
+	// Model and Usage record what generating this fix cost
+	Model string      `json:"model,omitempty"`
+	Usage *TokenUsage `json:"usage,omitempty"`
+}
+
+// TokenUsage holds the token counts reported by an AI provider for one request
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 // Client interface for AI fix generation
@@ -39,12 +70,36 @@ type Client interface {
 	ValidateConfiguration() error
 }
 
+// defaultOpenAITimeout is the request timeout used when none is configured
+const defaultOpenAITimeout = 45 * time.Second
+
+// DefaultAzureAPIVersion is the Azure OpenAI REST API version used when none is configured
+const DefaultAzureAPIVersion = "2024-02-01"
+
+// AzureConfig identifies an Azure OpenAI deployment
+type AzureConfig struct {
+	Endpoint   string // e.g. https://my-resource.openai.azure.com
+	Deployment string // deployment name, which selects the model on Azure
+	APIVersion string // defaults to DefaultAzureAPIVersion
+}
+
+// GenerationParams overrides the temperature, max tokens and top_p of AI
+// requests; unset fields keep each provider's defaults
+type GenerationParams = internal.AIGenerationParams
+
 // OpenAIClient implements the Client interface for OpenAI API integration
 type OpenAIClient struct {
 	apiKey     string
 	model      string
 	httpClient *http.Client
+	timeout    time.Duration
 	logger     Logger
+	azure      *AzureConfig // nil for the public OpenAI API
+	params     GenerationParams
+
+	// responseFormat is the most structured response format the model has not
+	// rejected yet, one of the format* levels
+	responseFormat atomic.Int32
 
 	// Embedded components
 	promptGenerator *PromptGenerator
@@ -53,16 +108,23 @@ type OpenAIClient struct {
 	httpHandler     *HTTPHandler
 }
 
-// NewOpenAIClient creates a new OpenAI client with proper HTTP client configuration
-func NewOpenAIClient(apiKey, model string, logger Logger) *OpenAIClient {
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
+// NewOpenAIClient creates a new OpenAI client. A nil httpClient creates one
+// with timeout; a client passed in should not be shared with other AI
+// clients, since SetStreaming changes its timeout.
+func NewOpenAIClient(apiKey, model string, timeout time.Duration, httpClient *http.Client, logger Logger) *OpenAIClient {
+	if timeout <= 0 {
+		timeout = defaultOpenAITimeout
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: timeout}
 	}
 
 	client := &OpenAIClient{
 		apiKey:     apiKey,
 		model:      model,
 		httpClient: httpClient,
+		timeout:    timeout,
 		logger:     logger,
 	}
 
@@ -75,12 +137,60 @@ func NewOpenAIClient(apiKey, model string, logger Logger) *OpenAIClient {
 	return client
 }
 
+// NewAzureOpenAIClient creates an OpenAI client that sends requests to an Azure OpenAI deployment
+func NewAzureOpenAIClient(apiKey, model string, azure AzureConfig, timeout time.Duration, httpClient *http.Client, logger Logger) *OpenAIClient {
+	client := NewOpenAIClient(apiKey, model, timeout, httpClient, logger)
+	client.azure = &azure
+	client.httpHandler.azure = client.azure
+	return client
+}
+
+// NewOpenAICompatibleClient creates an OpenAI client that sends requests to
+// an OpenAI-compatible API such as Mistral, Together, Groq or vLLM. baseURL
+// is the URL that /chat/completions is appended to, e.g.
+// https://api.mistral.ai/v1. authHeader names the header carrying apiKey and
+// defaults to Authorization, where the key is sent as a bearer token.
+func NewOpenAICompatibleClient(apiKey, model, baseURL, authHeader string, timeout time.Duration, httpClient *http.Client, logger Logger) *OpenAIClient {
+	client := NewOpenAIClient(apiKey, model, timeout, httpClient, logger)
+	client.httpHandler.baseURL = baseURL
+	client.httpHandler.authHeader = authHeader
+	return client
+}
+
+// SetGenerationParams overrides the sampling parameters of requests
+func (ai *OpenAIClient) SetGenerationParams(params GenerationParams) {
+	ai.params = params
+}
+
+// SetErrorPrompts replaces the guidance added to prompts for each error
+// category, as returned by Config.GetErrorPrompts
+func (ai *OpenAIClient) SetErrorPrompts(prompts map[string]string) {
+	ai.promptGenerator.SetErrorPrompts(prompts)
+}
+
+// SetStreaming switches the client to streamed responses. While streaming,
+// the client timeout applies to the gap between chunks instead of to the
+// whole response, so long fixes that keep producing output are not cut off;
+// the context deadline still bounds the whole call.
+func (ai *OpenAIClient) SetStreaming(enabled bool) {
+	ai.httpHandler.stream = enabled
+	ai.httpHandler.idleTimeout = ai.timeout
+	if enabled {
+		ai.httpClient.Timeout = 0
+	} else {
+		ai.httpClient.Timeout = ai.timeout
+	}
+}
+
 // GenerateFix sends a request to OpenAI and returns a proposed fix with enhanced error handling
 func (ai *OpenAIClient) GenerateFix(ctx context.Context, request FixRequest) (*FixResponse, error) {
+	// Scrub secrets that slipped past capture-time redaction
+	request = redactRequest(request)
+
 	// Add timeout to context if not already present
 	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, 45*time.Second)
+		ctx, cancel = context.WithTimeout(ctx, ai.timeout)
 		defer cancel()
 	}
 
@@ -105,14 +215,30 @@ func (ai *OpenAIClient) GenerateFix(ctx context.Context, request FixRequest) (*F
 				Content: prompt,
 			},
 		},
-		Temperature: 0.1, // Low temperature for more deterministic code generation
-		MaxTokens:   2000,
-		TopP:        0.9,
+		Temperature: ai.params.TemperatureOr(0.1), // Low temperature for more deterministic code generation
+		MaxTokens:   ai.params.MaxTokensOr(2000),
+		TopP:        ai.params.TopPOr(0.9),
 	}
 
-	// Make API call with retry logic for rate limits and transient errors
-	response, err := ai.httpHandler.MakeAPICallWithRetry(ctx, apiRequest, ai.apiKey)
-	if err != nil {
+	// Ask for JSON matching the fix schema, falling back to looser formats
+	// for models that do not support it
+	var response *openAIResponse
+	for {
+		level := ai.responseFormat.Load()
+		apiRequest.ResponseFormat = responseFormatFor(level)
+
+		// Make API call with retry logic for rate limits and transient errors
+		var err error
+		response, err = ai.httpHandler.MakeAPICallWithRetry(ctx, apiRequest, ai.apiKey)
+		if err == nil {
+			break
+		}
+		if isResponseFormatError(err) && downgradeResponseFormat(&ai.responseFormat, level) {
+			if ai.logger != nil {
+				ai.logger.Debug("Model %s rejected response format, retrying with a simpler one: %v", ai.model, err)
+			}
+			continue
+		}
 		return nil, fmt.Errorf("OpenAI API call failed: %w", err)
 	}
 
@@ -123,10 +249,16 @@ func (ai *OpenAIClient) GenerateFix(ctx context.Context, request FixRequest) (*F
 	}
 
 	// Validate the proposed Go code for syntax correctness and calculate confidence
-	fixResponse.IsValid = ai.codeValidator.ValidateGoSyntax(fixResponse.ProposedFix)
+	fixResponse.IsValid = ai.codeValidator.ValidateFix(fixResponse)
 	fixResponse.Confidence = ai.adjustConfidenceScore(fixResponse.Confidence, fixResponse.IsValid, request)
 	fixResponse.Provider = "openai"
 	fixResponse.UsedMCP = request.MCPContext != nil
+	fixResponse.Model = ai.model
+	fixResponse.Usage = &TokenUsage{
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+		TotalTokens:      response.Usage.TotalTokens,
+	}
 
 	// Log the result for debugging
 	if ai.logger != nil {
@@ -219,5 +351,8 @@ func (ai *OpenAIClient) ValidateConfiguration() error {
 	if ai.model == "" {
 		return fmt.Errorf("OpenAI model is required")
 	}
+	if ai.azure != nil && (ai.azure.Endpoint == "" || ai.azure.Deployment == "") {
+		return fmt.Errorf("Azure OpenAI endpoint and deployment are required")
+	}
 	return nil
 }
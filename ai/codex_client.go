@@ -12,38 +12,56 @@ import (
 	"github.com/ajeet-kumar1087/go-code-healer/internal"
 )
 
+// defaultCodexTimeout is the request timeout used when none is configured
+const defaultCodexTimeout = 60 * time.Second
+
 // CodexClient implements the Client interface for GitHub Codex API
 type CodexClient struct {
 	apiKey     string
 	model      string
 	httpClient *http.Client
+	timeout    time.Duration
 	logger     internal.LoggerInterface
 	baseURL    string
+	params     GenerationParams
+
+	errorPrompts map[string]string // guidance by error category
 }
 
-// NewCodexClient creates a new Codex client
-func NewCodexClient(apiKey, model string, logger internal.LoggerInterface) *CodexClient {
+// NewCodexClient creates a new Codex client. A nil httpClient creates one with timeout.
+func NewCodexClient(apiKey, model string, timeout time.Duration, httpClient *http.Client, logger internal.LoggerInterface) *CodexClient {
 	if model == "" {
 		model = "code-davinci-002"
 	}
+	if timeout <= 0 {
+		timeout = defaultCodexTimeout
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: timeout}
+	}
 
 	return &CodexClient{
-		apiKey:  apiKey,
-		model:   model,
-		baseURL: "https://api.openai.com/v1/completions",
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
-		},
-		logger: logger,
+		apiKey:     apiKey,
+		model:      model,
+		baseURL:    "https://api.openai.com/v1/completions",
+		httpClient: httpClient,
+		timeout:    timeout,
+		logger:     logger,
+
+		errorPrompts: internal.DefaultErrorPrompts,
 	}
 }
 
 // GenerateFix implements the Client interface for Codex
 func (c *CodexClient) GenerateFix(ctx context.Context, request FixRequest) (*FixResponse, error) {
+	// Scrub secrets that slipped past capture-time redaction
+	request = redactRequest(request)
+
 	// Add timeout to context if not already present
 	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, 60*time.Second)
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
 		defer cancel()
 	}
 
@@ -54,8 +72,9 @@ func (c *CodexClient) GenerateFix(ctx context.Context, request FixRequest) (*Fix
 	codexReq := codexRequest{
 		Model:       c.model,
 		Prompt:      prompt,
-		MaxTokens:   1500,
-		Temperature: 0.1, // Low temperature for more deterministic code generation
+		MaxTokens:   c.params.MaxTokensOr(1500),
+		Temperature: c.params.TemperatureOr(0.1), // Low temperature for more deterministic code generation
+		TopP:        c.params.TopP,
 		Stop:        []string{"```", "---END---"},
 	}
 
@@ -74,6 +93,12 @@ func (c *CodexClient) GenerateFix(ctx context.Context, request FixRequest) (*Fix
 	// Set provider info
 	fixResponse.Provider = "codex"
 	fixResponse.UsedMCP = request.MCPContext != nil
+	fixResponse.Model = c.model
+	fixResponse.Usage = &TokenUsage{
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+		TotalTokens:      response.Usage.TotalTokens,
+	}
 
 	if c.logger != nil {
 		c.logger.Debug("Codex generated fix with confidence %.2f", fixResponse.Confidence)
@@ -82,6 +107,17 @@ func (c *CodexClient) GenerateFix(ctx context.Context, request FixRequest) (*Fix
 	return fixResponse, nil
 }
 
+// SetGenerationParams overrides the sampling parameters of requests.
+func (c *CodexClient) SetGenerationParams(params GenerationParams) {
+	c.params = params
+}
+
+// SetErrorPrompts replaces the guidance added to prompts for each error
+// category, as returned by Config.GetErrorPrompts
+func (c *CodexClient) SetErrorPrompts(prompts map[string]string) {
+	c.errorPrompts = prompts
+}
+
 // GetProviderName returns the provider name
 func (c *CodexClient) GetProviderName() string {
 	return "codex"
@@ -112,6 +148,13 @@ func (c *CodexClient) generateCodexPrompt(request FixRequest) string {
 		}
 	}
 
+	// Completion models read guidance as comments
+	if guidance := errorGuidance(request, c.errorPrompts); guidance != "" {
+		for _, line := range strings.Split(strings.TrimSpace(guidance), "\n") {
+			prompt.WriteString("// " + strings.TrimPrefix(line, "## ") + "\n")
+		}
+	}
+
 	prompt.WriteString("\n// Original problematic code:\n")
 	prompt.WriteString("/*\n")
 	prompt.WriteString(request.SourceCode)
@@ -119,15 +162,6 @@ func (c *CodexClient) generateCodexPrompt(request FixRequest) string {
 
 	prompt.WriteString("// Fixed code:\n")
 
-	// Add context about the fix needed
-	if strings.Contains(request.Error, "nil pointer") {
-		prompt.WriteString("// Fix: Add nil check before dereferencing pointer\n")
-	} else if strings.Contains(request.Error, "index out of range") {
-		prompt.WriteString("// Fix: Add bounds checking before array/slice access\n")
-	} else if strings.Contains(request.Error, "concurrent map") {
-		prompt.WriteString("// Fix: Add proper synchronization for concurrent map access\n")
-	}
-
 	return prompt.String()
 }
 
@@ -172,7 +206,7 @@ func (c *CodexClient) parseCodexResponse(response *codexResponse, request FixReq
 	}
 
 	// Extract the generated code
-	generatedText := strings.TrimSpace(response.Choices[0].Text)
+	generatedText := stripCodeFences(strings.TrimSpace(response.Choices[0].Text))
 
 	// Clean up the generated code
 	proposedFix := c.cleanupGeneratedCode(generatedText)
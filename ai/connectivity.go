@@ -0,0 +1,168 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConnectivityChecker is implemented by clients that can verify their
+// endpoint and credentials with a cheap authenticated call, such as listing
+// models, without generating a fix
+type ConnectivityChecker interface {
+	CheckConnectivity(ctx context.Context) error
+}
+
+// CheckConnectivity makes a cheap authenticated call to every configured
+// provider and returns the failures joined, one per provider. Providers that
+// do not implement ConnectivityChecker are skipped.
+func (pm *ProviderManager) CheckConnectivity(ctx context.Context) error {
+	errs := make([]error, len(pm.providers))
+	var wg sync.WaitGroup
+	for i, provider := range pm.providers {
+		checker, ok := provider.(ConnectivityChecker)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := checker.CheckConnectivity(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", provider.GetProviderName(), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// CheckConnectivity lists the models of the OpenAI API, the OpenAI-compatible
+// endpoint or the Azure resource
+func (ai *OpenAIClient) CheckConnectivity(ctx context.Context) error {
+	hh := ai.httpHandler
+	var endpoint string
+	if hh.azure != nil {
+		apiVersion := hh.azure.APIVersion
+		if apiVersion == "" {
+			apiVersion = DefaultAzureAPIVersion
+		}
+		endpoint = fmt.Sprintf("%s/openai/models?api-version=%s", strings.TrimRight(hh.azure.Endpoint, "/"), url.QueryEscape(apiVersion))
+	} else {
+		baseURL := hh.baseURL
+		if baseURL == "" {
+			baseURL = DefaultOpenAIBaseURL
+		}
+		endpoint = strings.TrimRight(baseURL, "/") + "/models"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if hh.azure != nil {
+		req.Header.Set("api-key", ai.apiKey)
+	} else {
+		hh.setAuthHeader(req, ai.apiKey)
+	}
+	return probe(ai.httpClient, req, ai.timeout)
+}
+
+// CheckConnectivity lists the models of the Anthropic API
+func (c *ClaudeClient) CheckConnectivity(ctx context.Context) error {
+	endpoint := strings.TrimSuffix(c.baseURL, "/messages") + "/models?limit=1"
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	return probe(c.httpClient, req, c.timeout)
+}
+
+// CheckConnectivity lists the models of the OpenAI API
+func (c *CodexClient) CheckConnectivity(ctx context.Context) error {
+	endpoint := strings.TrimSuffix(c.baseURL, "/completions") + "/models"
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	return probe(c.httpClient, req, c.timeout)
+}
+
+// CheckConnectivity gets the configured Gemini model, which also confirms
+// the model exists
+func (g *GeminiClient) CheckConnectivity(ctx context.Context) error {
+	endpoint := fmt.Sprintf("%s/%s", g.baseURL, url.PathEscape(g.model))
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-goog-api-key", g.apiKey)
+	return probe(g.httpClient, req, g.timeout)
+}
+
+// CheckConnectivity lists the models pulled into the Ollama server
+func (o *OllamaClient) CheckConnectivity(ctx context.Context) error {
+	endpoint := strings.TrimSuffix(strings.TrimRight(o.baseURL, "/"), "/api/generate") + "/api/tags"
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	return probe(o.httpClient, req, o.timeout)
+}
+
+// CheckConnectivity gets the configured foundation model from the Bedrock
+// control plane, which confirms the AWS credentials and the model ID
+func (b *BedrockClient) CheckConnectivity(ctx context.Context) error {
+	creds, err := b.credentials.retrieve(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Model details are served by bedrock.<region>, next to bedrock-runtime.<region>
+	baseURL := strings.Replace(strings.TrimRight(b.baseURL, "/"), "://bedrock-runtime.", "://bedrock.", 1)
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/foundation-models/"+awsURIEncode(b.modelID, true), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	signAWSRequest(req, nil, creds, b.region, "bedrock", time.Now())
+	return probe(b.httpClient, req, b.timeout)
+}
+
+// maxProbeErrorBody bounds how much of a failed probe response is included in the error
+const maxProbeErrorBody = 512
+
+// probe sends req and fails unless the response is successful. The call is
+// bounded by timeout unless ctx already has a deadline.
+func probe(httpClient *http.Client, req *http.Request, timeout time.Duration) error {
+	ctx := req.Context()
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline && timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxProbeErrorBody))
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("credentials rejected with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return fmt.Errorf("returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
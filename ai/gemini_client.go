@@ -0,0 +1,236 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
+)
+
+// defaultGeminiTimeout is the request timeout used when none is configured
+const defaultGeminiTimeout = 60 * time.Second
+
+// GeminiClient implements the Client interface for Google's Gemini API
+type GeminiClient struct {
+	apiKey     string
+	model      string
+	httpClient *http.Client
+	timeout    time.Duration
+	logger     internal.LoggerInterface
+	baseURL    string
+	params     GenerationParams
+
+	// Embedded components
+	promptGenerator *PromptGenerator
+	codeValidator   *CodeValidator
+}
+
+// NewGeminiClient creates a new Gemini client. A nil httpClient creates one with timeout.
+func NewGeminiClient(apiKey, model string, timeout time.Duration, httpClient *http.Client, logger internal.LoggerInterface) *GeminiClient {
+	if model == "" {
+		model = "gemini-1.5-pro"
+	}
+	if timeout <= 0 {
+		timeout = defaultGeminiTimeout
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: timeout}
+	}
+
+	return &GeminiClient{
+		apiKey:          apiKey,
+		model:           model,
+		baseURL:         "https://generativelanguage.googleapis.com/v1beta/models",
+		httpClient:      httpClient,
+		timeout:         timeout,
+		logger:          logger,
+		promptGenerator: NewPromptGenerator(),
+		codeValidator:   NewCodeValidator(logger),
+	}
+}
+
+// GenerateFix implements the Client interface for Gemini
+func (g *GeminiClient) GenerateFix(ctx context.Context, request FixRequest) (*FixResponse, error) {
+	// Scrub secrets that slipped past capture-time redaction
+	request = redactRequest(request)
+
+	// Add timeout to context if not already present
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+
+	// Create Gemini API request using the shared Go-fix prompt
+	geminiReq := geminiRequest{
+		Contents: []geminiContent{
+			{
+				Role:  "user",
+				Parts: []geminiPart{{Text: g.promptGenerator.GeneratePromptWithMCP(request)}},
+			},
+		},
+		SystemInstruction: &geminiContent{
+			Parts: []geminiPart{{Text: g.promptGenerator.GetSystemPrompt()}},
+		},
+		GenerationConfig: &geminiGenerationConfig{
+			Temperature:      g.params.TemperatureOr(0.1), // Low temperature for more deterministic code generation
+			MaxOutputTokens:  g.params.MaxTokensOr(2000),
+			TopP:             g.params.TopPOr(0.9),
+			ResponseMimeType: "application/json",
+		},
+	}
+
+	// Make API call
+	response, err := g.makeGeminiAPICall(ctx, geminiReq)
+	if err != nil {
+		return nil, fmt.Errorf("Gemini API call failed: %w", err)
+	}
+
+	// Parse response
+	fixResponse, err := g.parseGeminiResponse(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Gemini response: %w", err)
+	}
+
+	// Set provider info
+	fixResponse.Provider = "gemini"
+	fixResponse.UsedMCP = request.MCPContext != nil
+	fixResponse.Model = g.model
+	fixResponse.Usage = &TokenUsage{
+		PromptTokens:     response.UsageMetadata.PromptTokenCount,
+		CompletionTokens: response.UsageMetadata.CandidatesTokenCount,
+		TotalTokens:      response.UsageMetadata.TotalTokenCount,
+	}
+
+	if g.logger != nil {
+		g.logger.Debug("Gemini generated fix with confidence %.2f", fixResponse.Confidence)
+	}
+
+	return fixResponse, nil
+}
+
+// SetGenerationParams overrides the sampling parameters of requests.
+func (g *GeminiClient) SetGenerationParams(params GenerationParams) {
+	g.params = params
+}
+
+// SetErrorPrompts replaces the guidance added to prompts for each error
+// category, as returned by Config.GetErrorPrompts
+func (g *GeminiClient) SetErrorPrompts(prompts map[string]string) {
+	g.promptGenerator.SetErrorPrompts(prompts)
+}
+
+// GetProviderName returns the provider name
+func (g *GeminiClient) GetProviderName() string {
+	return "gemini"
+}
+
+// ValidateConfiguration validates the Gemini client configuration
+func (g *GeminiClient) ValidateConfiguration() error {
+	if g.apiKey == "" {
+		return fmt.Errorf("Gemini API key is required")
+	}
+	if g.model == "" {
+		return fmt.Errorf("Gemini model is required")
+	}
+	return nil
+}
+
+// makeGeminiAPICall makes an HTTP request to the Gemini generateContent endpoint
+func (g *GeminiClient) makeGeminiAPICall(ctx context.Context, request geminiRequest) (*geminiResponse, error) {
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// The key goes in a header: transport errors include the URL, and they are logged
+	endpoint := fmt.Sprintf("%s/%s:generateContent", g.baseURL, url.PathEscape(g.model))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-goog-api-key", g.apiKey)
+
+	resp, err := g.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var geminiResp geminiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&geminiResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("Gemini API returned status %d", resp.StatusCode)
+		}
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if geminiResp.Error != nil {
+		return nil, fmt.Errorf("Gemini API error: %s (status: %s, code: %d)",
+			geminiResp.Error.Message, geminiResp.Error.Status, geminiResp.Error.Code)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Gemini API returned status %d", resp.StatusCode)
+	}
+
+	return &geminiResp, nil
+}
+
+// parseGeminiResponse parses Gemini API response into FixResponse
+func (g *GeminiClient) parseGeminiResponse(response *geminiResponse) (*FixResponse, error) {
+	if len(response.Candidates) == 0 || len(response.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("empty response from Gemini")
+	}
+
+	// Concatenate all text parts of the first candidate
+	var text strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+
+	// Try to parse as JSON
+	var jsonResponse struct {
+		ProposedFix string  `json:"proposed_fix"`
+		Explanation string  `json:"explanation"`
+		Confidence  float64 `json:"confidence"`
+		fixPatch
+	}
+
+	if err := json.Unmarshal([]byte(text.String()), &jsonResponse); err != nil {
+		// If JSON parsing fails, keep the raw text but mark it as unusable
+		return &FixResponse{
+			ProposedFix: text.String(),
+			Explanation: "Gemini provided a text response that couldn't be parsed as JSON",
+			Confidence:  0.5,
+			IsValid:     false,
+		}, nil
+	}
+
+	// Validate confidence score
+	if jsonResponse.Confidence < 0 {
+		jsonResponse.Confidence = 0
+	} else if jsonResponse.Confidence > 1 {
+		jsonResponse.Confidence = 1
+	}
+
+	fixResponse := &FixResponse{
+		ProposedFix: trimProposedFix(jsonResponse.ProposedFix),
+		Explanation: strings.TrimSpace(jsonResponse.Explanation),
+		Confidence:  jsonResponse.Confidence,
+	}
+	jsonResponse.fixPatch.apply(fixResponse)
+	fixResponse.IsValid = g.codeValidator.ValidateFix(fixResponse)
+
+	return fixResponse, nil
+}
@@ -5,15 +5,32 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
 
+// DefaultOpenAIBaseURL is the base URL of the public OpenAI API
+const DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
 // HTTPHandler handles HTTP requests to the OpenAI API
 type HTTPHandler struct {
 	httpClient *http.Client
 	logger     Logger
+	azure      *AzureConfig // routes requests to an Azure OpenAI deployment when set
+
+	// baseURL replaces DefaultOpenAIBaseURL for OpenAI-compatible endpoints.
+	// authHeader names the header carrying the API key; the key is sent as a
+	// bearer token in Authorization and verbatim in any other header.
+	baseURL    string
+	authHeader string
+
+	// When streaming, responses are read as server-sent events and fail if no
+	// data arrives for idleTimeout
+	stream      bool
+	idleTimeout time.Duration
 }
 
 // NewHTTPHandler creates a new HTTP handler
@@ -66,6 +83,14 @@ func (hh *HTTPHandler) MakeAPICallWithRetry(ctx context.Context, request openAIR
 
 // makeAPICall performs the HTTP request to OpenAI API
 func (hh *HTTPHandler) makeAPICall(ctx context.Context, request openAIRequest, apiKey string) (*openAIResponse, error) {
+	if hh.stream {
+		request.Stream = true
+		// Older Azure API versions reject stream_options
+		if hh.azure == nil {
+			request.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+		}
+	}
+
 	// Marshal request to JSON
 	requestBody, err := json.Marshal(request)
 	if err != nil {
@@ -73,14 +98,18 @@ func (hh *HTTPHandler) makeAPICall(ctx context.Context, request openAIRequest, a
 	}
 
 	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(requestBody))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", hh.endpointURL(), bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	// Set headers
+	// Set headers; Azure authenticates with an api-key header instead of a bearer token
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	if hh.azure != nil {
+		httpReq.Header.Set("api-key", apiKey)
+	} else {
+		hh.setAuthHeader(httpReq, apiKey)
+	}
 
 	// Log the request (without API key)
 	if hh.logger != nil {
@@ -94,6 +123,10 @@ func (hh *HTTPHandler) makeAPICall(ctx context.Context, request openAIRequest, a
 	}
 	defer resp.Body.Close()
 
+	if hh.stream && resp.StatusCode == http.StatusOK {
+		return hh.readStream(ctx, resp.Body, request.Model)
+	}
+
 	// Parse response
 	var apiResponse openAIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
@@ -119,6 +152,88 @@ func (hh *HTTPHandler) makeAPICall(ctx context.Context, request openAIRequest, a
 	return &apiResponse, nil
 }
 
+// readStream assembles a streamed chat completion into a regular response so
+// it can be parsed the same way
+func (hh *HTTPHandler) readStream(ctx context.Context, body io.ReadCloser, model string) (*openAIResponse, error) {
+	var content strings.Builder
+	var usage openAIUsage
+	var finishReason string
+
+	err := readSSE(ctx, body, hh.idleTimeout, func(_, data string) error {
+		if data == "[DONE]" {
+			return errStreamDone
+		}
+
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("OpenAI API error: %s (type: %s, code: %s)",
+				chunk.Error.Message, chunk.Error.Type, chunk.Error.Code)
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+		if chunk.Usage != nil {
+			usage = *chunk.Usage
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if content.Len() == 0 {
+		return nil, fmt.Errorf("OpenAI API returned no choices")
+	}
+
+	if hh.logger != nil {
+		hh.logger.Debug("OpenAI stream finished (%d characters, finish reason: %s)", content.Len(), finishReason)
+	}
+
+	return &openAIResponse{
+		Model: model,
+		Choices: []openAIChoice{{
+			Message:      openAIMessage{Role: "assistant", Content: content.String()},
+			FinishReason: finishReason,
+		}},
+		Usage: usage,
+	}, nil
+}
+
+// setAuthHeader sets the API key header of an OpenAI or OpenAI-compatible request
+func (hh *HTTPHandler) setAuthHeader(req *http.Request, apiKey string) {
+	if hh.authHeader == "" || strings.EqualFold(hh.authHeader, "Authorization") {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+		return
+	}
+	req.Header.Set(hh.authHeader, apiKey)
+}
+
+// endpointURL returns the chat completions URL for OpenAI, the configured
+// OpenAI-compatible endpoint or the configured Azure deployment
+func (hh *HTTPHandler) endpointURL() string {
+	if hh.azure == nil {
+		baseURL := hh.baseURL
+		if baseURL == "" {
+			baseURL = DefaultOpenAIBaseURL
+		}
+		return strings.TrimRight(baseURL, "/") + "/chat/completions"
+	}
+
+	apiVersion := hh.azure.APIVersion
+	if apiVersion == "" {
+		apiVersion = DefaultAzureAPIVersion
+	}
+
+	return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+		strings.TrimRight(hh.azure.Endpoint, "/"), url.PathEscape(hh.azure.Deployment), url.QueryEscape(apiVersion))
+}
+
 // handleAPIRateLimit handles rate limiting and retry logic for API calls
 func (hh *HTTPHandler) handleAPIRateLimit(err error) (shouldRetry bool, delay time.Duration) {
 	if err == nil {
@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// mcpCacheKey identifies the code location context was gathered for
+type mcpCacheKey struct {
+	sourceFile string
+	function   string
+}
+
+// mcpCacheEntry is gathered context and the time it stops being reused
+type mcpCacheEntry struct {
+	response ContextResponse
+	expires  time.Time
+}
+
+// mcpContextCache keeps context gathered from MCP servers for a TTL so
+// recurring panics in the same place do not query the servers again
+type mcpContextCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[mcpCacheKey]mcpCacheEntry
+}
+
+// newMCPContextCache creates a cache that keeps context for ttl, or nil when ttl is not positive
+func newMCPContextCache(ttl time.Duration) *mcpContextCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &mcpContextCache{ttl: ttl, entries: make(map[mcpCacheKey]mcpCacheEntry)}
+}
+
+// get returns a copy of the unexpired context cached for request
+func (c *mcpContextCache) get(request ContextRequest) (*ContextResponse, bool) {
+	key := mcpCacheKey{sourceFile: request.SourceFile, function: request.Function}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return cloneContextResponse(entry.response), true
+}
+
+// put caches response for request, dropping any entries that have expired
+func (c *mcpContextCache) put(request ContextRequest, response *ContextResponse) {
+	key := mcpCacheKey{sourceFile: request.SourceFile, function: request.Function}
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = mcpCacheEntry{response: *cloneContextResponse(*response), expires: now.Add(c.ttl)}
+}
+
+// cloneContextResponse copies response so cached context is not shared with callers
+func cloneContextResponse(response ContextResponse) *ContextResponse {
+	response.Dependencies = slices.Clone(response.Dependencies)
+	response.RelatedFiles = slices.Clone(response.RelatedFiles)
+	response.Suggestions = slices.Clone(response.Suggestions)
+	response.Sources = slices.Clone(response.Sources)
+	response.Environment = maps.Clone(response.Environment)
+	return &response
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ajeet-kumar1087/go-code-healer/internal"
@@ -41,24 +43,41 @@ type MCPClient struct {
 	httpClient *http.Client
 	logger     internal.LoggerInterface
 	timeout    time.Duration
+	cache      *mcpContextCache // nil when caching is disabled
+
+	nextID atomic.Int64 // last JSON-RPC request id
+
+	sessionsMu   sync.Mutex
+	sessions     map[string]*stdioSession // running stdio servers, keyed by server name
+	httpSessions map[string]string        // session ids of initialized HTTP servers, keyed by server name
+	closed       bool
 }
 
-// NewMCPClient creates a new MCP client with the given configuration
-func NewMCPClient(servers []MCPServerConfig, timeout time.Duration, logger internal.LoggerInterface) *MCPClient {
+// NewMCPClient creates a new MCP client with the given configuration. A nil
+// httpClient creates one with timeout for servers reached over HTTP.
+func NewMCPClient(servers []MCPServerConfig, timeout time.Duration, httpClient *http.Client, logger internal.LoggerInterface) *MCPClient {
 	if timeout == 0 {
 		timeout = 10 * time.Second
 	}
 
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: timeout}
+	}
+
 	return &MCPClient{
-		servers: servers,
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-		logger:  logger,
-		timeout: timeout,
+		servers:    servers,
+		httpClient: httpClient,
+		logger:     logger,
+		timeout:    timeout,
 	}
 }
 
+// SetCacheTTL makes GatherContext reuse context gathered for the same source
+// file and function for ttl. A ttl of 0 or less disables caching.
+func (mc *MCPClient) SetCacheTTL(ttl time.Duration) {
+	mc.cache = newMCPContextCache(ttl)
+}
+
 // GatherContext collects additional context from configured MCP servers
 func (mc *MCPClient) GatherContext(ctx context.Context, request ContextRequest) (*ContextResponse, error) {
 	if len(mc.servers) == 0 {
@@ -68,6 +87,15 @@ func (mc *MCPClient) GatherContext(ctx context.Context, request ContextRequest)
 		}, nil
 	}
 
+	if mc.cache != nil {
+		if cached, ok := mc.cache.get(request); ok {
+			if mc.logger != nil {
+				mc.logger.Debug("Using cached MCP context for %s %s", request.SourceFile, request.Function)
+			}
+			return cached, nil
+		}
+	}
+
 	response := &ContextResponse{
 		Environment:  make(map[string]string),
 		Dependencies: []string{},
@@ -77,24 +105,37 @@ func (mc *MCPClient) GatherContext(ctx context.Context, request ContextRequest)
 		Confidence:   0.0,
 	}
 
-	// Gather context from each configured MCP server
-	successCount := 0
+	// Query all servers at once so gathering takes as long as the slowest
+	// server rather than the sum of them; results are merged as they arrive
+	var (
+		wg           sync.WaitGroup
+		mergeMu      sync.Mutex
+		successCount int
+	)
 	for _, server := range mc.servers {
-		serverCtx, cancel := context.WithTimeout(ctx, mc.getServerTimeout(server))
-		defer cancel()
-
-		serverResponse, err := mc.queryMCPServer(serverCtx, server, request)
-		if err != nil {
-			if mc.logger != nil {
-				mc.logger.Warn("Failed to gather context from MCP server %s: %v", server.Name, err)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			serverCtx, cancel := context.WithTimeout(ctx, mc.getServerTimeout(server))
+			defer cancel()
+
+			serverResponse, err := mc.queryMCPServer(serverCtx, server, request)
+			if err != nil {
+				if mc.logger != nil {
+					mc.logger.Warn("Failed to gather context from MCP server %s: %v", server.Name, err)
+				}
+				return
 			}
-			continue
-		}
 
-		// Merge server response into aggregated response
-		mc.mergeContextResponse(response, serverResponse, server.Name)
-		successCount++
+			// Merge server response into aggregated response
+			mergeMu.Lock()
+			defer mergeMu.Unlock()
+			mc.mergeContextResponse(response, serverResponse, server.Name)
+			successCount++
+		}()
 	}
+	wg.Wait()
 
 	// Calculate overall confidence based on successful responses
 	if successCount > 0 {
@@ -105,6 +146,11 @@ func (mc *MCPClient) GatherContext(ctx context.Context, request ContextRequest)
 		mc.logger.Debug("Gathered context from %d/%d MCP servers", successCount, len(mc.servers))
 	}
 
+	// Only cache context some server provided, so failures are retried
+	if mc.cache != nil && successCount > 0 {
+		mc.cache.put(request, response)
+	}
+
 	return response, nil
 }
 
@@ -130,83 +176,61 @@ func (mc *MCPClient) ValidateServers(ctx context.Context) error {
 
 // queryMCPServer queries a specific MCP server for context
 func (mc *MCPClient) queryMCPServer(ctx context.Context, server MCPServerConfig, request ContextRequest) (*ContextResponse, error) {
-	// Create MCP-compliant request
-	mcpRequest := map[string]interface{}{
-		"method": "tools/call",
-		"params": map[string]interface{}{
-			"name":      "gather_context",
-			"arguments": request,
-		},
+	params := map[string]interface{}{
+		"name":      "gather_context",
+		"arguments": request,
 	}
 
-	reqBody, err := json.Marshal(mcpRequest)
+	raw, err := mc.call(ctx, server, "tools/call", params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal MCP request: %w", err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", server.Endpoint, strings.NewReader(string(reqBody)))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
-
-	// Add authentication if configured
-	mc.addAuthentication(httpReq, server)
-
-	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("User-Agent", "go-code-healer/1.0")
-
-	// Make the request
-	resp, err := mc.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("MCP server returned status %d", resp.StatusCode)
+		return nil, err
 	}
 
-	// Parse MCP response
-	var mcpResponse map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&mcpResponse); err != nil {
+	var result map[string]interface{}
+	if err := json.Unmarshal(raw, &result); err != nil {
 		return nil, fmt.Errorf("failed to decode MCP response: %w", err)
 	}
+	if isError, _ := result["isError"].(bool); isError {
+		return nil, fmt.Errorf("gather_context tool failed: %s", toolErrorText(result))
+	}
 
 	// Extract context from MCP response
-	return mc.extractContextFromMCPResponse(mcpResponse)
+	return mc.extractContextFromMCPResponse(result)
 }
 
-// validateServer validates connectivity to a specific MCP server
+// validateServer initializes a specific MCP server and checks that it offers
+// the tools the healer uses
 func (mc *MCPClient) validateServer(ctx context.Context, server MCPServerConfig) error {
-	// Create a simple ping request to validate connectivity
-	pingRequest := map[string]interface{}{
-		"method": "ping",
-		"params": map[string]interface{}{},
-	}
-
-	reqBody, err := json.Marshal(pingRequest)
+	raw, err := mc.call(ctx, server, "tools/list", nil)
 	if err != nil {
-		return fmt.Errorf("failed to marshal ping request: %w", err)
+		return err
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", server.Endpoint, strings.NewReader(string(reqBody)))
-	if err != nil {
-		return fmt.Errorf("failed to create ping request: %w", err)
+	var list struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
 	}
-
-	mc.addAuthentication(httpReq, server)
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := mc.httpClient.Do(httpReq)
-	if err != nil {
-		return fmt.Errorf("ping request failed: %w", err)
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return fmt.Errorf("failed to decode tools/list response: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("server returned status %d", resp.StatusCode)
+	available := make([]string, len(list.Tools))
+	for i, tool := range list.Tools {
+		available[i] = tool.Name
+	}
+	required := server.Tools
+	if len(required) == 0 {
+		required = []string{"gather_context"}
+	}
+	var missing []string
+	for _, tool := range required {
+		if !contains(available, tool) {
+			missing = append(missing, tool)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("server does not provide tools: %s", strings.Join(missing, ", "))
 	}
 
 	return nil
@@ -240,7 +264,8 @@ func (mc *MCPClient) getServerTimeout(server MCPServerConfig) time.Duration {
 	return mc.timeout
 }
 
-// mergeContextResponse merges a server response into the aggregated response
+// mergeContextResponse merges a server response into the aggregated response.
+// Callers merging concurrently must serialize calls for the same aggregate.
 func (mc *MCPClient) mergeContextResponse(aggregate *ContextResponse, serverResponse *ContextResponse, serverName string) {
 	// Add server to sources
 	aggregate.Sources = append(aggregate.Sources, serverName)
@@ -284,13 +309,13 @@ func (mc *MCPClient) mergeContextResponse(aggregate *ContextResponse, serverResp
 	}
 }
 
-// extractContextFromMCPResponse extracts context information from MCP response
-func (mc *MCPClient) extractContextFromMCPResponse(mcpResponse map[string]interface{}) (*ContextResponse, error) {
-	// Extract result from MCP response
-	result, ok := mcpResponse["result"].(map[string]interface{})
-	if !ok {
+// extractContextFromMCPResponse extracts context information from the result
+// of a tools/call request
+func (mc *MCPClient) extractContextFromMCPResponse(result map[string]interface{}) (*ContextResponse, error) {
+	if result == nil {
 		return nil, fmt.Errorf("invalid MCP response format: missing result")
 	}
+	result = toolCallResult(result)
 
 	response := &ContextResponse{
 		Environment:  make(map[string]string),
@@ -349,6 +374,48 @@ func (mc *MCPClient) extractContextFromMCPResponse(mcpResponse map[string]interf
 	return response, nil
 }
 
+// toolCallResult returns the context fields of a tools/call result. MCP tools
+// return structured output in structuredContent or as JSON in a text content
+// block; results that use neither are returned as they are.
+func toolCallResult(result map[string]interface{}) map[string]interface{} {
+	if structured, ok := result["structuredContent"].(map[string]interface{}); ok {
+		return structured
+	}
+	content, ok := result["content"].([]interface{})
+	if !ok {
+		return result
+	}
+	for _, item := range content {
+		block, ok := item.(map[string]interface{})
+		if !ok || block["type"] != "text" {
+			continue
+		}
+		text, _ := block["text"].(string)
+		var fields map[string]interface{}
+		if json.Unmarshal([]byte(text), &fields) == nil {
+			return fields
+		}
+	}
+	return result
+}
+
+// toolErrorText returns the text content of a failed tools/call result
+func toolErrorText(result map[string]interface{}) string {
+	var texts []string
+	content, _ := result["content"].([]interface{})
+	for _, item := range content {
+		if block, ok := item.(map[string]interface{}); ok {
+			if text, ok := block["text"].(string); ok {
+				texts = append(texts, text)
+			}
+		}
+	}
+	if len(texts) == 0 {
+		return "no details"
+	}
+	return strings.Join(texts, "; ")
+}
+
 // contains checks if a slice contains a specific string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -0,0 +1,224 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubMCPRequest is a JSON-RPC message received by the stub MCP server
+type stubMCPRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// stubMCPResponse answers request like a minimal MCP server offering a
+// gather_context tool
+func stubMCPResponse(request stubMCPRequest) rpcResponse {
+	response := rpcResponse{JSONRPC: "2.0", ID: request.ID}
+	var result any
+	switch request.Method {
+	case "initialize":
+		result = map[string]any{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "stub", "version": "1.0"},
+		}
+	case "tools/list":
+		result = map[string]any{"tools": []map[string]any{{"name": "gather_context"}}}
+	case "tools/call":
+		var params struct {
+			Arguments ContextRequest `json:"arguments"`
+		}
+		json.Unmarshal(request.Params, &params)
+		if params.Arguments.SourceFile == "unknown.go" {
+			response.Error = &rpcError{Code: -32602, Message: "unknown source file"}
+			return response
+		}
+		result = map[string]any{
+			"content": []map[string]any{{"type": "text", "text": "context for " + params.Arguments.SourceFile}},
+			"structuredContent": map[string]any{
+				"file_structure": "main.go\nhandler.go",
+				"dependencies":   []string{"net/http"},
+			},
+		}
+	default:
+		response.Error = &rpcError{Code: -32601, Message: "method not found"}
+		return response
+	}
+	response.Result, _ = json.Marshal(result)
+	return response
+}
+
+// newStubMCPServer serves the stub MCP server over HTTP. It assigns a session
+// at initialization and rejects requests that are not JSON-RPC 2.0 or that
+// do not carry the session. The returned counter counts tools/call requests.
+func newStubMCPServer(t *testing.T) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var toolCalls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request stubMCPRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.JSONRPC != "2.0" {
+			http.Error(w, "invalid JSON-RPC request", http.StatusBadRequest)
+			return
+		}
+		if request.Method == "initialize" {
+			w.Header().Set(mcpSessionHeader, "session-1")
+		} else if r.Header.Get(mcpSessionHeader) != "session-1" {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		if request.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		if request.Method == "tools/call" {
+			toolCalls.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stubMCPResponse(request))
+	}))
+	t.Cleanup(server.Close)
+	return server, &toolCalls
+}
+
+// TestMCPStubServerProcess is not a real test: it runs the stub MCP server
+// over stdin and stdout when the test binary is started as a stdio server
+func TestMCPStubServerProcess(t *testing.T) {
+	if os.Getenv("HEALER_MCP_STUB_SERVER") != "1" {
+		return
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var request stubMCPRequest
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil || request.ID == nil {
+			continue
+		}
+		encoder.Encode(stubMCPResponse(request))
+	}
+	os.Exit(0)
+}
+
+func TestMCPClientGathersContextOverEachTransport(t *testing.T) {
+	httpServer, _ := newStubMCPServer(t)
+	servers := map[string]MCPServerConfig{
+		"http": {Name: "http-stub", Endpoint: httpServer.URL},
+		"stdio": {
+			Name:      "stdio-stub",
+			Transport: "stdio",
+			Command:   os.Args[0],
+			Args:      []string{"-test.run=^TestMCPStubServerProcess$"},
+			Env:       map[string]string{"HEALER_MCP_STUB_SERVER": "1"},
+		},
+	}
+
+	for transport, server := range servers {
+		t.Run(transport, func(t *testing.T) {
+			client := NewMCPClient([]MCPServerConfig{server}, 5*time.Second, nil, nil)
+			defer client.Close()
+			ctx := context.Background()
+
+			if err := client.ValidateServers(ctx); err != nil {
+				t.Fatalf("ValidateServers failed: %v", err)
+			}
+
+			response, err := client.GatherContext(ctx, ContextRequest{SourceFile: "handler.go"})
+			if err != nil {
+				t.Fatalf("GatherContext failed: %v", err)
+			}
+			if response.FileStructure != "main.go\nhandler.go" || len(response.Dependencies) != 1 {
+				t.Errorf("unexpected context: %+v", response)
+			}
+			if len(response.Sources) != 1 || response.Sources[0] != server.Name {
+				t.Errorf("Sources = %v, want [%s]", response.Sources, server.Name)
+			}
+
+			_, err = client.queryMCPServer(ctx, server, ContextRequest{SourceFile: "unknown.go"})
+			var rpcErr *rpcError
+			if !errors.As(err, &rpcErr) || rpcErr.Code != -32602 {
+				t.Errorf("expected JSON-RPC error -32602, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMCPClientValidationRequiresConfiguredTools(t *testing.T) {
+	httpServer, _ := newStubMCPServer(t)
+	client := NewMCPClient([]MCPServerConfig{
+		{Name: "stub", Endpoint: httpServer.URL, Tools: []string{"gather_context", "parse_ast"}},
+	}, 5*time.Second, nil, nil)
+
+	err := client.ValidateServers(context.Background())
+	if err == nil {
+		t.Fatal("expected validation to fail for a tool the server does not provide")
+	}
+}
+
+func TestMCPClientCachesContextPerLocation(t *testing.T) {
+	httpServer, toolCalls := newStubMCPServer(t)
+	client := NewMCPClient([]MCPServerConfig{{Name: "stub", Endpoint: httpServer.URL}}, 5*time.Second, nil, nil)
+	client.SetCacheTTL(time.Minute)
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := client.GatherContext(ctx, ContextRequest{SourceFile: "handler.go", Function: "Serve"}); err != nil {
+			t.Fatalf("GatherContext failed: %v", err)
+		}
+	}
+	if got := toolCalls.Load(); got != 1 {
+		t.Errorf("expected repeated panics in one function to query the server once, got %d calls", got)
+	}
+
+	if _, err := client.GatherContext(ctx, ContextRequest{SourceFile: "handler.go", Function: "Close"}); err != nil {
+		t.Fatalf("GatherContext failed: %v", err)
+	}
+	if got := toolCalls.Load(); got != 2 {
+		t.Errorf("expected a different function to query the server again, got %d calls", got)
+	}
+}
+
+func TestMCPClientQueriesServersConcurrently(t *testing.T) {
+	const delay = 300 * time.Millisecond
+	var servers []MCPServerConfig
+	for _, name := range []string{"slow-a", "slow-b", "slow-c"} {
+		stub, _ := newStubMCPServer(t)
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(mcpSessionHeader) != "" {
+				time.Sleep(delay)
+			}
+			stub.Config.Handler.ServeHTTP(w, r)
+		}))
+		t.Cleanup(slow.Close)
+		servers = append(servers, MCPServerConfig{Name: name, Endpoint: slow.URL})
+	}
+	client := NewMCPClient(servers, 5*time.Second, nil, nil)
+	ctx := context.Background()
+
+	// Initialize every server first so only the tool calls are timed
+	if err := client.ValidateServers(ctx); err != nil {
+		t.Fatalf("ValidateServers failed: %v", err)
+	}
+
+	start := time.Now()
+	response, err := client.GatherContext(ctx, ContextRequest{SourceFile: "handler.go"})
+	if err != nil {
+		t.Fatalf("GatherContext failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 2*delay {
+		t.Errorf("GatherContext took %v, expected servers to be queried concurrently", elapsed)
+	}
+	if len(response.Sources) != len(servers) || response.Confidence != 1.0 {
+		t.Errorf("expected context from all %d servers, got sources %v with confidence %.2f", len(servers), response.Sources, response.Confidence)
+	}
+}
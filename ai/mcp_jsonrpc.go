@@ -0,0 +1,224 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// mcpProtocolVersion is the MCP protocol revision sent in the initialize handshake
+const mcpProtocolVersion = "2024-11-05"
+
+// mcpSessionHeader carries the session id an HTTP server assigns at initialization
+const mcpSessionHeader = "Mcp-Session-Id"
+
+// rpcRequest is a JSON-RPC 2.0 request, or a notification when ID is nil
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      *int64 `json:"id,omitempty"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is the error object of a failed JSON-RPC request
+type rpcError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("MCP error %d: %s", e.Code, e.Message)
+}
+
+// newRPCRequest builds a request with the next request id
+func (mc *MCPClient) newRPCRequest(method string, params any) rpcRequest {
+	id := mc.nextID.Add(1)
+	return rpcRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params}
+}
+
+// newRPCNotification builds a notification, which the server does not answer
+func newRPCNotification(method string) rpcRequest {
+	return rpcRequest{JSONRPC: "2.0", Method: method}
+}
+
+// initializeParams returns the params of the MCP initialize request
+func initializeParams() map[string]any {
+	return map[string]any{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "go-code-healer", "version": "1.0"},
+	}
+}
+
+// result returns the result of response to request, or its error
+func (r rpcResponse) result(request rpcRequest) (json.RawMessage, error) {
+	if r.ID == nil || request.ID == nil || *r.ID != *request.ID {
+		return nil, fmt.Errorf("MCP response id does not match request %s", request.Method)
+	}
+	if r.Error != nil {
+		return nil, r.Error
+	}
+	return r.Result, nil
+}
+
+// call sends a JSON-RPC request to server over its transport, performing the
+// initialize handshake first if the server has not been initialized yet
+func (mc *MCPClient) call(ctx context.Context, server MCPServerConfig, method string, params any) (json.RawMessage, error) {
+	if server.Transport == "stdio" {
+		session, err := mc.stdioSession(ctx, server)
+		if err != nil {
+			return nil, err
+		}
+		return session.call(ctx, mc.newRPCRequest(method, params))
+	}
+
+	sessionID, err := mc.httpSession(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	result, status, err := mc.postRPC(ctx, server, sessionID, mc.newRPCRequest(method, params))
+	if status == http.StatusNotFound && sessionID != "" {
+		// The server ended the session; initialize again on the next call
+		mc.forgetHTTPSession(server.Name)
+	}
+	return result, err
+}
+
+// httpSession returns the session id of an initialized HTTP server, running the
+// initialize handshake if needed. Servers that do not use sessions get "".
+func (mc *MCPClient) httpSession(ctx context.Context, server MCPServerConfig) (string, error) {
+	mc.sessionsMu.Lock()
+	sessionID, ok := mc.httpSessions[server.Name]
+	mc.sessionsMu.Unlock()
+	if ok {
+		return sessionID, nil
+	}
+
+	request := mc.newRPCRequest("initialize", initializeParams())
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal MCP request: %w", err)
+	}
+	resp, err := mc.postHTTP(ctx, server, "", body)
+	if err != nil {
+		return "", fmt.Errorf("initialize failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if _, err := mc.decodeRPCResponse(ctx, resp, request); err != nil {
+		return "", fmt.Errorf("initialize failed: %w", err)
+	}
+	sessionID = resp.Header.Get(mcpSessionHeader)
+
+	if _, _, err := mc.postRPC(ctx, server, sessionID, newRPCNotification("notifications/initialized")); err != nil {
+		return "", fmt.Errorf("initialize failed: %w", err)
+	}
+
+	mc.sessionsMu.Lock()
+	if mc.httpSessions == nil {
+		mc.httpSessions = make(map[string]string)
+	}
+	mc.httpSessions[server.Name] = sessionID
+	mc.sessionsMu.Unlock()
+	return sessionID, nil
+}
+
+// forgetHTTPSession drops the session of server so it is initialized again
+func (mc *MCPClient) forgetHTTPSession(name string) {
+	mc.sessionsMu.Lock()
+	delete(mc.httpSessions, name)
+	mc.sessionsMu.Unlock()
+}
+
+// postRPC posts request to an HTTP server and returns its result along with
+// the HTTP status code. Notifications return no result.
+func (mc *MCPClient) postRPC(ctx context.Context, server MCPServerConfig, sessionID string, request rpcRequest) (json.RawMessage, int, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal MCP request: %w", err)
+	}
+
+	resp, err := mc.postHTTP(ctx, server, sessionID, body)
+	if err != nil {
+		if resp != nil {
+			return nil, resp.StatusCode, err
+		}
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if request.ID == nil {
+		return nil, resp.StatusCode, nil
+	}
+	result, err := mc.decodeRPCResponse(ctx, resp, request)
+	return result, resp.StatusCode, err
+}
+
+// postHTTP sends one JSON-RPC message to an HTTP server and checks the
+// status. On an error status the response is returned with its body closed.
+func (mc *MCPClient) postHTTP(ctx context.Context, server MCPServerConfig, sessionID string, body []byte) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", server.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	mc.addAuthentication(httpReq, server)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	httpReq.Header.Set("User-Agent", "go-code-healer/1.0")
+	if sessionID != "" {
+		httpReq.Header.Set(mcpSessionHeader, sessionID)
+	}
+
+	resp, err := mc.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		resp.Body.Close()
+		return resp, fmt.Errorf("MCP server returned status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// decodeRPCResponse reads the response to request from resp. Servers may
+// answer with a single JSON object or with a stream of server-sent events
+// that ends with the response.
+func (mc *MCPClient) decodeRPCResponse(ctx context.Context, resp *http.Response, request rpcRequest) (json.RawMessage, error) {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/event-stream" {
+		var response rpcResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return nil, fmt.Errorf("failed to decode MCP response: %w", err)
+		}
+		return response.result(request)
+	}
+
+	var response *rpcResponse
+	err := readSSE(ctx, resp.Body, mc.timeout, func(_, data string) error {
+		var message rpcResponse
+		if json.Unmarshal([]byte(data), &message) != nil || message.ID == nil || *message.ID != *request.ID {
+			return nil // server requests and notifications sent before the response
+		}
+		response = &message
+		return errStreamDone
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MCP response stream: %w", err)
+	}
+	if response == nil {
+		return nil, fmt.Errorf("MCP response stream ended without a response to %s", request.Method)
+	}
+	return response.result(request)
+}
@@ -0,0 +1,227 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
+)
+
+// stdioShutdownTimeout is how long a stdio server has to exit after its stdin is closed
+const stdioShutdownTimeout = 2 * time.Second
+
+// errStdioSessionClosed is returned for calls on a stdio server that has exited
+var errStdioSessionClosed = errors.New("MCP server process exited")
+
+// stdioSession is a running stdio MCP server. Requests are written to the
+// process's stdin as newline-delimited JSON-RPC messages and a reader goroutine
+// routes responses from stdout back to the waiting caller by id.
+type stdioSession struct {
+	name   string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	logger internal.LoggerInterface
+
+	writeMu sync.Mutex // serializes messages written to stdin
+
+	mu      sync.Mutex
+	pending map[int64]chan rpcResponse
+	err     error         // set once the process has exited
+	done    chan struct{} // closed once the process has exited
+}
+
+// startStdioSession launches the server's command and performs the MCP
+// initialize handshake. The process keeps running until close is called or
+// it exits on its own.
+func (mc *MCPClient) startStdioSession(ctx context.Context, server MCPServerConfig) (*stdioSession, error) {
+	// The process outlives ctx, so it is not started with exec.CommandContext
+	cmd := exec.Command(server.Command, server.Args...)
+	cmd.Env = os.Environ()
+	for key, value := range server.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", server.Command, err)
+	}
+
+	s := &stdioSession{
+		name:    server.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		logger:  mc.logger,
+		pending: make(map[int64]chan rpcResponse),
+		done:    make(chan struct{}),
+	}
+	go s.readLoop(stdout)
+
+	if _, err := s.call(ctx, mc.newRPCRequest("initialize", initializeParams())); err != nil {
+		s.close()
+		return nil, fmt.Errorf("initialize failed: %w", err)
+	}
+	if err := s.write(newRPCNotification("notifications/initialized")); err != nil {
+		s.close()
+		return nil, fmt.Errorf("initialize failed: %w", err)
+	}
+
+	return s, nil
+}
+
+// call sends a JSON-RPC request and waits for its response
+func (s *stdioSession) call(ctx context.Context, request rpcRequest) (json.RawMessage, error) {
+	id := *request.ID
+	responses := make(chan rpcResponse, 1)
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return nil, s.err
+	}
+	s.pending[id] = responses
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+	}()
+
+	if err := s.write(request); err != nil {
+		return nil, err
+	}
+
+	select {
+	case response := <-responses:
+		return response.result(request)
+	case <-s.done:
+		return nil, s.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// write sends one newline-delimited message to the server
+func (s *stdioSession) write(message rpcRequest) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal MCP request: %w", err)
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if _, err := s.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to MCP server: %w", err)
+	}
+	return nil
+}
+
+// readLoop routes responses to their callers until stdout is closed, then
+// marks the session closed and reaps the process
+func (s *stdioSession) readLoop(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var response rpcResponse
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			if s.logger != nil {
+				s.logger.Debug("Ignoring non-JSON output from MCP server %s", s.name)
+			}
+			continue
+		}
+		// Server requests and notifications carry no id we are waiting on
+		if response.ID == nil {
+			continue
+		}
+
+		s.mu.Lock()
+		responses, ok := s.pending[*response.ID]
+		s.mu.Unlock()
+		if ok {
+			responses <- response
+		}
+	}
+
+	err := s.cmd.Wait()
+	s.mu.Lock()
+	s.err = errStdioSessionClosed
+	if err != nil {
+		s.err = fmt.Errorf("%w: %v", errStdioSessionClosed, err)
+	}
+	s.mu.Unlock()
+	close(s.done)
+}
+
+// exited reports whether the server process has exited
+func (s *stdioSession) exited() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// close stops the server by closing its stdin, as the MCP spec asks clients
+// to, and kills it if it has not exited shortly after
+func (s *stdioSession) close() {
+	s.stdin.Close()
+	select {
+	case <-s.done:
+	case <-time.After(stdioShutdownTimeout):
+		s.cmd.Process.Kill()
+		<-s.done
+	}
+}
+
+// stdioSession returns the running session for server, starting the process
+// if it is not running yet or has exited
+func (mc *MCPClient) stdioSession(ctx context.Context, server MCPServerConfig) (*stdioSession, error) {
+	mc.sessionsMu.Lock()
+	defer mc.sessionsMu.Unlock()
+
+	if mc.closed {
+		return nil, errors.New("MCP client is closed")
+	}
+	if session, ok := mc.sessions[server.Name]; ok && !session.exited() {
+		return session, nil
+	}
+
+	session, err := mc.startStdioSession(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	if mc.sessions == nil {
+		mc.sessions = make(map[string]*stdioSession)
+	}
+	mc.sessions[server.Name] = session
+	return session, nil
+}
+
+// Close stops any stdio MCP servers the client started
+func (mc *MCPClient) Close() {
+	mc.sessionsMu.Lock()
+	sessions := mc.sessions
+	mc.sessions = nil
+	mc.closed = true
+	mc.sessionsMu.Unlock()
+
+	for _, session := range sessions {
+		session.close()
+	}
+}
@@ -0,0 +1,245 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
+)
+
+// DefaultOllamaBaseURL is the generate endpoint of a locally running Ollama server
+const DefaultOllamaBaseURL = "http://localhost:11434/api/generate"
+
+// defaultOllamaTimeout is the request timeout used when none is configured.
+// Local models can be slow on CPU-only hosts.
+const defaultOllamaTimeout = 120 * time.Second
+
+// OllamaClient implements the Client interface for a self-hosted Ollama server
+type OllamaClient struct {
+	model      string
+	httpClient *http.Client
+	timeout    time.Duration
+	logger     internal.LoggerInterface
+	baseURL    string
+	params     GenerationParams
+
+	// Embedded components
+	promptGenerator *PromptGenerator
+	codeValidator   *CodeValidator
+}
+
+// NewOllamaClient creates a new Ollama client. No API key is required since
+// Ollama runs locally; baseURL defaults to DefaultOllamaBaseURL. A nil
+// httpClient creates one with timeout.
+func NewOllamaClient(baseURL, model string, timeout time.Duration, httpClient *http.Client, logger internal.LoggerInterface) *OllamaClient {
+	if baseURL == "" {
+		baseURL = DefaultOllamaBaseURL
+	}
+	if model == "" {
+		model = "codellama"
+	}
+	if timeout <= 0 {
+		timeout = defaultOllamaTimeout
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: timeout}
+	}
+
+	return &OllamaClient{
+		model:           model,
+		baseURL:         baseURL,
+		httpClient:      httpClient,
+		timeout:         timeout,
+		logger:          logger,
+		promptGenerator: NewPromptGenerator(),
+		codeValidator:   NewCodeValidator(logger),
+	}
+}
+
+// GenerateFix implements the Client interface for Ollama
+func (o *OllamaClient) GenerateFix(ctx context.Context, request FixRequest) (*FixResponse, error) {
+	// Scrub secrets that slipped past capture-time redaction
+	request = redactRequest(request)
+
+	// Add timeout to context if not already present
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	// Create Ollama API request using the shared Go-fix prompt
+	ollamaReq := ollamaRequest{
+		Model:  o.model,
+		Prompt: o.promptGenerator.GeneratePromptWithMCP(request),
+		System: o.promptGenerator.GetSystemPrompt(),
+		Stream: false,
+		Format: "json",
+		Options: &ollamaOptions{
+			Temperature: o.params.TemperatureOr(0.1), // Low temperature for more deterministic code generation
+			NumPredict:  o.params.MaxTokensOr(2000),
+			TopP:        o.params.TopPOr(0.9),
+		},
+	}
+
+	// Make API call
+	text, usage, err := o.makeOllamaAPICall(ctx, ollamaReq)
+	if err != nil {
+		return nil, fmt.Errorf("Ollama API call failed: %w", err)
+	}
+
+	// Parse response
+	fixResponse, err := o.parseOllamaResponse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Ollama response: %w", err)
+	}
+
+	// Set provider info
+	fixResponse.Provider = "ollama"
+	fixResponse.UsedMCP = request.MCPContext != nil
+	fixResponse.Model = o.model
+	fixResponse.Usage = usage
+
+	if o.logger != nil {
+		o.logger.Debug("Ollama generated fix with confidence %.2f", fixResponse.Confidence)
+	}
+
+	return fixResponse, nil
+}
+
+// SetGenerationParams overrides the sampling parameters of requests.
+func (o *OllamaClient) SetGenerationParams(params GenerationParams) {
+	o.params = params
+}
+
+// SetErrorPrompts replaces the guidance added to prompts for each error
+// category, as returned by Config.GetErrorPrompts
+func (o *OllamaClient) SetErrorPrompts(prompts map[string]string) {
+	o.promptGenerator.SetErrorPrompts(prompts)
+}
+
+// GetProviderName returns the provider name
+func (o *OllamaClient) GetProviderName() string {
+	return "ollama"
+}
+
+// ValidateConfiguration validates the Ollama client configuration
+func (o *OllamaClient) ValidateConfiguration() error {
+	if o.baseURL == "" {
+		return fmt.Errorf("Ollama base URL is required")
+	}
+	if o.model == "" {
+		return fmt.Errorf("Ollama model is required")
+	}
+	return nil
+}
+
+// makeOllamaAPICall makes an HTTP request to the Ollama generate endpoint and
+// returns the assembled completion text and token usage. Both streaming (newline-delimited
+// JSON chunks) and non-streaming (single object) replies are handled.
+func (o *OllamaClient) makeOllamaAPICall(ctx context.Context, request ollamaRequest) (string, *TokenUsage, error) {
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", o.baseURL, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.httpClient.Do(httpReq)
+	if err != nil {
+		return "", nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", nil, fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var text strings.Builder
+	usage := &TokenUsage{}
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk ollamaResponse
+		if err := decoder.Decode(&chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		if chunk.Error != "" {
+			return "", nil, fmt.Errorf("Ollama API error: %s", chunk.Error)
+		}
+
+		text.WriteString(chunk.Response)
+		if chunk.Done {
+			usage.PromptTokens = chunk.PromptEvalCount
+			usage.CompletionTokens = chunk.EvalCount
+			usage.TotalTokens = chunk.PromptEvalCount + chunk.EvalCount
+			if o.logger != nil {
+				o.logger.Debug("Ollama completion finished (prompt tokens: %d, completion tokens: %d)",
+					chunk.PromptEvalCount, chunk.EvalCount)
+			}
+			break
+		}
+	}
+
+	return text.String(), usage, nil
+}
+
+// parseOllamaResponse parses the Ollama completion text into FixResponse
+func (o *OllamaClient) parseOllamaResponse(text string) (*FixResponse, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("empty response from Ollama")
+	}
+
+	// Try to parse as JSON
+	var jsonResponse struct {
+		ProposedFix string  `json:"proposed_fix"`
+		Explanation string  `json:"explanation"`
+		Confidence  float64 `json:"confidence"`
+		fixPatch
+	}
+
+	if err := json.Unmarshal([]byte(text), &jsonResponse); err != nil {
+		// If JSON parsing fails, keep the raw text but mark it as unusable
+		return &FixResponse{
+			ProposedFix: text,
+			Explanation: "Ollama provided a text response that couldn't be parsed as JSON",
+			Confidence:  0.5,
+			IsValid:     false,
+		}, nil
+	}
+
+	// Validate confidence score
+	if jsonResponse.Confidence < 0 {
+		jsonResponse.Confidence = 0
+	} else if jsonResponse.Confidence > 1 {
+		jsonResponse.Confidence = 1
+	}
+
+	fixResponse := &FixResponse{
+		ProposedFix: trimProposedFix(jsonResponse.ProposedFix),
+		Explanation: strings.TrimSpace(jsonResponse.Explanation),
+		Confidence:  jsonResponse.Confidence,
+	}
+	jsonResponse.fixPatch.apply(fixResponse)
+	fixResponse.IsValid = o.codeValidator.ValidateFix(fixResponse)
+
+	return fixResponse, nil
+}
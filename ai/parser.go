@@ -40,6 +40,7 @@ func (rp *ResponseParser) ParseResponseWithValidation(response *openAIResponse)
 		ProposedFix string  `json:"proposed_fix"`
 		Explanation string  `json:"explanation"`
 		Confidence  float64 `json:"confidence"`
+		fixPatch
 	}
 
 	if err := json.Unmarshal([]byte(content), &jsonResponse); err != nil {
@@ -53,11 +54,12 @@ func (rp *ResponseParser) ParseResponseWithValidation(response *openAIResponse)
 
 	// Validate and sanitize the response
 	fixResponse := &FixResponse{
-		ProposedFix: strings.TrimSpace(jsonResponse.ProposedFix),
+		ProposedFix: trimProposedFix(jsonResponse.ProposedFix),
 		Explanation: strings.TrimSpace(jsonResponse.Explanation),
 		Confidence:  jsonResponse.Confidence,
 		IsValid:     false, // Will be set by validateGoSyntax
 	}
+	jsonResponse.fixPatch.apply(fixResponse)
 
 	// Validate confidence score
 	if fixResponse.Confidence < 0.0 || fixResponse.Confidence > 1.0 {
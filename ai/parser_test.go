@@ -0,0 +1,63 @@
+package ai
+
+import "testing"
+
+func TestTrimProposedFixStripsCodeFences(t *testing.T) {
+	tests := []struct {
+		name string
+		fix  string
+		want string
+	}{
+		{
+			name: "fenced with language hint",
+			fix:  "```go\n\tif p == nil {\n\t\treturn\n\t}\n```",
+			want: "\tif p == nil {\n\t\treturn\n\t}",
+		},
+		{
+			name: "double fenced",
+			fix:  "```\n```go\nreturn a / b\n```\n```\n",
+			want: "return a / b",
+		},
+		{
+			name: "single line fence",
+			fix:  "```return a / b```",
+			want: "return a / b",
+		},
+		{
+			name: "unfenced",
+			fix:  "\n\treturn a / b\n\n",
+			want: "\treturn a / b",
+		},
+		{
+			name: "backticks inside code are kept",
+			fix:  "s := \"```\"\nfmt.Println(s)",
+			want: "s := \"```\"\nfmt.Println(s)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimProposedFix(tt.fix); got != tt.want {
+				t.Errorf("trimProposedFix() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseResponseStripsFencedFix(t *testing.T) {
+	parser := NewResponseParser(nil)
+	response := &openAIResponse{Choices: []openAIChoice{{
+		Message: openAIMessage{Content: `{"proposed_fix": "` + "```go\\nif p != nil { fmt.Println(*p) }\\n```" + `", "explanation": "Add nil check", "confidence": 0.8}`},
+	}}}
+
+	fixResponse, err := parser.ParseResponseWithValidation(response)
+	if err != nil {
+		t.Fatalf("ParseResponseWithValidation failed: %v", err)
+	}
+	if fixResponse.ProposedFix != "if p != nil { fmt.Println(*p) }" {
+		t.Errorf("Expected fences to be stripped, got %q", fixResponse.ProposedFix)
+	}
+	if !NewCodeValidator(nil).ValidateFix(fixResponse) {
+		t.Error("Expected stripped fix to pass validation")
+	}
+}
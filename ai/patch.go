@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"strings"
+
+	"github.com/ajeet-kumar1087/go-code-healer/github"
+)
+
+// Patch formats a FixResponse can use to describe its proposed fix
+const (
+	PatchFormatFullFile    = github.PatchFormatFullFile
+	PatchFormatUnifiedDiff = github.PatchFormatUnifiedDiff
+	PatchFormatLineRange   = github.PatchFormatLineRange
+)
+
+// fixPatch holds the patch fields shared by every provider's JSON response
+type fixPatch struct {
+	PatchFormat string `json:"patch_format"`
+	StartLine   int    `json:"start_line"`
+	EndLine     int    `json:"end_line"`
+}
+
+// apply copies the patch description onto a FixResponse, dropping unknown formats
+// so the patch applier can infer the format from the content instead
+func (p fixPatch) apply(fixResponse *FixResponse) {
+	switch p.PatchFormat {
+	case PatchFormatFullFile, PatchFormatUnifiedDiff, PatchFormatLineRange:
+		fixResponse.PatchFormat = p.PatchFormat
+	}
+	if p.StartLine > 0 {
+		fixResponse.StartLine = p.StartLine
+		fixResponse.EndLine = max(p.EndLine, p.StartLine)
+	}
+}
+
+// trimProposedFix removes markdown code fences and surrounding blank lines
+// while keeping the indentation of the first line, which matters when the fix
+// replaces a range of lines
+func trimProposedFix(fix string) string {
+	fix = stripCodeFences(fix)
+	return strings.TrimRight(strings.TrimLeft(fix, "\r\n"), " \t\r\n")
+}
+
+// stripCodeFences removes markdown code fences, including any language hint
+// such as ```go, that wrap a proposed fix. Fences nested directly inside each
+// other are all removed; text that is not fenced is returned unchanged.
+func stripCodeFences(fix string) string {
+	for {
+		trimmed := strings.TrimSpace(fix)
+		if len(trimmed) < 6 || !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") {
+			return fix
+		}
+
+		body := strings.TrimSuffix(trimmed, "```")
+		newline := strings.IndexByte(body, '\n')
+		if newline == -1 {
+			// Fenced on a single line, so there is no room for a language hint
+			fix = strings.TrimPrefix(body, "```")
+			continue
+		}
+
+		// Drop the opening fence line with its language hint
+		fix = body[newline+1:]
+	}
+}
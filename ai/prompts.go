@@ -3,14 +3,25 @@ package ai
 import (
 	"fmt"
 	"strings"
+
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
 )
 
 // PromptGenerator handles the generation of prompts for AI requests
-type PromptGenerator struct{}
+type PromptGenerator struct {
+	errorPrompts map[string]string // guidance by error category
+}
 
-// NewPromptGenerator creates a new prompt generator
+// NewPromptGenerator creates a new prompt generator using the default
+// guidance for each error category
 func NewPromptGenerator() *PromptGenerator {
-	return &PromptGenerator{}
+	return &PromptGenerator{errorPrompts: internal.DefaultErrorPrompts}
+}
+
+// SetErrorPrompts replaces the guidance added to prompts for each error
+// category, as returned by Config.GetErrorPrompts
+func (pg *PromptGenerator) SetErrorPrompts(prompts map[string]string) {
+	pg.errorPrompts = prompts
 }
 
 // GeneratePrompt creates a structured prompt for Go code fixes (legacy method)
@@ -51,6 +62,8 @@ func (pg *PromptGenerator) GeneratePromptWithMCP(request FixRequest) string {
 		pg.addMCPContextToPrompt(&prompt, request.MCPContext)
 	}
 
+	prompt.WriteString(errorGuidance(request, pg.errorPrompts))
+
 	prompt.WriteString("Please provide:\n")
 	prompt.WriteString("1. A corrected version of the problematic code\n")
 	prompt.WriteString("2. A clear explanation of what caused the error\n")
@@ -59,14 +72,34 @@ func (pg *PromptGenerator) GeneratePromptWithMCP(request FixRequest) string {
 
 	prompt.WriteString("Format your response as JSON with the following structure:\n")
 	prompt.WriteString("{\n")
-	prompt.WriteString("  \"proposed_fix\": \"// Your corrected Go code here\",\n")
+	prompt.WriteString("  \"proposed_fix\": \"// Replacement Go code here\",\n")
+	prompt.WriteString("  \"patch_format\": \"line_range\",\n")
+	prompt.WriteString("  \"start_line\": 42,\n")
+	prompt.WriteString("  \"end_line\": 45,\n")
 	prompt.WriteString("  \"explanation\": \"Detailed explanation of the fix\",\n")
 	prompt.WriteString("  \"confidence\": 0.85\n")
-	prompt.WriteString("}")
+	prompt.WriteString("}\n\n")
+	prompt.WriteString(patchFormatInstructions)
 
 	return prompt.String()
 }
 
+// patchFormatInstructions explains how the proposed fix is merged into the source file
+const patchFormatInstructions = `The source code context is shown with line numbers. Prefer "patch_format": "line_range": ` +
+	`set start_line and end_line to the original lines being replaced and proposed_fix to only the replacement lines, ` +
+	`with their original indentation. Use "unified_diff" with proposed_fix containing a unified diff for changes ` +
+	`spanning distant lines, and "full_file" only if proposed_fix is the complete file.`
+
+// errorGuidance returns the prompt section with the guidance in prompts for
+// the error category of request, or "" if there is none
+func errorGuidance(request FixRequest, prompts map[string]string) string {
+	guidance := strings.TrimSpace(prompts[(&CodeValidator{}).ClassifyError(request)])
+	if guidance == "" {
+		return ""
+	}
+	return "## Guidance for This Error\n" + guidance + "\n\n"
+}
+
 // GetSystemPrompt returns the system prompt for the AI
 func (pg *PromptGenerator) GetSystemPrompt() string {
 	return `You are an expert Go developer specializing in debugging and fixing runtime errors. 
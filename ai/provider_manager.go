@@ -2,8 +2,12 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ajeet-kumar1087/go-code-healer/internal"
@@ -16,8 +20,41 @@ type ProviderManager struct {
 	logger     internal.LoggerInterface
 	maxRetries int
 	retryDelay time.Duration
+	counters   map[string]*providerCounters        // keyed by provider name, fixed after construction
+	pricing    map[string]float64                  // USD per 1K tokens, keyed by model or provider name
+	cache      *fixCache                           // nil when caching is disabled
+	breakers   map[string]*internal.CircuitBreaker // keyed by provider name, fixed after construction
+	tracer     internal.Tracer                     // nil disables tracing
+	queryAll   bool                                // query every provider and keep the most confident fix
 }
 
+// ProviderStats holds fix-generation outcome counts and token usage for a single provider
+type ProviderStats struct {
+	Successes        int64   `json:"successes"`
+	Failures         int64   `json:"failures"`
+	Requests         int64   `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	EstimatedCost    float64 `json:"estimated_cost_usd"`
+}
+
+// providerCounters tracks fix outcomes and token usage for a provider across concurrent workers
+type providerCounters struct {
+	successes        atomic.Int64
+	failures         atomic.Int64
+	requests         atomic.Int64
+	promptTokens     atomic.Int64
+	completionTokens atomic.Int64
+	totalTokens      atomic.Int64
+
+	costMu sync.Mutex
+	cost   float64
+}
+
+// errCircuitOpen is returned for providers skipped because their circuit breaker is open
+var errCircuitOpen = errors.New("circuit breaker is OPEN")
+
 // ProviderConfig holds configuration for AI providers
 type ProviderConfig struct {
 	Primary    string   `json:"primary"`   // Primary provider name
@@ -26,63 +63,175 @@ type ProviderConfig struct {
 	RetryDelay int      `json:"retry_delay_seconds"`
 }
 
+// Order returns the providers to try, primary first, without duplicates
+func (pc ProviderConfig) Order() []string {
+	order := []string{pc.Primary}
+	for _, name := range pc.Fallbacks {
+		if !slices.Contains(order, name) {
+			order = append(order, name)
+		}
+	}
+	return order
+}
+
 // NewProviderManager creates a new provider manager
 func NewProviderManager(config internal.Config, logger internal.LoggerInterface) (*ProviderManager, error) {
 	var providers []Client
 
+	// Every client shares one transport and so one connection pool
+	clients, err := internal.NewHTTPClientFactory(&config)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create MCP client if enabled
 	var mcpClient *MCPClient
 	if config.MCPEnabled && len(config.MCPServers) > 0 {
 		mcpTimeout := time.Duration(config.MCPTimeout) * time.Second
-		mcpClient = NewMCPClient(config.MCPServers, mcpTimeout, logger)
+		mcpClient = NewMCPClient(config.MCPServers, mcpTimeout, clients.Client(mcpTimeout), logger)
+		mcpClient.SetCacheTTL(config.GetMCPCacheTTL())
 	}
 
-	// Create AI providers based on configuration
-	switch config.AIProvider {
-	case "openai":
+	// Create AI providers based on configuration. An injected client replaces
+	// them all; an explicit fallback list fixes the exact order; otherwise
+	// every provider with credentials is a fallback.
+	switch {
+	case config.AIClient != nil:
+		client, ok := config.AIClient.(Client)
+		if !ok {
+			return nil, fmt.Errorf("config AIClient of type %T does not implement ai.Client", config.AIClient)
+		}
+		providers = append(providers, client)
+
+	case len(config.FallbackProviders) > 0:
+		providerConfig := ProviderConfig{Primary: config.AIProvider, Fallbacks: config.FallbackProviders}
+		for _, name := range providerConfig.Order() {
+			provider, err := newProvider(name, config, clients, logger)
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, provider)
+		}
+
+	case config.AIProvider == "openai":
 		if config.OpenAIAPIKey != "" {
-			openaiClient := NewOpenAIClient(config.OpenAIAPIKey, config.OpenAIModel, logger)
+			openaiClient := newOpenAIClient(config, clients, logger)
 			providers = append(providers, openaiClient)
 		}
 		// Add fallback providers
 		if config.ClaudeAPIKey != "" {
-			claudeClient := NewClaudeClient(config.ClaudeAPIKey, config.ClaudeModel, logger)
+			claudeClient := newClaudeClient(config, clients, logger)
 			providers = append(providers, claudeClient)
 		}
 		if config.CodexAPIKey != "" {
-			codexClient := NewCodexClient(config.CodexAPIKey, config.CodexModel, logger)
+			codexClient := newCodexClient(config, clients, logger)
 			providers = append(providers, codexClient)
 		}
+		if config.GeminiAPIKey != "" {
+			geminiClient := newGeminiClient(config, clients, logger)
+			providers = append(providers, geminiClient)
+		}
 
-	case "claude":
+	case config.AIProvider == "claude":
 		if config.ClaudeAPIKey != "" {
-			claudeClient := NewClaudeClient(config.ClaudeAPIKey, config.ClaudeModel, logger)
+			claudeClient := newClaudeClient(config, clients, logger)
 			providers = append(providers, claudeClient)
 		}
 		// Add fallback providers
 		if config.OpenAIAPIKey != "" {
-			openaiClient := NewOpenAIClient(config.OpenAIAPIKey, config.OpenAIModel, logger)
+			openaiClient := newOpenAIClient(config, clients, logger)
 			providers = append(providers, openaiClient)
 		}
 		if config.CodexAPIKey != "" {
-			codexClient := NewCodexClient(config.CodexAPIKey, config.CodexModel, logger)
+			codexClient := newCodexClient(config, clients, logger)
 			providers = append(providers, codexClient)
 		}
+		if config.GeminiAPIKey != "" {
+			geminiClient := newGeminiClient(config, clients, logger)
+			providers = append(providers, geminiClient)
+		}
 
-	case "codex":
+	case config.AIProvider == "codex":
 		if config.CodexAPIKey != "" {
-			codexClient := NewCodexClient(config.CodexAPIKey, config.CodexModel, logger)
+			codexClient := newCodexClient(config, clients, logger)
 			providers = append(providers, codexClient)
 		}
 		// Add fallback providers
 		if config.OpenAIAPIKey != "" {
-			openaiClient := NewOpenAIClient(config.OpenAIAPIKey, config.OpenAIModel, logger)
+			openaiClient := newOpenAIClient(config, clients, logger)
 			providers = append(providers, openaiClient)
 		}
 		if config.ClaudeAPIKey != "" {
-			claudeClient := NewClaudeClient(config.ClaudeAPIKey, config.ClaudeModel, logger)
+			claudeClient := newClaudeClient(config, clients, logger)
 			providers = append(providers, claudeClient)
 		}
+		if config.GeminiAPIKey != "" {
+			geminiClient := newGeminiClient(config, clients, logger)
+			providers = append(providers, geminiClient)
+		}
+
+	case config.AIProvider == "gemini":
+		if config.GeminiAPIKey != "" {
+			geminiClient := newGeminiClient(config, clients, logger)
+			providers = append(providers, geminiClient)
+		}
+		// Add fallback providers
+		if config.OpenAIAPIKey != "" {
+			openaiClient := newOpenAIClient(config, clients, logger)
+			providers = append(providers, openaiClient)
+		}
+		if config.ClaudeAPIKey != "" {
+			claudeClient := newClaudeClient(config, clients, logger)
+			providers = append(providers, claudeClient)
+		}
+		if config.CodexAPIKey != "" {
+			codexClient := newCodexClient(config, clients, logger)
+			providers = append(providers, codexClient)
+		}
+
+	case config.AIProvider == "ollama":
+		// Ollama is self-hosted, so it is only used when explicitly selected
+		ollamaClient := newOllamaClient(config, clients, logger)
+		providers = append(providers, ollamaClient)
+		// Add fallback providers
+		if config.OpenAIAPIKey != "" {
+			openaiClient := newOpenAIClient(config, clients, logger)
+			providers = append(providers, openaiClient)
+		}
+		if config.ClaudeAPIKey != "" {
+			claudeClient := newClaudeClient(config, clients, logger)
+			providers = append(providers, claudeClient)
+		}
+		if config.CodexAPIKey != "" {
+			codexClient := newCodexClient(config, clients, logger)
+			providers = append(providers, codexClient)
+		}
+		if config.GeminiAPIKey != "" {
+			geminiClient := newGeminiClient(config, clients, logger)
+			providers = append(providers, geminiClient)
+		}
+
+	case config.AIProvider == "bedrock":
+		// Bedrock needs no API key, so it is only used when explicitly selected
+		bedrockClient := newBedrockClient(config, clients, logger)
+		providers = append(providers, bedrockClient)
+		// Add fallback providers
+		if config.OpenAIAPIKey != "" {
+			openaiClient := newOpenAIClient(config, clients, logger)
+			providers = append(providers, openaiClient)
+		}
+		if config.ClaudeAPIKey != "" {
+			claudeClient := newClaudeClient(config, clients, logger)
+			providers = append(providers, claudeClient)
+		}
+		if config.CodexAPIKey != "" {
+			codexClient := newCodexClient(config, clients, logger)
+			providers = append(providers, codexClient)
+		}
+		if config.GeminiAPIKey != "" {
+			geminiClient := newGeminiClient(config, clients, logger)
+			providers = append(providers, geminiClient)
+		}
 
 	default:
 		return nil, fmt.Errorf("unsupported AI provider: %s", config.AIProvider)
@@ -97,17 +246,149 @@ func NewProviderManager(config internal.Config, logger internal.LoggerInterface)
 		maxRetries = 3
 	}
 
+	counters := make(map[string]*providerCounters, len(providers))
+	breakers := make(map[string]*internal.CircuitBreaker, len(providers))
+	for _, provider := range providers {
+		counters[provider.GetProviderName()] = &providerCounters{}
+		breakers[provider.GetProviderName()] = internal.NewCircuitBreaker(internal.DefaultCircuitBreakerConfig(), logger)
+	}
+
 	return &ProviderManager{
 		providers:  providers,
 		mcpClient:  mcpClient,
 		logger:     logger,
 		maxRetries: maxRetries,
 		retryDelay: 2 * time.Second,
+		counters:   counters,
+		pricing:    config.ModelPricing,
+		cache:      newFixCache(config.GetFixCacheSize()),
+		breakers:   breakers,
+		tracer:     config.Tracer,
+		queryAll:   config.QueryAllProviders,
 	}, nil
 }
 
+// newProvider creates the named provider, failing if its credentials are missing
+func newProvider(name string, config internal.Config, clients *internal.HTTPClientFactory, logger internal.LoggerInterface) (Client, error) {
+	switch name {
+	case "openai":
+		if config.OpenAIAPIKey == "" {
+			return nil, fmt.Errorf("provider openai has no API key configured")
+		}
+		return newOpenAIClient(config, clients, logger), nil
+	case "claude":
+		if config.ClaudeAPIKey == "" {
+			return nil, fmt.Errorf("provider claude has no API key configured")
+		}
+		return newClaudeClient(config, clients, logger), nil
+	case "codex":
+		if config.CodexAPIKey == "" {
+			return nil, fmt.Errorf("provider codex has no API key configured")
+		}
+		return newCodexClient(config, clients, logger), nil
+	case "gemini":
+		if config.GeminiAPIKey == "" {
+			return nil, fmt.Errorf("provider gemini has no API key configured")
+		}
+		return newGeminiClient(config, clients, logger), nil
+	case "ollama":
+		return newOllamaClient(config, clients, logger), nil
+	case "bedrock":
+		if config.BedrockRegion == "" {
+			return nil, fmt.Errorf("provider bedrock has no region configured")
+		}
+		return newBedrockClient(config, clients, logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported AI provider: %s", name)
+	}
+}
+
+// newOpenAIClient creates an OpenAI client, routed through Azure or an
+// OpenAI-compatible endpoint when one is configured
+func newOpenAIClient(config internal.Config, clients *internal.HTTPClientFactory, logger internal.LoggerInterface) *OpenAIClient {
+	timeout := config.GetAITimeout("openai")
+	var client *OpenAIClient
+	if config.AzureEndpoint != "" {
+		azure := AzureConfig{
+			Endpoint:   config.AzureEndpoint,
+			Deployment: config.AzureDeployment,
+			APIVersion: config.AzureAPIVersion,
+		}
+		client = NewAzureOpenAIClient(config.OpenAIAPIKey, config.OpenAIModel, azure, timeout, clients.Client(timeout), logger)
+	} else if config.OpenAICompatibleBaseURL != "" {
+		client = NewOpenAICompatibleClient(config.OpenAIAPIKey, config.OpenAIModel, config.OpenAICompatibleBaseURL,
+			config.OpenAICompatibleAuthHeader, timeout, clients.Client(timeout), logger)
+	} else {
+		client = NewOpenAIClient(config.OpenAIAPIKey, config.OpenAIModel, timeout, clients.Client(timeout), logger)
+	}
+	client.SetStreaming(config.StreamResponses)
+	client.SetGenerationParams(config.GetAIGenerationParams("openai"))
+	client.SetErrorPrompts(config.GetErrorPrompts())
+	return client
+}
+
+// newClaudeClient creates a Claude client from config
+func newClaudeClient(config internal.Config, clients *internal.HTTPClientFactory, logger internal.LoggerInterface) *ClaudeClient {
+	timeout := config.GetAITimeout("claude")
+	client := NewClaudeClient(config.ClaudeAPIKey, config.ClaudeModel, timeout, clients.Client(timeout), logger)
+	client.SetStreaming(config.StreamResponses)
+	client.SetGenerationParams(config.GetAIGenerationParams("claude"))
+	client.SetErrorPrompts(config.GetErrorPrompts())
+	return client
+}
+
+// newCodexClient creates a Codex client from config
+func newCodexClient(config internal.Config, clients *internal.HTTPClientFactory, logger internal.LoggerInterface) *CodexClient {
+	timeout := config.GetAITimeout("codex")
+	client := NewCodexClient(config.CodexAPIKey, config.CodexModel, timeout, clients.Client(timeout), logger)
+	client.SetGenerationParams(config.GetAIGenerationParams("codex"))
+	client.SetErrorPrompts(config.GetErrorPrompts())
+	return client
+}
+
+// newGeminiClient creates a Gemini client from config
+func newGeminiClient(config internal.Config, clients *internal.HTTPClientFactory, logger internal.LoggerInterface) *GeminiClient {
+	timeout := config.GetAITimeout("gemini")
+	client := NewGeminiClient(config.GeminiAPIKey, config.GeminiModel, timeout, clients.Client(timeout), logger)
+	client.SetGenerationParams(config.GetAIGenerationParams("gemini"))
+	client.SetErrorPrompts(config.GetErrorPrompts())
+	return client
+}
+
+// newOllamaClient creates an Ollama client from config
+func newOllamaClient(config internal.Config, clients *internal.HTTPClientFactory, logger internal.LoggerInterface) *OllamaClient {
+	timeout := config.GetAITimeout("ollama")
+	client := NewOllamaClient(config.OllamaBaseURL, config.OllamaModel, timeout, clients.Client(timeout), logger)
+	client.SetGenerationParams(config.GetAIGenerationParams("ollama"))
+	client.SetErrorPrompts(config.GetErrorPrompts())
+	return client
+}
+
+// newBedrockClient creates a Bedrock client from config
+func newBedrockClient(config internal.Config, clients *internal.HTTPClientFactory, logger internal.LoggerInterface) *BedrockClient {
+	timeout := config.GetAITimeout("bedrock")
+	client := NewBedrockClient(config.BedrockRegion, config.BedrockModelID, timeout, clients.Client(timeout), logger)
+	client.SetGenerationParams(config.GetAIGenerationParams("bedrock"))
+	client.SetErrorPrompts(config.GetErrorPrompts())
+	return client
+}
+
 // GenerateFixWithFallback attempts fix generation with primary provider, falls back to others
 func (pm *ProviderManager) GenerateFixWithFallback(ctx context.Context, request FixRequest) (*FixResponse, error) {
+	// Recurring panics reuse the fix generated the first time
+	var cacheKey string
+	if pm.cache != nil {
+		cacheKey = fixCacheKey(request)
+		if cached, ok := pm.cache.get(cacheKey); ok {
+			cached.FromCache = true
+			cached.Usage = nil // no tokens were spent on this response
+			if pm.logger != nil {
+				pm.logger.Info("Using cached fix from provider %s (confidence: %.2f)", cached.Provider, cached.Confidence)
+			}
+			return cached, nil
+		}
+	}
+
 	// Enhance request with MCP context if available
 	if pm.mcpClient != nil {
 		mcpContext, err := pm.gatherMCPContext(ctx, request)
@@ -120,62 +401,41 @@ func (pm *ProviderManager) GenerateFixWithFallback(ctx context.Context, request
 		}
 	}
 
+	if pm.queryAll {
+		return pm.generateFromAllProviders(ctx, request, cacheKey)
+	}
+
 	var lastError error
 	var bestResponse *FixResponse
+	var consulted []string
 
 	// Try each provider in order
-	for i, provider := range pm.providers {
-		if pm.logger != nil {
-			pm.logger.Debug("Attempting fix generation with provider: %s", provider.GetProviderName())
-		}
-
-		// Optimize request for specific provider
-		optimizedRequest := pm.optimizeRequestForProvider(request, provider.GetProviderName())
-
-		// Try with retries for each provider
-		for attempt := 0; attempt < pm.maxRetries; attempt++ {
-			response, err := provider.GenerateFix(ctx, optimizedRequest)
-			if err == nil && response != nil {
-				// Check if this is a valid response
-				if pm.isValidResponse(response) {
-					if pm.logger != nil {
-						pm.logger.Info("Successfully generated fix with provider %s (attempt %d, confidence: %.2f)",
-							provider.GetProviderName(), attempt+1, response.Confidence)
-					}
-					return response, nil
-				}
-
-				// Keep track of best response even if not fully valid
-				if bestResponse == nil || response.Confidence > bestResponse.Confidence {
-					bestResponse = response
-				}
+	for _, provider := range pm.providers {
+		response, valid, err := pm.tryProvider(ctx, provider, request)
+		if !errors.Is(err, errCircuitOpen) {
+			consulted = append(consulted, provider.GetProviderName())
+		}
+		if valid {
+			response.ConsultedProviders = consulted
+			if pm.cache != nil {
+				pm.cache.put(cacheKey, response)
 			}
+			return response, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 
+		// Keep track of best response even if not fully valid
+		if response != nil && (bestResponse == nil || response.Confidence > bestResponse.Confidence) {
+			bestResponse = response
+		}
+		if err != nil {
 			lastError = err
-			if pm.logger != nil {
-				pm.logger.Warn("Provider %s attempt %d failed: %v",
-					provider.GetProviderName(), attempt+1, err)
-			}
-
-			// Wait before retry (except for last attempt)
-			if attempt < pm.maxRetries-1 {
-				select {
-				case <-ctx.Done():
-					return nil, ctx.Err()
-				case <-time.After(pm.retryDelay):
-					// Continue to next attempt
-				}
-			}
 		}
 
 		if pm.logger != nil {
-			pm.logger.Warn("Provider %s failed after %d attempts, trying next provider",
-				provider.GetProviderName(), pm.maxRetries)
-		}
-
-		// If this is not the last provider, continue to next
-		if i < len(pm.providers)-1 {
-			continue
+			pm.logger.Warn("Provider %s failed, trying next provider", provider.GetProviderName())
 		}
 	}
 
@@ -185,12 +445,247 @@ func (pm *ProviderManager) GenerateFixWithFallback(ctx context.Context, request
 			pm.logger.Warn("No fully valid response found, returning best response with confidence %.2f",
 				bestResponse.Confidence)
 		}
+		bestResponse.ConsultedProviders = consulted
 		return bestResponse, nil
 	}
 
 	return nil, fmt.Errorf("all AI providers failed, last error: %w", lastError)
 }
 
+// generateFromAllProviders queries every provider in parallel and returns the
+// highest-confidence valid fix, or the highest-confidence response when none
+// is valid
+func (pm *ProviderManager) generateFromAllProviders(ctx context.Context, request FixRequest, cacheKey string) (*FixResponse, error) {
+	type providerResult struct {
+		response *FixResponse
+		valid    bool
+		err      error
+	}
+
+	results := make([]providerResult, len(pm.providers))
+	var wg sync.WaitGroup
+	for i, provider := range pm.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, valid, err := pm.tryProvider(ctx, provider, request)
+			results[i] = providerResult{response: response, valid: valid, err: err}
+		}()
+	}
+	wg.Wait()
+
+	var best *FixResponse
+	var bestValid bool
+	var lastError error
+	var consulted []string
+	for i, result := range results {
+		if result.err != nil {
+			lastError = result.err
+		}
+		if errors.Is(result.err, errCircuitOpen) {
+			continue
+		}
+		consulted = append(consulted, pm.providers[i].GetProviderName())
+		if result.response == nil {
+			continue
+		}
+
+		// A valid fix always beats an invalid one, then confidence decides
+		better := best == nil ||
+			(result.valid && !bestValid) ||
+			(result.valid == bestValid && result.response.Confidence > best.Confidence)
+		if better {
+			best, bestValid = result.response, result.valid
+		}
+	}
+
+	if best == nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("all AI providers failed, last error: %w", lastError)
+	}
+
+	best.ConsultedProviders = consulted
+	if bestValid {
+		if pm.cache != nil {
+			pm.cache.put(cacheKey, best)
+		}
+		if pm.logger != nil {
+			pm.logger.Info("Selected fix from provider %s out of %d consulted (confidence: %.2f)",
+				best.Provider, len(consulted), best.Confidence)
+		}
+	} else if pm.logger != nil {
+		pm.logger.Warn("No fully valid response found, returning best response with confidence %.2f",
+			best.Confidence)
+	}
+	return best, nil
+}
+
+// tryProvider asks one provider for a fix, retrying failed attempts. It returns
+// the first valid response, or otherwise the highest-confidence response seen
+// and the last error, which wraps errCircuitOpen if the provider was skipped.
+func (pm *ProviderManager) tryProvider(ctx context.Context, provider Client, request FixRequest) (*FixResponse, bool, error) {
+	name := provider.GetProviderName()
+	breaker := pm.breakers[name]
+
+	// Skip providers whose own circuit breaker is open
+	if breaker != nil && !breaker.Allow() {
+		if pm.logger != nil {
+			pm.logger.Warn("Skipping provider %s: circuit breaker is open", name)
+		}
+		return nil, false, fmt.Errorf("%w for provider %s", errCircuitOpen, name)
+	}
+
+	if pm.logger != nil {
+		pm.logger.Debug("Attempting fix generation with provider: %s", name)
+	}
+
+	// Optimize request for specific provider
+	optimizedRequest := pm.optimizeRequestForProvider(request, name)
+
+	var bestResponse *FixResponse
+	var lastError error
+
+	// Try with retries for each provider
+	for attempt := 0; attempt < pm.maxRetries; attempt++ {
+		// Stop retrying once repeated failures have opened the breaker
+		if attempt > 0 && breaker != nil && !breaker.Allow() {
+			break
+		}
+
+		response, err := provider.GenerateFix(ctx, optimizedRequest)
+		pm.recordUsage(name, response)
+		if breaker != nil {
+			breaker.RecordResult(name, err)
+		}
+		if err == nil && response != nil {
+			// Check if this is a valid response
+			if pm.isValidResponse(response) {
+				pm.recordOutcome(name, true)
+				if pm.logger != nil {
+					pm.logger.Info("Successfully generated fix with provider %s (attempt %d, confidence: %.2f)",
+						name, attempt+1, response.Confidence)
+				}
+				return response, true, nil
+			}
+
+			// Keep track of best response even if not fully valid
+			if bestResponse == nil || response.Confidence > bestResponse.Confidence {
+				bestResponse = response
+			}
+		}
+
+		pm.recordOutcome(name, false)
+		if err != nil {
+			lastError = err
+		}
+		if pm.logger != nil {
+			pm.logger.Warn("Provider %s attempt %d failed: %v", name, attempt+1, err)
+		}
+
+		// Wait before retry (except for last attempt)
+		if attempt < pm.maxRetries-1 {
+			select {
+			case <-ctx.Done():
+				return bestResponse, false, ctx.Err()
+			case <-time.After(pm.retryDelay):
+				// Continue to next attempt
+			}
+		}
+	}
+
+	if bestResponse == nil && lastError == nil {
+		lastError = fmt.Errorf("provider %s returned no valid fix", name)
+	}
+	return bestResponse, false, lastError
+}
+
+// recordOutcome increments the success or failure counter for a provider
+func (pm *ProviderManager) recordOutcome(providerName string, success bool) {
+	counters, ok := pm.counters[providerName]
+	if !ok {
+		return
+	}
+	if success {
+		counters.successes.Add(1)
+	} else {
+		counters.failures.Add(1)
+	}
+}
+
+// recordUsage counts a request against a provider and accumulates the token
+// usage and estimated cost reported in its response
+func (pm *ProviderManager) recordUsage(providerName string, response *FixResponse) {
+	counters, ok := pm.counters[providerName]
+	if !ok {
+		return
+	}
+	counters.requests.Add(1)
+
+	if response == nil || response.Usage == nil {
+		return
+	}
+	usage := response.Usage
+	counters.promptTokens.Add(int64(usage.PromptTokens))
+	counters.completionTokens.Add(int64(usage.CompletionTokens))
+	counters.totalTokens.Add(int64(usage.TotalTokens))
+
+	if price, ok := pm.pricePerThousand(providerName, response.Model); ok {
+		counters.costMu.Lock()
+		counters.cost += float64(usage.TotalTokens) / 1000 * price
+		counters.costMu.Unlock()
+	}
+}
+
+// pricePerThousand looks up the configured price for a model, falling back to the provider price
+func (pm *ProviderManager) pricePerThousand(providerName, model string) (float64, bool) {
+	if model != "" {
+		if price, ok := pm.pricing[model]; ok {
+			return price, true
+		}
+	}
+	price, ok := pm.pricing[providerName]
+	return price, ok
+}
+
+// GetProviderStats returns fix-generation outcome counts and token usage for each provider
+func (pm *ProviderManager) GetProviderStats() map[string]ProviderStats {
+	stats := make(map[string]ProviderStats, len(pm.counters))
+	for name, counters := range pm.counters {
+		counters.costMu.Lock()
+		cost := counters.cost
+		counters.costMu.Unlock()
+
+		stats[name] = ProviderStats{
+			Successes:        counters.successes.Load(),
+			Failures:         counters.failures.Load(),
+			Requests:         counters.requests.Load(),
+			PromptTokens:     counters.promptTokens.Load(),
+			CompletionTokens: counters.completionTokens.Load(),
+			TotalTokens:      counters.totalTokens.Load(),
+			EstimatedCost:    cost,
+		}
+	}
+	return stats
+}
+
+// GetCircuitBreakerStates returns the circuit breaker state of each provider
+func (pm *ProviderManager) GetCircuitBreakerStates() map[string]internal.CircuitBreakerState {
+	states := make(map[string]internal.CircuitBreakerState, len(pm.breakers))
+	for name, breaker := range pm.breakers {
+		states[name] = breaker.GetState()
+	}
+	return states
+}
+
+// ResetCircuitBreakers closes the circuit breaker of every provider
+func (pm *ProviderManager) ResetCircuitBreakers() {
+	for _, breaker := range pm.breakers {
+		breaker.Reset()
+	}
+}
+
 // optimizeRequestForProvider optimizes the request for a specific provider
 func (pm *ProviderManager) optimizeRequestForProvider(request FixRequest, providerName string) FixRequest {
 	optimized := request
@@ -205,6 +700,9 @@ func (pm *ProviderManager) optimizeRequestForProvider(request FixRequest, provid
 	case "openai":
 		// OpenAI works well with the standard format
 		optimized = pm.optimizeForOpenAI(request)
+	case "gemini":
+		// Gemini handles long context well but benefits from explicit hints
+		optimized = pm.optimizeForGemini(request)
 	}
 
 	return optimized
@@ -269,6 +767,33 @@ func (pm *ProviderManager) optimizeForOpenAI(request FixRequest) FixRequest {
 	return optimized
 }
 
+// optimizeForGemini optimizes the request for Gemini's preferences
+func (pm *ProviderManager) optimizeForGemini(request FixRequest) FixRequest {
+	optimized := request
+
+	// Gemini has a large context window, so keep the full stack trace and
+	// fold MCP suggestions into the context when none was provided
+	if optimized.Context == "" && optimized.MCPContext != nil && len(optimized.MCPContext.Suggestions) > 0 {
+		optimized.Context = "Additional insights: " + strings.Join(optimized.MCPContext.Suggestions, "; ")
+	}
+
+	// Give the model an explicit error classification to anchor its answer
+	if optimized.Metadata == nil {
+		optimized.Metadata = make(map[string]string)
+	}
+	if _, ok := optimized.Metadata["error_type"]; !ok {
+		if strings.Contains(optimized.Error, "nil pointer") {
+			optimized.Metadata["error_type"] = "nil_pointer"
+		} else if strings.Contains(optimized.Error, "index out of range") {
+			optimized.Metadata["error_type"] = "bounds_check"
+		} else if strings.Contains(optimized.Error, "concurrent map") {
+			optimized.Metadata["error_type"] = "concurrency"
+		}
+	}
+
+	return optimized
+}
+
 // isValidResponse checks if a response is valid and usable
 func (pm *ProviderManager) isValidResponse(response *FixResponse) bool {
 	if response == nil {
@@ -295,6 +820,9 @@ func (pm *ProviderManager) isValidResponse(response *FixResponse) bool {
 
 // gatherMCPContext collects context using MCP tools
 func (pm *ProviderManager) gatherMCPContext(ctx context.Context, request FixRequest) (*ContextResponse, error) {
+	ctx, span := internal.StartSpan(ctx, pm.tracer, "healer.mcp_context")
+	defer span.End()
+
 	mcpRequest := ContextRequest{
 		ErrorType:  request.Error,
 		StackTrace: request.StackTrace,
@@ -311,7 +839,11 @@ func (pm *ProviderManager) gatherMCPContext(ctx context.Context, request FixRequ
 		}
 	}
 
-	return pm.mcpClient.GatherContext(ctx, mcpRequest)
+	response, err := pm.mcpClient.GatherContext(ctx, mcpRequest)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return response, err
 }
 
 // CreateSession creates a new AI session for comprehensive error handling
@@ -351,6 +883,14 @@ func (pm *ProviderManager) ValidateProviders() error {
 	return nil
 }
 
+// Close releases resources held by the provider manager, stopping any stdio
+// MCP servers it started
+func (pm *ProviderManager) Close() {
+	if pm.mcpClient != nil {
+		pm.mcpClient.Close()
+	}
+}
+
 // GetProviderStatus returns status information for all providers
 func (pm *ProviderManager) GetProviderStatus() map[string]interface{} {
 	status := make(map[string]interface{})
@@ -368,5 +908,32 @@ func (pm *ProviderManager) GetProviderStatus() map[string]interface{} {
 	status["mcp_enabled"] = pm.mcpClient != nil
 	status["max_retries"] = pm.maxRetries
 
+	var totalTokens, totalRequests int64
+	var estimatedCost float64
+	usage := pm.GetProviderStats()
+	for _, stats := range usage {
+		totalTokens += stats.TotalTokens
+		totalRequests += stats.Requests
+		estimatedCost += stats.EstimatedCost
+	}
+	status["usage"] = usage
+
+	breakers := make(map[string]string, len(pm.breakers))
+	for name, state := range pm.GetCircuitBreakerStates() {
+		breakers[name] = state.String()
+	}
+	status["circuit_breakers"] = breakers
+	status["fix_cache_enabled"] = pm.cache != nil
+	if pm.cache != nil {
+		status["fix_cache_entries"] = pm.cache.len()
+		status["fix_cache_hits"] = pm.cache.hits.Load()
+		status["fix_cache_misses"] = pm.cache.misses.Load()
+	}
+	status["total_tokens"] = totalTokens
+	status["total_requests"] = totalRequests
+	if len(pm.pricing) > 0 {
+		status["estimated_cost_usd"] = estimatedCost
+	}
+
 	return status
 }
@@ -1,16 +1,24 @@
 package ai
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ajeet-kumar1087/go-code-healer/internal"
 )
 
 func TestProviderCreation(t *testing.T) {
-	logger := internal.NewDefaultLogger(internal.LogLevelInfo)
+	logger := internal.NewDefaultLogger("info")
 
 	// Test Claude client creation
-	claudeClient := NewClaudeClient("test-key", "claude-3-sonnet-20240229", logger)
+	claudeClient := NewClaudeClient("test-key", "claude-3-sonnet-20240229", 0, nil, logger)
 	if claudeClient == nil {
 		t.Fatal("Failed to create Claude client")
 	}
@@ -19,7 +27,7 @@ func TestProviderCreation(t *testing.T) {
 	}
 
 	// Test Codex client creation
-	codexClient := NewCodexClient("test-key", "code-davinci-002", logger)
+	codexClient := NewCodexClient("test-key", "code-davinci-002", 0, nil, logger)
 	if codexClient == nil {
 		t.Fatal("Failed to create Codex client")
 	}
@@ -28,53 +36,72 @@ func TestProviderCreation(t *testing.T) {
 	}
 
 	// Test OpenAI client creation
-	openaiClient := NewOpenAIClient("test-key", "gpt-4", logger)
+	openaiClient := NewOpenAIClient("test-key", "gpt-4", 0, nil, logger)
 	if openaiClient == nil {
 		t.Fatal("Failed to create OpenAI client")
 	}
 	if openaiClient.GetProviderName() != "openai" {
 		t.Errorf("Expected provider name 'openai', got '%s'", openaiClient.GetProviderName())
 	}
+
+	// Test Gemini client creation
+	geminiClient := NewGeminiClient("test-key", "gemini-1.5-pro", 0, nil, logger)
+	if geminiClient == nil {
+		t.Fatal("Failed to create Gemini client")
+	}
+	if geminiClient.GetProviderName() != "gemini" {
+		t.Errorf("Expected provider name 'gemini', got '%s'", geminiClient.GetProviderName())
+	}
 }
 
 func TestProviderValidation(t *testing.T) {
-	logger := internal.NewDefaultLogger(internal.LogLevelInfo)
+	logger := internal.NewDefaultLogger("info")
 
 	// Test validation with empty API key
-	claudeClient := NewClaudeClient("", "claude-3-sonnet-20240229", logger)
+	claudeClient := NewClaudeClient("", "claude-3-sonnet-20240229", 0, nil, logger)
 	if err := claudeClient.ValidateConfiguration(); err == nil {
 		t.Error("Expected validation error for empty API key")
 	}
 
-	codexClient := NewCodexClient("", "code-davinci-002", logger)
+	codexClient := NewCodexClient("", "code-davinci-002", 0, nil, logger)
 	if err := codexClient.ValidateConfiguration(); err == nil {
 		t.Error("Expected validation error for empty API key")
 	}
 
-	openaiClient := NewOpenAIClient("", "gpt-4", logger)
+	openaiClient := NewOpenAIClient("", "gpt-4", 0, nil, logger)
 	if err := openaiClient.ValidateConfiguration(); err == nil {
 		t.Error("Expected validation error for empty API key")
 	}
 
+	geminiClient := NewGeminiClient("", "gemini-1.5-pro", 0, nil, logger)
+	if err := geminiClient.ValidateConfiguration(); err == nil {
+		t.Error("Expected validation error for empty API key")
+	}
+
 	// Test validation with valid configuration
-	claudeClient = NewClaudeClient("sk-ant-test", "claude-3-sonnet-20240229", logger)
+	claudeClient = NewClaudeClient("sk-ant-test", "claude-3-sonnet-20240229", 0, nil, logger)
 	if err := claudeClient.ValidateConfiguration(); err != nil {
 		t.Errorf("Unexpected validation error: %v", err)
 	}
 
-	codexClient = NewCodexClient("sk-test", "code-davinci-002", logger)
+	codexClient = NewCodexClient("sk-test", "code-davinci-002", 0, nil, logger)
 	if err := codexClient.ValidateConfiguration(); err != nil {
 		t.Errorf("Unexpected validation error: %v", err)
 	}
 
-	openaiClient = NewOpenAIClient("sk-test", "gpt-4", logger)
+	openaiClient = NewOpenAIClient("sk-test", "gpt-4", 0, nil, logger)
 	if err := openaiClient.ValidateConfiguration(); err != nil {
 		t.Errorf("Unexpected validation error: %v", err)
 	}
+
+	geminiClient = NewGeminiClient("AIza-test", "gemini-1.5-pro", 0, nil, logger)
+	if err := geminiClient.ValidateConfiguration(); err != nil {
+		t.Errorf("Unexpected validation error: %v", err)
+	}
 }
 
 func TestProviderManagerCreation(t *testing.T) {
-	logger := internal.NewDefaultLogger(internal.LogLevelInfo)
+	logger := internal.NewDefaultLogger("info")
 
 	// Test with Claude as primary
 	config := internal.Config{
@@ -117,10 +144,60 @@ func TestProviderManagerCreation(t *testing.T) {
 	if status["primary_provider"] != "openai" {
 		t.Errorf("Expected primary provider 'openai', got '%v'", status["primary_provider"])
 	}
+
+	// Test with Gemini as primary
+	config.AIProvider = "gemini"
+	config.GeminiAPIKey = "AIza-test"
+	pm, err = NewProviderManager(config, logger)
+	if err != nil {
+		t.Fatalf("Failed to create provider manager: %v", err)
+	}
+
+	status = pm.GetProviderStatus()
+	if status["primary_provider"] != "gemini" {
+		t.Errorf("Expected primary provider 'gemini', got '%v'", status["primary_provider"])
+	}
+
+	providers, ok = status["providers"].([]string)
+	if !ok {
+		t.Fatal("Expected providers to be []string")
+	}
+
+	if len(providers) != 4 {
+		t.Errorf("Expected 4 providers, got %d", len(providers))
+	}
+}
+
+func TestProviderManagerExplicitFallbackOrder(t *testing.T) {
+	logger := internal.NewDefaultLogger("info")
+
+	config := internal.Config{
+		AIProvider:        "claude",
+		FallbackProviders: []string{"openai"},
+		ClaudeAPIKey:      "sk-ant-test",
+		OpenAIAPIKey:      "sk-test",
+		CodexAPIKey:       "sk-test",
+	}
+
+	pm, err := NewProviderManager(config, logger)
+	if err != nil {
+		t.Fatalf("Failed to create provider manager: %v", err)
+	}
+
+	providers, _ := pm.GetProviderStatus()["providers"].([]string)
+	if !slices.Equal(providers, []string{"claude", "openai"}) {
+		t.Errorf("Expected providers [claude openai], got %v", providers)
+	}
+
+	// A listed fallback without credentials is an error
+	config.FallbackProviders = []string{"gemini"}
+	if _, err := NewProviderManager(config, logger); err == nil {
+		t.Error("Expected error for fallback provider without an API key")
+	}
 }
 
 func TestProviderOptimization(t *testing.T) {
-	logger := internal.NewDefaultLogger(internal.LogLevelInfo)
+	logger := internal.NewDefaultLogger("info")
 	config := internal.Config{
 		AIProvider:   "claude",
 		ClaudeAPIKey: "sk-ant-test",
@@ -160,4 +237,432 @@ func TestProviderOptimization(t *testing.T) {
 	if openaiReq.Metadata == nil {
 		t.Error("OpenAI optimization should add metadata")
 	}
+
+	// Test Gemini optimization
+	geminiReq := pm.optimizeRequestForProvider(request, "gemini")
+	if geminiReq.Error != request.Error {
+		t.Error("Gemini optimization should preserve error")
+	}
+	if geminiReq.Metadata["error_type"] != "nil_pointer" {
+		t.Errorf("Expected Gemini optimization to classify error as nil_pointer, got '%s'", geminiReq.Metadata["error_type"])
+	}
+}
+
+func TestOllamaClientStreamingResponse(t *testing.T) {
+	logger := internal.NewDefaultLogger("info")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Emit the JSON fix in several newline-delimited chunks like a streaming Ollama server
+		chunks := []string{
+			`{"model":"codellama","response":"{\"proposed_fix\": \"if p != nil { fmt.Println(*p) }\",","done":false}`,
+			`{"model":"codellama","response":" \"explanation\": \"Add nil check\", \"confidence\": 0.8}","done":false}`,
+			`{"model":"codellama","response":"","done":true,"prompt_eval_count":12,"eval_count":34}`,
+		}
+		for _, chunk := range chunks {
+			fmt.Fprintln(w, chunk)
+		}
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "codellama", 0, nil, logger)
+	if err := client.ValidateConfiguration(); err != nil {
+		t.Fatalf("Unexpected validation error: %v", err)
+	}
+
+	response, err := client.GenerateFix(context.Background(), FixRequest{
+		Error:      "runtime error: invalid memory address or nil pointer dereference",
+		SourceCode: "fmt.Println(*p)",
+	})
+	if err != nil {
+		t.Fatalf("GenerateFix failed: %v", err)
+	}
+
+	if response.Provider != "ollama" {
+		t.Errorf("Expected provider 'ollama', got '%s'", response.Provider)
+	}
+	if response.ProposedFix != "if p != nil { fmt.Println(*p) }" {
+		t.Errorf("Unexpected proposed fix: %q", response.ProposedFix)
+	}
+	if response.Confidence != 0.8 {
+		t.Errorf("Expected confidence 0.8, got %.2f", response.Confidence)
+	}
+	if !response.IsValid {
+		t.Error("Expected fix to pass syntax validation")
+	}
+}
+
+func TestClaudeClientStreamingResponse(t *testing.T) {
+	logger := internal.NewDefaultLogger("info")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		events := []string{
+			`{"type":"message_start","message":{"usage":{"input_tokens":12}}}`,
+			`{"type":"content_block_delta","delta":{"type":"text_delta","text":"{\"proposed_fix\": \"if p != nil { fmt.Println(*p) }\", "}}`,
+			`{"type":"content_block_delta","delta":{"type":"text_delta","text":"\"explanation\": \"Add nil check\", \"confidence\": 0.8}"}}`,
+			`{"type":"message_delta","usage":{"output_tokens":34}}`,
+			`{"type":"message_stop"}`,
+		}
+		for _, event := range events {
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", event)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClaudeClient("sk-ant-test", "", time.Second, nil, logger)
+	client.baseURL = server.URL
+	client.SetStreaming(true)
+
+	response, err := client.GenerateFix(context.Background(), FixRequest{
+		Error:      "runtime error: invalid memory address or nil pointer dereference",
+		SourceCode: "fmt.Println(*p)",
+	})
+	if err != nil {
+		t.Fatalf("GenerateFix failed: %v", err)
+	}
+
+	if response.ProposedFix != "if p != nil { fmt.Println(*p) }" {
+		t.Errorf("Unexpected proposed fix: %q", response.ProposedFix)
+	}
+	if response.Confidence != 0.8 {
+		t.Errorf("Expected confidence 0.8, got %.2f", response.Confidence)
+	}
+	if response.Usage == nil || response.Usage.TotalTokens != 46 {
+		t.Errorf("Expected 46 total tokens, got %+v", response.Usage)
+	}
+}
+
+func TestProviderManagerCachesRecurringFixes(t *testing.T) {
+	logger := internal.NewDefaultLogger("info")
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprintln(w, `{"model":"codellama","response":"{\"proposed_fix\": \"if p != nil { fmt.Println(*p) }\", \"explanation\": \"Add nil check\", \"confidence\": 0.8}","done":false}`)
+		fmt.Fprintln(w, `{"model":"codellama","response":"","done":true,"prompt_eval_count":12,"eval_count":34}`)
+	}))
+	defer server.Close()
+
+	config := internal.DefaultConfig()
+	config.AIProvider = "ollama"
+	config.OllamaBaseURL = server.URL
+
+	pm, err := NewProviderManager(config, logger)
+	if err != nil {
+		t.Fatalf("Failed to create provider manager: %v", err)
+	}
+
+	request := FixRequest{
+		Error:      "runtime error: invalid memory address or nil pointer dereference",
+		StackTrace: "goroutine 7 [running]:\nmain.handler(0xc000010000)\n\t/app/main.go:12 +0x1d",
+		SourceCode: "fmt.Println(*p)",
+	}
+
+	first, err := pm.GenerateFixWithFallback(context.Background(), request)
+	if err != nil {
+		t.Fatalf("GenerateFixWithFallback failed: %v", err)
+	}
+	if first.FromCache {
+		t.Error("First response should not come from the cache")
+	}
+
+	// Same panic on a different goroutine and address
+	request.StackTrace = "goroutine 42 [running]:\nmain.handler(0xc000020000)\n\t/app/main.go:12 +0x1d"
+	second, err := pm.GenerateFixWithFallback(context.Background(), request)
+	if err != nil {
+		t.Fatalf("GenerateFixWithFallback failed: %v", err)
+	}
+	if !second.FromCache {
+		t.Error("Expected recurring panic to be served from the cache")
+	}
+	if second.ProposedFix != first.ProposedFix {
+		t.Errorf("Cached fix %q does not match original %q", second.ProposedFix, first.ProposedFix)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 provider call, got %d", calls)
+	}
+
+	stats := pm.GetProviderStats()["ollama"]
+	if stats.Requests != 1 || stats.TotalTokens != 46 {
+		t.Errorf("Expected 1 request and 46 tokens, got %d requests and %d tokens", stats.Requests, stats.TotalTokens)
+	}
+}
+
+func TestAzureOpenAIEndpointURL(t *testing.T) {
+	client := NewAzureOpenAIClient("azure-key", "gpt-4", AzureConfig{
+		Endpoint:   "https://my-resource.openai.azure.com/",
+		Deployment: "gpt4-prod",
+	}, 0, nil, nil)
+
+	want := "https://my-resource.openai.azure.com/openai/deployments/gpt4-prod/chat/completions?api-version=" + DefaultAzureAPIVersion
+	if got := client.httpHandler.endpointURL(); got != want {
+		t.Errorf("endpointURL() = %s, want %s", got, want)
+	}
+
+	if err := NewAzureOpenAIClient("azure-key", "gpt-4", AzureConfig{Endpoint: "https://my-resource.openai.azure.com"}, 0, nil, nil).ValidateConfiguration(); err == nil {
+		t.Error("Expected validation error when Azure deployment is missing")
+	}
+}
+
+func TestOpenAICompatibleClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("X-API-Key"); got != "mistral-key" {
+			t.Errorf("Expected the API key in X-API-Key, got %q", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Expected no Authorization header, got %q", got)
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"{\"proposed_fix\": \"if p != nil { fmt.Println(*p) }\", \"explanation\": \"Add nil check\", \"confidence\": 0.8}"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	client := NewOpenAICompatibleClient("mistral-key", "mistral-large-latest", server.URL+"/v1/", "X-API-Key", time.Second, nil, nil)
+	request := FixRequest{Error: "runtime error: invalid memory address or nil pointer dereference", SourceCode: "fmt.Println(*p)"}
+	if _, err := client.GenerateFix(context.Background(), request); err != nil {
+		t.Fatalf("GenerateFix failed: %v", err)
+	}
+
+	// The default client still talks to OpenAI
+	if got := NewOpenAIClient("sk-test", "gpt-4", 0, nil, nil).httpHandler.endpointURL(); got != DefaultOpenAIBaseURL+"/chat/completions" {
+		t.Errorf("endpointURL() = %s, want the OpenAI endpoint", got)
+	}
+}
+
+func TestProviderManagerAppliesGenerationParams(t *testing.T) {
+	var request openAIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"{\"proposed_fix\": \"if p != nil { fmt.Println(*p) }\", \"explanation\": \"Add nil check\", \"confidence\": 0.8}"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	temperature, topP := 0.7, 0.5
+	pm, err := NewProviderManager(internal.Config{
+		AIProvider:              "openai",
+		OpenAIAPIKey:            "test-key",
+		OpenAIModel:             "gpt-4",
+		OpenAICompatibleBaseURL: server.URL,
+		AITimeoutSeconds:        5,
+		AITemperature:           &temperature,
+		AIMaxTokens:             3000,
+		AIProviderParams:        map[string]internal.AIGenerationParams{"openai": {TopP: &topP}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create provider manager: %v", err)
+	}
+
+	fixRequest := FixRequest{Error: "runtime error: invalid memory address or nil pointer dereference", SourceCode: "fmt.Println(*p)"}
+	if _, err := pm.GenerateFixWithFallback(context.Background(), fixRequest); err != nil {
+		t.Fatalf("GenerateFixWithFallback failed: %v", err)
+	}
+	if request.Temperature != 0.7 || request.MaxTokens != 3000 || request.TopP != 0.5 {
+		t.Errorf("Expected temperature 0.7, max tokens 3000 and top_p 0.5, got %v, %d and %v",
+			request.Temperature, request.MaxTokens, request.TopP)
+	}
+}
+
+func TestPromptGeneratorAddsErrorGuidance(t *testing.T) {
+	pg := NewPromptGenerator()
+
+	race := FixRequest{Error: "fatal error: concurrent map writes"}
+	if category := (&CodeValidator{}).ClassifyError(race); category != internal.ErrorCategoryConcurrency {
+		t.Errorf("Expected concurrent map writes to be a concurrency error, got %q", category)
+	}
+	if prompt := pg.GeneratePromptWithMCP(race); !strings.Contains(prompt, "sync.Mutex") {
+		t.Errorf("Expected concurrency guidance in prompt, got:\n%s", prompt)
+	}
+
+	bounds := FixRequest{Error: "runtime error: index out of range [3] with length 3"}
+	if prompt := pg.GeneratePromptWithMCP(bounds); strings.Contains(prompt, "sync.Mutex") || !strings.Contains(prompt, "off-by-one") {
+		t.Errorf("Expected only bounds guidance in prompt, got:\n%s", prompt)
+	}
+
+	config := internal.Config{ErrorPrompts: map[string]string{
+		internal.ErrorCategoryBounds:      "Use the length check helper.",
+		internal.ErrorCategoryConcurrency: "",
+	}}
+	pg.SetErrorPrompts(config.GetErrorPrompts())
+	if prompt := pg.GeneratePromptWithMCP(bounds); !strings.Contains(prompt, "Use the length check helper.") {
+		t.Errorf("Expected overridden bounds guidance in prompt, got:\n%s", prompt)
+	}
+	if prompt := pg.GeneratePromptWithMCP(race); strings.Contains(prompt, "Guidance for This Error") {
+		t.Errorf("Expected no guidance for a category overridden with an empty prompt, got:\n%s", prompt)
+	}
+}
+
+func TestOpenAIClientFallsBackFromUnsupportedResponseFormat(t *testing.T) {
+	var formats []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		format := "text"
+		if request.ResponseFormat != nil {
+			format = request.ResponseFormat.Type
+		}
+		formats = append(formats, format)
+
+		// This model only supports JSON mode, not structured outputs
+		if format == "json_schema" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"Invalid parameter: 'response_format' of type 'json_schema' is not supported with this model.","type":"invalid_request_error","code":""}}`)
+			return
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"{\"proposed_fix\": \"if p != nil { fmt.Println(*p) }\", \"explanation\": \"Add nil check\", \"confidence\": 0.8}"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	client := NewAzureOpenAIClient("azure-key", "gpt-4", AzureConfig{Endpoint: server.URL, Deployment: "gpt4"}, time.Second, nil, nil)
+	request := FixRequest{Error: "runtime error: invalid memory address or nil pointer dereference", SourceCode: "fmt.Println(*p)"}
+
+	for range 2 {
+		response, err := client.GenerateFix(context.Background(), request)
+		if err != nil {
+			t.Fatalf("GenerateFix failed: %v", err)
+		}
+		if response.ProposedFix != "if p != nil { fmt.Println(*p) }" {
+			t.Errorf("Unexpected proposed fix: %q", response.ProposedFix)
+		}
+	}
+
+	// The rejected format is remembered rather than retried on every request
+	if !slices.Equal(formats, []string{"json_schema", "json_object", "json_object"}) {
+		t.Errorf("Unexpected response formats requested: %v", formats)
+	}
+}
+
+// staticClient returns the same fix for every request
+type staticClient struct {
+	name       string
+	confidence float64
+}
+
+func (c staticClient) GenerateFix(ctx context.Context, request FixRequest) (*FixResponse, error) {
+	return &FixResponse{
+		ProposedFix: "if p != nil { fmt.Println(*p) }",
+		Confidence:  c.confidence,
+		IsValid:     true,
+		Provider:    c.name,
+	}, nil
+}
+
+func (c staticClient) GetProviderName() string      { return c.name }
+func (c staticClient) ValidateConfiguration() error { return nil }
+
+func TestProviderManagerQueryAllPicksMostConfidentFix(t *testing.T) {
+	providers := []Client{
+		staticClient{name: "openai", confidence: 0.6},
+		staticClient{name: "claude", confidence: 0.9},
+		staticClient{name: "gemini", confidence: 0.7},
+	}
+	pm := &ProviderManager{providers: providers, maxRetries: 1, queryAll: true}
+
+	response, err := pm.GenerateFixWithFallback(context.Background(), FixRequest{Error: "nil pointer dereference"})
+	if err != nil {
+		t.Fatalf("GenerateFixWithFallback failed: %v", err)
+	}
+	if response.Provider != "claude" {
+		t.Errorf("Expected the most confident fix from claude, got %s (%.2f)", response.Provider, response.Confidence)
+	}
+	if !slices.Equal(response.ConsultedProviders, []string{"openai", "claude", "gemini"}) {
+		t.Errorf("Expected all providers consulted, got %v", response.ConsultedProviders)
+	}
+
+	// Without QueryAllProviders the first valid fix wins
+	pm.queryAll = false
+	response, err = pm.GenerateFixWithFallback(context.Background(), FixRequest{Error: "nil pointer dereference"})
+	if err != nil {
+		t.Fatalf("GenerateFixWithFallback failed: %v", err)
+	}
+	if response.Provider != "openai" || !slices.Equal(response.ConsultedProviders, []string{"openai"}) {
+		t.Errorf("Expected first valid fix from openai, got %s consulted %v", response.Provider, response.ConsultedProviders)
+	}
+}
+
+func TestGeminiClientSendsKeyInHeader(t *testing.T) {
+	var rawQuery, key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery, key = r.URL.RawQuery, r.Header.Get("x-goog-api-key")
+		fmt.Fprint(w, `{"candidates":[{"content":{"parts":[{"text":"{\"proposed_fix\": \"if p != nil { fmt.Println(*p) }\", \"explanation\": \"Add nil check\", \"confidence\": 0.8}"}]}}]}`)
+	}))
+	defer server.Close()
+
+	client := NewGeminiClient("AIza-secret", "gemini-1.5-pro", 5*time.Second, nil, internal.NewDefaultLogger("error"))
+	client.baseURL = server.URL
+	if _, err := client.GenerateFix(context.Background(), FixRequest{Error: "nil pointer dereference", SourceCode: "fmt.Println(*p)"}); err != nil {
+		t.Fatalf("GenerateFix failed: %v", err)
+	}
+	if strings.Contains(rawQuery, "AIza-secret") {
+		t.Errorf("Expected no API key in the request URL, got query %q", rawQuery)
+	}
+	if key != "AIza-secret" {
+		t.Errorf("Expected API key in x-goog-api-key header, got %q", key)
+	}
+
+	// Transport errors include the URL and are logged
+	server.Close()
+	if _, err := client.GenerateFix(context.Background(), FixRequest{Error: "nil pointer dereference"}); err == nil || strings.Contains(err.Error(), "AIza-secret") {
+		t.Errorf("Expected a transport error without the API key, got %v", err)
+	}
+}
+
+func TestSignAWSRequestMatchesReferenceSignature(t *testing.T) {
+	// get-vanilla from the AWS Signature Version 4 test suite
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signAWSRequest(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q\nwant %q", got, want)
+	}
+}
+
+func TestBedrockClientInvokesModel(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/model/anthropic.claude-3-5-sonnet-20240620-v1%3A0/invoke" {
+			t.Errorf("Unexpected path %s", r.URL.EscapedPath())
+		}
+		if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/us-west-2/bedrock/aws4_request") {
+			t.Errorf("Request not signed for Bedrock: %q", auth)
+		}
+		if r.Header.Get("X-Amz-Security-Token") != "session" {
+			t.Error("Expected the session token to be sent")
+		}
+		var body bedrockRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.AnthropicVersion != bedrockAnthropicVersion || len(body.Messages) != 1 {
+			t.Errorf("Unexpected request body: %+v", body)
+		}
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"{\"proposed_fix\": \"if p != nil { fmt.Println(*p) }\", \"explanation\": \"Add nil check\", \"confidence\": 0.8}"}],"usage":{"input_tokens":12,"output_tokens":34}}`)
+	}))
+	defer server.Close()
+
+	client := NewBedrockClient("us-west-2", "", 0, nil, nil)
+	client.baseURL = server.URL
+
+	response, err := client.GenerateFix(context.Background(), FixRequest{
+		Error:      "runtime error: invalid memory address or nil pointer dereference",
+		SourceCode: "fmt.Println(*p)",
+	})
+	if err != nil {
+		t.Fatalf("GenerateFix failed: %v", err)
+	}
+	if response.Provider != "bedrock" || response.ProposedFix != "if p != nil { fmt.Println(*p) }" || response.Confidence != 0.8 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	if response.Usage == nil || response.Usage.TotalTokens != 46 {
+		t.Errorf("Expected token usage from the response, got %+v", response.Usage)
+	}
 }
@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// Response format levels, most structured first. Clients start at the top and
+// step down when the model rejects a format, so older models still work.
+const (
+	formatJSONSchema int32 = iota // strict schema, supported by recent models
+	formatJSONObject              // any JSON object
+	formatText                    // no constraint; the text fallback parser applies
+)
+
+// openAIResponseFormat is the response_format field of a chat completion request
+type openAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string         `json:"name"`
+	Strict bool           `json:"strict"`
+	Schema map[string]any `json:"schema"`
+}
+
+// fixResponseSchema describes the JSON object every prompt asks for. Strict
+// schemas need every property to be required, so models report 0 for line
+// numbers that do not apply.
+var fixResponseSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"proposed_fix": map[string]any{"type": "string"},
+		"explanation":  map[string]any{"type": "string"},
+		"confidence":   map[string]any{"type": "number"},
+		"patch_format": map[string]any{
+			"type": "string",
+			"enum": []string{PatchFormatFullFile, PatchFormatUnifiedDiff, PatchFormatLineRange},
+		},
+		"start_line": map[string]any{"type": "integer"},
+		"end_line":   map[string]any{"type": "integer"},
+	},
+	"required":             []string{"proposed_fix", "explanation", "confidence", "patch_format", "start_line", "end_line"},
+	"additionalProperties": false,
+}
+
+// responseFormatFor returns the response_format for a level, or nil for formatText
+func responseFormatFor(level int32) *openAIResponseFormat {
+	switch level {
+	case formatJSONSchema:
+		return &openAIResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &openAIJSONSchema{
+				Name:   "code_fix",
+				Strict: true,
+				Schema: fixResponseSchema,
+			},
+		}
+	case formatJSONObject:
+		return &openAIResponseFormat{Type: "json_object"}
+	default:
+		return nil
+	}
+}
+
+// isResponseFormatError reports whether err is the API rejecting response_format
+func isResponseFormatError(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "response_format")
+}
+
+// downgradeResponseFormat steps level down from the format that failed. It
+// returns false when there is nothing left to fall back to.
+func downgradeResponseFormat(level *atomic.Int32, failed int32) bool {
+	if failed >= formatText {
+		return false
+	}
+	level.CompareAndSwap(failed, failed+1)
+	return true
+}
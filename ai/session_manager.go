@@ -34,6 +34,7 @@ type SessionContext struct {
 // ErrorInfo contains detailed error information
 type ErrorInfo struct {
 	Error      string    `json:"error"`
+	ErrorType  string    `json:"error_type,omitempty"`
 	StackTrace string    `json:"stack_trace"`
 	SourceFile string    `json:"source_file"`
 	LineNumber int       `json:"line_number"`
@@ -53,7 +54,7 @@ type CodeContext struct {
 
 // GitClientInterface defines the interface for Git operations
 type GitClientInterface interface {
-	CreatePullRequest(ctx context.Context, request PRRequest) error
+	CreatePullRequest(ctx context.Context, request PRRequest) (*github.PRResult, error)
 }
 
 // PRRequest is an alias to github.PRRequest
@@ -168,6 +169,9 @@ func (sm *SessionManager) generateComprehensiveFix(ctx context.Context) (*FixRes
 			"imported_pkgs": fmt.Sprintf("%v", sm.context.CodeContext.ImportedPkgs),
 		},
 	}
+	if sm.context.ErrorInfo.ErrorType != "" {
+		fixRequest.Metadata["error_go_type"] = sm.context.ErrorInfo.ErrorType
+	}
 
 	return sm.aiClient.GenerateFix(ctx, fixRequest)
 }
@@ -198,8 +202,14 @@ func (sm *SessionManager) buildContextString() string {
 
 // applyPatchAndCreatePR applies the generated fix and creates a pull request
 func (sm *SessionManager) applyPatchAndCreatePR(ctx context.Context, fixResponse *FixResponse) (*PRResult, error) {
-	// Create branch name based on session and error type
-	branchName := fmt.Sprintf("fix/%s-%s", sm.sessionID, sm.sanitizeBranchName(sm.context.ErrorInfo.Error))
+	// Name the branch after the panic, as fixes from the worker are
+	errorInfo := sm.context.ErrorInfo
+	branchName := github.GenerateBranchName(github.PanicEvent{
+		Error:      errorInfo.Error,
+		SourceFile: errorInfo.SourceFile,
+		LineNumber: errorInfo.LineNumber,
+		Function:   errorInfo.Function,
+	})
 
 	// Create comprehensive PR title and description
 	prTitle := fmt.Sprintf("AI Fix: %s in %s",
@@ -210,8 +220,11 @@ func (sm *SessionManager) applyPatchAndCreatePR(ctx context.Context, fixResponse
 	// Create file changes
 	changes := []FileChange{
 		{
-			FilePath: sm.context.ErrorInfo.SourceFile,
-			Content:  fixResponse.ProposedFix,
+			FilePath:    sm.context.ErrorInfo.SourceFile,
+			Content:     fixResponse.ProposedFix,
+			PatchFormat: fixResponse.PatchFormat,
+			StartLine:   fixResponse.StartLine,
+			EndLine:     fixResponse.EndLine,
 		},
 	}
 
@@ -224,18 +237,24 @@ func (sm *SessionManager) applyPatchAndCreatePR(ctx context.Context, fixResponse
 	}
 
 	// Create the pull request
-	err := sm.gitClient.CreatePullRequest(ctx, prRequest)
+	created, err := sm.gitClient.CreatePullRequest(ctx, prRequest)
 	if err != nil {
 		return nil, err
 	}
 
-	return &PRResult{
+	result := &PRResult{
 		BranchName:   branchName,
 		Title:        prTitle,
 		Description:  prDescription,
 		FilesChanged: len(changes),
 		Success:      true,
-	}, nil
+	}
+	if created != nil {
+		result.URL = created.URL
+		result.Number = created.Number
+	}
+
+	return result, nil
 }
 
 // buildPRDescription creates a comprehensive PR description
@@ -301,35 +320,6 @@ This fix was generated with enhanced context from MCP tools:
 	return description
 }
 
-// sanitizeBranchName creates a valid Git branch name from error text
-func (sm *SessionManager) sanitizeBranchName(errorText string) string {
-	// Replace spaces and special characters with hyphens
-	sanitized := ""
-	for _, char := range errorText {
-		if (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9') {
-			sanitized += string(char)
-		} else if char == ' ' || char == '_' || char == '.' || char == ':' {
-			sanitized += "-"
-		}
-	}
-
-	// Limit length and remove trailing hyphens
-	if len(sanitized) > 30 {
-		sanitized = sanitized[:30]
-	}
-
-	// Remove trailing hyphens
-	for len(sanitized) > 0 && sanitized[len(sanitized)-1] == '-' {
-		sanitized = sanitized[:len(sanitized)-1]
-	}
-
-	if sanitized == "" {
-		sanitized = "runtime-error"
-	}
-
-	return sanitized
-}
-
 // SessionResult represents the result of an AI session
 type SessionResult struct {
 	SessionID   string          `json:"session_id"`
@@ -350,6 +340,7 @@ type PRResult struct {
 	FilesChanged int    `json:"files_changed"`
 	Success      bool   `json:"success"`
 	URL          string `json:"url,omitempty"`
+	Number       int    `json:"number,omitempty"`
 }
 
 // GetSessionSummary returns a summary of the session
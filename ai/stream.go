@@ -0,0 +1,86 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// errStreamDone is returned by an event callback to stop reading a stream early
+var errStreamDone = errors.New("stream done")
+
+// readSSE reads server-sent events from body and calls onEvent with the name
+// and data of each one until the stream ends or onEvent returns errStreamDone.
+// The stream fails if no data arrives for idleTimeout, so a stalled response
+// is cut off while one that keeps producing output is not. Cancelling ctx
+// aborts the read.
+func readSSE(ctx context.Context, body io.ReadCloser, idleTimeout time.Duration, onEvent func(event, data string) error) error {
+	var idle atomic.Bool
+	var timer *time.Timer
+	if idleTimeout > 0 {
+		timer = time.AfterFunc(idleTimeout, func() {
+			idle.Store(true)
+			body.Close()
+		})
+		defer timer.Stop()
+	}
+
+	var event string
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			event = ""
+			return nil
+		}
+		err := onEvent(event, strings.Join(data, "\n"))
+		event, data = "", nil
+		return err
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if timer != nil {
+			timer.Reset(idleTimeout)
+		}
+
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				if errors.Is(err, errStreamDone) {
+					return nil
+				}
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment, often sent as a keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case idle.Load():
+			return fmt.Errorf("stream timeout: no data received for %v", idleTimeout)
+		default:
+			return fmt.Errorf("failed to read stream: %w", err)
+		}
+	}
+
+	// A final event may not be followed by a blank line
+	if err := dispatch(); err != nil && !errors.Is(err, errStreamDone) {
+		return err
+	}
+	return nil
+}
@@ -7,6 +7,27 @@ type openAIRequest struct {
 	Temperature float64         `json:"temperature"`
 	MaxTokens   int             `json:"max_tokens"`
 	TopP        float64         `json:"top_p"`
+
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openAIStreamChunk is one server-sent event of a streamed chat completion
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage,omitempty"`
+	Error *openAIError `json:"error,omitempty"`
 }
 
 type openAIMessage struct {
@@ -44,10 +65,28 @@ type openAIError struct {
 
 // Claude API request/response structures
 type claudeRequest struct {
-	Model     string          `json:"model"`
-	MaxTokens int             `json:"max_tokens"`
-	Messages  []claudeMessage `json:"messages"`
-	System    string          `json:"system,omitempty"`
+	Model       string          `json:"model"`
+	MaxTokens   int             `json:"max_tokens"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	TopP        *float64        `json:"top_p,omitempty"`
+	Messages    []claudeMessage `json:"messages"`
+	System      string          `json:"system,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
+}
+
+// claudeStreamEvent is one server-sent event of a streamed message. Only the
+// fields of the event types used to assemble the response are decoded.
+type claudeStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Usage claudeUsage `json:"usage"`
+	} `json:"message"` // message_start
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"` // content_block_delta
+	Usage claudeUsage  `json:"usage"` // message_delta
+	Error *claudeError `json:"error,omitempty"`
 }
 
 type claudeMessage struct {
@@ -76,12 +115,24 @@ type claudeError struct {
 	Message string `json:"message"`
 }
 
+// bedrockRequest is the InvokeModel body for Claude models on Bedrock. The
+// response has the same shape as claudeResponse.
+type bedrockRequest struct {
+	AnthropicVersion string          `json:"anthropic_version"`
+	MaxTokens        int             `json:"max_tokens"`
+	Temperature      *float64        `json:"temperature,omitempty"`
+	TopP             *float64        `json:"top_p,omitempty"`
+	Messages         []claudeMessage `json:"messages"`
+	System           string          `json:"system,omitempty"`
+}
+
 // Codex API request/response structures
 type codexRequest struct {
 	Model       string   `json:"model"`
 	Prompt      string   `json:"prompt"`
 	MaxTokens   int      `json:"max_tokens"`
 	Temperature float64  `json:"temperature"`
+	TopP        *float64 `json:"top_p,omitempty"`
 	Stop        []string `json:"stop,omitempty"`
 }
 
@@ -107,3 +158,74 @@ type codexError struct {
 	Type    string `json:"type"`
 	Code    string `json:"code"`
 }
+
+// Gemini API request/response structures
+type geminiRequest struct {
+	Contents          []geminiContent         `json:"contents"`
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature      float64 `json:"temperature"`
+	MaxOutputTokens  int     `json:"maxOutputTokens"`
+	TopP             float64 `json:"topP"`
+	ResponseMimeType string  `json:"responseMimeType,omitempty"`
+}
+
+type geminiResponse struct {
+	Candidates    []geminiCandidate   `json:"candidates"`
+	UsageMetadata geminiUsageMetadata `json:"usageMetadata"`
+	Error         *geminiError        `json:"error,omitempty"`
+}
+
+type geminiCandidate struct {
+	Content      geminiContent `json:"content"`
+	FinishReason string        `json:"finishReason"`
+}
+
+type geminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
+
+type geminiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+// Ollama API request/response structures
+type ollamaRequest struct {
+	Model   string         `json:"model"`
+	Prompt  string         `json:"prompt"`
+	System  string         `json:"system,omitempty"`
+	Stream  bool           `json:"stream"`
+	Format  string         `json:"format,omitempty"`
+	Options *ollamaOptions `json:"options,omitempty"`
+}
+
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature"`
+	NumPredict  int     `json:"num_predict"`
+	TopP        float64 `json:"top_p"`
+}
+
+type ollamaResponse struct {
+	Model           string `json:"model"`
+	Response        string `json:"response"`
+	Done            bool   `json:"done"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	Error           string `json:"error,omitempty"`
+}
@@ -4,6 +4,8 @@ import (
 	"go/parser"
 	"go/token"
 	"strings"
+
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
 )
 
 // CodeValidator handles validation of generated code
@@ -18,6 +20,16 @@ func NewCodeValidator(logger Logger) *CodeValidator {
 	}
 }
 
+// ValidateFix validates a proposed fix according to its patch format
+func (cv *CodeValidator) ValidateFix(fixResponse *FixResponse) bool {
+	if fixResponse.PatchFormat == PatchFormatUnifiedDiff {
+		// Diff hunks are fragments and cannot be parsed on their own; require a
+		// well-formed hunk so the patch can be applied and checked against the file
+		return strings.Contains(fixResponse.ProposedFix, "@@ -")
+	}
+	return cv.ValidateGoSyntax(fixResponse.ProposedFix)
+}
+
 // ValidateGoSyntax performs basic Go syntax validation on the proposed fix
 func (cv *CodeValidator) ValidateGoSyntax(code string) bool {
 	if code == "" {
@@ -128,13 +140,10 @@ func (cv *CodeValidator) basicSyntaxCheck(code string) bool {
 	return balanced
 }
 
-// AssessErrorComplexity analyzes the error to determine its complexity level
-func (cv *CodeValidator) AssessErrorComplexity(request FixRequest) string {
-	errorLower := strings.ToLower(request.Error)
-	stackLower := strings.ToLower(request.StackTrace)
-
-	// Simple, common errors
-	simplePatterns := []string{
+// Error patterns shared by complexity and severity assessment
+var (
+	// simpleErrorPatterns are common, well-understood runtime errors
+	simpleErrorPatterns = []string{
 		"nil pointer dereference",
 		"index out of range",
 		"slice bounds out of range",
@@ -142,16 +151,11 @@ func (cv *CodeValidator) AssessErrorComplexity(request FixRequest) string {
 		"assignment to entry in nil map",
 	}
 
-	for _, pattern := range simplePatterns {
-		if strings.Contains(errorLower, pattern) {
-			return "simple"
-		}
-	}
-
-	// Complex errors
-	complexPatterns := []string{
+	// complexErrorPatterns point at concurrency, reflection or unsafe code
+	complexErrorPatterns = []string{
 		"deadlock",
 		"race condition",
+		"concurrent map",
 		"goroutine",
 		"channel",
 		"interface conversion",
@@ -159,11 +163,79 @@ func (cv *CodeValidator) AssessErrorComplexity(request FixRequest) string {
 		"unsafe",
 	}
 
-	for _, pattern := range complexPatterns {
-		if strings.Contains(errorLower, pattern) || strings.Contains(stackLower, pattern) {
-			return "complex"
+	// criticalErrorPatterns are fatal runtime errors that crash or wedge the process
+	criticalErrorPatterns = []string{
+		"fatal error:",
+		"deadlock",
+		"race condition",
+		"concurrent map",
+	}
+
+	// typeAssertionErrorPatterns come from failed type assertions
+	typeAssertionErrorPatterns = []string{
+		"interface conversion",
+	}
+
+	// concurrencyErrorPatterns come from data races, deadlocks and channel misuse
+	concurrencyErrorPatterns = []string{
+		"deadlock",
+		"race condition",
+		"data race",
+		"concurrent map",
+		"close of closed channel",
+		"close of nil channel",
+		"send on closed channel",
+	}
+
+	// nilMapErrorPatterns come from writes to nil maps
+	nilMapErrorPatterns = []string{
+		"assignment to entry in nil map",
+	}
+
+	// nilPointerErrorPatterns come from nil pointer dereferences
+	nilPointerErrorPatterns = []string{
+		"nil pointer dereference",
+		"invalid memory address",
+	}
+
+	// boundsErrorPatterns come from out-of-range indexes and slice expressions
+	boundsErrorPatterns = []string{
+		"index out of range",
+		"slice bounds out of range",
+	}
+)
+
+// Severity levels returned by AssessErrorSeverity, most severe first
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+)
+
+// containsAny reports whether s contains any of patterns
+func containsAny(s string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(s, pattern) {
+			return true
 		}
 	}
+	return false
+}
+
+// AssessErrorComplexity analyzes the error to determine its complexity level
+func (cv *CodeValidator) AssessErrorComplexity(request FixRequest) string {
+	errorLower := strings.ToLower(request.Error)
+	stackLower := strings.ToLower(request.StackTrace)
+
+	// Simple, common errors
+	if containsAny(errorLower, simpleErrorPatterns) {
+		return "simple"
+	}
+
+	// Complex errors
+	if containsAny(errorLower, complexErrorPatterns) || containsAny(stackLower, complexErrorPatterns) {
+		return "complex"
+	}
 
 	// Check stack trace depth - deeper stacks might indicate more complex issues
 	stackLines := strings.Count(request.StackTrace, "\n")
@@ -175,3 +247,45 @@ func (cv *CodeValidator) AssessErrorComplexity(request FixRequest) string {
 
 	return "moderate"
 }
+
+// AssessErrorSeverity classifies how urgently a panic needs attention: fatal
+// runtime errors such as concurrent map writes and deadlocks are critical,
+// memory, bounds and channel errors are high, and failed type assertions and
+// application panics are medium
+func (cv *CodeValidator) AssessErrorSeverity(request FixRequest) string {
+	errorLower := strings.ToLower(request.Error)
+
+	switch {
+	case containsAny(errorLower, criticalErrorPatterns):
+		return SeverityCritical
+	case containsAny(errorLower, typeAssertionErrorPatterns):
+		return SeverityMedium
+	case containsAny(errorLower, simpleErrorPatterns), containsAny(errorLower, complexErrorPatterns):
+		return SeverityHigh
+	default:
+		return SeverityMedium
+	}
+}
+
+// ClassifyError returns the error category of the panic, one of
+// internal.ErrorCategories, or "" if it matches none. Categories are matched
+// in the order of internal.ErrorCategories, so a concurrent map write is a
+// concurrency error rather than a map error.
+func (cv *CodeValidator) ClassifyError(request FixRequest) string {
+	errorLower := strings.ToLower(request.Error)
+
+	switch {
+	case containsAny(errorLower, concurrencyErrorPatterns):
+		return internal.ErrorCategoryConcurrency
+	case containsAny(errorLower, typeAssertionErrorPatterns):
+		return internal.ErrorCategoryTypeAssertion
+	case containsAny(errorLower, nilMapErrorPatterns):
+		return internal.ErrorCategoryNilMap
+	case containsAny(errorLower, nilPointerErrorPatterns):
+		return internal.ErrorCategoryNilPointer
+	case containsAny(errorLower, boundsErrorPatterns):
+		return internal.ErrorCategoryBounds
+	default:
+		return ""
+	}
+}
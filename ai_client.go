@@ -15,7 +15,7 @@ type OpenAIClient struct {
 // NewOpenAIClient creates a new OpenAI client with proper HTTP client configuration
 func NewOpenAIClient(apiKey, model string, logger Logger) *OpenAIClient {
 	return &OpenAIClient{
-		client: ai.NewOpenAIClient(apiKey, model, logger),
+		client: ai.NewOpenAIClient(apiKey, model, 0, nil, logger),
 	}
 }
 
@@ -53,6 +53,18 @@
 //	    // This goroutine will capture and handle panics gracefully
 //	})
 //
+//	// For functions that return values, a panic becomes a *PanicError
+//	loadUser := healer.WrapFn(func() (*User, error) { return repo.Find(id) })
+//	user, err := loadUser()
+//
+// # Request Context
+//
+// Attach request-scoped metadata to a context so captured panics, AI prompts and
+// PR descriptions can be correlated with traces:
+//
+//	ctx = healer.WithContext(ctx, map[string]string{"trace_id": traceID, "user_id": userID})
+//	defer healer.RecoverAndHandleCtx(ctx)
+//
 // # Configuration
 //
 // The healer can be configured through environment variables or configuration files:
@@ -99,7 +111,11 @@
 //   - GitHub operations use minimal required permissions
 package healer
 
-import "net/http"
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
 
 // PublicAPI documents the main public interface of the healer package.
 // This interface is stable and follows semantic versioning.
@@ -108,6 +124,7 @@ type PublicAPI interface {
 	Initialize(config Config) (*Healer, error)
 	Start() error
 	Stop() error
+	ReloadConfig(newConfig Config) error
 
 	// Panic handling installation
 	InstallPanicHandler()
@@ -116,7 +133,14 @@ type PublicAPI interface {
 	// Status and monitoring
 	GetStatus() map[string]any
 	GetQueueStats() map[string]any
+	GetFailedEvents() []PanicEvent
 	ResetCircuitBreaker()
+	MetricsCollector() *Collector
+	StatusHandler() http.Handler
+	HealthzHandler() http.Handler
+
+	// Lifecycle hooks
+	RegisterHook(hook EventHook)
 }
 
 // GlobalFunctions documents the global functions available for panic handling.
@@ -132,6 +156,12 @@ type GlobalFunctions interface {
 	WrapHTTPHandler(handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) // Wraps HTTP handler
 	SafeGoroutine(fn func())                                                                                   // Starts goroutine with panic capture
 
+	// Context-aware panic capture
+	WithContext(ctx context.Context, metadata map[string]string) context.Context // Attaches panic metadata to a context
+	HandlePanicCtx(ctx context.Context)                                          // HandlePanic with context metadata
+	RecoverAndHandleCtx(ctx context.Context)                                     // RecoverAndHandle with context metadata
+	SafeGoroutineCtx(ctx context.Context, fn func(ctx context.Context))          // SafeGoroutine with context metadata
+
 	// Convenience functions
 	InstallGlobalPanicHandler(config Config) (*Healer, error) // Initialize and install in one call
 	MustInstallGlobalPanicHandler(config Config) *Healer      // Like InstallGlobalPanicHandler but panics on error
@@ -167,6 +197,10 @@ type LoggingAPI interface {
 
 	// Logger creation
 	NewDefaultLogger(levelStr string) Logger
+	NewJSONLogger(levelStr string) Logger
+	NewSlogLogger(logger *slog.Logger) Logger
+	NewLogger(levelStr, format string) Logger // format is "text" or "json"
+	WithLogFields(logger Logger, fields LogFields) Logger
 }
 
 // TypesAPI documents the main types and data structures.
@@ -0,0 +1,242 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	gh "github.com/ajeet-kumar1087/go-code-healer/github"
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
+)
+
+// fixBatcher holds fixes that target the same source file for the batch
+// window so they can share one pull request. Fixes whose changes edit
+// overlapping lines are split into separate pull requests rather than
+// applied on top of each other.
+type fixBatcher struct {
+	healer  *Healer
+	mu      sync.Mutex
+	pending map[string][]batchedFix // keyed by source file
+	timers  map[string]*time.Timer  // running batch windows, keyed by source file
+}
+
+// batchedFix is a fix waiting for its batch window to close
+type batchedFix struct {
+	event  PanicEvent
+	fix    *FixResponse
+	change FileChange
+}
+
+// newFixBatcher creates an empty batcher for h
+func newFixBatcher(h *Healer) *fixBatcher {
+	return &fixBatcher{
+		healer:  h,
+		pending: make(map[string][]batchedFix),
+		timers:  make(map[string]*time.Timer),
+	}
+}
+
+// add holds a fix, starting the batch window for its file if none is running.
+// Fixes are batched by the source file of the panic, since files of different
+// routed repositories can have the same path in their repository.
+func (b *fixBatcher) add(event PanicEvent, fix *FixResponse, change FileChange) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	file := event.SourceFile
+	b.pending[file] = append(b.pending[file], batchedFix{event: event, fix: fix, change: change})
+	if _, running := b.timers[file]; !running {
+		config := b.healer.getConfig()
+		b.timers[file] = time.AfterFunc(config.GetBatchWindow(), func() { b.flush(file) })
+	}
+}
+
+// flush opens pull requests for the fixes held for file
+func (b *fixBatcher) flush(file string) {
+	b.mu.Lock()
+	fixes := b.pending[file]
+	delete(b.pending, file)
+	if timer, ok := b.timers[file]; ok {
+		timer.Stop()
+		delete(b.timers, file)
+	}
+	b.mu.Unlock()
+
+	if len(fixes) == 0 {
+		return
+	}
+
+	groups := groupNonConflicting(fixes)
+	if len(groups) > 1 && b.healer.logger != nil {
+		b.healer.logger.Info("Fixes for %s edit overlapping lines, opening %d pull requests", file, len(groups))
+	}
+	for _, group := range groups {
+		b.submit(group)
+	}
+}
+
+// flushAll opens pull requests for every held fix without waiting for the
+// batch windows to close
+func (b *fixBatcher) flushAll() {
+	b.mu.Lock()
+	files := make([]string, 0, len(b.pending))
+	for file := range b.pending {
+		files = append(files, file)
+	}
+	b.mu.Unlock()
+
+	for _, file := range files {
+		b.flush(file)
+	}
+}
+
+// submit opens one pull request for group and reports the outcome for each event
+func (b *fixBatcher) submit(group []batchedFix) {
+	h := b.healer
+	config := h.getConfig()
+	ctx, cancel := context.WithTimeout(context.Background(), config.GetGitTimeout())
+	defer cancel()
+
+	if !h.allowPR() {
+		for _, fix := range group {
+			h.deadLetterRateLimited(fix.event)
+		}
+		return
+	}
+
+	prRequest := batchPRRequest(group)
+	prRequest.CommitMessage = h.commitMessage(group[0].event, group[0].fix, len(group))
+	h.applyPRTemplates(&prRequest, group[0].event, group[0].fix)
+	primary := group[0]
+
+	prResult, err := h.submitPullRequest(ctx, primary.event, prRequest)
+	if err == nil && prResult == nil {
+		prResult = &PRResult{Title: prRequest.Title}
+	}
+
+	for _, fix := range group {
+		logger := internal.WithFields(h.logger, internal.Fields{"event_id": fix.event.ID})
+		if err != nil {
+			fix.event.Status = "failed"
+			fix.event.LastError = err.Error()
+			h.deadLetters.add(fix.event)
+			h.markProcessed(fix.event)
+			h.notifyError(fix.event, err)
+			h.publishResult(NewProcessingResult(fix.event, nil, err))
+			if logger != nil {
+				logger.Error("Failed to create batched PR: %v", err)
+			}
+			continue
+		}
+		fix.event.Status = "completed"
+		h.markProcessed(fix.event)
+		if !prResult.AlreadyExisted {
+			h.notifyPRCreated(fix.event, prResult)
+		}
+		h.publishResult(NewProcessingResult(fix.event, prResult, nil))
+	}
+
+	if err != nil {
+		return
+	}
+	if prResult.AlreadyExisted {
+		if h.logger != nil {
+			h.logger.Info("Found existing PR, skipping creation: %s", prResult.URL)
+		}
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("Successfully created PR for %d fixes: %s %s", len(group), prResult.Title, prResult.URL)
+	}
+	h.notify(HealerEvent{Type: EventPRCreated, Time: time.Now(), Event: primary.event, Fix: primary.fix, PR: prResult})
+}
+
+// groupNonConflicting splits fixes into groups whose changes can all be
+// applied to the file together, keeping the original order within each group
+func groupNonConflicting(fixes []batchedFix) [][]batchedFix {
+	var groups [][]batchedFix
+	for _, fix := range fixes {
+		placed := false
+		for i, group := range groups {
+			conflicts := slices.ContainsFunc(group, func(other batchedFix) bool {
+				return gh.ChangesConflict(fix.change, other.change)
+			})
+			if !conflicts {
+				groups[i] = append(group, fix)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			groups = append(groups, []batchedFix{fix})
+		}
+	}
+	return groups
+}
+
+// batchPRRequest builds the pull request for a group of fixes to one file. A
+// group of one gets the same pull request as an unbatched fix.
+func batchPRRequest(group []batchedFix) PRRequest {
+	primary := group[0]
+	if len(group) == 1 {
+		return PRRequest{
+			BranchName:  GenerateBranchName(primary.event),
+			Title:       GeneratePRTitle(primary.event),
+			Description: GeneratePRDescription(primary.event, primary.fix),
+			Changes:     []FileChange{primary.change},
+			Fingerprint: primary.event.Fingerprint,
+		}
+	}
+
+	// Apply changes bottom-up so line ranges above are not shifted by edits below
+	changes := make([]FileChange, len(group))
+	for i, fix := range group {
+		changes[i] = fix.change
+	}
+	slices.SortStableFunc(changes, func(a, b FileChange) int {
+		aStart, _, _ := gh.ChangedLines(a)
+		bStart, _, _ := gh.ChangedLines(b)
+		return bStart - aStart
+	})
+
+	filename := filepath.Base(primary.event.SourceFile)
+	title := fmt.Sprintf("Fix %d panics in %s", len(group), filename)
+	if severity := highestSeverity(group); severity != "" {
+		title = fmt.Sprintf("[%s] %s", severity, title)
+	}
+
+	var description strings.Builder
+	description.WriteString("## Automatic Panic Fixes\n\n")
+	description.WriteString(fmt.Sprintf("This pull request combines %d automatically generated fixes for panics in `%s`.\n\n", len(group), primary.event.SourceFile))
+	for i, fix := range group {
+		description.WriteString(fmt.Sprintf("---\n\n# Fix %d of %d\n\n", i+1, len(group)))
+		description.WriteString(GeneratePRDescription(fix.event, fix.fix))
+		// Markers let later occurrences of each panic find this PR
+		description.WriteString(gh.FingerprintMarker(fix.event.Fingerprint))
+		description.WriteString("\n\n")
+	}
+
+	return PRRequest{
+		BranchName:  gh.SanitizeBranchName(fmt.Sprintf("%s-and-%d-more", GenerateBranchName(primary.event), len(group)-1)),
+		Title:       title,
+		Description: description.String(),
+		Changes:     changes,
+	}
+}
+
+// highestSeverity returns the most severe classification in group
+func highestSeverity(group []batchedFix) string {
+	for _, severity := range severityBands {
+		for _, fix := range group {
+			if fix.event.Severity == severity {
+				return severity
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,283 @@
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingGitClient records pull request requests instead of calling a Git host
+type recordingGitClient struct {
+	mu       sync.Mutex
+	requests []PRRequest
+}
+
+func (c *recordingGitClient) CreatePullRequest(ctx context.Context, request PRRequest) (*PRResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, request)
+	return &PRResult{Title: request.Title, URL: "https://example.com/pr"}, nil
+}
+
+func TestFixBatcher_SplitsConflictingFixes(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.BatchByFile = true
+
+	healer, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	git := &recordingGitClient{}
+	healer.gitClient = git
+
+	lines := func(id string, start, end int) (PanicEvent, *FixResponse, FileChange) {
+		event := PanicEvent{ID: id, SourceFile: "/app/handler.go", LineNumber: start}
+		change := FileChange{FilePath: event.SourceFile, Content: "fixed", PatchFormat: "line_range", StartLine: start, EndLine: end}
+		return event, &FixResponse{IsValid: true, Confidence: 0.9}, change
+	}
+	healer.batcher.add(lines("top", 10, 12))
+	healer.batcher.add(lines("bottom", 40, 42))
+	healer.batcher.add(lines("overlap", 11, 11))
+	healer.batcher.flushAll()
+
+	if len(git.requests) != 2 {
+		t.Fatalf("Expected 2 pull requests, got %d", len(git.requests))
+	}
+	batched := git.requests[0]
+	if len(batched.Changes) != 2 || batched.Changes[0].StartLine != 40 || batched.Changes[1].StartLine != 10 {
+		t.Errorf("Expected batched changes applied bottom-up, got %+v", batched.Changes)
+	}
+	if !strings.Contains(batched.Title, "Fix 2 panics in handler.go") {
+		t.Errorf("Unexpected batched title %q", batched.Title)
+	}
+	if len(git.requests[1].Changes) != 1 || git.requests[1].Changes[0].StartLine != 11 {
+		t.Errorf("Expected the overlapping fix in its own pull request, got %+v", git.requests[1].Changes)
+	}
+}
+
+func TestPRRateLimit_DeadLettersExcessFixes(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.BatchByFile = true
+	config.MaxPRsPerHour = 1
+
+	healer, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	git := &recordingGitClient{}
+	healer.gitClient = git
+
+	for _, file := range []string{"/app/a.go", "/app/b.go"} {
+		event := PanicEvent{ID: file, SourceFile: file, LineNumber: 3}
+		change := FileChange{FilePath: file, Content: "fixed", PatchFormat: "line_range", StartLine: 3, EndLine: 3}
+		healer.batcher.add(event, &FixResponse{IsValid: true, Confidence: 0.9}, change)
+	}
+	healer.batcher.flushAll()
+
+	if len(git.requests) != 1 {
+		t.Errorf("Expected 1 pull request within the limit, got %d", len(git.requests))
+	}
+	failed := healer.GetFailedEvents()
+	if len(failed) != 1 || failed[0].LastError != errPRRateLimited.Error() {
+		t.Errorf("Expected the second fix to be dead-lettered, got %+v", failed)
+	}
+	if got := healer.GetQueueStats()["pr_rate_limited"]; got != int64(1) {
+		t.Errorf("Expected pr_rate_limited 1, got %v", got)
+	}
+
+	// Each fix reports its outcome once on the results channel
+	var results []ProcessingResult
+	for len(healer.Results()) > 0 {
+		results = append(results, <-healer.Results())
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %+v", results)
+	}
+	if !results[0].Success || results[0].PRUrl != "https://example.com/pr" {
+		t.Errorf("Expected the first fix to report its pull request, got %+v", results[0])
+	}
+	if results[1].Success || results[1].Error != errPRRateLimited.Error() {
+		t.Errorf("Expected the second fix to report the rate limit, got %+v", results[1])
+	}
+}
+
+func TestHealer_AppliesPRTemplates(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.PRTitleTemplate = "fix({{.Event.Severity}}): {{.Event.Error}}"
+	config.PRBodyTemplate = "{{.DefaultBody}}\n## Checklist\n- [ ] Reviewed ({{printf \"%.0f\" (mul .Fix.Confidence)}})"
+
+	// Unknown functions and fields are reported when the config is loaded
+	if _, err := Initialize(config); err == nil || !strings.Contains(err.Error(), "PR body") {
+		t.Fatalf("Expected the PR body template to be rejected, got %v", err)
+	}
+	config.PRBodyTemplate = "{{.DefaultBody}}\n## Checklist\n- [ ] Reviewed {{.Fix.Explanation}}"
+	for _, bad := range []string{"{{.Event.Missing}}", "{{.Fix"} {
+		config.PRTitleTemplate = bad
+		if _, err := Initialize(config); err == nil {
+			t.Errorf("Expected PR title template %q to be rejected", bad)
+		}
+	}
+	config.PRTitleTemplate = "fix({{.Event.Severity}}): {{.Event.Error}}"
+
+	healer, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	event := PanicEvent{ID: "1", Error: "index out of range", Severity: SeverityHigh, SourceFile: "/app/a.go"}
+	request := PRRequest{Title: "default title", Description: "default body"}
+	healer.applyPRTemplates(&request, event, &FixResponse{Explanation: "bounds check"})
+
+	if request.Title != "fix(high): index out of range" {
+		t.Errorf("Unexpected title %q", request.Title)
+	}
+	if request.Description != "default body\n## Checklist\n- [ ] Reviewed bounds check" {
+		t.Errorf("Unexpected description %q", request.Description)
+	}
+}
+
+func TestRepoRouting_OpensPRsInRoutedRepository(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.BatchByFile = true
+	config.RepoOwner = "acme"
+	config.RepoName = "service"
+	config.RepoRouting = []RepoRoute{
+		{PathPrefix: "/app/vendor/", Owner: "acme", Repo: "shared"},
+		{PathPrefix: "/app/vendor/billing/", Owner: "acme", Repo: "billing"},
+	}
+
+	healer, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	git := &recordingGitClient{}
+	healer.gitClient = git
+	created := make(map[string]*recordingGitClient)
+	healer.routed.factory = func(owner, repo string) GitClient {
+		created[owner+"/"+repo] = &recordingGitClient{}
+		return created[owner+"/"+repo]
+	}
+
+	for _, file := range []string{"/app/main.go", "/app/vendor/billing/invoice.go", "/app/vendor/billing/tax.go", "/app/vendor/log.go"} {
+		event := PanicEvent{ID: file, SourceFile: file, LineNumber: 3}
+		change := FileChange{FilePath: file, Content: "fixed", PatchFormat: "line_range", StartLine: 3, EndLine: 3}
+		healer.batcher.add(event, &FixResponse{IsValid: true, Confidence: 0.9}, change)
+	}
+	healer.batcher.flushAll()
+
+	if len(git.requests) != 1 || git.requests[0].Changes[0].FilePath != "/app/main.go" {
+		t.Errorf("Expected unrouted fixes in the default repository, got %+v", git.requests)
+	}
+	if len(created) != 2 {
+		t.Fatalf("Expected one client per routed repository, got %v", created)
+	}
+	if billing := created["acme/billing"]; len(billing.requests) != 2 {
+		t.Errorf("Expected the longest prefix to route both billing fixes, got %+v", billing.requests)
+	}
+	if shared := created["acme/shared"]; len(shared.requests) != 1 || shared.requests[0].Changes[0].FilePath != "/app/vendor/log.go" {
+		t.Errorf("Unexpected pull requests in acme/shared: %+v", shared.requests)
+	}
+}
+
+func TestRepoRouting_NamesFilesRelativeToRoute(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.RepoOwner = "acme"
+	config.RepoName = "service"
+	config.RepoRouting = []RepoRoute{{PathPrefix: "/home/app/billing/", Owner: "acme", Repo: "billing"}}
+
+	healer, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	healer.gitClient = &recordingGitClient{}
+	billing := &recordingGitClient{}
+	healer.routed.factory = func(owner, repo string) GitClient { return billing }
+
+	event := PanicEvent{ID: "routed", SourceFile: "/home/app/billing/invoice/tax.go", LineNumber: 3}
+	fix := &FixResponse{IsValid: true, Confidence: 0.9, ProposedFix: "fixed", PatchFormat: "line_range", StartLine: 3, EndLine: 3}
+	worker := NewBackgroundWorker(1, healer, nil, nil)
+	if _, err := worker.processEventWithGit(context.Background(), event, fix); err != nil {
+		t.Fatalf("processEventWithGit failed: %v", err)
+	}
+
+	if len(billing.requests) != 1 {
+		t.Fatalf("Expected a pull request in acme/billing, got %d", len(billing.requests))
+	}
+	if path := billing.requests[0].Changes[0].FilePath; path != "invoice/tax.go" {
+		t.Errorf("Expected the change to target invoice/tax.go, got %q", path)
+	}
+}
+
+// hostRewriter sends every request to a test server in place of its host
+type hostRewriter struct {
+	target *url.URL
+}
+
+func (rw hostRewriter) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = rw.target.Scheme
+	req.URL.Host = rw.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestSubmitPullRequest_DraftByDefault(t *testing.T) {
+	var payload map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/service/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/acme/service", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"default_branch": "main"}`))
+	})
+	mux.HandleFunc("GET /repos/acme/service/git/refs/heads/main", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"object": {"sha": "base"}}`))
+	})
+	mux.HandleFunc("GET /repos/acme/service/contents/app/guard.go", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("GET /repos/acme/service/git/commits/base", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tree": {"sha": "base-tree"}}`))
+	})
+	for _, path := range []string{"git/blobs", "git/trees", "git/commits", "git/refs"} {
+		mux.HandleFunc("POST /repos/acme/service/"+path, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"sha": "created"}`))
+		})
+	}
+	mux.HandleFunc("POST /repos/acme/service/pulls", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number": 7, "html_url": "https://github.com/acme/service/pull/7"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	// A Config built without DefaultConfig leaves CreateDraftPR unset
+	healer, err := Initialize(Config{GitHubToken: "token", RepoOwner: "acme", RepoName: "service"})
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	healer.gitClient = NewGitHubClient("token", "acme", "service", &http.Client{Transport: hostRewriter{target}}, healer.logger)
+
+	_, err = healer.submitPullRequest(context.Background(), PanicEvent{ID: "p1", SourceFile: "app/guard.go"}, PRRequest{
+		BranchName: "healer/fix",
+		Title:      "Fix panic",
+		Changes:    []FileChange{{FilePath: "app/guard.go", Content: "package app\n", PatchFormat: "full_file"}},
+	})
+	if err != nil {
+		t.Fatalf("submitPullRequest failed: %v", err)
+	}
+	if payload["draft"] != true {
+		t.Errorf("Expected the pull request opened as a draft, got payload %v", payload)
+	}
+}
@@ -0,0 +1,130 @@
+// Command healer proposes a fix for a panic that already happened, for example
+// in a CI test run, without embedding the healer in the running service.
+//
+// It reads a Go panic dump from a file or stdin, generates a fix with the
+// configured AI provider and opens a pull request for it:
+//
+//	go test ./... 2>&1 | healer
+//	healer -trace panic.log -dry-run
+//
+// Configuration is read from the file given with -config and from the same
+// HEALER_* environment variables as the library; flags override both.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	healer "github.com/ajeet-kumar1087/go-code-healer"
+	"github.com/ajeet-kumar1087/go-code-healer/ai"
+)
+
+func main() {
+	configPath := flag.String("config", "", "path to a JSON or TOML config file")
+	tracePath := flag.String("trace", "-", "file holding the panic dump, or - for stdin")
+	dryRun := flag.Bool("dry-run", false, "print the proposed fix without opening a pull request")
+	timeout := flag.Duration("timeout", 5*time.Minute, "time allowed to generate the fix and open the pull request")
+	jsonOutput := flag.Bool("json", false, "print the result as JSON")
+	flag.Parse()
+
+	if err := run(*configPath, *tracePath, *dryRun, *timeout, *jsonOutput); err != nil {
+		fmt.Fprintf(os.Stderr, "healer: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath, tracePath string, dryRun bool, timeout time.Duration, jsonOutput bool) error {
+	raw, err := readTrace(tracePath)
+	if err != nil {
+		return err
+	}
+	event, err := healer.ParseStackTrace(string(raw))
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath, dryRun)
+	if err != nil {
+		return err
+	}
+	h, err := healer.Initialize(config)
+	if err != nil {
+		return err
+	}
+	defer h.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := h.HealNow(ctx, *event)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	printResult(event, result)
+	return nil
+}
+
+// readTrace reads the panic dump from path, or from stdin for "-"
+func readTrace(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// loadConfig loads the config file and environment like healer.LoadConfig,
+// but applies the flags before validating so a dry run needs no Git token
+func loadConfig(path string, dryRun bool) (healer.Config, error) {
+	config := healer.DefaultConfig()
+	if path != "" {
+		if err := config.LoadFromFile(path); err != nil {
+			return config, fmt.Errorf("failed to load config from file: %w", err)
+		}
+	}
+	if err := config.LoadFromEnv(); err != nil {
+		return config, fmt.Errorf("failed to load config from environment: %w", err)
+	}
+
+	config.Enabled = true
+	if dryRun {
+		config.DryRun = true
+	}
+	config.ApplyDefaults()
+	return config, nil
+}
+
+// printResult prints the proposed fix and the pull request opened for it
+func printResult(event *healer.PanicEvent, result *ai.SessionResult) {
+	fmt.Printf("Panic: %s\n", event.Error)
+	if event.SourceFile != "" {
+		fmt.Printf("Location: %s:%d (%s)\n", event.SourceFile, event.LineNumber, event.Function)
+	}
+
+	if fix := result.FixResponse; fix != nil {
+		fmt.Printf("Provider: %s %s, confidence %.2f\n", fix.Provider, fix.Model, fix.Confidence)
+		if fix.Explanation != "" {
+			fmt.Printf("\n%s\n", fix.Explanation)
+		}
+		if fix.ProposedFix != "" {
+			fmt.Printf("\nProposed fix:\n%s\n", fix.ProposedFix)
+		}
+	}
+
+	switch {
+	case result.PRResult != nil:
+		fmt.Printf("\nPull request: %s\n", result.PRResult.URL)
+	case result.Error != "":
+		fmt.Printf("\nNo pull request opened: %s\n", result.Error)
+	}
+}
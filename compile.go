@@ -0,0 +1,94 @@
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gh "github.com/ajeet-kumar1087/go-code-healer/github"
+)
+
+// compileCheckTimeout bounds how long a single compile check may run
+const compileCheckTimeout = 2 * time.Minute
+
+// errCompileCheckUnavailable is returned when a fix cannot be compiled
+// locally, because the Go toolchain or the source file is missing
+var errCompileCheckUnavailable = errors.New("compile check unavailable")
+
+// checkFixCompiles applies change to the source file on disk and builds the
+// package with the patched file swapped in through a build overlay, so
+// nothing in the working tree is modified. A fix that parses but references
+// undefined symbols or breaks types fails here.
+func checkFixCompiles(ctx context.Context, change FileChange) error {
+	if err := checkWithinWorkingDir(change.FilePath); err != nil {
+		return fmt.Errorf("%w: %v", errCompileCheckUnavailable, err)
+	}
+
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		return fmt.Errorf("%w: go toolchain not found", errCompileCheckUnavailable)
+	}
+
+	absPath, err := filepath.Abs(change.FilePath)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errCompileCheckUnavailable, err)
+	}
+
+	original, err := os.ReadFile(absPath)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errCompileCheckUnavailable, err)
+	}
+
+	patched, err := gh.ApplyPatch(string(original), change)
+	if err != nil {
+		return fmt.Errorf("fix does not apply to %s: %w", change.FilePath, err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "healer-compile-")
+	if err != nil {
+		return fmt.Errorf("%w: %v", errCompileCheckUnavailable, err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	patchedPath := filepath.Join(tmpDir, filepath.Base(absPath))
+	if err := os.WriteFile(patchedPath, []byte(patched), 0o644); err != nil {
+		return fmt.Errorf("%w: %v", errCompileCheckUnavailable, err)
+	}
+
+	overlay, err := json.Marshal(map[string]map[string]string{
+		"Replace": {absPath: patchedPath},
+	})
+	if err != nil {
+		return err
+	}
+	overlayPath := filepath.Join(tmpDir, "overlay.json")
+	if err := os.WriteFile(overlayPath, overlay, 0o644); err != nil {
+		return fmt.Errorf("%w: %v", errCompileCheckUnavailable, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, compileCheckTimeout)
+	defer cancel()
+
+	// Test files are only compiled by vet, so use it when the fix targets one
+	args := []string{"build", "-overlay", overlayPath, "-o", os.DevNull, "."}
+	if strings.HasSuffix(absPath, "_test.go") {
+		args = []string{"vet", "-overlay", overlayPath, "."}
+	}
+
+	cmd := exec.CommandContext(ctx, goBin, args...)
+	cmd.Dir = filepath.Dir(absPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("compile check cancelled: %w", ctx.Err())
+		}
+		return fmt.Errorf("fix does not compile:\n%s", strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
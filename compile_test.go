@@ -0,0 +1,63 @@
+package healer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gh "github.com/ajeet-kumar1087/go-code-healer/github"
+)
+
+func TestCheckFixCompiles(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	dir, err := os.MkdirTemp(".", "compile-test-")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":  "module sample\n\ngo 1.21\n",
+		"main.go": "package main\n\nfunc divide(a, b int) int {\n\treturn a / b\n}\n\nfunc main() { _ = divide(1, 1) }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	path := filepath.Join(dir, "main.go")
+
+	good := FileChange{
+		FilePath:    path,
+		Content:     "\tif b == 0 {\n\t\treturn 0\n\t}\n\treturn a / b",
+		PatchFormat: gh.PatchFormatLineRange,
+		StartLine:   4,
+		EndLine:     4,
+	}
+	if err := checkFixCompiles(context.Background(), good); err != nil {
+		t.Errorf("Expected valid fix to compile, got %v", err)
+	}
+
+	bad := good
+	bad.Content = "\tif b == zero {\n\t\treturn 0\n\t}\n\treturn a / b"
+	err = checkFixCompiles(context.Background(), bad)
+	if err == nil || errors.Is(err, errCompileCheckUnavailable) {
+		t.Fatalf("Expected fix referencing an undefined symbol to be rejected, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "undefined: zero") {
+		t.Errorf("Expected compiler output in error, got %v", err)
+	}
+
+	// The working tree is never modified
+	content, _ := os.ReadFile(path)
+	if string(content) != files["main.go"] {
+		t.Errorf("Source file was modified by the compile check")
+	}
+}
@@ -0,0 +1,64 @@
+package healer
+
+import (
+	"context"
+	"maps"
+)
+
+// metadataKey is the context key under which panic metadata is stored
+type metadataKey struct{}
+
+// WithContext returns a copy of ctx carrying metadata (trace ID, user ID,
+// request ID, ...) that is attached to any panic captured with HandlePanicCtx
+// or RecoverAndHandleCtx. Metadata already on ctx is kept unless overridden.
+func WithContext(ctx context.Context, metadata map[string]string) context.Context {
+	merged := maps.Clone(MetadataFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(metadata))
+	}
+	maps.Copy(merged, metadata)
+	return context.WithValue(ctx, metadataKey{}, merged)
+}
+
+// MetadataFromContext returns the panic metadata stored on ctx, or nil if there is none.
+// The returned map must not be modified.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	metadata, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return metadata
+}
+
+// HandlePanicCtx is like HandlePanic but attaches the metadata stored on ctx
+// Usage: defer healer.HandlePanicCtx(ctx)
+func HandlePanicCtx(ctx context.Context) {
+	if r := recover(); r != nil {
+		CapturePanicCtx(ctx, r)
+
+		// Re-panic to maintain normal panic behavior
+		panic(r)
+	}
+}
+
+// RecoverAndHandleCtx is like RecoverAndHandle but attaches the metadata stored on ctx
+// Usage: defer healer.RecoverAndHandleCtx(ctx)
+func RecoverAndHandleCtx(ctx context.Context) {
+	if r := recover(); r != nil {
+		CapturePanicCtx(ctx, r)
+
+		// Log the panic but don't re-panic (graceful recovery)
+		if logger := globalLogger(); logger != nil {
+			logger.Error("Recovered from panic: %v", r)
+		}
+	}
+}
+
+// SafeGoroutineCtx starts a goroutine with panic capture and recovery,
+// attaching the metadata stored on ctx to any captured panic
+func SafeGoroutineCtx(ctx context.Context, fn func(ctx context.Context)) {
+	go func() {
+		defer RecoverAndHandleCtx(ctx)
+		fn(ctx)
+	}()
+}
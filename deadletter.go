@@ -0,0 +1,137 @@
+package healer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// maxDeadLetterEvents bounds the in-memory dead-letter ring buffer
+const maxDeadLetterEvents = 100
+
+// deadLetterQueue keeps events that failed processing after all retries so
+// operators can handle them manually. The most recent events are kept in a
+// ring buffer and, when a path is configured, every event is also appended to
+// a JSON-lines file. All methods are safe to call on a nil queue.
+type deadLetterQueue struct {
+	logger Logger
+	mu     sync.Mutex
+	events []PanicEvent // ring buffer, next is the oldest entry once full
+	next   int
+	full   bool
+	file   *os.File
+}
+
+// newDeadLetterQueue creates a dead-letter queue, opening path for appending if set
+func newDeadLetterQueue(path string, logger Logger) (*deadLetterQueue, error) {
+	dlq := &deadLetterQueue{
+		logger: logger,
+		events: make([]PanicEvent, maxDeadLetterEvents),
+	}
+
+	if path == "" {
+		return dlq, nil
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create dead-letter directory: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	dlq.file = file
+
+	return dlq, nil
+}
+
+// add records a terminally failed event
+func (d *deadLetterQueue) add(event PanicEvent) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.events[d.next] = event
+	d.next = (d.next + 1) % len(d.events)
+	if d.next == 0 {
+		d.full = true
+	}
+
+	if d.file == nil {
+		return
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		d.warn("Failed to encode dead-letter event %s: %v", event.ID, err)
+		return
+	}
+	if _, err := d.file.Write(append(line, '\n')); err != nil {
+		d.warn("Failed to write dead-letter event %s: %v", event.ID, err)
+	}
+}
+
+// list returns the buffered events, oldest first
+func (d *deadLetterQueue) list() []PanicEvent {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.full {
+		return append([]PanicEvent(nil), d.events[:d.next]...)
+	}
+
+	result := make([]PanicEvent, 0, len(d.events))
+	result = append(result, d.events[d.next:]...)
+	return append(result, d.events[:d.next]...)
+}
+
+// len returns the number of buffered events
+func (d *deadLetterQueue) len() int {
+	if d == nil {
+		return 0
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.full {
+		return len(d.events)
+	}
+	return d.next
+}
+
+// close closes the dead-letter file if one is open
+func (d *deadLetterQueue) close() error {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.file == nil {
+		return nil
+	}
+	err := d.file.Close()
+	d.file = nil
+	return err
+}
+
+// warn logs a dead-letter problem without failing the caller
+func (d *deadLetterQueue) warn(msg string, args ...any) {
+	if d.logger != nil {
+		d.logger.Warn(msg, args...)
+	}
+}
@@ -0,0 +1,138 @@
+package healer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DiscordNotifier posts an embed to a Discord webhook when a fix PR is opened
+type DiscordNotifier struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// discordTimeout bounds a single webhook post
+const discordTimeout = 10 * time.Second
+
+// discordEmbedColor is the color bar of the embed, Discord's green
+const discordEmbedColor = 0x57F287
+
+// NewDiscordNotifier creates a Discord notifier for the given webhook URL.
+// A nil httpClient creates one with a 10 second timeout.
+func NewDiscordNotifier(webhookURL string, httpClient *http.Client) *DiscordNotifier {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: discordTimeout}
+	}
+	return &DiscordNotifier{
+		webhookURL: webhookURL,
+		httpClient: httpClient,
+	}
+}
+
+// discordMessage is the payload accepted by Discord webhooks
+type discordMessage struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	URL         string              `json:"url,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// Notify posts created pull requests to Discord (implements Notifier)
+func (d *DiscordNotifier) Notify(ctx context.Context, event HealerEvent) error {
+	if event.Type != EventPRCreated {
+		return nil
+	}
+
+	payload, err := json.Marshal(discordMessage{Embeds: []discordEmbed{formatDiscordEmbed(event)}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Discord message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", d.webhookURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create Discord request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Discord request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Discord answers 204 No Content unless the webhook URL asks to wait for the message
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("Discord webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// formatDiscordEmbed builds the embed for a created PR with the error,
+// location, confidence, provider and PR link
+func formatDiscordEmbed(event HealerEvent) discordEmbed {
+	panicEvent := event.Event
+	embed := discordEmbed{
+		Title:       "Healer opened a fix PR",
+		Description: truncateDiscordField(panicEvent.Error, 4096),
+		Color:       discordEmbedColor,
+	}
+	if !event.Time.IsZero() {
+		embed.Timestamp = event.Time.UTC().Format(time.RFC3339)
+	}
+
+	field := func(name, value string, inline bool) {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: name, Value: truncateDiscordField(value, 1024), Inline: inline})
+	}
+	if panicEvent.SourceFile != "" {
+		field("Location", fmt.Sprintf("`%s:%d` in `%s`", panicEvent.SourceFile, panicEvent.LineNumber, panicEvent.Function), false)
+	}
+	if fix := event.Fix; fix != nil {
+		field("Confidence", fmt.Sprintf("%.0f%%", fix.Confidence*100), true)
+		if fix.Provider != "" {
+			field("Provider", fix.Provider, true)
+		}
+	}
+	if len(panicEvent.Tags) > 0 {
+		tags := make([]string, 0, len(panicEvent.Tags))
+		for _, key := range sortedKeys(panicEvent.Tags) {
+			tags = append(tags, fmt.Sprintf("`%s=%s`", key, panicEvent.Tags[key]))
+		}
+		field("Tags", strings.Join(tags, " "), false)
+	}
+	if pr := event.PR; pr != nil {
+		embed.URL = pr.URL
+		if pr.URL != "" {
+			field("Pull Request", fmt.Sprintf("[#%d %s](%s)", pr.Number, pr.Title, pr.URL), false)
+		} else if pr.Title != "" {
+			field("Pull Request", pr.Title, false)
+		}
+	}
+	return embed
+}
+
+// truncateDiscordField shortens s to the limit of characters Discord accepts for an embed field
+func truncateDiscordField(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,47 @@
+// Package echohealer adapts go-code-healer panic capture to the Echo web framework.
+//
+// It lives in its own module so applications that do not use Echo never pull
+// in the Echo dependency:
+//
+//	e := echo.New()
+//	e.Use(echohealer.Middleware())
+package echohealer
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	healer "github.com/ajeet-kumar1087/go-code-healer"
+)
+
+// Middleware returns an Echo middleware that recovers panics raised by the
+// handler, reports them to the global healer together with the request method
+// and route, and returns a 500 echo.HTTPError for Echo's error handler
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					healer.CapturePanicWithMetadata(r, requestMetadata(c))
+					err = echo.NewHTTPError(http.StatusInternalServerError).SetInternal(fmt.Errorf("panic: %v", r))
+				}
+			}()
+
+			return next(c)
+		}
+	}
+}
+
+// requestMetadata describes the request being served when the panic occurred
+func requestMetadata(c echo.Context) map[string]string {
+	metadata := map[string]string{
+		"http_method": c.Request().Method,
+		"http_path":   c.Request().URL.Path,
+	}
+	if route := c.Path(); route != "" {
+		metadata["http_route"] = route
+	}
+	return metadata
+}
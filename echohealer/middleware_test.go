@@ -0,0 +1,54 @@
+package echohealer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	healer "github.com/ajeet-kumar1087/go-code-healer"
+)
+
+func TestMiddlewareCapturesHandlerPanic(t *testing.T) {
+	config := healer.DefaultConfig()
+	config.Enabled = false // queue events without starting workers
+
+	h, err := healer.Initialize(config)
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	h.InstallPanicHandler()
+	defer h.RestorePanicHandler()
+
+	e := echo.New()
+	e.Use(Middleware())
+	e.GET("/orders/:id", func(c echo.Context) error {
+		panic("order not found")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+
+	queue := h.GetErrorQueue()
+	if len(queue) != 1 {
+		t.Fatalf("queue length = %d, want 1", len(queue))
+	}
+
+	select {
+	case event := <-queue:
+		if event.Error != "order not found" {
+			t.Errorf("event error = %q", event.Error)
+		}
+		if event.Metadata["http_method"] != http.MethodGet || event.Metadata["http_route"] != "/orders/:id" {
+			t.Errorf("unexpected request metadata: %v", event.Metadata)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+}
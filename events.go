@@ -0,0 +1,119 @@
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// eventStreamBuffer is how many events a stream subscriber can fall behind
+// before further events are dropped for it
+const eventStreamBuffer = 64
+
+// HealerEventType identifies what happened in a HealerEvent
+type HealerEventType string
+
+// Event stream event types, one per lifecycle hook
+const (
+	EventPanicCaptured    HealerEventType = "panic_captured"
+	EventFixGenerated     HealerEventType = "fix_generated"
+	EventPRCreated        HealerEventType = "pr_created"
+	EventProcessingFailed HealerEventType = "processing_failed"
+)
+
+// HealerEvent is a lifecycle notification delivered by EventStream
+type HealerEvent struct {
+	Type  HealerEventType `json:"type"`
+	Time  time.Time       `json:"time"`
+	Event PanicEvent      `json:"event"`
+	Fix   *FixResponse    `json:"fix,omitempty"`   // set for EventFixGenerated, and for EventPRCreated sent to notifiers
+	PR    *PRResult       `json:"pr,omitempty"`    // set for EventPRCreated
+	Error string          `json:"error,omitempty"` // set for EventProcessingFailed
+}
+
+// eventStreams fans lifecycle events out to stream subscribers. Sends never
+// block: a subscriber whose buffer is full misses the event, so a slow
+// consumer can never hold up a worker.
+type eventStreams struct {
+	mu          sync.Mutex
+	subscribers map[chan HealerEvent]struct{}
+	dropped     atomic.Int64
+}
+
+// subscribe adds a subscriber that is removed and closed once ctx is done
+func (s *eventStreams) subscribe(ctx context.Context) <-chan HealerEvent {
+	ch := make(chan HealerEvent, eventStreamBuffer)
+
+	s.mu.Lock()
+	if s.subscribers == nil {
+		s.subscribers = make(map[chan HealerEvent]struct{})
+	}
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		close(ch)
+		s.mu.Unlock()
+	}()
+
+	return ch
+}
+
+// publish delivers event to every subscriber with room in its buffer
+func (s *eventStreams) publish(event HealerEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// EventStream returns a channel of lifecycle events for live dashboards. Each
+// call creates an independent subscription that is closed when ctx is done.
+// Events are dropped for a subscriber that falls more than 64 events behind.
+func (h *Healer) EventStream(ctx context.Context) <-chan HealerEvent {
+	return h.events.subscribe(ctx)
+}
+
+// EventStreamHandler returns an HTTP handler that streams lifecycle events as
+// server-sent events, one JSON-encoded HealerEvent per message with the event
+// type as the SSE event name.
+// Usage: mux.Handle("/healer/events", h.EventStreamHandler())
+func (h *Healer) EventStreamHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for event := range h.EventStream(r.Context()) {
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	healer "github.com/ajeet-kumar1087/go-code-healer"
 	"github.com/ajeet-kumar1087/go-code-healer/ai"
+	"github.com/ajeet-kumar1087/go-code-healer/github"
 	"github.com/ajeet-kumar1087/go-code-healer/internal"
 )
 
@@ -21,12 +22,12 @@ func (l *SimpleLogger) Error(msg string, args ...any) { log.Printf("[ERROR] "+ms
 // MockGitClient implements GitClientInterface for demonstration
 type MockGitClient struct{}
 
-func (m *MockGitClient) CreatePullRequest(ctx context.Context, request ai.PRRequest) error {
+func (m *MockGitClient) CreatePullRequest(ctx context.Context, request ai.PRRequest) (*github.PRResult, error) {
 	log.Printf("Creating PR: %s", request.Title)
 	log.Printf("Branch: %s", request.BranchName)
 	log.Printf("Files changed: %d", len(request.Changes))
 	log.Printf("Description preview: %.100s...", request.Description)
-	return nil
+	return &github.PRResult{Title: request.Title}, nil
 }
 
 func main() {
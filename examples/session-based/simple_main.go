@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/ajeet-kumar1087/go-code-healer/ai"
+	"github.com/ajeet-kumar1087/go-code-healer/github"
 )
 
 // SimpleLogger implements a basic logger for the demo
@@ -20,12 +21,12 @@ func (l *SimpleLogger) SetLevel(level interface{})    { /* no-op for demo */ }
 // MockGitClient implements GitClientInterface for demonstration
 type MockGitClient struct{}
 
-func (m *MockGitClient) CreatePullRequest(ctx context.Context, request ai.PRRequest) error {
+func (m *MockGitClient) CreatePullRequest(ctx context.Context, request ai.PRRequest) (*github.PRResult, error) {
 	log.Printf("Creating PR: %s", request.Title)
 	log.Printf("Branch: %s", request.BranchName)
 	log.Printf("Files changed: %d", len(request.Changes))
 	log.Printf("Description preview: %.100s...", request.Description)
-	return nil
+	return &github.PRResult{Title: request.Title}, nil
 }
 
 func main() {
@@ -39,7 +40,7 @@ func main() {
 	gitClient := &MockGitClient{}
 
 	// Create a simple OpenAI client for demonstration (nil logger for simplicity)
-	openaiClient := ai.NewOpenAIClient("demo-key", "gpt-4", nil)
+	openaiClient := ai.NewOpenAIClient("demo-key", "gpt-4", 0, nil, nil)
 
 	// Create session manager directly
 	session := ai.NewSessionManager(openaiClient, nil, gitClient, nil)
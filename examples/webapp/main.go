@@ -288,21 +288,16 @@ func (app *Application) handlePanic(w http.ResponseWriter, r *http.Request) {
 }
 
 func (app *Application) handleHealerStatus(w http.ResponseWriter, r *http.Request) {
-	var status map[string]interface{}
-
 	if app.healer != nil {
-		status = app.healer.GetStatus()
-		queueStats := app.healer.GetQueueStats()
-		status["queue_stats"] = queueStats
-	} else {
-		status = map[string]interface{}{
-			"enabled": false,
-			"message": "Healer not installed",
-		}
+		app.healer.StatusHandler().ServeHTTP(w, r)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled": false,
+		"message": "Healer not installed",
+	})
 }
 
 // Background Workers
@@ -0,0 +1,167 @@
+package healer_test
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	healer "github.com/ajeet-kumar1087/go-code-healer"
+)
+
+// panicSite records the line of the statement that panics
+var panicSite int
+
+func indexOutOfRange() {
+	var values []int
+	_, _, panicSite, _ = runtime.Caller(0)
+	_ = values[5]
+}
+
+// capturedHook forwards captured panics to a channel
+type capturedHook struct {
+	healer.NoopHook
+	events chan healer.PanicEvent
+}
+
+func (c capturedHook) OnPanicCaptured(event healer.PanicEvent) {
+	c.events <- event
+}
+
+func TestPanicEvent_ReportsUserPanicSite(t *testing.T) {
+	config := healer.DefaultConfig()
+	config.Enabled = false
+	config.DedupWindow = -1
+	config.HealExcludePaths = []string{} // the panics come from a _test.go file
+
+	h, err := healer.Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	h.InstallPanicHandler()
+	defer h.RestorePanicHandler()
+
+	captured := capturedHook{events: make(chan healer.PanicEvent, 1)}
+	h.RegisterHook(captured)
+
+	entryPoints := map[string]func(){
+		"HandlePanic": func() {
+			defer func() { recover() }()
+			defer healer.HandlePanic()
+			indexOutOfRange()
+		},
+		"RecoverAndHandle": func() {
+			defer healer.RecoverAndHandle()
+			indexOutOfRange()
+		},
+		"WrapFunctionWithRecovery": healer.WrapFunctionWithRecovery(indexOutOfRange),
+		"WrapFn": func() {
+			_, _ = healer.WrapFn(func() (int, error) {
+				indexOutOfRange()
+				return 0, nil
+			})()
+		},
+	}
+
+	for name, run := range entryPoints {
+		t.Run(name, func(t *testing.T) {
+			run()
+
+			var event healer.PanicEvent
+			select {
+			case event = <-captured.events:
+			case <-time.After(time.Second):
+				t.Fatal("Expected the panic to be captured")
+			}
+			if !strings.HasSuffix(event.SourceFile, "frames_test.go") || event.LineNumber != panicSite+1 {
+				t.Errorf("Expected panic site frames_test.go:%d, got %s:%d", panicSite+1, event.SourceFile, event.LineNumber)
+			}
+			if !strings.HasSuffix(event.Function, ".indexOutOfRange") {
+				t.Errorf("Expected function indexOutOfRange, got %s", event.Function)
+			}
+		})
+	}
+}
+
+func TestHandlePanic_RepanicKeepsOriginalValueAndSite(t *testing.T) {
+	var (
+		recovered any
+		stack     string
+	)
+	func() {
+		defer func() {
+			recovered = recover()
+			stack = string(debug.Stack())
+		}()
+		defer healer.HandlePanic()
+		indexOutOfRange()
+	}()
+
+	if err, ok := recovered.(runtime.Error); !ok || !strings.Contains(err.Error(), "index out of range") {
+		t.Fatalf("Expected the original runtime error to be re-panicked, got %T: %v", recovered, recovered)
+	}
+	if !strings.Contains(stack, ".indexOutOfRange(") {
+		t.Errorf("Expected an outer recover to still see the original panic site on the stack, got:\n%s", stack)
+	}
+}
+
+// recurse calls indexOutOfRange depth calls deep
+func recurse(depth int) {
+	if depth == 0 {
+		indexOutOfRange()
+		return
+	}
+	recurse(depth - 1)
+}
+
+func TestPanicEvent_TrimsDeepStacks(t *testing.T) {
+	config := healer.DefaultConfig()
+	config.Enabled = false
+	config.DedupWindow = -1
+	config.HealExcludePaths = []string{}
+	config.MaxStackFrames = 5
+	config.MaxStackTraceBytes = 1024
+
+	h, err := healer.Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	h.InstallPanicHandler()
+	defer h.RestorePanicHandler()
+
+	captured := capturedHook{events: make(chan healer.PanicEvent, 1)}
+	h.RegisterHook(captured)
+
+	func() {
+		defer healer.RecoverAndHandle()
+		recurse(100)
+	}()
+
+	var event healer.PanicEvent
+	select {
+	case event = <-captured.events:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the panic to be captured")
+	}
+
+	lines := strings.Split(event.RawStackTrace, "\n")
+	if len(lines) != 6 || len(event.RawStackTrace) > config.MaxStackTraceBytes {
+		t.Fatalf("Expected 5 frames and a marker within %d bytes, got %d bytes:\n%s", config.MaxStackTraceBytes, len(event.RawStackTrace), event.RawStackTrace)
+	}
+	// The frame raising the panic comes first, then the top user frames
+	if !strings.Contains(lines[0], " runtime.") {
+		t.Errorf("Expected the runtime frame raising the panic first, got %s", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], ".indexOutOfRange") || !strings.HasSuffix(lines[2], ".recurse") {
+		t.Errorf("Expected the top user frames next, got %v", lines[1:3])
+	}
+	if !strings.HasSuffix(lines[5], "frames omitted)") {
+		t.Errorf("Expected a marker for the omitted frames, got %s", lines[5])
+	}
+
+	// The cleaned trace keeps only the user's call chain
+	if want := strings.Join(lines[1:], "\n"); event.StackTrace != want {
+		t.Errorf("Expected the stack trace without the runtime frame, got:\n%s", event.StackTrace)
+	}
+}
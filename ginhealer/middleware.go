@@ -0,0 +1,52 @@
+// Package ginhealer adapts go-code-healer panic capture to the gin web framework.
+//
+// It lives in its own module so applications that do not use gin never pull
+// in the gin dependency:
+//
+//	router := gin.New()
+//	router.Use(ginhealer.Middleware())
+package ginhealer
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	healer "github.com/ajeet-kumar1087/go-code-healer"
+)
+
+// Middleware returns a gin middleware that recovers panics raised further down
+// the handler chain, reports them to the global healer together with the
+// request method and route, and responds with a 500 JSON error
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				healer.CapturePanicWithMetadata(r, requestMetadata(c))
+
+				// Headers may already be on the wire if the handler panicked mid-response
+				if c.Writer.Written() {
+					c.Abort()
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": http.StatusText(http.StatusInternalServerError),
+				})
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// requestMetadata describes the request being served when the panic occurred
+func requestMetadata(c *gin.Context) map[string]string {
+	metadata := map[string]string{
+		"http_method": c.Request.Method,
+		"http_path":   c.Request.URL.Path,
+	}
+	if route := c.FullPath(); route != "" {
+		metadata["http_route"] = route
+	}
+	return metadata
+}
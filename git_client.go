@@ -2,109 +2,204 @@ package healer
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
 
 	gh "github.com/ajeet-kumar1087/go-code-healer/github"
+	"github.com/ajeet-kumar1087/go-code-healer/gitlab"
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
 )
 
+// gitClientTimeout bounds a single Git provider API request
+const gitClientTimeout = 30 * time.Second
+
 // GitHubAPIClient wraps the github module client to implement GitClient interface
 type GitHubAPIClient struct {
 	client *gh.GitHubAPIClient
 }
 
-// NewGitHubClient creates a new GitHub API client using the github module
-func NewGitHubClient(token, repoOwner, repoName string, logger Logger) *GitHubAPIClient {
+// NewGitHubClient creates a new GitHub API client using the github module.
+// A nil httpClient creates one with a 30 second timeout.
+func NewGitHubClient(token, repoOwner, repoName string, httpClient *http.Client, logger Logger) *GitHubAPIClient {
 	return &GitHubAPIClient{
-		client: gh.NewGitHubClient(token, repoOwner, repoName, logger),
+		client: gh.NewGitHubClient(token, repoOwner, repoName, httpClient, logger),
 	}
 }
 
 // CreatePullRequest creates a new branch, commits changes, and opens a PR
-func (gc *GitHubAPIClient) CreatePullRequest(ctx context.Context, request PRRequest) error {
-	// Convert healer types to github module types
-	githubRequest := gh.PRRequest{
-		BranchName:  request.BranchName,
-		Title:       request.Title,
-		Description: request.Description,
-		Changes:     make([]gh.FileChange, len(request.Changes)),
+func (gc *GitHubAPIClient) CreatePullRequest(ctx context.Context, request PRRequest) (*PRResult, error) {
+	return gc.client.CreatePullRequest(ctx, request)
+}
+
+// CreateIssue opens an issue tracking a panic that could not be fixed confidently
+func (gc *GitHubAPIClient) CreateIssue(ctx context.Context, request IssueRequest) (*IssueResult, error) {
+	return gc.client.CreateIssue(ctx, request)
+}
+
+// GitLabAPIClient wraps the gitlab module client to implement GitClient interface
+type GitLabAPIClient struct {
+	client *gitlab.GitLabAPIClient
+}
+
+// NewGitLabClient creates a new GitLab API client using the gitlab module.
+// namespace is the group (or user) owning the project; an empty baseURL targets gitlab.com.
+// A nil httpClient creates one with a 30 second timeout.
+func NewGitLabClient(token, namespace, project, baseURL string, httpClient *http.Client, logger Logger) *GitLabAPIClient {
+	return &GitLabAPIClient{
+		client: gitlab.NewGitLabClient(token, namespace, project, baseURL, httpClient, logger),
+	}
+}
+
+// CreatePullRequest creates a new branch, commits changes, and opens a merge request
+func (gc *GitLabAPIClient) CreatePullRequest(ctx context.Context, request PRRequest) (*PRResult, error) {
+	return gc.client.CreatePullRequest(ctx, request)
+}
+
+// submitPullRequest creates a pull request through the Git circuit breaker,
+// retrying transient failures. The configured labels, assignees and reviewers
+// are added to the request, and it is opened as a draft if configured. The
+// pull request is opened in the repository event's source file routes to.
+func (h *Healer) submitPullRequest(ctx context.Context, event PanicEvent, prRequest PRRequest) (*PRResult, error) {
+	config := h.getConfig()
+	prRequest.Labels = config.PRLabels
+	prRequest.Assignees = config.PRAssignees
+	prRequest.Reviewers = config.PRReviewers
+	prRequest.Draft = config.WantsDraftPR()
+	prRequest.BaseSHA = h.baseSHA(event.SourceFile)
+
+	gitClient := h.gitClientFor(event.SourceFile)
+	var prResult *PRResult
+	err := h.retryManager.ExecuteWithRetry(ctx, fmt.Sprintf("git-pr-%s", event.ID), func() error {
+		err := h.gitBreaker.Execute(ctx, "git-pull-request", func() error {
+			var err error
+			prResult, err = gitClient.CreatePullRequest(ctx, prRequest)
+			return err
+		})
+		h.pauseGitOnRateLimit(err)
+		return err
+	})
+	return prResult, err
+}
+
+// commitMessage renders the configured commit message template for the commit
+// fixing event, together with fixes-1 other panics batched with it. It returns
+// an empty message, leaving the default to the Git client, if rendering fails.
+func (h *Healer) commitMessage(event PanicEvent, fix *FixResponse, fixes int) string {
+	data := internal.CommitMessageData{
+		File:      event.SourceFile,
+		Line:      event.LineNumber,
+		Function:  event.Function,
+		Error:     event.Error,
+		ErrorType: event.ErrorType,
+		Severity:  event.Severity,
+		Fixes:     fixes,
+	}
+	if fix != nil {
+		data.Confidence = fix.Confidence
 	}
 
-	for i, change := range request.Changes {
-		githubRequest.Changes[i] = gh.FileChange{
-			FilePath: change.FilePath,
-			Content:  change.Content,
+	tmpl, err := internal.NewCommitMessageTemplate(h.getConfig().CommitMessageTemplate)
+	if err == nil {
+		var message string
+		if message, err = tmpl.Render(data); err == nil {
+			return message
 		}
 	}
+	if h.logger != nil {
+		h.logger.Warn("Using the default commit message: %v", err)
+	}
+	return ""
+}
+
+// pauseGitOnRateLimit holds the Git circuit breaker open until a rate limit
+// reported by err resets, so queued fixes fail fast instead of each hitting
+// the limit, and healing resumes on its own afterwards
+func (h *Healer) pauseGitOnRateLimit(err error) {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		h.gitBreaker.OpenUntil(rateLimitErr.Reset)
+	}
+}
+
+// submitIssue opens an issue through the Git circuit breaker, retrying
+// transient failures, in the repository event's source file routes to. It
+// returns nil when the Git client cannot open issues.
+func (h *Healer) submitIssue(ctx context.Context, event PanicEvent, issueRequest IssueRequest) (*IssueResult, error) {
+	creator, ok := h.gitClientFor(event.SourceFile).(IssueCreator)
+	if !ok {
+		return nil, nil
+	}
+
+	issueRequest.Labels = h.getConfig().PRLabels
 
-	// Delegate to the github module
-	return gc.client.CreatePullRequest(ctx, githubRequest)
+	var issueResult *IssueResult
+	err := h.retryManager.ExecuteWithRetry(ctx, fmt.Sprintf("git-issue-%s", event.ID), func() error {
+		err := h.gitBreaker.Execute(ctx, "git-issue", func() error {
+			var err error
+			issueResult, err = creator.CreateIssue(ctx, issueRequest)
+			return err
+		})
+		h.pauseGitOnRateLimit(err)
+		return err
+	})
+	return issueResult, err
 }
 
 // GenerateBranchName creates a descriptive branch name for the panic fix
 func GenerateBranchName(panicEvent PanicEvent) string {
-	// Convert healer PanicEvent to github PanicEvent
-	githubEvent := gh.PanicEvent{
-		ID:         panicEvent.ID,
-		Timestamp:  panicEvent.Timestamp,
-		Error:      panicEvent.Error,
-		StackTrace: panicEvent.StackTrace,
-		SourceFile: panicEvent.SourceFile,
-		LineNumber: panicEvent.LineNumber,
-		Function:   panicEvent.Function,
-		Status:     panicEvent.Status,
-	}
-	if panicEvent.ProcessedAt != nil {
-		githubEvent.ProcessedAt = panicEvent.ProcessedAt
-	}
+	githubEvent := toGitHubPanicEvent(panicEvent)
 
 	return gh.GenerateBranchName(githubEvent)
 }
 
 // GeneratePRTitle creates a descriptive title for the pull request
 func GeneratePRTitle(panicEvent PanicEvent) string {
-	// Convert healer PanicEvent to github PanicEvent
-	githubEvent := gh.PanicEvent{
-		ID:         panicEvent.ID,
-		Timestamp:  panicEvent.Timestamp,
-		Error:      panicEvent.Error,
-		StackTrace: panicEvent.StackTrace,
-		SourceFile: panicEvent.SourceFile,
-		LineNumber: panicEvent.LineNumber,
-		Function:   panicEvent.Function,
-		Status:     panicEvent.Status,
-	}
-	if panicEvent.ProcessedAt != nil {
-		githubEvent.ProcessedAt = panicEvent.ProcessedAt
-	}
+	githubEvent := toGitHubPanicEvent(panicEvent)
 
 	return gh.GeneratePRTitle(githubEvent)
 }
 
 // GeneratePRDescription creates a comprehensive description for the pull request
 func GeneratePRDescription(panicEvent PanicEvent, fixResponse *FixResponse) string {
-	// Convert healer types to github types
+	return gh.GeneratePRDescription(toGitHubPanicEvent(panicEvent), toGitHubFixResponse(fixResponse))
+}
+
+// toGitHubFixResponse converts an AI fix response to the github module's FixResponse
+func toGitHubFixResponse(fixResponse *FixResponse) *gh.FixResponse {
+	if fixResponse == nil {
+		return nil
+	}
+	return &gh.FixResponse{
+		ProposedFix: fixResponse.ProposedFix,
+		Explanation: fixResponse.Explanation,
+		Confidence:  fixResponse.Confidence,
+		IsValid:     fixResponse.IsValid,
+	}
+}
+
+// toGitHubPanicEvent converts a healer PanicEvent to the github module's PanicEvent
+func toGitHubPanicEvent(panicEvent PanicEvent) gh.PanicEvent {
 	githubEvent := gh.PanicEvent{
 		ID:         panicEvent.ID,
 		Timestamp:  panicEvent.Timestamp,
 		Error:      panicEvent.Error,
+		ErrorType:  panicEvent.ErrorType,
 		StackTrace: panicEvent.StackTrace,
 		SourceFile: panicEvent.SourceFile,
 		LineNumber: panicEvent.LineNumber,
 		Function:   panicEvent.Function,
 		Status:     panicEvent.Status,
+		Severity:   panicEvent.Severity,
+
+		Fingerprint:   panicEvent.Fingerprint,
+		AllGoroutines: panicEvent.AllGoroutines,
+		Metadata:      panicEvent.Metadata,
+		Tags:          panicEvent.Tags,
 	}
 	if panicEvent.ProcessedAt != nil {
 		githubEvent.ProcessedAt = panicEvent.ProcessedAt
 	}
-
-	var githubFixResponse *gh.FixResponse
-	if fixResponse != nil {
-		githubFixResponse = &gh.FixResponse{
-			ProposedFix: fixResponse.ProposedFix,
-			Explanation: fixResponse.Explanation,
-			Confidence:  fixResponse.Confidence,
-			IsValid:     fixResponse.IsValid,
-		}
-	}
-
-	return gh.GeneratePRDescription(githubEvent, githubFixResponse)
+	return githubEvent
 }
@@ -21,7 +21,7 @@ func (gc *GitHubAPIClient) getDefaultBranch(ctx context.Context) (string, error)
 	req.Header.Set("Authorization", "token "+gc.token)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	resp, err := gc.httpClient.Do(req)
+	resp, err := gc.do(req)
 	if err != nil {
 		return "", err
 	}
@@ -55,7 +55,7 @@ func (gc *GitHubAPIClient) getBranchSHA(ctx context.Context, branchName string)
 	req.Header.Set("Authorization", "token "+gc.token)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	resp, err := gc.httpClient.Do(req)
+	resp, err := gc.do(req)
 	if err != nil {
 		return "", err
 	}
@@ -79,7 +79,7 @@ func (gc *GitHubAPIClient) getBranchSHA(ctx context.Context, branchName string)
 	return ref.Object.SHA, nil
 }
 
-// createBranch creates a new branch from the base SHA
+// createBranch creates a new branch pointing at the commit baseSHA
 func (gc *GitHubAPIClient) createBranch(ctx context.Context, branchName, baseSHA string) error {
 	url := fmt.Sprintf("%s/repos/%s/%s/git/refs", gc.baseURL, gc.repoOwner, gc.repoName)
 
@@ -102,7 +102,7 @@ func (gc *GitHubAPIClient) createBranch(ctx context.Context, branchName, baseSHA
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := gc.httpClient.Do(req)
+	resp, err := gc.do(req)
 	if err != nil {
 		return err
 	}
@@ -116,3 +116,43 @@ func (gc *GitHubAPIClient) createBranch(ctx context.Context, branchName, baseSHA
 	gc.logger.Debug("Created branch: %s", branchName)
 	return nil
 }
+
+// CheckAccess confirms the token can read the repository and push to it,
+// so misconfigured credentials are reported before the first fix
+func (gc *GitHubAPIClient) CheckAccess(ctx context.Context) error {
+	url := fmt.Sprintf("%s/repos/%s/%s", gc.baseURL, gc.repoOwner, gc.repoName)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "token "+gc.token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := gc.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &GitHubError{StatusCode: resp.StatusCode, Message: string(body), URL: url}
+	}
+
+	var repo struct {
+		Permissions *struct {
+			Push bool `json:"push"`
+		} `json:"permissions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&repo); err != nil {
+		return err
+	}
+
+	// Permissions are only returned for authenticated requests
+	if repo.Permissions == nil || !repo.Permissions.Push {
+		return fmt.Errorf("token cannot push to %s/%s", gc.repoOwner, gc.repoName)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package github
 
 import (
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/ajeet-kumar1087/go-code-healer/internal"
@@ -16,17 +17,27 @@ type GitHubAPIClient struct {
 	httpClient *http.Client
 	logger     Logger
 	baseURL    string
+	retry      retryPolicy
+
+	// Rate limit pause shared by all calls of the client
+	rateLimitMu sync.Mutex
+	pausedUntil time.Time
 }
 
-func NewGitHubClient(token, owner, repo string, logger Logger) *GitHubAPIClient {
+// NewGitHubClient creates a client for the repository owner/repo. A nil
+// httpClient creates one with a 30 second timeout.
+func NewGitHubClient(token, owner, repo string, httpClient *http.Client, logger Logger) *GitHubAPIClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
 	return &GitHubAPIClient{
-		token:     token,
-		repoOwner: owner,
-		repoName:  repo,
-		logger:    logger,
-		baseURL:   "https://api.github.com",
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		token:      token,
+		repoOwner:  owner,
+		repoName:   repo,
+		logger:     logger,
+		baseURL:    "https://api.github.com",
+		httpClient: httpClient,
+		retry:      defaultRetryPolicy,
 	}
 }
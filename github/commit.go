@@ -0,0 +1,141 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// regularFileMode is the Git tree mode of a non-executable file
+const regularFileMode = "100644"
+
+// treeEntry is an entry of a tree created through the Git Data API
+type treeEntry struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+	Type string `json:"type"`
+	SHA  string `json:"sha"`
+}
+
+// commitFiles creates a single commit on top of parentSHA that writes every
+// file in contents, through the Git Data API: a blob per file, one tree and
+// one commit. It returns the SHA of the commit, which no ref points to yet.
+func (gc *GitHubAPIClient) commitFiles(ctx context.Context, parentSHA, message string, contents map[string]string) (string, error) {
+	var parent struct {
+		Tree struct {
+			SHA string `json:"sha"`
+		} `json:"tree"`
+	}
+	if err := gc.gitData(ctx, "GET", "commits/"+parentSHA, nil, http.StatusOK, &parent); err != nil {
+		return "", fmt.Errorf("failed to get base commit: %w", err)
+	}
+
+	// Sort paths so the same changes always produce the same tree request
+	paths := make([]string, 0, len(contents))
+	for path := range contents {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	entries := make([]treeEntry, 0, len(paths))
+	for _, path := range paths {
+		var blob struct {
+			SHA string `json:"sha"`
+		}
+		payload := map[string]string{"content": gc.encodeBase64(contents[path]), "encoding": "base64"}
+		if err := gc.gitData(ctx, "POST", "blobs", payload, http.StatusCreated, &blob); err != nil {
+			return "", fmt.Errorf("failed to create blob for %s: %w", path, err)
+		}
+		entries = append(entries, treeEntry{Path: path, Mode: regularFileMode, Type: "blob", SHA: blob.SHA})
+	}
+
+	var tree struct {
+		SHA string `json:"sha"`
+	}
+	payload := map[string]any{"base_tree": parent.Tree.SHA, "tree": entries}
+	if err := gc.gitData(ctx, "POST", "trees", payload, http.StatusCreated, &tree); err != nil {
+		return "", fmt.Errorf("failed to create tree: %w", err)
+	}
+
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	payload = map[string]any{"message": message, "tree": tree.SHA, "parents": []string{parentSHA}}
+	if err := gc.gitData(ctx, "POST", "commits", payload, http.StatusCreated, &commit); err != nil {
+		return "", fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	gc.logger.Debug("Created commit %s changing %d file(s)", commit.SHA, len(entries))
+	return commit.SHA, nil
+}
+
+// commitExists reports whether sha is a commit of the repository. Lookup
+// failures count as missing, so callers fall back to the default branch.
+func (gc *GitHubAPIClient) commitExists(ctx context.Context, sha string) bool {
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	if err := gc.gitData(ctx, "GET", "commits/"+url.PathEscape(sha), nil, http.StatusOK, &commit); err != nil {
+		gc.logger.Debug("Failed to look up commit %s: %v", sha, err)
+		return false
+	}
+	return true
+}
+
+// gitData calls the Git Data API endpoint path under /repos/{owner}/{repo}/git,
+// sending payload as JSON if set and decoding a response with status want into out
+func (gc *GitHubAPIClient) gitData(ctx context.Context, method, path string, payload any, want int, out any) error {
+	url := fmt.Sprintf("%s/repos/%s/%s/git/%s", gc.baseURL, gc.repoOwner, gc.repoName, path)
+
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "token "+gc.token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := gc.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != want {
+		respBody, _ := io.ReadAll(resp.Body)
+		return &GitHubError{
+			StatusCode: resp.StatusCode,
+			Message:    string(respBody),
+			URL:        url,
+		}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// commitMessage describes a commit writing the files in contents
+func commitMessage(contents map[string]string) string {
+	if len(contents) == 1 {
+		for path := range contents {
+			return fmt.Sprintf("Fix panic in %s\n\nAutomatically generated fix for runtime panic", path)
+		}
+	}
+	return fmt.Sprintf("Fix panics in %d files\n\nAutomatically generated fixes for runtime panics", len(contents))
+}
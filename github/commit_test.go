@@ -0,0 +1,242 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
+)
+
+func TestCreatePullRequest_CommitsAllChangesAtOnce(t *testing.T) {
+	original := "package app\n\nfunc a() {}\n\nfunc b() {}\n"
+	var blobs []string
+	var tree []treeEntry
+	var commits int
+	var branchSHA string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/service/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/acme/service", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"default_branch": "main"}`))
+	})
+	mux.HandleFunc("GET /repos/acme/service/git/refs/heads/main", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"object": {"sha": "base"}}`))
+	})
+	mux.HandleFunc("GET /repos/acme/service/contents/app/a.go", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"sha": "a-blob", "encoding": "base64", "content": base64.StdEncoding.EncodeToString([]byte(original)),
+		})
+	})
+	mux.HandleFunc("GET /repos/acme/service/git/commits/base", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tree": {"sha": "base-tree"}}`))
+	})
+	mux.HandleFunc("POST /repos/acme/service/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+		var blob struct{ Content string }
+		json.NewDecoder(r.Body).Decode(&blob)
+		content, _ := base64.StdEncoding.DecodeString(blob.Content)
+		blobs = append(blobs, string(content))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sha": "blob"}`))
+	})
+	mux.HandleFunc("POST /repos/acme/service/git/trees", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			BaseTree string      `json:"base_tree"`
+			Tree     []treeEntry `json:"tree"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		if request.BaseTree != "base-tree" {
+			t.Errorf("expected the tree to build on base-tree, got %q", request.BaseTree)
+		}
+		tree = request.Tree
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sha": "fix-tree"}`))
+	})
+	mux.HandleFunc("POST /repos/acme/service/git/commits", func(w http.ResponseWriter, r *http.Request) {
+		commits++
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sha": "fix-commit"}`))
+	})
+	mux.HandleFunc("POST /repos/acme/service/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		var ref struct{ SHA string }
+		json.NewDecoder(r.Body).Decode(&ref)
+		branchSHA = ref.SHA
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("POST /repos/acme/service/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number": 7, "html_url": "https://github.com/acme/service/pull/7"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewGitHubClient("token", "acme", "service", nil, internal.NewDefaultLogger("error"))
+	client.baseURL = server.URL
+
+	result, err := client.CreatePullRequest(context.Background(), PRRequest{
+		BranchName: "healer/fixes",
+		Title:      "Fix panics",
+		Changes: []FileChange{
+			{FilePath: "app/a.go", Content: "func b() { guard() }", PatchFormat: "line_range", StartLine: 5, EndLine: 5},
+			{FilePath: "app/a.go", Content: "func a() { guard() }", PatchFormat: "line_range", StartLine: 3, EndLine: 3},
+			{FilePath: "app/guard.go", Content: "package app\n\nfunc guard() {}\n", PatchFormat: "full_file"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreatePullRequest failed: %v", err)
+	}
+	if result.Number != 7 {
+		t.Errorf("expected pull request 7, got %+v", result)
+	}
+
+	if commits != 1 || branchSHA != "fix-commit" {
+		t.Errorf("expected the branch created at a single commit, got %d commits and branch at %q", commits, branchSHA)
+	}
+	if len(tree) != 2 || tree[0].Path != "app/a.go" || tree[1].Path != "app/guard.go" {
+		t.Fatalf("expected one tree entry per file, got %+v", tree)
+	}
+	if len(blobs) != 2 || !strings.Contains(blobs[0], "func a() { guard() }") || !strings.Contains(blobs[0], "func b() { guard() }") {
+		t.Errorf("expected both fixes to app/a.go in one blob, got %q", blobs)
+	}
+}
+
+func TestCreatePullRequest_BranchesFromBaseSHA(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		baseSHA string
+		want    string
+	}{
+		{name: "known commit", baseSHA: "deployed", want: "deployed"},
+		{name: "unknown commit", baseSHA: "unpushed", want: "tip"},
+		{name: "no commit", want: "tip"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var contentsRef, parent string
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /repos/acme/service/pulls", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`[]`))
+			})
+			mux.HandleFunc("GET /repos/acme/service", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"default_branch": "main"}`))
+			})
+			mux.HandleFunc("GET /repos/acme/service/git/refs/heads/main", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"object": {"sha": "tip"}}`))
+			})
+			mux.HandleFunc("GET /repos/acme/service/git/commits/{sha}", func(w http.ResponseWriter, r *http.Request) {
+				if sha := r.PathValue("sha"); sha != "deployed" && sha != "tip" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write([]byte(`{"sha": "` + r.PathValue("sha") + `", "tree": {"sha": "tree"}}`))
+			})
+			mux.HandleFunc("GET /repos/acme/service/contents/app/a.go", func(w http.ResponseWriter, r *http.Request) {
+				contentsRef = r.URL.Query().Get("ref")
+				json.NewEncoder(w).Encode(map[string]string{
+					"sha": "a-blob", "encoding": "base64", "content": base64.StdEncoding.EncodeToString([]byte("package app\n\nfunc a() {}\n")),
+				})
+			})
+			mux.HandleFunc("POST /repos/acme/service/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"sha": "blob"}`))
+			})
+			mux.HandleFunc("POST /repos/acme/service/git/trees", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"sha": "fix-tree"}`))
+			})
+			mux.HandleFunc("POST /repos/acme/service/git/commits", func(w http.ResponseWriter, r *http.Request) {
+				var commit struct{ Parents []string }
+				json.NewDecoder(r.Body).Decode(&commit)
+				parent = strings.Join(commit.Parents, ",")
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"sha": "fix-commit"}`))
+			})
+			mux.HandleFunc("POST /repos/acme/service/git/refs", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+			})
+			mux.HandleFunc("POST /repos/acme/service/pulls", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"number": 8, "html_url": "https://github.com/acme/service/pull/8"}`))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			client := NewGitHubClient("token", "acme", "service", nil, internal.NewDefaultLogger("error"))
+			client.baseURL = server.URL
+
+			_, err := client.CreatePullRequest(context.Background(), PRRequest{
+				BranchName: "healer/fix",
+				Title:      "Fix panic",
+				BaseSHA:    tc.baseSHA,
+				Changes:    []FileChange{{FilePath: "app/a.go", Content: "func a() { guard() }", PatchFormat: "line_range", StartLine: 3, EndLine: 3}},
+			})
+			if err != nil {
+				t.Fatalf("CreatePullRequest failed: %v", err)
+			}
+			if contentsRef != tc.want || parent != tc.want {
+				t.Errorf("expected the fix patched and committed on %s, got file at %q and parent %q", tc.want, contentsRef, parent)
+			}
+		})
+	}
+}
+
+func TestCreatePullRequest_AbortsWhenFileLookupFails(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusForbidden} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var commits int
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /repos/acme/service/pulls", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`[]`))
+			})
+			mux.HandleFunc("GET /repos/acme/service", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"default_branch": "main"}`))
+			})
+			mux.HandleFunc("GET /repos/acme/service/git/refs/heads/main", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"object": {"sha": "base"}}`))
+			})
+			mux.HandleFunc("GET /repos/acme/service/contents/app/a.go", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			})
+			mux.HandleFunc("GET /repos/acme/service/git/commits/base", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"tree": {"sha": "base-tree"}}`))
+			})
+			for _, path := range []string{"git/blobs", "git/trees", "git/refs", "pulls"} {
+				mux.HandleFunc("POST /repos/acme/service/"+path, func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{"sha": "created", "number": 7}`))
+				})
+			}
+			mux.HandleFunc("POST /repos/acme/service/git/commits", func(w http.ResponseWriter, r *http.Request) {
+				commits++
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"sha": "fix-commit"}`))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			client := NewGitHubClient("token", "acme", "service", nil, internal.NewDefaultLogger("error"))
+			client.baseURL = server.URL
+			client.retry = retryPolicy{maxAttempts: 2, initialDelay: time.Millisecond, maxDelay: time.Second}
+
+			// A full-file fix must not replace a file that could not be read
+			_, err := client.CreatePullRequest(context.Background(), PRRequest{
+				BranchName: "healer/fixes",
+				Title:      "Fix panics",
+				Changes:    []FileChange{{FilePath: "app/a.go", Content: "package app\n", PatchFormat: "full_file"}},
+			})
+			if err == nil {
+				t.Fatal("expected CreatePullRequest to fail")
+			}
+			if commits != 0 {
+				t.Errorf("expected no commit, got %d", commits)
+			}
+		})
+	}
+}
@@ -1,104 +1,115 @@
 package github
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 )
 
-// updateFile updates or creates a file in the repository
-func (gc *GitHubAPIClient) updateFile(ctx context.Context, branchName string, change FileChange) error {
-	// First, try to get the current file to get its SHA (needed for updates)
-	currentSHA, err := gc.getFileSHA(ctx, change.FilePath, branchName)
-	if err != nil {
-		gc.logger.Debug("File %s not found, will create new file", change.FilePath)
-	}
-
-	url := fmt.Sprintf("%s/repos/%s/%s/contents/%s", gc.baseURL, gc.repoOwner, gc.repoName, change.FilePath)
-
-	// Create commit message
-	commitMessage := fmt.Sprintf("Fix panic in %s\n\nAutomatically generated fix for runtime panic", change.FilePath)
-
-	payload := map[string]any{
-		"message": commitMessage,
-		"content": gc.encodeBase64(change.Content),
-		"branch":  branchName,
-	}
-
-	// If file exists, include SHA for update
-	if currentSHA != "" {
-		payload["sha"] = currentSHA
-	}
-
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Authorization", "token "+gc.token)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := gc.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("GitHub API error updating file: %d - %s", resp.StatusCode, string(body))
+// errFileNotFound is returned by getFile for files missing at the requested ref
+var errFileNotFound = errors.New("file not found")
+
+// applyChanges returns the new content of every file touched by changes,
+// keyed by repository path, with each fix merged into the file as of ref.
+// Changes to the same file are applied in order to the already changed content.
+func (gc *GitHubAPIClient) applyChanges(ctx context.Context, ref string, changes []FileChange) (map[string]string, error) {
+	contents := make(map[string]string, len(changes))
+	for i, change := range changes {
+		gc.logger.Debug("Applying change %d/%d: %s", i+1, len(changes), change.FilePath)
+		path := strings.TrimPrefix(change.FilePath, "/")
+
+		original, seen := contents[path]
+		exists := seen
+		if !seen {
+			// Missing files are created from full-file fixes
+			sha, content, err := gc.getFile(ctx, change.FilePath, ref)
+			if errors.Is(err, errFileNotFound) {
+				gc.logger.Debug("File %s not found, will create new file", change.FilePath)
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to get %s: %w", change.FilePath, err)
+			}
+			original, exists = content, sha != ""
+		}
+
+		// Merge the fix into the existing file rather than overwriting it
+		content := change.Content
+		if exists {
+			var err error
+			content, err = ApplyPatch(original, change)
+			if err != nil {
+				gc.logger.Warn("Rejected fix for %s: %v", change.FilePath, err)
+				return nil, fmt.Errorf("failed to apply fix to %s: %w", change.FilePath, err)
+			}
+		} else if DetectPatchFormat(change) != PatchFormatFullFile {
+			return nil, fmt.Errorf("cannot apply %s patch to missing file %s", DetectPatchFormat(change), change.FilePath)
+		}
+		contents[path] = content
 	}
-
-	gc.logger.Debug("Updated file: %s", change.FilePath)
-	return nil
+	return contents, nil
 }
 
-// getFileSHA gets the SHA of a file (needed for updates)
-func (gc *GitHubAPIClient) getFileSHA(ctx context.Context, filePath, branchName string) (string, error) {
+// getFile gets the SHA (needed for updates) and decoded content of a file
+func (gc *GitHubAPIClient) getFile(ctx context.Context, filePath, branchName string) (string, string, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/contents/%s?ref=%s", gc.baseURL, gc.repoOwner, gc.repoName, filePath, branchName)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	req.Header.Set("Authorization", "token "+gc.token)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	resp, err := gc.httpClient.Do(req)
+	resp, err := gc.do(req)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return "", fmt.Errorf("file not found")
+		return "", "", errFileNotFound
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("GitHub API error: %d - %s", resp.StatusCode, string(body))
+		return "", "", fmt.Errorf("GitHub API error: %d - %s", resp.StatusCode, string(body))
 	}
 
 	var file struct {
-		SHA string `json:"sha"`
+		SHA      string `json:"sha"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
-		return "", err
+		return "", "", err
+	}
+
+	content := file.Content
+	if file.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+		if err != nil {
+			return "", "", fmt.Errorf("failed to decode file content: %w", err)
+		}
+		content = string(decoded)
 	}
 
-	return file.SHA, nil
+	return file.SHA, content, nil
+}
+
+// ReadFile returns the content of the file at path, relative to the
+// repository root, as of ref, a branch, tag or commit SHA
+func (gc *GitHubAPIClient) ReadFile(ctx context.Context, path, ref string) (string, error) {
+	_, content, err := gc.getFile(ctx, strings.TrimPrefix(path, "/"), ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s at %s: %w", path, ref, err)
+	}
+	return content, nil
 }
 
 // encodeBase64 encodes content to base64 for GitHub API
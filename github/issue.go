@@ -0,0 +1,197 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GenerateIssueTitle creates a descriptive title for a panic tracking issue
+func GenerateIssueTitle(panicEvent PanicEvent) string {
+	parts := strings.Split(panicEvent.SourceFile, "/")
+	filename := parts[len(parts)-1]
+
+	title := fmt.Sprintf("Panic in %s at line %d", filename, panicEvent.LineNumber)
+	if panicEvent.Severity != "" {
+		title = fmt.Sprintf("[%s] %s", panicEvent.Severity, title)
+	}
+	return title
+}
+
+// GenerateIssueBody documents a panic whose AI fix was not confident enough
+// for a pull request, including the suggestion for a human to review
+func GenerateIssueBody(panicEvent PanicEvent, fixResponse *FixResponse) string {
+	var body strings.Builder
+
+	body.WriteString("## Unresolved Panic\n\n")
+	body.WriteString("A runtime panic was captured, but the AI-generated fix was not confident enough to open a pull request.\n\n")
+
+	body.WriteString("### Panic Details\n")
+	body.WriteString(fmt.Sprintf("- **Error**: %s\n", panicEvent.Error))
+	if panicEvent.ErrorType != "" {
+		body.WriteString(fmt.Sprintf("- **Error Type**: `%s`\n", panicEvent.ErrorType))
+	}
+	if panicEvent.Severity != "" {
+		body.WriteString(fmt.Sprintf("- **Severity**: %s\n", panicEvent.Severity))
+	}
+	body.WriteString(fmt.Sprintf("- **Location**: %s:%d\n", panicEvent.SourceFile, panicEvent.LineNumber))
+	body.WriteString(fmt.Sprintf("- **Function**: %s\n", panicEvent.Function))
+	body.WriteString(fmt.Sprintf("- **Timestamp**: %s\n\n", panicEvent.Timestamp.Format(time.RFC3339)))
+
+	if fixResponse != nil {
+		body.WriteString("### Low-Confidence Suggestion\n")
+		body.WriteString(fmt.Sprintf("**Confidence**: %.1f%%\n\n", fixResponse.Confidence*100))
+		if fixResponse.Explanation != "" {
+			body.WriteString("**Explanation**:\n")
+			body.WriteString(fixResponse.Explanation)
+			body.WriteString("\n\n")
+		}
+		if fixResponse.ProposedFix != "" {
+			body.WriteString("```go\n")
+			body.WriteString(fixResponse.ProposedFix)
+			body.WriteString("\n```\n\n")
+		}
+	}
+
+	body.WriteString("### Stack Trace\n")
+	body.WriteString("```\n")
+	body.WriteString(panicEvent.StackTrace)
+	body.WriteString("\n```\n\n")
+
+	body.WriteString("---\n")
+	body.WriteString("*This issue was automatically generated by Go Code Healer*")
+
+	return body.String()
+}
+
+// CreateIssue opens an issue, reusing an open issue for the same panic if there is one
+func (gc *GitHubAPIClient) CreateIssue(ctx context.Context, request IssueRequest) (*IssueResult, error) {
+	if request.Title == "" {
+		return nil, fmt.Errorf("invalid issue request: title is required")
+	}
+
+	if request.Fingerprint != "" {
+		existing, err := gc.findExistingIssue(ctx, request.Fingerprint)
+		if err != nil {
+			gc.logger.Warn("Failed to check for existing issues: %v", err)
+		} else if existing != nil {
+			gc.logger.Info("Found existing issue #%d for this panic: %s", existing.Number, existing.URL)
+			return existing, nil
+		}
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/issues", gc.baseURL, gc.repoOwner, gc.repoName)
+
+	payload := map[string]any{
+		"title": request.Title,
+		"body":  request.Body + FingerprintMarker(request.Fingerprint),
+	}
+	if len(request.Labels) > 0 {
+		payload["labels"] = request.Labels
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "token "+gc.token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := gc.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &GitHubError{
+			StatusCode: resp.StatusCode,
+			Message:    string(body),
+			URL:        url,
+		}
+	}
+
+	var issueResponse struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+		Title   string `json:"title"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&issueResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode issue response: %w", err)
+	}
+
+	gc.logger.Info("Successfully created issue #%d: %s", issueResponse.Number, issueResponse.HTMLURL)
+	return &IssueResult{
+		URL:    issueResponse.HTMLURL,
+		Number: issueResponse.Number,
+		Title:  issueResponse.Title,
+	}, nil
+}
+
+// findExistingIssue looks for an open issue carrying the fingerprint marker
+func (gc *GitHubAPIClient) findExistingIssue(ctx context.Context, fingerprint string) (*IssueResult, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/issues?state=open&per_page=100", gc.baseURL, gc.repoOwner, gc.repoName)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "token "+gc.token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := gc.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &GitHubError{
+			StatusCode: resp.StatusCode,
+			Message:    string(body),
+			URL:        url,
+		}
+	}
+
+	var issues []struct {
+		Number      int             `json:"number"`
+		HTMLURL     string          `json:"html_url"`
+		Title       string          `json:"title"`
+		Body        string          `json:"body"`
+		PullRequest json.RawMessage `json:"pull_request"` // set when the issue is a pull request
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
+		return nil, fmt.Errorf("failed to decode issues: %w", err)
+	}
+
+	marker := strings.TrimSpace(FingerprintMarker(fingerprint))
+	for _, issue := range issues {
+		if issue.PullRequest == nil && strings.Contains(issue.Body, marker) {
+			return &IssueResult{
+				URL:            issue.HTMLURL,
+				Number:         issue.Number,
+				Title:          issue.Title,
+				AlreadyExisted: true,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,264 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Patch formats understood by ApplyPatch
+const (
+	PatchFormatFullFile    = "full_file"
+	PatchFormatUnifiedDiff = "unified_diff"
+	PatchFormatLineRange   = "line_range"
+)
+
+// Thresholds used to detect an AI snippet that would overwrite a whole file
+const (
+	minLinesForSnippetCheck = 20
+	minReplacementRatio     = 0.25
+)
+
+// hunkHeaderPattern matches unified diff hunk headers such as "@@ -12,7 +12,9 @@"
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// DetectPatchFormat returns the patch format of a change, inferring it when unset
+func DetectPatchFormat(change FileChange) string {
+	if change.PatchFormat != "" {
+		return change.PatchFormat
+	}
+	if change.StartLine > 0 {
+		return PatchFormatLineRange
+	}
+	trimmed := strings.TrimSpace(change.Content)
+	if strings.HasPrefix(trimmed, "--- ") || strings.HasPrefix(trimmed, "@@ ") {
+		return PatchFormatUnifiedDiff
+	}
+	return PatchFormatFullFile
+}
+
+// ApplyPatch merges a change into the original file content and returns the new content.
+// Full-file replacements that would shrink a non-trivial file to a small snippet are
+// rejected, since that almost always means the AI returned only the fixed fragment.
+func ApplyPatch(original string, change FileChange) (string, error) {
+	switch format := DetectPatchFormat(change); format {
+	case PatchFormatFullFile:
+		return applyFullFile(original, change.Content)
+	case PatchFormatLineRange:
+		return applyLineRange(original, change.Content, change.StartLine, change.EndLine)
+	case PatchFormatUnifiedDiff:
+		return applyUnifiedDiff(original, change.Content)
+	default:
+		return "", fmt.Errorf("unsupported patch format '%s'", format)
+	}
+}
+
+// ChangedLines returns the first and last line (1-based, inclusive) of the
+// original file that a change edits. ok is false for full-file replacements
+// and unparsable diffs, which must be treated as editing the whole file.
+func ChangedLines(change FileChange) (start, end int, ok bool) {
+	switch DetectPatchFormat(change) {
+	case PatchFormatLineRange:
+		end = change.EndLine
+		if end == 0 {
+			end = change.StartLine
+		}
+		return change.StartLine, end, true
+	case PatchFormatUnifiedDiff:
+		hunks, err := parseUnifiedDiff(change.Content)
+		if err != nil {
+			return 0, 0, false
+		}
+		start = hunks[0].oldStart
+		for _, hunk := range hunks {
+			start = min(start, hunk.oldStart)
+			end = max(end, hunk.oldStart+max(len(hunk.oldLines), 1)-1)
+		}
+		return start, end, true
+	default:
+		return 0, 0, false
+	}
+}
+
+// ChangesConflict reports whether two changes to the same file edit
+// overlapping lines, so applying one would clobber the other
+func ChangesConflict(a, b FileChange) bool {
+	aStart, aEnd, aOK := ChangedLines(a)
+	bStart, bEnd, bOK := ChangedLines(b)
+	if !aOK || !bOK {
+		return true
+	}
+	return aStart <= bEnd && bStart <= aEnd
+}
+
+// applyFullFile returns content unless it looks like a snippet replacing a larger file
+func applyFullFile(original, content string) (string, error) {
+	originalLines := countLines(original)
+	if originalLines >= minLinesForSnippetCheck {
+		if float64(countLines(content)) < float64(originalLines)*minReplacementRatio {
+			return "", fmt.Errorf("refusing to replace %d-line file with %d-line snippet", originalLines, countLines(content))
+		}
+	}
+	return content, nil
+}
+
+// applyLineRange replaces lines start..end (1-based, inclusive) with content
+func applyLineRange(original, content string, start, end int) (string, error) {
+	lines, trailingNewline := splitLines(original)
+
+	if end == 0 {
+		end = start
+	}
+	if start < 1 || end < start || end > len(lines) {
+		return "", fmt.Errorf("line range %d-%d is out of bounds for %d-line file", start, end, len(lines))
+	}
+
+	replacement, _ := splitLines(content)
+
+	merged := make([]string, 0, len(lines)-(end-start+1)+len(replacement))
+	merged = append(merged, lines[:start-1]...)
+	merged = append(merged, replacement...)
+	merged = append(merged, lines[end:]...)
+
+	return joinLines(merged, trailingNewline), nil
+}
+
+// diffHunk is a single hunk of a unified diff
+type diffHunk struct {
+	oldStart int
+	oldLines []string // context and removed lines
+	newLines []string // context and added lines
+}
+
+// applyUnifiedDiff applies the hunks of a unified diff to original
+func applyUnifiedDiff(original, diff string) (string, error) {
+	hunks, err := parseUnifiedDiff(diff)
+	if err != nil {
+		return "", err
+	}
+
+	lines, trailingNewline := splitLines(original)
+	offset := 0
+	for i, hunk := range hunks {
+		pos := findHunk(lines, hunk.oldLines, hunk.oldStart-1+offset)
+		if pos < 0 {
+			return "", fmt.Errorf("hunk %d does not apply at line %d", i+1, hunk.oldStart)
+		}
+
+		merged := make([]string, 0, len(lines)-len(hunk.oldLines)+len(hunk.newLines))
+		merged = append(merged, lines[:pos]...)
+		merged = append(merged, hunk.newLines...)
+		merged = append(merged, lines[pos+len(hunk.oldLines):]...)
+		lines = merged
+
+		offset += len(hunk.newLines) - len(hunk.oldLines)
+	}
+
+	return joinLines(lines, trailingNewline), nil
+}
+
+// parseUnifiedDiff extracts the hunks from a unified diff
+func parseUnifiedDiff(diff string) ([]diffHunk, error) {
+	var hunks []diffHunk
+	var current *diffHunk
+
+	for _, line := range strings.Split(strings.ReplaceAll(diff, "\r\n", "\n"), "\n") {
+		if match := hunkHeaderPattern.FindStringSubmatch(line); match != nil {
+			start, _ := strconv.Atoi(match[1])
+			hunks = append(hunks, diffHunk{oldStart: start})
+			current = &hunks[len(hunks)-1]
+			continue
+		}
+		if current == nil {
+			continue // file headers and preamble
+		}
+
+		switch {
+		case strings.HasPrefix(line, "-"):
+			current.oldLines = append(current.oldLines, line[1:])
+		case strings.HasPrefix(line, "+"):
+			current.newLines = append(current.newLines, line[1:])
+		case strings.HasPrefix(line, " "):
+			current.oldLines = append(current.oldLines, line[1:])
+			current.newLines = append(current.newLines, line[1:])
+		case line == "":
+			// Some generators strip the leading space from empty context lines
+			current.oldLines = append(current.oldLines, "")
+			current.newLines = append(current.newLines, "")
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+		default:
+			return nil, fmt.Errorf("malformed diff line: %q", line)
+		}
+	}
+
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("diff contains no hunks")
+	}
+
+	// Drop the empty context line produced by the diff's final newline
+	for i := range hunks {
+		hunk := &hunks[i]
+		for len(hunk.oldLines) > 0 && len(hunk.newLines) > 0 &&
+			hunk.oldLines[len(hunk.oldLines)-1] == "" && hunk.newLines[len(hunk.newLines)-1] == "" {
+			hunk.oldLines = hunk.oldLines[:len(hunk.oldLines)-1]
+			hunk.newLines = hunk.newLines[:len(hunk.newLines)-1]
+		}
+	}
+
+	return hunks, nil
+}
+
+// findHunk locates block in lines, preferring the expected position and
+// searching outwards from it when line numbers have drifted
+func findHunk(lines, block []string, expected int) int {
+	if expected < 0 {
+		expected = 0
+	}
+	for delta := 0; delta <= len(lines); delta++ {
+		for _, pos := range []int{expected - delta, expected + delta} {
+			if pos < 0 || pos+len(block) > len(lines) {
+				continue
+			}
+			if matchesAt(lines, block, pos) {
+				return pos
+			}
+		}
+	}
+	return -1
+}
+
+// matchesAt reports whether block matches lines starting at pos
+func matchesAt(lines, block []string, pos int) bool {
+	for i, line := range block {
+		if lines[pos+i] != line {
+			return false
+		}
+	}
+	return true
+}
+
+// splitLines splits content into lines, reporting whether it ended with a newline
+func splitLines(content string) ([]string, bool) {
+	if content == "" {
+		return nil, false
+	}
+	trailingNewline := strings.HasSuffix(content, "\n")
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n"), trailingNewline
+}
+
+// joinLines is the inverse of splitLines
+func joinLines(lines []string, trailingNewline bool) string {
+	joined := strings.Join(lines, "\n")
+	if trailingNewline {
+		joined += "\n"
+	}
+	return joined
+}
+
+// countLines returns the number of lines in content
+func countLines(content string) int {
+	lines, _ := splitLines(content)
+	return len(lines)
+}
@@ -0,0 +1,133 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+const patchTestOriginal = `package main
+
+func divide(a, b int) int {
+	return a / b
+}
+`
+
+func TestApplyPatchLineRange(t *testing.T) {
+	change := FileChange{
+		FilePath:    "main.go",
+		Content:     "\tif b == 0 {\n\t\treturn 0\n\t}\n\treturn a / b",
+		PatchFormat: PatchFormatLineRange,
+		StartLine:   4,
+		EndLine:     4,
+	}
+
+	got, err := ApplyPatch(patchTestOriginal, change)
+	if err != nil {
+		t.Fatalf("ApplyPatch returned error: %v", err)
+	}
+
+	want := "package main\n\nfunc divide(a, b int) int {\n\tif b == 0 {\n\t\treturn 0\n\t}\n\treturn a / b\n}\n"
+	if got != want {
+		t.Errorf("unexpected result:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestApplyPatchLineRangeOutOfBounds(t *testing.T) {
+	change := FileChange{Content: "x", PatchFormat: PatchFormatLineRange, StartLine: 10, EndLine: 12}
+
+	if _, err := ApplyPatch(patchTestOriginal, change); err == nil {
+		t.Error("expected error for out-of-range lines")
+	}
+}
+
+func TestApplyPatchUnifiedDiff(t *testing.T) {
+	diff := `--- a/main.go
++++ b/main.go
+@@ -3,3 +3,6 @@
+ func divide(a, b int) int {
++	if b == 0 {
++		return 0
++	}
+ 	return a / b
+ }
+`
+	// Prepend lines so the hunk has to be located away from its stated position
+	original := "// Code generated for tests.\n\n" + patchTestOriginal
+
+	got, err := ApplyPatch(original, FileChange{Content: diff})
+	if err != nil {
+		t.Fatalf("ApplyPatch returned error: %v", err)
+	}
+
+	if !strings.Contains(got, "\tif b == 0 {\n\t\treturn 0\n\t}\n\treturn a / b\n}\n") {
+		t.Errorf("diff not applied:\n%s", got)
+	}
+	if !strings.HasPrefix(got, "// Code generated for tests.\n") {
+		t.Errorf("leading content was modified:\n%s", got)
+	}
+}
+
+func TestApplyPatchUnifiedDiffMismatch(t *testing.T) {
+	diff := "@@ -1,1 +1,1 @@\n-func missing() {}\n+func present() {}\n"
+
+	if _, err := ApplyPatch(patchTestOriginal, FileChange{Content: diff, PatchFormat: PatchFormatUnifiedDiff}); err == nil {
+		t.Error("expected error when hunk context does not match")
+	}
+}
+
+func TestApplyPatchRejectsSnippetReplacingFile(t *testing.T) {
+	original := "package main\n" + strings.Repeat("// filler\n", 40)
+
+	_, err := ApplyPatch(original, FileChange{Content: "return a / b", PatchFormat: PatchFormatFullFile})
+	if err == nil {
+		t.Error("expected snippet replacing a large file to be rejected")
+	}
+}
+
+func TestDetectPatchFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		change FileChange
+		want   string
+	}{
+		{"explicit", FileChange{PatchFormat: PatchFormatFullFile, StartLine: 3}, PatchFormatFullFile},
+		{"line range", FileChange{StartLine: 3}, PatchFormatLineRange},
+		{"diff", FileChange{Content: "--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n"}, PatchFormatUnifiedDiff},
+		{"full file", FileChange{Content: "package main\n"}, PatchFormatFullFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectPatchFormat(tt.change); got != tt.want {
+				t.Errorf("DetectPatchFormat() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangesConflict(t *testing.T) {
+	lines := func(start, end int) FileChange {
+		return FileChange{Content: "x", PatchFormat: PatchFormatLineRange, StartLine: start, EndLine: end}
+	}
+	diff := FileChange{Content: "@@ -10,3 +10,4 @@\n a\n-b\n+c\n+d\n e\n"}
+
+	tests := []struct {
+		name string
+		a, b FileChange
+		want bool
+	}{
+		{"separate ranges", lines(1, 3), lines(10, 12), false},
+		{"overlapping ranges", lines(1, 5), lines(5, 8), true},
+		{"range inside diff", lines(11, 11), diff, true},
+		{"range after diff", lines(13, 14), diff, false},
+		{"full file", FileChange{Content: "package main\n"}, lines(1, 1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChangesConflict(tt.a, tt.b); got != tt.want {
+				t.Errorf("ChangesConflict() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,34 +3,86 @@ package github
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"path"
+	"sort"
 	"strings"
 	"time"
 )
 
-// GenerateBranchName creates a descriptive branch name for the panic fix
-func GenerateBranchName(panicEvent PanicEvent) string {
-	// Extract filename from full path
-	parts := strings.Split(panicEvent.SourceFile, "/")
-	filename := parts[len(parts)-1]
+// panicKinds maps error text to the kind of panic named in branch names,
+// checked in order
+var panicKinds = []struct{ pattern, kind string }{
+	{"nil pointer dereference", "nil-pointer"},
+	{"nil map", "nil-map"},
+	{"index out of range", "index-out-of-range"},
+	{"slice bounds out of range", "slice-bounds"},
+	{"concurrent map", "concurrent-map"},
+	{"interface conversion", "type-assertion"},
+	{"closed channel", "closed-channel"},
+	{"deadlock", "deadlock"},
+	{"divide by zero", "divide-by-zero"},
+}
+
+// maxBranchSlugLength bounds the panic kind and file parts of branch names
+const maxBranchSlugLength = 30
 
-	// Remove file extension
-	if idx := strings.LastIndex(filename, "."); idx != -1 {
-		filename = filename[:idx]
+// GenerateBranchName creates the branch for a panic fix from the kind of
+// panic, the file it happened in and a short hash identifying the panic, e.g.
+// "healer/nil-pointer-user-go-1a2b3c4d". The hash is taken from the panic
+// fingerprint, so every occurrence of a panic maps to the same branch while
+// distinct panics get distinct ones.
+func GenerateBranchName(panicEvent PanicEvent) string {
+	parts := []string{panicKind(panicEvent.Error)}
+	if file := branchSlug(path.Base(panicEvent.SourceFile), true); file != "" {
+		parts = append(parts, file)
 	}
+	parts = append(parts, panicHash(panicEvent))
+	return SanitizeBranchName("healer/" + strings.Join(parts, "-"))
+}
 
-	// Create branch name with panic context
-	branchName := fmt.Sprintf("fix/panic-%s-line-%d", filename, panicEvent.LineNumber)
+// panicKind names the kind of panic in err, falling back to the start of its
+// text without digits, which vary between occurrences of the same panic
+func panicKind(err string) string {
+	lower := strings.ToLower(err)
+	for _, k := range panicKinds {
+		if strings.Contains(lower, k.pattern) {
+			return k.kind
+		}
+	}
+	if kind := branchSlug(strings.TrimPrefix(lower, "runtime error: "), false); kind != "" {
+		return kind
+	}
+	return "panic"
+}
 
-	// Replace invalid characters
-	branchName = strings.ReplaceAll(branchName, " ", "-")
-	branchName = strings.ReplaceAll(branchName, "_", "-")
-	branchName = strings.ToLower(branchName)
+// panicHash returns the first eight characters of the panic fingerprint, or
+// of a hash of the panic's message and location when it has none
+func panicHash(panicEvent PanicEvent) string {
+	if len(panicEvent.Fingerprint) >= 8 {
+		return panicEvent.Fingerprint[:8]
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%s",
+		panicEvent.Error, panicEvent.SourceFile, panicEvent.LineNumber, panicEvent.Function)))
+	return hex.EncodeToString(sum[:4])
+}
 
-	return branchName
+// branchSlug lowercases s and joins its runs of letters, and of digits if
+// keepDigits is set, with hyphens
+func branchSlug(s string, keepDigits bool) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || keepDigits && r >= '0' && r <= '9')
+	})
+	slug := strings.Join(words, "-")
+	if len(slug) > maxBranchSlugLength {
+		slug = strings.TrimRight(slug[:maxBranchSlugLength], "-")
+	}
+	return slug
 }
 
 // GeneratePRTitle creates a descriptive title for the pull request
@@ -38,7 +90,11 @@ func GeneratePRTitle(panicEvent PanicEvent) string {
 	parts := strings.Split(panicEvent.SourceFile, "/")
 	filename := parts[len(parts)-1]
 
-	return fmt.Sprintf("Fix panic in %s at line %d", filename, panicEvent.LineNumber)
+	title := fmt.Sprintf("Fix panic in %s at line %d", filename, panicEvent.LineNumber)
+	if panicEvent.Severity != "" {
+		title = fmt.Sprintf("[%s] %s", panicEvent.Severity, title)
+	}
+	return title
 }
 
 // GeneratePRDescription creates a comprehensive description for the pull request
@@ -50,10 +106,42 @@ func GeneratePRDescription(panicEvent PanicEvent, fixResponse *FixResponse) stri
 
 	description.WriteString("### Panic Details\n")
 	description.WriteString(fmt.Sprintf("- **Error**: %s\n", panicEvent.Error))
+	if panicEvent.ErrorType != "" {
+		description.WriteString(fmt.Sprintf("- **Error Type**: `%s`\n", panicEvent.ErrorType))
+	}
+	if panicEvent.Severity != "" {
+		description.WriteString(fmt.Sprintf("- **Severity**: %s\n", panicEvent.Severity))
+	}
 	description.WriteString(fmt.Sprintf("- **Location**: %s:%d\n", panicEvent.SourceFile, panicEvent.LineNumber))
 	description.WriteString(fmt.Sprintf("- **Function**: %s\n", panicEvent.Function))
 	description.WriteString(fmt.Sprintf("- **Timestamp**: %s\n\n", panicEvent.Timestamp.Format(time.RFC3339)))
 
+	if len(panicEvent.Metadata) > 0 {
+		description.WriteString("### Request Context\n")
+		keys := make([]string, 0, len(panicEvent.Metadata))
+		for key := range panicEvent.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			description.WriteString(fmt.Sprintf("- **%s**: `%s`\n", key, panicEvent.Metadata[key]))
+		}
+		description.WriteString("\n")
+	}
+
+	if len(panicEvent.Tags) > 0 {
+		description.WriteString("### Tags\n")
+		keys := make([]string, 0, len(panicEvent.Tags))
+		for key := range panicEvent.Tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			description.WriteString(fmt.Sprintf("- **%s**: `%s`\n", key, panicEvent.Tags[key]))
+		}
+		description.WriteString("\n")
+	}
+
 	if fixResponse != nil {
 		description.WriteString("### AI-Generated Fix\n")
 		description.WriteString(fmt.Sprintf("**Confidence**: %.1f%%\n\n", fixResponse.Confidence*100))
@@ -72,6 +160,13 @@ func GeneratePRDescription(panicEvent PanicEvent, fixResponse *FixResponse) stri
 	description.WriteString(panicEvent.StackTrace)
 	description.WriteString("\n```\n\n")
 
+	if panicEvent.AllGoroutines != "" {
+		description.WriteString("<details>\n<summary>All Goroutines</summary>\n\n")
+		description.WriteString("```\n")
+		description.WriteString(panicEvent.AllGoroutines)
+		description.WriteString("\n```\n\n</details>\n\n")
+	}
+
 	description.WriteString("---\n")
 	description.WriteString("*This PR was automatically generated by Go Code Healer*")
 
@@ -79,59 +174,154 @@ func GeneratePRDescription(panicEvent PanicEvent, fixResponse *FixResponse) stri
 }
 
 // CreatePullRequest creates a new branch, commits changes, and opens a PR
-func (gc *GitHubAPIClient) CreatePullRequest(ctx context.Context, request PRRequest) error {
+func (gc *GitHubAPIClient) CreatePullRequest(ctx context.Context, request PRRequest) (*PRResult, error) {
 	gc.logger.Info("Creating pull request: %s", request.Title)
 
 	// Validate request
 	if err := gc.validatePRRequest(request); err != nil {
-		return fmt.Errorf("invalid PR request: %w", err)
+		return nil, fmt.Errorf("invalid PR request: %w", err)
+	}
+
+	// Skip creation if an open PR already addresses this panic
+	existing, err := gc.findExistingPR(ctx, request)
+	if err != nil {
+		gc.logger.Warn("Failed to check for existing pull requests: %v", err)
+	} else if existing != nil {
+		gc.logger.Info("PR already exists for this panic: %s", existing.URL)
+		return existing, nil
 	}
 
-	// Step 1: Get the default branch SHA
+	// Step 1: Get the commit to base the fix on, the default branch tip unless
+	// the request names one
 	defaultBranch, err := gc.getDefaultBranch(ctx)
 	if err != nil {
 		gc.logger.Error("Failed to get default branch: %v", err)
-		return fmt.Errorf("failed to get default branch: %w", err)
+		return nil, fmt.Errorf("failed to get default branch: %w", err)
 	}
 	gc.logger.Debug("Default branch: %s", defaultBranch)
 
-	baseSHA, err := gc.getBranchSHA(ctx, defaultBranch)
-	if err != nil {
-		gc.logger.Error("Failed to get base branch SHA: %v", err)
-		return fmt.Errorf("failed to get base branch SHA: %w", err)
+	baseSHA := request.BaseSHA
+	if baseSHA != "" && !gc.commitExists(ctx, baseSHA) {
+		gc.logger.Warn("Commit %s not found, basing the fix on %s instead", baseSHA, defaultBranch)
+		baseSHA = ""
+	}
+	if baseSHA == "" {
+		baseSHA, err = gc.getBranchSHA(ctx, defaultBranch)
+		if err != nil {
+			gc.logger.Error("Failed to get base branch SHA: %v", err)
+			return nil, fmt.Errorf("failed to get base branch SHA: %w", err)
+		}
 	}
 	gc.logger.Debug("Base SHA: %s", baseSHA)
 
-	// Step 2: Create a new branch
-	if err := gc.createBranch(ctx, request.BranchName, baseSHA); err != nil {
-		gc.logger.Error("Failed to create branch %s: %v", request.BranchName, err)
-		return fmt.Errorf("failed to create branch: %w", err)
+	// Step 2: Apply the file changes to the base and commit them all at once
+	contents, err := gc.applyChanges(ctx, baseSHA, request.Changes)
+	if err != nil {
+		return nil, err
+	}
+	message := request.CommitMessage
+	if message == "" {
+		message = commitMessage(contents)
+	}
+	commitSHA, err := gc.commitFiles(ctx, baseSHA, message, contents)
+	if err != nil {
+		gc.logger.Error("Failed to commit changes: %v", err)
+		return nil, fmt.Errorf("failed to commit changes: %w", err)
 	}
 
-	// Step 3: Apply file changes
-	for i, change := range request.Changes {
-		gc.logger.Debug("Applying change %d/%d: %s", i+1, len(request.Changes), change.FilePath)
-		if err := gc.updateFile(ctx, request.BranchName, change); err != nil {
-			gc.logger.Error("Failed to update file %s: %v", change.FilePath, err)
-			return fmt.Errorf("failed to update file %s: %w", change.FilePath, err)
-		}
+	// Step 3: Create the branch at the fix commit, so it never exists without the fix
+	if err := gc.createBranch(ctx, request.BranchName, commitSHA); err != nil {
+		gc.logger.Error("Failed to create branch %s: %v", request.BranchName, err)
+		return nil, fmt.Errorf("failed to create branch: %w", err)
 	}
 
 	// Step 4: Create the pull request
 	prResult, err := gc.createPR(ctx, request, defaultBranch)
 	if err != nil {
 		gc.logger.Error("Failed to create pull request: %v", err)
-		return fmt.Errorf("failed to create pull request: %w", err)
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
 	}
 
+	// Step 5: Label the pull request and request reviews
+	gc.applyPRMetadata(ctx, prResult.Number, request)
+
 	gc.logger.Info("Successfully created pull request #%d: %s", prResult.Number, prResult.URL)
-	return nil
+	return prResult, nil
+}
+
+// findExistingPR looks for an open pull request for the same panic. PRs are matched
+// on the fingerprint marker in their body, since branch names derive from error text
+// that can change between versions, and fall back to the branch name otherwise.
+func (gc *GitHubAPIClient) findExistingPR(ctx context.Context, request PRRequest) (*PRResult, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls?state=open&per_page=100", gc.baseURL, gc.repoOwner, gc.repoName)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "token "+gc.token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := gc.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &GitHubError{
+			StatusCode: resp.StatusCode,
+			Message:    string(body),
+			URL:        url,
+		}
+	}
+
+	var pulls []struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		Draft   bool   `json:"draft"`
+		Head    struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&pulls); err != nil {
+		return nil, fmt.Errorf("failed to decode pull requests: %w", err)
+	}
+
+	marker := strings.TrimSpace(FingerprintMarker(request.Fingerprint))
+	for _, pull := range pulls {
+		if (marker != "" && strings.Contains(pull.Body, marker)) || pull.Head.Ref == request.BranchName {
+			return &PRResult{
+				URL:            pull.HTMLURL,
+				Number:         pull.Number,
+				Title:          pull.Title,
+				Draft:          pull.Draft,
+				AlreadyExisted: true,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FingerprintMarker returns the hidden comment appended to PR descriptions to
+// identify the panic they fix, or an empty string when there is no fingerprint
+func FingerprintMarker(fingerprint string) string {
+	if fingerprint == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\n<!-- healer-fingerprint: %s -->", fingerprint)
 }
 
 // validatePRRequest validates the pull request request
 func (gc *GitHubAPIClient) validatePRRequest(request PRRequest) error {
-	if request.BranchName == "" {
-		return fmt.Errorf("branch name is required")
+	if err := ValidateBranchName(request.BranchName); err != nil {
+		return err
 	}
 	if request.Title == "" {
 		return fmt.Errorf("title is required")
@@ -154,11 +344,12 @@ func (gc *GitHubAPIClient) validatePRRequest(request PRRequest) error {
 func (gc *GitHubAPIClient) createPR(ctx context.Context, request PRRequest, baseBranch string) (*PRResult, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/pulls", gc.baseURL, gc.repoOwner, gc.repoName)
 
-	payload := map[string]string{
+	payload := map[string]any{
 		"title": request.Title,
 		"head":  request.BranchName,
 		"base":  baseBranch,
-		"body":  request.Description,
+		"body":  request.Description + FingerprintMarker(request.Fingerprint),
+		"draft": request.Draft,
 	}
 
 	jsonData, err := json.Marshal(payload)
@@ -175,7 +366,7 @@ func (gc *GitHubAPIClient) createPR(ctx context.Context, request PRRequest, base
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := gc.httpClient.Do(req)
+	resp, err := gc.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -194,6 +385,7 @@ func (gc *GitHubAPIClient) createPR(ctx context.Context, request PRRequest, base
 		Number  int    `json:"number"`
 		HTMLURL string `json:"html_url"`
 		Title   string `json:"title"`
+		Draft   bool   `json:"draft"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&prResponse); err != nil {
@@ -204,6 +396,7 @@ func (gc *GitHubAPIClient) createPR(ctx context.Context, request PRRequest, base
 		URL:    prResponse.HTMLURL,
 		Number: prResponse.Number,
 		Title:  prResponse.Title,
+		Draft:  prResponse.Draft,
 	}
 
 	gc.logger.Debug("Created pull request: %s", request.Title)
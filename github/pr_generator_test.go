@@ -0,0 +1,45 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateBranchName(t *testing.T) {
+	nilPointer := PanicEvent{
+		Error:       "runtime error: invalid memory address or nil pointer dereference",
+		SourceFile:  "/app/user.go",
+		LineNumber:  45,
+		Fingerprint: "1a2b3c4d5e6f",
+	}
+	if got := GenerateBranchName(nilPointer); got != "healer/nil-pointer-user-go-1a2b3c4d" {
+		t.Errorf("GenerateBranchName = %q, want healer/nil-pointer-user-go-1a2b3c4d", got)
+	}
+
+	// A later occurrence of the same panic reuses its branch
+	repeat := nilPointer
+	repeat.Timestamp = repeat.Timestamp.Add(1)
+	if GenerateBranchName(repeat) != GenerateBranchName(nilPointer) {
+		t.Error("expected identical panics to get identical branches")
+	}
+
+	// Another panic in the same file and of the same kind gets its own branch
+	other := nilPointer
+	other.LineNumber = 80
+	other.Fingerprint = "9f8e7d6c5b4a"
+	if GenerateBranchName(other) == GenerateBranchName(nilPointer) {
+		t.Error("expected distinct panics to get distinct branches")
+	}
+
+	// Without a fingerprint the hash is derived from the message and location
+	unfingerprinted := PanicEvent{Error: "custom failure 42: order #17 rejected", SourceFile: "/app/order_service.go", LineNumber: 12}
+	moved := unfingerprinted
+	moved.LineNumber = 13
+	name := GenerateBranchName(unfingerprinted)
+	if !strings.HasPrefix(name, "healer/custom-failure-order-rejected-order-service-go-") {
+		t.Errorf("GenerateBranchName = %q, want the error text and file without digits in the kind", name)
+	}
+	if name != GenerateBranchName(unfingerprinted) || name == GenerateBranchName(moved) {
+		t.Errorf("expected a stable hash of the panic location, got %q", name)
+	}
+}
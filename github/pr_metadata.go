@@ -0,0 +1,86 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// applyPRMetadata adds the labels, assignees and reviewers from request to an
+// opened pull request. The pull request already exists at this point, so
+// failures are logged as warnings instead of failing the whole operation.
+func (gc *GitHubAPIClient) applyPRMetadata(ctx context.Context, number int, request PRRequest) {
+	issueURL := fmt.Sprintf("%s/repos/%s/%s/issues/%d", gc.baseURL, gc.repoOwner, gc.repoName, number)
+
+	if len(request.Labels) > 0 {
+		if err := gc.postJSON(ctx, issueURL+"/labels", map[string]any{"labels": request.Labels}); err != nil {
+			gc.logger.Warn("Failed to add labels to pull request #%d: %v", number, err)
+		}
+	}
+
+	if len(request.Assignees) > 0 {
+		if err := gc.postJSON(ctx, issueURL+"/assignees", map[string]any{"assignees": request.Assignees}); err != nil {
+			gc.logger.Warn("Failed to add assignees to pull request #%d: %v", number, err)
+		}
+	}
+
+	if len(request.Reviewers) > 0 {
+		users, teams := splitReviewers(request.Reviewers)
+		payload := map[string]any{"reviewers": users, "team_reviewers": teams}
+		url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/requested_reviewers", gc.baseURL, gc.repoOwner, gc.repoName, number)
+		if err := gc.postJSON(ctx, url, payload); err != nil {
+			gc.logger.Warn("Failed to request reviewers for pull request #%d: %v", number, err)
+		}
+	}
+}
+
+// splitReviewers separates user logins from "org/team-slug" team reviewers,
+// returning team slugs without the organization
+func splitReviewers(reviewers []string) (users, teams []string) {
+	users, teams = []string{}, []string{}
+	for _, reviewer := range reviewers {
+		if _, team, ok := strings.Cut(reviewer, "/"); ok {
+			teams = append(teams, team)
+		} else {
+			users = append(users, reviewer)
+		}
+	}
+	return users, teams
+}
+
+// postJSON sends payload to url and fails on any non-2xx response
+func (gc *GitHubAPIClient) postJSON(ctx context.Context, url string, payload any) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "token "+gc.token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := gc.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return &GitHubError{
+			StatusCode: resp.StatusCode,
+			Message:    string(body),
+			URL:        url,
+		}
+	}
+	return nil
+}
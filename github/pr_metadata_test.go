@@ -0,0 +1,17 @@
+package github
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitReviewers(t *testing.T) {
+	users, teams := splitReviewers([]string{"alice", "acme/backend", "bob"})
+
+	if !slices.Equal(users, []string{"alice", "bob"}) {
+		t.Errorf("users = %v, want [alice bob]", users)
+	}
+	if !slices.Equal(teams, []string{"backend"}) {
+		t.Errorf("teams = %v, want [backend]", teams)
+	}
+}
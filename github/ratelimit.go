@@ -0,0 +1,79 @@
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// secondaryRateLimitWait is how long to pause after a secondary rate limit or
+// abuse detection response that does not say when to retry. GitHub asks
+// clients to wait at least a minute.
+const secondaryRateLimitWait = time.Minute
+
+// maxRateLimitBody bounds how much of a 403 or 429 response is read to look
+// for a rate limit message
+const maxRateLimitBody = 64 << 10
+
+// RateLimitError is returned while GitHub rate limits the client, including
+// secondary rate limits and abuse detection. API calls fail with it without
+// reaching GitHub until Reset.
+type RateLimitError struct {
+	StatusCode int       // status of the response that hit the limit, 0 if the client was already paused
+	Reset      time.Time // when GitHub accepts calls again
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("GitHub API rate limit exceeded, paused until %s", e.Reset.Format(time.RFC3339))
+}
+
+// rateLimitedUntil returns when the current rate limit pause ends
+func (gc *GitHubAPIClient) rateLimitedUntil() time.Time {
+	gc.rateLimitMu.Lock()
+	defer gc.rateLimitMu.Unlock()
+	return gc.pausedUntil
+}
+
+// pauseUntil pauses API calls until reset
+func (gc *GitHubAPIClient) pauseUntil(reset time.Time) {
+	gc.rateLimitMu.Lock()
+	defer gc.rateLimitMu.Unlock()
+	if reset.After(gc.pausedUntil) {
+		gc.pausedUntil = reset
+		gc.logger.Warn("GitHub API rate limit exceeded, pausing API calls until %s", reset.Format(time.RFC3339))
+	}
+}
+
+// rateLimitReset reports whether resp is a rate limit response and when the
+// limit resets: from Retry-After, from X-RateLimit-Reset once
+// X-RateLimit-Remaining reaches 0, or a minute from now for secondary rate
+// limit and abuse detection messages. The body of resp is left readable.
+func rateLimitReset(resp *http.Response) (time.Time, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+
+	if after, ok := retryAfter(resp); ok {
+		return time.Now().Add(after), true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Unix(reset, 0), true
+		}
+		return time.Now().Add(secondaryRateLimitWait), true
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxRateLimitBody))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	message := strings.ToLower(string(body))
+	if resp.StatusCode == http.StatusTooManyRequests ||
+		strings.Contains(message, "rate limit") || strings.Contains(message, "abuse") {
+		return time.Now().Add(secondaryRateLimitWait), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,94 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxBranchNameLength keeps "refs/heads/" plus the branch name within the
+// 255 bytes most filesystems, and so most Git hosts, allow for a ref
+const maxBranchNameLength = 255 - len("refs/heads/")
+
+// fallbackBranchName is used when nothing of a branch name survives sanitizing
+const fallbackBranchName = "healer/fix"
+
+// invalidRefChars matches the characters Git forbids in ref names, and any
+// other non-ASCII character, which some Git hosts mishandle in URLs
+var invalidRefChars = regexp.MustCompile(`[^\x21-\x7e]|[~^:?*\[\\]|@\{`)
+
+// ValidateBranchName reports whether name is a valid Git branch name under
+// the rules of git check-ref-format: no control characters, spaces or any of
+// ~^:?*[\, no "..", "@{" or "//", no component starting with a dot or ending
+// with ".lock", and no leading or trailing slash or trailing dot.
+func ValidateBranchName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("branch name is required")
+	case len(name) > maxBranchNameLength:
+		return fmt.Errorf("branch name is %d bytes long, over the limit of %d", len(name), maxBranchNameLength)
+	case name == "@":
+		return fmt.Errorf("branch name cannot be '@'")
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("branch name %q cannot start with '-'", name)
+	case strings.Contains(name, ".."):
+		return fmt.Errorf("branch name %q cannot contain '..'", name)
+	case strings.HasSuffix(name, "."):
+		return fmt.Errorf("branch name %q cannot end with '.'", name)
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return fmt.Errorf("branch name %q contains invalid character %q", name, r)
+		}
+	}
+	if strings.Contains(name, "@{") {
+		return fmt.Errorf("branch name %q cannot contain '@{'", name)
+	}
+	for _, component := range strings.Split(name, "/") {
+		switch {
+		case component == "":
+			return fmt.Errorf("branch name %q cannot have empty path components", name)
+		case strings.HasPrefix(component, "."):
+			return fmt.Errorf("branch name %q has a component starting with '.'", name)
+		case strings.HasSuffix(component, ".lock"):
+			return fmt.Errorf("branch name %q has a component ending with '.lock'", name)
+		}
+	}
+	return nil
+}
+
+// SanitizeBranchName turns name into a branch name that passes
+// ValidateBranchName, replacing invalid and non-ASCII characters with hyphens
+// and dropping what cannot be repaired, such as empty components
+func SanitizeBranchName(name string) string {
+	name = invalidRefChars.ReplaceAllString(name, "-")
+	if len(name) > maxBranchNameLength {
+		name = name[:maxBranchNameLength]
+	}
+
+	var components []string
+	for _, component := range strings.Split(name, "/") {
+		for strings.Contains(component, "..") {
+			component = strings.ReplaceAll(component, "..", ".")
+		}
+		for strings.Contains(component, "--") {
+			component = strings.ReplaceAll(component, "--", "-")
+		}
+		for {
+			trimmed := strings.Trim(strings.TrimSuffix(component, ".lock"), ".-")
+			if trimmed == component {
+				break
+			}
+			component = trimmed
+		}
+		if component != "" {
+			components = append(components, component)
+		}
+	}
+
+	name = strings.Join(components, "/")
+	if name == "" || name == "@" {
+		return fallbackBranchName
+	}
+	return name
+}
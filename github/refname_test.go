@@ -0,0 +1,68 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateBranchName(t *testing.T) {
+	valid := []string{"healer/nil-pointer-user-go-1a2b3c4d", "fix/v1.2", "feature/ünïcode"}
+	for _, name := range valid {
+		if err := ValidateBranchName(name); err != nil {
+			t.Errorf("ValidateBranchName(%q) = %v, want nil", name, err)
+		}
+	}
+
+	invalid := []string{
+		"", "@", "-fix", "fix..panic", "fix.", "fix/", "/fix", "fix//panic", "fix/.hidden",
+		"fix.lock", "fix/panic.lock/more", "fix panic", "fix~1", "fix^", "fix:panic", "fix?",
+		"fix*", "fix[0]", `fix\panic`, "fix@{1}", "fix\x00", "fix\tpanic", "fix\x7f",
+		strings.Repeat("a", maxBranchNameLength+1),
+	}
+	for _, name := range invalid {
+		if err := ValidateBranchName(name); err == nil {
+			t.Errorf("ValidateBranchName(%q) = nil, want an error", name)
+		}
+	}
+}
+
+func TestSanitizeBranchName(t *testing.T) {
+	tests := map[string]string{
+		"healer/nil-pointer-user-go-1a2b3c4d": "healer/nil-pointer-user-go-1a2b3c4d",
+		"fix/ panic: héllo wörld ":            "fix/panic-h-llo-w-rld",
+		"fix//../.git/config.lock":            "fix/git/config",
+		"-fix.lock.lock.":                     "fix",
+		"fix@{upstream}~1^2":                  "fix-upstream}-1-2",
+		"..//..":                              fallbackBranchName,
+		"":                                    fallbackBranchName,
+	}
+	for name, want := range tests {
+		if got := SanitizeBranchName(name); got != want {
+			t.Errorf("SanitizeBranchName(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	long := SanitizeBranchName("fix/" + strings.Repeat("x", 300) + "/.lock")
+	if err := ValidateBranchName(long); err != nil {
+		t.Errorf("expected a valid name after truncation, got %v", err)
+	}
+}
+
+func TestGenerateBranchName_PathologicalErrors(t *testing.T) {
+	errors := []string{
+		"💥 ünïcode панік 日本語",
+		"failed to open ../../etc/passwd: no such file",
+		"bad ref refs/heads/main.lock..@{0}~^:?*[\\",
+		strings.Repeat("a very long panic message ", 100),
+		"\x00\x01\x02\n\t",
+	}
+	for _, msg := range errors {
+		name := GenerateBranchName(PanicEvent{Error: msg, SourceFile: "/app/ユーザー/.hidden.go", LineNumber: 1})
+		if err := ValidateBranchName(name); err != nil {
+			t.Errorf("GenerateBranchName for %q = %q: %v", msg, name, err)
+		}
+		if !strings.HasPrefix(name, "healer/") || strings.Count(name, "/") != 1 {
+			t.Errorf("GenerateBranchName for %q = %q, want a single healer/ component", msg, name)
+		}
+	}
+}
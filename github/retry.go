@@ -0,0 +1,141 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// retryPolicy controls how API calls are retried after transient failures,
+// with exponential backoff like the healer's RetryManager
+type retryPolicy struct {
+	maxAttempts  int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+}
+
+// defaultRetryPolicy retries a call up to three times, waiting 1s, 2s and 4s
+var defaultRetryPolicy = retryPolicy{
+	maxAttempts:  4,
+	initialDelay: time.Second,
+	maxDelay:     30 * time.Second,
+}
+
+// backoff returns how long to wait after the given failed attempt (starting at 1)
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	delay := p.initialDelay
+	for i := 1; i < attempt && delay < p.maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.maxDelay)
+}
+
+// do sends req, retrying server errors of idempotent requests with exponential
+// backoff; other requests may have taken effect before failing. Rate limit
+// responses pause every call of the client until the limit resets; calls
+// wait out pauses up to maxDelay that end before their context deadline and
+// fail with a RateLimitError otherwise.
+func (gc *GitHubAPIClient) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		if until := gc.rateLimitedUntil(); time.Now().Before(until) {
+			if !gc.canWait(ctx, time.Until(until)) {
+				return nil, &RateLimitError{Reset: until}
+			}
+			if err := sleep(ctx, time.Until(until)); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := gc.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var delay time.Duration
+		if reset, limited := rateLimitReset(resp); limited {
+			gc.pauseUntil(reset)
+			delay = time.Until(reset)
+			if attempt >= gc.retry.maxAttempts || !gc.canWait(ctx, delay) {
+				resp.Body.Close()
+				return nil, &RateLimitError{StatusCode: resp.StatusCode, Reset: reset}
+			}
+		} else if resp.StatusCode >= 500 && attempt < gc.retry.maxAttempts && idempotent(req) {
+			delay = gc.retry.backoff(attempt)
+			if !gc.canWait(ctx, delay) {
+				return resp, nil
+			}
+		} else {
+			return resp, nil
+		}
+
+		// Rewind the body for the next attempt
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req.Body = body
+		} else if req.Body != nil && req.Body != http.NoBody {
+			return resp, nil
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		gc.logger.Debug("GitHub API %s %s returned %d, retrying in %v (attempt %d/%d)",
+			req.Method, req.URL.Path, resp.StatusCode, delay, attempt, gc.retry.maxAttempts)
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// idempotent reports whether sending req twice has the same effect as sending
+// it once: every method but POST, and POSTs creating Git blobs and trees, which
+// are addressed by their content
+func idempotent(req *http.Request) bool {
+	if req.Method != http.MethodPost {
+		return true
+	}
+	return strings.HasSuffix(req.URL.Path, "/git/blobs") || strings.HasSuffix(req.URL.Path, "/git/trees")
+}
+
+// canWait reports whether a call may wait delay before retrying: no longer
+// than maxDelay and leaving time before the context deadline
+func (gc *GitHubAPIClient) canWait(ctx context.Context, delay time.Duration) bool {
+	if delay > gc.retry.maxDelay {
+		return false
+	}
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > delay
+}
+
+// sleep waits for delay or until ctx is done
+func sleep(ctx context.Context, delay time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// retryAfter parses the Retry-After header of resp, given either in seconds
+// or as an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,129 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
+)
+
+func TestGitHubClient_RetriesTransientFailures(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.Write([]byte(`{"default_branch": "main"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewGitHubClient("token", "acme", "service", nil, internal.NewDefaultLogger("error"))
+	client.baseURL = server.URL
+	client.retry = retryPolicy{maxAttempts: 3, initialDelay: time.Millisecond, maxDelay: time.Second}
+
+	branch, err := client.getDefaultBranch(context.Background())
+	if err != nil || branch != "main" {
+		t.Fatalf("getDefaultBranch = %q, %v; want main after retries", branch, err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+
+	// Permission errors without Retry-After fail immediately
+	calls = 0
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusForbidden)
+	})
+	if _, err := client.getDefaultBranch(context.Background()); err == nil || calls != 1 {
+		t.Errorf("expected a single failed call, got %d calls and error %v", calls, err)
+	}
+}
+
+func TestGitHubClient_PausesWhenRateLimited(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message": "API rate limit exceeded"}`))
+	}))
+	defer server.Close()
+
+	client := NewGitHubClient("token", "acme", "service", nil, internal.NewDefaultLogger("error"))
+	client.baseURL = server.URL
+
+	for range 2 {
+		_, err := client.getDefaultBranch(context.Background())
+		var rateLimitErr *RateLimitError
+		if !errors.As(err, &rateLimitErr) || !rateLimitErr.Reset.Equal(reset) {
+			t.Fatalf("expected a rate limit error resetting at %v, got %v", reset, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected calls to stop reaching GitHub until the reset, got %d calls", calls)
+	}
+
+	// Secondary rate limits are recognized by their message
+	resp := &http.Response{
+		StatusCode: http.StatusForbidden,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(`{"message": "You have exceeded a secondary rate limit."}`)),
+	}
+	if until, ok := rateLimitReset(resp); !ok || time.Until(until) < 59*time.Second {
+		t.Errorf("expected a one minute pause for a secondary rate limit, got %v, %v", until, ok)
+	}
+	if body, _ := io.ReadAll(resp.Body); !strings.Contains(string(body), "secondary rate limit") {
+		t.Errorf("expected the response body to stay readable, got %q", body)
+	}
+}
+
+func TestGitHubClient_RetriesOnlyIdempotentPosts(t *testing.T) {
+	calls := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		if calls[r.URL.Path] == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sha": "blob", "number": 7}`))
+	}))
+	defer server.Close()
+
+	client := NewGitHubClient("token", "acme", "service", nil, internal.NewDefaultLogger("error"))
+	client.baseURL = server.URL
+	client.retry = retryPolicy{maxAttempts: 3, initialDelay: time.Millisecond, maxDelay: time.Second}
+
+	// Blobs are addressed by their content, so creating one twice is harmless
+	var blob struct{ SHA string }
+	if err := client.gitData(context.Background(), "POST", "blobs", map[string]string{"content": "x"}, http.StatusCreated, &blob); err != nil {
+		t.Fatalf("creating a blob failed after a retry: %v", err)
+	}
+	if calls["/repos/acme/service/git/blobs"] != 2 {
+		t.Errorf("expected the blob creation retried, got %d calls", calls["/repos/acme/service/git/blobs"])
+	}
+
+	// A pull request may have been opened before the server error
+	if _, err := client.createPR(context.Background(), PRRequest{Title: "Fix", BranchName: "healer/fix"}, "main"); err == nil {
+		t.Error("expected the pull request creation to fail without a retry")
+	}
+	if calls["/repos/acme/service/pulls"] != 1 {
+		t.Errorf("expected a single pull request creation, got %d calls", calls["/repos/acme/service/pulls"])
+	}
+}
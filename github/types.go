@@ -8,6 +8,23 @@ type PRRequest struct {
 	Title       string       `json:"title"`
 	Description string       `json:"description"`
 	Changes     []FileChange `json:"changes"`
+	Fingerprint string       `json:"fingerprint,omitempty"` // stable panic identity used to find existing PRs
+	Draft       bool         `json:"draft,omitempty"`       // open as a draft so it cannot be merged before review
+
+	// CommitMessage is the message of the commit holding the changes; defaults
+	// to one naming the changed files
+	CommitMessage string `json:"commit_message,omitempty"`
+
+	// BaseSHA is the commit the fix is made against, such as the one the
+	// panicking binary was built from, so the fix applies to the code that
+	// panicked. The tip of the default branch is used when it is empty or not
+	// in the repository. The pull request still targets the default branch.
+	BaseSHA string `json:"base_sha,omitempty"`
+
+	// Applied after the pull request is opened; failures only log a warning
+	Labels    []string `json:"labels,omitempty"`
+	Assignees []string `json:"assignees,omitempty"` // user logins
+	Reviewers []string `json:"reviewers,omitempty"` // user logins, or "org/team-slug" for teams
 }
 
 // PRResult represents the result of creating a pull request
@@ -15,12 +32,37 @@ type PRResult struct {
 	URL    string `json:"url"`
 	Number int    `json:"number"`
 	Title  string `json:"title"`
+	Draft  bool   `json:"draft,omitempty"`
+
+	// AlreadyExisted is true when an open PR for the same panic was found and reused
+	AlreadyExisted bool `json:"already_existed,omitempty"`
+}
+
+// IssueRequest represents an issue creation request
+type IssueRequest struct {
+	Title       string   `json:"title"`
+	Body        string   `json:"body"`
+	Labels      []string `json:"labels,omitempty"`
+	Fingerprint string   `json:"fingerprint,omitempty"` // stable panic identity used to find existing issues
+}
+
+// IssueResult represents the result of creating an issue
+type IssueResult struct {
+	URL    string `json:"url"`
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+
+	// AlreadyExisted is true when an open issue for the same panic was found and reused
+	AlreadyExisted bool `json:"already_existed,omitempty"`
 }
 
 // FileChange represents a file modification
 type FileChange struct {
-	FilePath string `json:"file_path"`
-	Content  string `json:"content"`
+	FilePath    string `json:"file_path"`
+	Content     string `json:"content"`
+	PatchFormat string `json:"patch_format,omitempty"` // "full_file", "unified_diff" or "line_range"
+	StartLine   int    `json:"start_line,omitempty"`   // first replaced line for "line_range"
+	EndLine     int    `json:"end_line,omitempty"`     // last replaced line for "line_range"
 }
 
 // PanicEvent represents a captured panic with context
@@ -28,12 +70,19 @@ type PanicEvent struct {
 	ID          string     `json:"id"`
 	Timestamp   time.Time  `json:"timestamp"`
 	Error       string     `json:"error"`
+	ErrorType   string     `json:"error_type,omitempty"`
 	StackTrace  string     `json:"stack_trace"`
 	SourceFile  string     `json:"source_file"`
 	LineNumber  int        `json:"line_number"`
 	Function    string     `json:"function"`
 	ProcessedAt *time.Time `json:"processed_at,omitempty"`
-	Status      string     `json:"status"` // "queued", "processing", "completed", "failed"
+	Status      string     `json:"status"`             // "queued", "processing", "completed", "failed"
+	Severity    string     `json:"severity,omitempty"` // "critical", "high" or "medium"
+	Fingerprint string     `json:"fingerprint,omitempty"`
+
+	AllGoroutines string            `json:"all_goroutines,omitempty"` // every goroutine's stack, for concurrency panics
+	Metadata      map[string]string `json:"metadata,omitempty"`       // request-scoped details such as the HTTP method and path
+	Tags          map[string]string `json:"tags,omitempty"`           // user metadata such as the service, environment or version
 }
 
 // FixResponse represents the AI's response with a proposed fix
@@ -0,0 +1,121 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// getDefaultBranch retrieves the default branch name for the project
+func (gc *GitLabAPIClient) getDefaultBranch(ctx context.Context) (string, error) {
+	endpoint := gc.projectURL()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", gc.token)
+
+	resp, err := gc.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("GitLab API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var project struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&project); err != nil {
+		return "", err
+	}
+
+	return project.DefaultBranch, nil
+}
+
+// createBranch creates a new branch from the given ref
+func (gc *GitLabAPIClient) createBranch(ctx context.Context, branchName, ref string) error {
+	endpoint := fmt.Sprintf("%s/repository/branches?branch=%s&ref=%s",
+		gc.projectURL(), url.QueryEscape(branchName), url.QueryEscape(ref))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", gc.token)
+
+	resp, err := gc.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitLab API error creating branch: %d - %s", resp.StatusCode, string(body))
+	}
+
+	gc.logger.Debug("Created branch: %s", branchName)
+	return nil
+}
+
+// developerAccessLevel is the lowest GitLab access level that can push branches
+const developerAccessLevel = 30
+
+// CheckAccess confirms the token can read the project and push to it,
+// so misconfigured credentials are reported before the first fix
+func (gc *GitLabAPIClient) CheckAccess(ctx context.Context) error {
+	endpoint := gc.projectURL()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", gc.token)
+
+	resp, err := gc.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &GitLabError{StatusCode: resp.StatusCode, Message: string(body), URL: endpoint}
+	}
+
+	type access struct {
+		AccessLevel int `json:"access_level"`
+	}
+	var project struct {
+		Permissions struct {
+			ProjectAccess *access `json:"project_access"`
+			GroupAccess   *access `json:"group_access"`
+		} `json:"permissions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&project); err != nil {
+		return err
+	}
+
+	level := 0
+	for _, a := range []*access{project.Permissions.ProjectAccess, project.Permissions.GroupAccess} {
+		if a != nil {
+			level = max(level, a.AccessLevel)
+		}
+	}
+	if level < developerAccessLevel {
+		return fmt.Errorf("token cannot push to %s/%s", gc.namespace, gc.project)
+	}
+	return nil
+}
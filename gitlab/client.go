@@ -0,0 +1,51 @@
+package gitlab
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
+)
+
+type Logger = internal.LoggerInterface
+
+// DefaultBaseURL is the API endpoint for gitlab.com
+const DefaultBaseURL = "https://gitlab.com/api/v4"
+
+type GitLabAPIClient struct {
+	token      string
+	namespace  string
+	project    string
+	httpClient *http.Client
+	logger     Logger
+	baseURL    string
+}
+
+// NewGitLabClient creates a client for the project at namespace/project.
+// An empty baseURL targets gitlab.com; pass the /api/v4 URL of a self-managed instance otherwise.
+// A nil httpClient creates one with a 30 second timeout.
+func NewGitLabClient(token, namespace, project, baseURL string, httpClient *http.Client, logger Logger) *GitLabAPIClient {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &GitLabAPIClient{
+		token:      token,
+		namespace:  namespace,
+		project:    project,
+		logger:     logger,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// projectURL returns the API URL for the project, using the URL-encoded path as its ID
+func (gc *GitLabAPIClient) projectURL() string {
+	return gc.baseURL + "/projects/" + url.PathEscape(gc.namespace+"/"+gc.project)
+}
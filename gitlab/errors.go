@@ -0,0 +1,13 @@
+package gitlab
+
+import "fmt"
+
+type GitLabError struct {
+	StatusCode int
+	Message    string
+	URL        string
+}
+
+func (e *GitLabError) Error() string {
+	return fmt.Sprintf("GitLab API error %d: %s (URL: %s)", e.StatusCode, e.Message, e.URL)
+}
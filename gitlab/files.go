@@ -0,0 +1,153 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/ajeet-kumar1087/go-code-healer/github"
+)
+
+// commitAction is a single file operation in a GitLab commit
+type commitAction struct {
+	Action   string `json:"action"` // "create" or "update"
+	FilePath string `json:"file_path"`
+	Content  string `json:"content"`
+}
+
+// commitChanges commits all file changes to the branch in a single commit,
+// with a default message if message is empty
+func (gc *GitLabAPIClient) commitChanges(ctx context.Context, branchName, message string, changes []FileChange) error {
+	// Changes to the same file are applied on top of each other and
+	// committed as a single action, since each action replaces the file
+	var actions []commitAction
+	index := make(map[string]int) // action index by file path
+	for _, change := range changes {
+		original, exists := "", false
+		if i, seen := index[change.FilePath]; seen {
+			original, exists = actions[i].Content, true
+		} else {
+			var err error
+			original, exists, err = gc.getFileContent(ctx, change.FilePath, branchName)
+			if err != nil {
+				return err
+			}
+		}
+
+		action := "update"
+		content := change.Content
+		if exists {
+			// Merge the fix into the existing file rather than overwriting it
+			var err error
+			content, err = github.ApplyPatch(original, change)
+			if err != nil {
+				gc.logger.Warn("Rejected fix for %s: %v", change.FilePath, err)
+				return fmt.Errorf("failed to apply fix to %s: %w", change.FilePath, err)
+			}
+		} else {
+			if format := github.DetectPatchFormat(change); format != github.PatchFormatFullFile {
+				return fmt.Errorf("cannot apply %s patch to missing file %s", format, change.FilePath)
+			}
+			gc.logger.Debug("File %s not found, will create new file", change.FilePath)
+			action = "create"
+		}
+
+		if i, seen := index[change.FilePath]; seen {
+			actions[i].Content = content
+			continue
+		}
+		index[change.FilePath] = len(actions)
+		actions = append(actions, commitAction{
+			Action:   action,
+			FilePath: change.FilePath,
+			Content:  content,
+		})
+	}
+
+	endpoint := gc.projectURL() + "/repository/commits"
+
+	if message == "" {
+		message = "Fix runtime panic\n\nAutomatically generated fix for runtime panic"
+	}
+	payload := map[string]any{
+		"branch":         branchName,
+		"commit_message": message,
+		"actions":        actions,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", gc.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := gc.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitLab API error committing files: %d - %s", resp.StatusCode, string(body))
+	}
+
+	gc.logger.Debug("Committed %d file(s) to branch %s", len(changes), branchName)
+	return nil
+}
+
+// ReadFile returns the content of the file at path, relative to the
+// project root, as of ref, a branch, tag or commit SHA
+func (gc *GitLabAPIClient) ReadFile(ctx context.Context, path, ref string) (string, error) {
+	content, exists, err := gc.getFileContent(ctx, path, ref)
+	if err == nil && !exists {
+		err = fmt.Errorf("file not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s at %s: %w", path, ref, err)
+	}
+	return content, nil
+}
+
+// getFileContent returns the raw content of a file on the given branch and whether it exists
+func (gc *GitLabAPIClient) getFileContent(ctx context.Context, filePath, branchName string) (string, bool, error) {
+	endpoint := fmt.Sprintf("%s/repository/files/%s/raw?ref=%s",
+		gc.projectURL(), url.PathEscape(filePath), url.QueryEscape(branchName))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return "", false, err
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", gc.token)
+
+	resp, err := gc.httpClient.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read file %s: %w", filePath, err)
+		}
+		return string(body), true, nil
+	case http.StatusNotFound:
+		return "", false, nil
+	default:
+		return "", false, fmt.Errorf("GitLab API error reading file %s: %d", filePath, resp.StatusCode)
+	}
+}
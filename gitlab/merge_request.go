@@ -0,0 +1,227 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ajeet-kumar1087/go-code-healer/github"
+)
+
+// CreatePullRequest creates a new branch, commits changes, and opens a merge request
+func (gc *GitLabAPIClient) CreatePullRequest(ctx context.Context, request PRRequest) (*PRResult, error) {
+	gc.logger.Info("Creating merge request: %s", request.Title)
+
+	// Validate request
+	if err := gc.validatePRRequest(request); err != nil {
+		return nil, fmt.Errorf("invalid merge request: %w", err)
+	}
+
+	// Skip creation if an open merge request already addresses this panic
+	existing, err := gc.findExistingMR(ctx, request)
+	if err != nil {
+		gc.logger.Warn("Failed to check for existing merge requests: %v", err)
+	} else if existing != nil {
+		gc.logger.Info("Merge request already exists for this panic: %s", existing.URL)
+		return existing, nil
+	}
+
+	// Step 1: Get the default branch
+	defaultBranch, err := gc.getDefaultBranch(ctx)
+	if err != nil {
+		gc.logger.Error("Failed to get default branch: %v", err)
+		return nil, fmt.Errorf("failed to get default branch: %w", err)
+	}
+	gc.logger.Debug("Default branch: %s", defaultBranch)
+
+	// Step 2: Create a new branch, from the requested commit if the project has it
+	branched := false
+	if request.BaseSHA != "" {
+		if err := gc.createBranch(ctx, request.BranchName, request.BaseSHA); err != nil {
+			gc.logger.Warn("Failed to branch from commit %s, basing the fix on %s instead: %v", request.BaseSHA, defaultBranch, err)
+		} else {
+			branched = true
+		}
+	}
+	if !branched {
+		if err := gc.createBranch(ctx, request.BranchName, defaultBranch); err != nil {
+			gc.logger.Error("Failed to create branch %s: %v", request.BranchName, err)
+			return nil, fmt.Errorf("failed to create branch: %w", err)
+		}
+	}
+
+	// Step 3: Commit file changes
+	if err := gc.commitChanges(ctx, request.BranchName, request.CommitMessage, request.Changes); err != nil {
+		gc.logger.Error("Failed to commit changes: %v", err)
+		return nil, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	// Step 4: Open the merge request
+	mrResult, err := gc.createMR(ctx, request, defaultBranch)
+	if err != nil {
+		gc.logger.Error("Failed to create merge request: %v", err)
+		return nil, fmt.Errorf("failed to create merge request: %w", err)
+	}
+
+	gc.logger.Info("Successfully created merge request !%d: %s", mrResult.Number, mrResult.URL)
+	return mrResult, nil
+}
+
+// findExistingMR looks for an open merge request for the same panic, matching the
+// fingerprint marker in its description or, failing that, the source branch
+func (gc *GitLabAPIClient) findExistingMR(ctx context.Context, request PRRequest) (*PRResult, error) {
+	endpoint := gc.projectURL() + "/merge_requests?state=opened&per_page=100"
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", gc.token)
+
+	resp, err := gc.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &GitLabError{
+			StatusCode: resp.StatusCode,
+			Message:    string(body),
+			URL:        endpoint,
+		}
+	}
+
+	var mergeRequests []struct {
+		IID          int    `json:"iid"`
+		WebURL       string `json:"web_url"`
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		SourceBranch string `json:"source_branch"`
+		Draft        bool   `json:"draft"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&mergeRequests); err != nil {
+		return nil, fmt.Errorf("failed to decode merge requests: %w", err)
+	}
+
+	marker := strings.TrimSpace(github.FingerprintMarker(request.Fingerprint))
+	for _, mr := range mergeRequests {
+		if (marker != "" && strings.Contains(mr.Description, marker)) || mr.SourceBranch == request.BranchName {
+			return &PRResult{
+				URL:            mr.WebURL,
+				Number:         mr.IID,
+				Title:          mr.Title,
+				Draft:          mr.Draft,
+				AlreadyExisted: true,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// validatePRRequest validates the merge request request
+func (gc *GitLabAPIClient) validatePRRequest(request PRRequest) error {
+	if err := github.ValidateBranchName(request.BranchName); err != nil {
+		return err
+	}
+	if request.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if len(request.Changes) == 0 {
+		return fmt.Errorf("at least one file change is required")
+	}
+	for i, change := range request.Changes {
+		if change.FilePath == "" {
+			return fmt.Errorf("file path is required for change %d", i)
+		}
+		if change.Content == "" {
+			return fmt.Errorf("content is required for change %d", i)
+		}
+	}
+	return nil
+}
+
+// createMR opens the merge request
+func (gc *GitLabAPIClient) createMR(ctx context.Context, request PRRequest, targetBranch string) (*PRResult, error) {
+	endpoint := gc.projectURL() + "/merge_requests"
+
+	// GitLab marks merge requests as drafts by their title prefix
+	title := request.Title
+	if request.Draft {
+		title = "Draft: " + title
+	}
+
+	payload := map[string]any{
+		"title":                title,
+		"source_branch":        request.BranchName,
+		"target_branch":        targetBranch,
+		"description":          request.Description + github.FingerprintMarker(request.Fingerprint),
+		"remove_source_branch": true,
+	}
+
+	// Labels are created on the fly by GitLab; users must be resolved to IDs
+	if len(request.Labels) > 0 {
+		payload["labels"] = strings.Join(request.Labels, ",")
+	}
+	if ids := gc.userIDs(ctx, request.Assignees, "assignee"); len(ids) > 0 {
+		payload["assignee_ids"] = ids
+	}
+	if ids := gc.userIDs(ctx, request.Reviewers, "reviewer"); len(ids) > 0 {
+		payload["reviewer_ids"] = ids
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", gc.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := gc.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &GitLabError{
+			StatusCode: resp.StatusCode,
+			Message:    string(body),
+			URL:        endpoint,
+		}
+	}
+
+	var mrResponse struct {
+		IID    int    `json:"iid"`
+		WebURL string `json:"web_url"`
+		Title  string `json:"title"`
+		Draft  bool   `json:"draft"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&mrResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode merge request response: %w", err)
+	}
+
+	gc.logger.Debug("Created merge request: %s", request.Title)
+	return &PRResult{
+		URL:    mrResponse.WebURL,
+		Number: mrResponse.IID,
+		Title:  mrResponse.Title,
+		Draft:  mrResponse.Draft,
+	}, nil
+}
@@ -0,0 +1,15 @@
+package gitlab
+
+import "github.com/ajeet-kumar1087/go-code-healer/github"
+
+// The request and result types are shared with the github package so either
+// backend can be plugged into the healer without changing the pipeline.
+
+// PRRequest represents a merge request creation request
+type PRRequest = github.PRRequest
+
+// PRResult represents the result of creating a merge request
+type PRResult = github.PRResult
+
+// FileChange represents a file modification
+type FileChange = github.FileChange
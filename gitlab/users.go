@@ -0,0 +1,70 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// userIDs resolves usernames to GitLab user IDs for assignees and reviewers.
+// Names that cannot be resolved, including GitHub-style "org/team" reviewers,
+// are logged and skipped so the merge request is still opened.
+func (gc *GitLabAPIClient) userIDs(ctx context.Context, usernames []string, role string) []int {
+	var ids []int
+	for _, username := range usernames {
+		if strings.Contains(username, "/") {
+			gc.logger.Warn("Skipping %s %s: GitLab does not support team %ss", role, username, role)
+			continue
+		}
+
+		id, err := gc.userID(ctx, username)
+		if err != nil {
+			gc.logger.Warn("Skipping %s %s: %v", role, username, err)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// userID looks up the ID of the user with the given username
+func (gc *GitLabAPIClient) userID(ctx context.Context, username string) (int, error) {
+	endpoint := gc.baseURL + "/users?username=" + url.QueryEscape(username)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", gc.token)
+
+	resp, err := gc.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, &GitLabError{
+			StatusCode: resp.StatusCode,
+			Message:    string(body),
+			URL:        endpoint,
+		}
+	}
+
+	var users []struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		return 0, fmt.Errorf("failed to decode users: %w", err)
+	}
+	if len(users) == 0 {
+		return 0, fmt.Errorf("user not found")
+	}
+	return users[0].ID, nil
+}
@@ -0,0 +1,60 @@
+// Package grpchealer adapts go-code-healer panic capture to gRPC servers.
+//
+// It lives in its own module so applications that do not use gRPC never pull
+// in the grpc dependency:
+//
+//	server := grpc.NewServer(
+//	    grpc.UnaryInterceptor(grpchealer.UnaryServerInterceptor()),
+//	    grpc.StreamInterceptor(grpchealer.StreamServerInterceptor()),
+//	)
+package grpchealer
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	healer "github.com/ajeet-kumar1087/go-code-healer"
+)
+
+// UnaryServerInterceptor returns an interceptor that recovers panics in unary
+// handlers, reports them to the global healer with the full method name, and
+// returns a codes.Internal error to the caller
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverPanic(r, info.FullMethod, "unary")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that recovers panics in
+// streaming handlers, reports them to the global healer with the full method
+// name, and returns a codes.Internal error to the caller
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverPanic(r, info.FullMethod, "stream")
+			}
+		}()
+
+		return handler(srv, stream)
+	}
+}
+
+// recoverPanic reports a recovered panic and converts it into a gRPC status error
+func recoverPanic(panicValue any, fullMethod, kind string) error {
+	healer.CapturePanicWithMetadata(panicValue, map[string]string{
+		"grpc_method": fullMethod,
+		"grpc_type":   kind,
+	})
+	// The panic value is not echoed to clients since it may contain internal details
+	return status.Errorf(codes.Internal, "internal error handling %s", fullMethod)
+}
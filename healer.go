@@ -4,8 +4,13 @@ package healer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ajeet-kumar1087/go-code-healer/ai"
@@ -23,7 +28,7 @@ func DefaultConfig() Config {
 	return internal.DefaultConfig()
 }
 
-// LoadConfig loads configuration from JSON file and environment variables
+// LoadConfig loads configuration from a JSON or TOML file and environment variables
 func LoadConfig(configPath string) (*Config, error) {
 	return internal.LoadConfig(configPath)
 }
@@ -36,47 +41,124 @@ func GetFallbackConfig() *Config {
 // Healer is the main struct that manages error healing
 type Healer struct {
 	config          Config
-	errorQueue      chan PanicEvent
+	configMu        sync.RWMutex // guards config and providerManager, which ReloadConfig can swap
+	reloadMu        sync.Mutex
+	queue           *priorityQueue
 	providerManager *ProviderManager
 	gitClient       GitClient
+	routed          routedGitClients // clients of the repositories in Config.RepoRouting
+	notifiers       []Notifier       // guarded by hooksMu
 	logger          Logger
+	tracer          Tracer // nil when tracing is disabled
 	workerPool      *WorkerPool
 	queueManager    *QueueManager
+	batcher         *fixBatcher
+	prLimiter       prRateLimiter
+	deadLetters     *deadLetterQueue
+	store           Store
+	resolver        SourceResolver // nil reads source from the local filesystem
 	retryManager    *RetryManager
-	circuitBreaker  *CircuitBreaker
-	panicCapture    *PanicCapture
+	gitBreaker      *CircuitBreaker              // AI providers have their own breakers in the provider manager
+	panicCapture    atomic.Pointer[PanicCapture] // set by InstallPanicHandler
+	redactor        *internal.Redactor
+	filter          *internal.CaptureFilter // guarded by configMu; rebuilt by ReloadConfig
+	ignored         atomic.Int64            // panics skipped by filter
+	sampler         panicSampler
+	claims          eventClaims // keeps workers from processing an event twice
+	hooks           []EventHook
+	hooksMu         sync.RWMutex // guards hooks and notifiers
+	events          eventStreams
+	results         chan ProcessingResult
+	droppedResults  atomic.Int64
 	ctx             context.Context
 	cancel          context.CancelFunc
+	stopOnce        sync.Once
+	stopErr         error // result of the first Stop
+	shutdownResult  ShutdownResult
+	inert           bool // created by Disabled: captures nothing and never processes events
 }
 
 // Initialize creates and starts the healer with the given configuration
 func Initialize(config Config) (*Healer, error) {
+	return InitializeWithContext(context.Background(), config)
+}
+
+// InitializeWithContext is like Initialize, but ties the healer's lifetime to
+// ctx: when ctx is cancelled the healer stops as if Stop had been called,
+// shutting down the workers and flushing pending batches. Calling Stop
+// explicitly as well is safe.
+func InitializeWithContext(ctx context.Context, config Config) (*Healer, error) {
 	// Apply defaults and validate configuration
 	config.ApplyDefaults()
 	if err := config.ValidateComplete(); err != nil {
 		return nil, err
 	}
+	if err := validatePRTemplates(config); err != nil {
+		return nil, err
+	}
 
-	// Create context for lifecycle management
+	// Create context for lifecycle management. It is not derived from the
+	// parent so workers are only interrupted through Stop, after draining.
+	parent := ctx
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// Create logger
-	logger := internal.NewDefaultLogger(config.LogLevel)
+	// Use the injected logger, or build one from the log settings
+	logger := config.Logger
+	if logger == nil {
+		logger = internal.NewLogger(config.LogLevel, config.LogFormat)
+	}
+
+	// Redaction patterns were validated above
+	redactor, err := internal.NewRedactor(config.RedactPatterns)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Ignore and heal-only settings were validated above too
+	filter, err := internal.NewCaptureFilter(&config)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Every outbound client shares one transport, built from the proxy and TLS
+	// settings unless one was injected. Keeping it in the configuration lets
+	// provider managers built on reload reuse its connections.
+	clients, err := internal.NewHTTPClientFactory(&config)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	config.HTTPTransport = clients.Transport()
 
 	// Create healer instance
 	healer := &Healer{
-		config:     config,
-		errorQueue: make(chan PanicEvent, config.MaxQueueSize),
-		logger:     logger,
-		ctx:        ctx,
-		cancel:     cancel,
+		config:   config,
+		queue:    newPriorityQueue(config.MaxQueueSize),
+		logger:   logger,
+		tracer:   config.Tracer,
+		redactor: redactor,
+		filter:   filter,
+		results:  make(chan ProcessingResult, resultsBuffer),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	// Release what was set up so far if initialization fails from here on
+	fail := func(err error) (*Healer, error) {
+		cancel()
+		if healer.providerManager != nil {
+			healer.providerManager.Close()
+		}
+		return nil, err
 	}
 
 	// Initialize provider manager with multi-AI support and MCP
 	if config.Enabled {
 		providerManager, err := ai.NewProviderManager(config, logger)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create provider manager: %w", err)
+			return fail(fmt.Errorf("failed to create provider manager: %w", err))
 		}
 		healer.providerManager = providerManager
 		logger.Info("Provider manager initialized with AI providers and MCP support")
@@ -89,41 +171,162 @@ func Initialize(config Config) (*Healer, error) {
 		logger.Info("Healer disabled - skipping provider initialization")
 	}
 
-	// Initialize Git client if enabled and configured
-	if config.Enabled && config.GitHubToken != "" && config.RepoOwner != "" && config.RepoName != "" {
-		healer.gitClient = NewGitHubClient(config.GitHubToken, config.RepoOwner, config.RepoName, logger)
+	// Initialize Git client for the configured provider if enabled and configured
+	switch {
+	case config.Enabled && config.GitClient != nil:
+		gitClient, ok := config.GitClient.(GitClient)
+		if !ok {
+			return fail(fmt.Errorf("config GitClient of type %T does not implement healer.GitClient", config.GitClient))
+		}
+		healer.gitClient = gitClient
+		logger.Info("Using the injected Git client")
+	case !config.Enabled || config.RepoOwner == "" || config.RepoName == "":
+		logger.Info("Git client disabled - missing repo owner or repo name")
+	case config.GitProvider == "gitlab" && config.GitLabToken != "":
+		healer.gitClient = NewGitLabClient(config.GitLabToken, config.RepoOwner, config.RepoName, config.GitLabBaseURL, clients.Client(gitClientTimeout), logger)
+		healer.routed.factory = func(owner, repo string) GitClient {
+			return NewGitLabClient(config.GitLabToken, owner, repo, config.GitLabBaseURL, clients.Client(gitClientTimeout), logger)
+		}
+		logger.Info("GitLab client initialized for project: %s/%s", config.RepoOwner, config.RepoName)
+	case config.GitProvider != "gitlab" && config.GitHubToken != "":
+		healer.gitClient = NewGitHubClient(config.GitHubToken, config.RepoOwner, config.RepoName, clients.Client(gitClientTimeout), logger)
+		healer.routed.factory = func(owner, repo string) GitClient {
+			return NewGitHubClient(config.GitHubToken, owner, repo, clients.Client(gitClientTimeout), logger)
+		}
 		logger.Info("Git client initialized for repository: %s/%s", config.RepoOwner, config.RepoName)
-	} else {
-		logger.Info("Git client disabled - missing GitHub token, repo owner, or repo name")
+	default:
+		logger.Info("Git client disabled - missing %s token", config.GitProvider)
+	}
+
+	// Initialize Slack and Discord notifications for created PRs
+	if config.SlackWebhookURL != "" {
+		healer.AddNotifier(NewSlackNotifier(config.SlackWebhookURL, clients.Client(slackTimeout)))
+		logger.Info("Slack notifications enabled for created pull requests")
+	}
+	if config.DiscordWebhookURL != "" {
+		healer.AddNotifier(NewDiscordNotifier(config.DiscordWebhookURL, clients.Client(discordTimeout)))
+		logger.Info("Discord notifications enabled for created pull requests")
+	}
+
+	// Post lifecycle events to a generic webhook
+	if config.WebhookURL != "" {
+		healer.AddNotifier(NewWebhookNotifier(config.WebhookURL, config.WebhookSecret, config.WebhookHeaders, clients.Client(webhookTimeout)))
+		logger.Info("Webhook notifications enabled for captured panics, fixes and pull requests")
 	}
 
 	// Create queue manager
 	healer.queueManager = NewQueueManager(healer, logger)
 
+	// Replay events persisted by a previous process
+	if config.QueuePersistencePath != "" {
+		journal, err := openQueueJournal(config.QueuePersistencePath, logger)
+		if err != nil {
+			return fail(err)
+		}
+		healer.queueManager.journal = journal
+		healer.queueManager.replayJournal()
+	}
+
+	// Create dead-letter queue for events that exhaust their retries
+	deadLetters, err := newDeadLetterQueue(config.DeadLetterPath, logger)
+	if err != nil {
+		return fail(err)
+	}
+	healer.deadLetters = deadLetters
+
+	// Record captured panics and their results for auditing
+	store, err := newStore(config)
+	if err != nil {
+		return fail(err)
+	}
+	healer.store = store
+
+	// Resolve the source sent to the AI through the injected resolver, if any
+	resolver, err := newSourceResolver(config)
+	if err != nil {
+		return fail(err)
+	}
+	healer.resolver = resolver
+
 	// Create retry manager with configuration from healer config
 	retryConfig := RetryConfig{
 		MaxAttempts:   config.RetryAttempts,
 		InitialDelay:  1 * time.Second,
 		MaxDelay:      30 * time.Second,
 		BackoffFactor: 2.0,
+		Jitter:        JitterEqual, // keep workers that fail together from retrying in lockstep
 	}
 	healer.retryManager = NewRetryManager(retryConfig, logger)
 
-	// Create circuit breaker
-	healer.circuitBreaker = NewCircuitBreaker(DefaultCircuitBreakerConfig(), logger)
+	// Create circuit breaker for the Git client
+	healer.gitBreaker = NewCircuitBreaker(DefaultCircuitBreakerConfig(), logger)
 
 	// Create worker pool
 	healer.workerPool = NewWorkerPool(healer, logger)
+	healer.batcher = newFixBatcher(healer)
 
 	// Set as global healer for panic handling
 	SetGlobalHealer(healer)
 
+	// Stop when the parent context is cancelled
+	if parent.Done() != nil {
+		go func() {
+			select {
+			case <-parent.Done():
+				healer.logger.Info("Parent context cancelled: %v", context.Cause(parent))
+				healer.Stop()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
 	return healer, nil
 }
 
+// Disabled returns a healer that does nothing, for libraries that want to
+// call the healer unconditionally instead of checking for nil. Every method
+// is safe to call: it captures no panics, starts no workers, makes no AI or
+// Git calls and logs nothing. It is not installed as the global healer, and
+// the global functions such as HandlePanic behave as if no healer was
+// installed even after InstallPanicHandler.
+func Disabled() *Healer {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.MaxQueueSize = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &Healer{
+		config:   config,
+		queue:    newPriorityQueue(0),
+		logger:   internal.NewNopLogger(),
+		redactor: internal.DefaultRedactor(),
+		store:    NewMemoryStore(1),
+		results:  make(chan ProcessingResult),
+		ctx:      ctx,
+		cancel:   cancel,
+		inert:    true,
+	}
+	h.batcher = newFixBatcher(h)
+	return h
+}
+
+// getConfig returns a snapshot of the current configuration
+func (h *Healer) getConfig() Config {
+	h.configMu.RLock()
+	defer h.configMu.RUnlock()
+	return h.config
+}
+
+// getProviderManager returns the current provider manager, which may be nil
+func (h *Healer) getProviderManager() *ProviderManager {
+	h.configMu.RLock()
+	defer h.configMu.RUnlock()
+	return h.providerManager
+}
+
 // Start begins background processing of errors
 func (h *Healer) Start() error {
-	if !h.config.Enabled {
+	if !h.getConfig().Enabled {
 		h.logger.Info("Healer is disabled, skipping background processing")
 		return nil
 	}
@@ -139,36 +342,200 @@ func (h *Healer) Start() error {
 	return nil
 }
 
-// Stop gracefully shuts down the healer
+// ShutdownResult reports what happened to queued events when the healer stopped
+type ShutdownResult struct {
+	Drained int // events processed while draining the queue
+	Dropped int // events left unprocessed, kept in the persisted queue if there is one and dead-lettered otherwise
+}
+
+// Stop gracefully shuts down the healer. Only the first call does anything;
+// later calls return its result.
 func (h *Healer) Stop() error {
-	h.logger.Info("Stopping healer")
+	_, err := h.Shutdown()
+	return err
+}
 
-	// Cancel context to signal shutdown
-	h.cancel()
+// Shutdown stops the healer like Stop and reports how many queued events
+// were processed before the workers stopped and how many were left over.
+// Workers keep processing queued events for up to ShutdownDrainTimeout.
+func (h *Healer) Shutdown() (ShutdownResult, error) {
+	h.stopOnce.Do(func() {
+		h.shutdownResult, h.stopErr = h.stop()
+	})
+	return h.shutdownResult, h.stopErr
+}
+
+// stop shuts down the healer for Shutdown
+func (h *Healer) stop() (ShutdownResult, error) {
+	h.logger.Info("Stopping healer")
 
-	// Stop the worker pool
+	var result ShutdownResult
 	if h.workerPool != nil {
+		processed := h.workerPool.GetProcessedCount()
+
+		// Let workers finish what was captured before shutdown
+		config := h.getConfig()
+		if timeout := config.GetShutdownDrainTimeout(); timeout > 0 && !h.workerPool.IsPaused() {
+			h.workerPool.Drain(timeout)
+		}
+
+		// Cancel context to signal shutdown
+		h.cancel()
+
 		if err := h.workerPool.Stop(); err != nil {
 			h.logger.Error("Error stopping worker pool: %v", err)
-			return err
+			return result, err
 		}
+		result.Drained = int(h.workerPool.GetProcessedCount() - processed)
+	} else {
+		h.cancel()
+	}
+
+	result.Dropped = h.dropQueued()
+	if result.Drained > 0 || result.Dropped > 0 {
+		h.logger.Info("Drained %d queued events on shutdown, %d left unprocessed", result.Drained, result.Dropped)
+	}
+
+	// Open pull requests for fixes still waiting on their batch window
+	h.batcher.flushAll()
+
+	// Stop any stdio MCP servers
+	if pm := h.getProviderManager(); pm != nil {
+		pm.Close()
+	}
+
+	// Close the persisted queue; unprocessed events are replayed on the next start
+	if h.queueManager != nil {
+		if err := h.queueManager.journal.close(); err != nil {
+			h.logger.Warn("Error closing queue persistence file: %v", err)
+		}
+	}
+
+	if err := h.deadLetters.close(); err != nil {
+		h.logger.Warn("Error closing dead-letter file: %v", err)
 	}
 
 	h.logger.Info("Healer stopped successfully")
+	return result, nil
+}
+
+// dropQueued empties the queue once the workers have stopped and returns how
+// many events it held. They stay in the persisted queue to be replayed on the
+// next start if there is one, and are dead-lettered otherwise.
+func (h *Healer) dropQueued() int {
+	persisted := h.persistsQueue()
+	dropped := 0
+	for _, band := range h.queue.bands {
+	drain:
+		for {
+			select {
+			case event := <-band:
+				dropped++
+				if !persisted {
+					event.Status = "failed"
+					event.LastError = "healer stopped before the event was processed"
+					h.deadLetters.add(event)
+				}
+			default:
+				break drain
+			}
+		}
+	}
+	return dropped
+}
+
+// Pause temporarily stops the healer from processing queued events, for example
+// during an incident. Panics are still captured and queued up to the queue
+// capacity, and the queue and circuit breaker state are kept, unlike Stop.
+func (h *Healer) Pause() {
+	if h.workerPool != nil {
+		h.workerPool.Pause()
+	}
+}
+
+// Resume continues processing queued events after Pause
+func (h *Healer) Resume() {
+	if h.workerPool != nil {
+		h.workerPool.Resume()
+	}
+}
+
+// SetWorkerCount scales the worker pool to n workers, between 1 and 50, for
+// example to add healing capacity during a deploy storm. It fails if the pool
+// is not running or is shutting down. The new count is kept in the
+// configuration so it also applies if the pool is restarted.
+func (h *Healer) SetWorkerCount(n int) error {
+	h.reloadMu.Lock()
+	defer h.reloadMu.Unlock()
+
+	if h.workerPool == nil {
+		return errors.New("the healer has no worker pool")
+	}
+	if err := h.workerPool.Resize(n); err != nil {
+		return err
+	}
+
+	h.configMu.Lock()
+	h.config.WorkerCount = n
+	h.configMu.Unlock()
+
 	return nil
 }
 
+// getTracer returns the configured tracer (implements eventTracer)
+func (h *Healer) getTracer() Tracer {
+	return h.tracer
+}
+
+// redactEvent scrubs secrets and PII from a captured event using the configured patterns
+func (h *Healer) redactEvent(event *PanicEvent) {
+	event.redact(h.redactor)
+}
+
+// stackLimits returns the configured stack trace limits (implements stackLimiter)
+func (h *Healer) stackLimits() (frames, bytes int) {
+	config := h.getConfig()
+	return config.GetStackLimits()
+}
+
+// applyDefaultTags adds the configured default tags the event does not set itself
+func (h *Healer) applyDefaultTags(event *PanicEvent) {
+	defaults := h.getConfig().DefaultTags
+	if len(defaults) == 0 {
+		return
+	}
+	tags := make(map[string]string, len(defaults)+len(event.Tags))
+	maps.Copy(tags, defaults)
+	maps.Copy(tags, event.Tags)
+	event.Tags = tags
+}
+
+// skipReason returns why a captured event should not be healed, or "" if it
+// should be (implements eventFilter). Skipped events are counted in the stats.
+func (h *Healer) skipReason(event PanicEvent) string {
+	h.configMu.RLock()
+	filter := h.filter
+	h.configMu.RUnlock()
+
+	reason := filter.SkipReason(event.Error, event.Function, event.SourceFile)
+	if reason != "" {
+		h.ignored.Add(1)
+	}
+	return reason
+}
+
 // InstallPanicHandler sets up the global panic handler
 // This method configures the healer to capture panics when they occur.
 // Due to Go's design, automatic panic capture requires explicit defer statements
 // in your code. Use the provided helper functions for panic capture.
 func (h *Healer) InstallPanicHandler() {
-	if h.panicCapture == nil {
-		h.panicCapture = NewPanicCapture(h, h.logger)
+	if h.inert {
+		return
 	}
+	h.panicCapture.CompareAndSwap(nil, NewPanicCapture(h, h.logger))
 
 	// Install the panic handler
-	h.panicCapture.InstallHandler()
+	h.panicCapture.Load().InstallHandler()
 
 	if h.logger != nil {
 		h.logger.Info("Panic handler installed successfully")
@@ -183,6 +550,10 @@ func (h *Healer) InstallPanicHandler() {
 // RestorePanicHandler restores the original panic handling behavior
 // This method provides cleanup functionality to restore original handlers
 func (h *Healer) RestorePanicHandler() {
+	if h.inert {
+		return
+	}
+
 	// Clear the global healer to disable panic capture
 	SetGlobalHealer(nil)
 
@@ -191,21 +562,49 @@ func (h *Healer) RestorePanicHandler() {
 	}
 }
 
-// Global healer instance for panic handling
-var globalHealer *Healer
+// Global healer instance for panic handling. It is swapped atomically because
+// panics are captured from any goroutine while healers are installed and stopped.
+var globalHealer atomic.Pointer[Healer]
 
 // SetGlobalHealer sets the global healer instance for panic handling
 func SetGlobalHealer(healer *Healer) {
-	globalHealer = healer
+	globalHealer.Store(healer)
+}
+
+// globalPanicCapture returns the panic capture of the global healer, or nil
+// if no healer is installed or its panic handler is not installed yet
+func globalPanicCapture() *PanicCapture {
+	if h := globalHealer.Load(); h != nil {
+		return h.panicCapture.Load()
+	}
+	return nil
+}
+
+// globalLogger returns the logger of the global healer, or nil if none is installed
+func globalLogger() Logger {
+	if h := globalHealer.Load(); h != nil {
+		return h.logger
+	}
+	return nil
 }
 
 // HandlePanic should be called in defer statements to capture panics
 // Usage: defer healer.HandlePanic()
+//
+// The panic is re-raised with the original value, so recover handlers further
+// up see the same value they would without the healer. The re-panic happens
+// before the panicking frames are unwound, so the original panic site is still
+// on the stack: it appears in the crash output below the HandlePanic frame and
+// in debug.Stack() taken by an outer recover, and the captured PanicEvent
+// reports it as its source location.
 func HandlePanic() {
 	if r := recover(); r != nil {
-		if globalHealer != nil && globalHealer.panicCapture != nil {
+		if pc := globalPanicCapture(); pc != nil {
 			// Capture the panic for processing
-			globalHealer.panicCapture.CapturePanic(r)
+			pc.CapturePanic(r)
+		}
+		if logger := globalLogger(); logger != nil {
+			logger.Debug("Re-panicking with the original value, stack at the panic site:\n%s", debug.Stack())
 		}
 
 		// Re-panic to maintain normal panic behavior
@@ -217,18 +616,45 @@ func HandlePanic() {
 // Usage: defer healer.RecoverAndHandle()
 func RecoverAndHandle() {
 	if r := recover(); r != nil {
-		if globalHealer != nil && globalHealer.panicCapture != nil {
+		if pc := globalPanicCapture(); pc != nil {
 			// Capture the panic for processing
-			globalHealer.panicCapture.CapturePanic(r)
+			pc.CapturePanic(r)
 		}
 
 		// Log the panic but don't re-panic (graceful recovery)
-		if globalHealer != nil && globalHealer.logger != nil {
-			globalHealer.logger.Error("Recovered from panic: %v", r)
+		if logger := globalLogger(); logger != nil {
+			logger.Error("Recovered from panic: %v", r)
 		}
 	}
 }
 
+// CapturePanicWithMetadata records an already-recovered panic value with the
+// global healer, attaching request metadata such as the HTTP method and path.
+// Framework adapters that run their own recover use this to report panics.
+func CapturePanicWithMetadata(panicValue any, metadata map[string]string) {
+	if pc := globalPanicCapture(); pc != nil {
+		pc.CapturePanicWithMetadata(panicValue, metadata)
+	}
+}
+
+// CaptureWithTags records an already-recovered panic value with the global
+// healer, attaching tags such as the service, environment or version. The
+// tags take precedence over Config.DefaultTags.
+func CaptureWithTags(panicValue any, tags map[string]string) {
+	if pc := globalPanicCapture(); pc != nil {
+		pc.CaptureWithTags(panicValue, tags)
+	}
+}
+
+// CapturePanicCtx records an already-recovered panic value with the global
+// healer, attaching the metadata stored on ctx. When tracing is enabled the
+// capture span is a child of any span in ctx.
+func CapturePanicCtx(ctx context.Context, panicValue any) {
+	if pc := globalPanicCapture(); pc != nil {
+		pc.CapturePanicCtx(ctx, panicValue)
+	}
+}
+
 // WrapFunction wraps a function to automatically capture any panics
 func WrapFunction(fn func()) func() {
 	return func() {
@@ -275,12 +701,12 @@ func MustInstallGlobalPanicHandler(config Config) *Healer {
 
 // GetGlobalHealer returns the current global healer instance
 func GetGlobalHealer() *Healer {
-	return globalHealer
+	return globalHealer.Load()
 }
 
 // IsGlobalHealerInstalled returns true if a global healer is currently installed
 func IsGlobalHealerInstalled() bool {
-	return globalHealer != nil
+	return globalHealer.Load() != nil
 }
 
 // WrapFunctionWithArgs wraps a function that takes arguments
@@ -299,11 +725,36 @@ func WrapFunctionWithArgsAndRecovery(fn func(...any)) func(...any) {
 	}
 }
 
-// WrapHTTPHandler wraps an HTTP handler function with panic capture
+// WrapHTTPHandler wraps an HTTP handler function with panic capture.
+// Captured panics include the request method and path along with any
+// metadata added to the request context with WithContext. After a panic the
+// client receives Config.HTTPPanicResponse, or a 500 JSON error by default.
 func WrapHTTPHandler(handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		defer RecoverAndHandle() // Use graceful recovery for HTTP handlers
-		handler(w, r)
+		tw := &trackingResponseWriter{ResponseWriter: w}
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				// http.ErrAbortHandler is how handlers deliberately abort; let net/http handle it
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				// The request metadata is only built once a panic needs capturing
+				ctx := WithContext(r.Context(), map[string]string{
+					"http_method": r.Method,
+					"http_path":   r.URL.Path,
+				})
+				CapturePanicCtx(ctx, rec)
+				if logger := globalLogger(); logger != nil {
+					logger.Error("Recovered from panic: %v", rec)
+				}
+
+				writePanicResponse(tw, r, rec)
+			}
+		}()
+
+		handler(tw, r)
 	}
 }
 
@@ -360,25 +811,44 @@ func (h *Healer) GetQueueStats() map[string]any {
 	stats := make(map[string]any)
 
 	// Queue size information
-	stats["queue_capacity"] = cap(h.errorQueue)
-	stats["queue_length"] = len(h.errorQueue)
-	stats["queue_available"] = cap(h.errorQueue) - len(h.errorQueue)
+	stats["queue_capacity"] = h.queue.capacity()
+	stats["queue_length"] = h.queue.len()
+	stats["queue_available"] = h.queue.capacity() - h.queue.len()
+	stats["queue_lengths"] = h.queue.lengths()
 
 	// Dropped events count
 	if h.queueManager != nil {
 		stats["dropped_events"] = h.queueManager.GetDroppedCount()
+		stats["deduplicated_events"] = h.queueManager.GetDedupCount()
+		stats["overflow_strategy"] = h.getConfig().OverflowStrategy
 	}
+	stats["dead_letter_events"] = h.deadLetters.len()
+	stats["pr_rate_limited"] = h.prLimiter.limitedCount()
+	stats["event_stream_dropped"] = h.events.dropped.Load()
+	stats["ignored_panics"] = h.ignored.Load()
+	stats["sampled_out"] = h.sampler.sampledOut.Load()
+	stats["dropped_results"] = h.droppedResults.Load()
+	stats["duplicate_deliveries"] = h.claims.skipped.Load()
 
 	// Worker pool information
 	if h.workerPool != nil {
 		stats["worker_count"] = h.workerPool.GetWorkerCount()
 		stats["workers_running"] = h.workerPool.IsRunning()
+		stats["processed_events"] = h.workerPool.GetProcessedCount()
+		stats["failed_events"] = h.workerPool.GetFailedCount()
 	}
 
-	// Circuit breaker status
-	if h.circuitBreaker != nil {
-		stats["circuit_breaker_state"] = h.circuitBreaker.GetState().String()
-		stats["circuit_breaker_failures"] = h.circuitBreaker.GetFailureCount()
+	// Circuit breaker status for the Git client and each AI provider
+	if h.gitBreaker != nil {
+		stats["circuit_breaker_state"] = h.gitBreaker.GetState().String()
+		stats["circuit_breaker_failures"] = h.gitBreaker.GetFailureCount()
+	}
+	if pm := h.getProviderManager(); pm != nil {
+		providerBreakers := make(map[string]string)
+		for name, state := range pm.GetCircuitBreakerStates() {
+			providerBreakers[name] = state.String()
+		}
+		stats["provider_circuit_breakers"] = providerBreakers
 	}
 
 	return stats
@@ -387,16 +857,19 @@ func (h *Healer) GetQueueStats() map[string]any {
 // GetStatus returns the current status of the healer
 func (h *Healer) GetStatus() map[string]any {
 	status := make(map[string]any)
+	config := h.getConfig()
 
-	status["enabled"] = h.config.Enabled
+	status["enabled"] = config.Enabled
+	status["dry_run"] = config.DryRun
 	status["running"] = h.workerPool != nil && h.workerPool.IsRunning()
+	status["paused"] = h.workerPool != nil && h.workerPool.IsPaused()
 
 	// Add configuration info
 	status["config"] = map[string]any{
-		"max_queue_size": h.config.MaxQueueSize,
-		"worker_count":   h.config.WorkerCount,
-		"retry_attempts": h.config.RetryAttempts,
-		"log_level":      h.config.LogLevel,
+		"max_queue_size": config.MaxQueueSize,
+		"worker_count":   config.WorkerCount,
+		"retry_attempts": config.RetryAttempts,
+		"log_level":      config.LogLevel,
 	}
 
 	// Add queue statistics
@@ -405,37 +878,52 @@ func (h *Healer) GetStatus() map[string]any {
 	return status
 }
 
-// ResetCircuitBreaker manually resets the circuit breaker
+// ResetCircuitBreaker manually resets the Git and AI provider circuit breakers
 func (h *Healer) ResetCircuitBreaker() {
-	if h.circuitBreaker != nil {
-		h.circuitBreaker.Reset()
-		if h.logger != nil {
-			h.logger.Info("Circuit breaker reset manually")
-		}
+	if h.gitBreaker != nil {
+		h.gitBreaker.Reset()
+	}
+	if pm := h.getProviderManager(); pm != nil {
+		pm.ResetCircuitBreakers()
+	}
+	if h.logger != nil {
+		h.logger.Info("Circuit breakers reset manually")
 	}
 }
 
+// GetFailedEvents returns the most recent events that failed processing after
+// all retries, oldest first. Each event's LastError holds the final error.
+func (h *Healer) GetFailedEvents() []PanicEvent {
+	return h.deadLetters.list()
+}
+
 // GetQueueManager returns the queue manager (implements HealerInterface)
 func (h *Healer) GetQueueManager() QueueManagerInterface {
+	if h.queueManager == nil {
+		return nil
+	}
 	return h.queueManager
 }
 
-// GetErrorQueue returns the error queue (implements HealerInterface)
+// GetErrorQueue returns the queue band for medium and unclassified severity
+// events (implements HealerInterface). Critical and high severity events are
+// queued on separate bands that workers drain first.
 func (h *Healer) GetErrorQueue() chan PanicEvent {
-	return h.errorQueue
+	return h.queue.band(SeverityMedium)
 }
 
 // CreateAISession creates a new AI session for comprehensive error analysis and fixing
 func (h *Healer) CreateAISession() *ai.SessionManager {
-	if h.providerManager == nil {
+	pm := h.getProviderManager()
+	if pm == nil {
 		return nil
 	}
-	return h.providerManager.CreateSession(h.gitClient)
+	return pm.CreateSession(h.gitClient)
 }
 
 // ProcessErrorWithSession processes an error using the session-based approach
 func (h *Healer) ProcessErrorWithSession(ctx context.Context, panicEvent PanicEvent) (*ai.SessionResult, error) {
-	if h.providerManager == nil {
+	if h.getProviderManager() == nil {
 		return nil, fmt.Errorf("provider manager not initialized")
 	}
 
@@ -445,20 +933,13 @@ func (h *Healer) ProcessErrorWithSession(ctx context.Context, panicEvent PanicEv
 		return nil, fmt.Errorf("failed to create AI session")
 	}
 
-	// Convert PanicEvent to ErrorInfo
-	errorInfo := &ai.ErrorInfo{
-		Error:      fmt.Sprintf("%v", panicEvent.Error),
-		StackTrace: panicEvent.StackTrace,
-		SourceFile: panicEvent.SourceFile,
-		LineNumber: panicEvent.LineNumber,
-		Function:   panicEvent.Function,
-		Timestamp:  panicEvent.Timestamp,
-		Severity:   "high", // Default severity
-	}
+	errorInfo := newErrorInfo(panicEvent)
+
+	// Read the source around the panic site, falling back to a placeholder
+	sourceCode := h.sourceCode(panicEvent)
 
-	// Create code context (this could be enhanced with actual source code extraction)
 	codeContext := &ai.CodeContext{
-		SourceCode:   "// Source code would be extracted here",
+		SourceCode:   sourceCode,
 		RelatedFiles: []string{panicEvent.SourceFile},
 		FunctionSig:  panicEvent.Function,
 	}
@@ -467,13 +948,136 @@ func (h *Healer) ProcessErrorWithSession(ctx context.Context, panicEvent PanicEv
 	return session.InitiateSession(ctx, errorInfo, codeContext)
 }
 
+// HealNow runs the fix pipeline for event inline instead of queueing it, for
+// tests and command-line tools. The fix goes through the same confidence,
+// compile and rate limit checks as queued events, but its pull request is
+// opened immediately even when BatchByFile is set. The result holds the
+// generated fix and the pull request, if one was opened.
+func (h *Healer) HealNow(ctx context.Context, event PanicEvent) (*ai.SessionResult, error) {
+	if h.getProviderManager() == nil {
+		return nil, fmt.Errorf("provider manager not initialized")
+	}
+
+	if event.ID == "" {
+		event.ID = generateID()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Fingerprint == "" {
+		event.Fingerprint = event.computeFingerprint()
+	}
+	if event.Severity == "" {
+		event.Severity = ClassifySeverity(event)
+	}
+	h.applyDefaultTags(&event)
+
+	// Worker IDs in the pool start at 1, so 0 marks inline processing
+	worker := NewBackgroundWorker(0, h, h.logger, nil)
+	worker.unbatched = true
+	start := time.Now()
+
+	fixResponse, err := worker.processEventWithAI(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	if fixResponse != nil {
+		h.notifyFixGenerated(event, fixResponse)
+	}
+
+	prResult, err := worker.processEventWithGit(ctx, event, fixResponse)
+	if errors.Is(err, errHandedOff) {
+		// Only the PR rate limit hands off unbatched fixes
+		err = errPRRateLimited
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ai.SessionResult{
+		SessionID:   event.ID,
+		Success:     prResult != nil,
+		FixResponse: fixResponse,
+		Duration:    time.Since(start),
+		Context: &ai.SessionContext{
+			ErrorInfo:   newErrorInfo(event),
+			Environment: make(map[string]string),
+			Metadata:    make(map[string]string),
+			SessionID:   event.ID,
+			Timestamp:   start,
+		},
+		Timestamp: time.Now(),
+	}
+	if prResult != nil {
+		result.PRResult = &ai.PRResult{
+			Title:        prResult.Title,
+			FilesChanged: 1,
+			Success:      true,
+			URL:          prResult.URL,
+			Number:       prResult.Number,
+		}
+	} else if !h.getConfig().DryRun {
+		result.Error = "fix did not qualify for a pull request"
+	}
+	return result, nil
+}
+
+// newErrorInfo converts a panic event to the error description used by AI sessions
+func newErrorInfo(event PanicEvent) *ai.ErrorInfo {
+	errorInfo := &ai.ErrorInfo{
+		Error:      fmt.Sprintf("%v", event.Error),
+		ErrorType:  event.ErrorType,
+		StackTrace: event.StackTrace,
+		SourceFile: event.SourceFile,
+		LineNumber: event.LineNumber,
+		Function:   event.Function,
+		Timestamp:  event.Timestamp,
+		Severity:   event.Severity,
+	}
+	if errorInfo.Severity == "" {
+		errorInfo.Severity = ClassifySeverity(event)
+	}
+	return errorInfo
+}
+
 // GetProviderStatus returns status of AI providers and MCP
 func (h *Healer) GetProviderStatus() map[string]interface{} {
-	if h.providerManager == nil {
+	pm := h.getProviderManager()
+	if pm == nil {
 		return map[string]interface{}{
 			"enabled": false,
 			"reason":  "provider manager not initialized",
 		}
 	}
-	return h.providerManager.GetProviderStatus()
+	return pm.GetProviderStatus()
+}
+
+// ValidateConnectivity makes a cheap authenticated call to every configured
+// AI provider and to the Git provider, which must grant push access to the
+// repository and to every repository of Config.RepoRouting, and returns every
+// failure joined. Call it at startup to fail fast on bad credentials, or from
+// a health check.
+func (h *Healer) ValidateConnectivity(ctx context.Context) error {
+	var errs []error
+	if pm := h.getProviderManager(); pm != nil {
+		if err := pm.CheckConnectivity(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("AI providers: %w", err))
+		}
+	}
+	if checker, ok := h.gitClient.(AccessChecker); ok {
+		if err := checker.CheckAccess(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("git provider: %w", err))
+		}
+	}
+	for _, route := range h.getConfig().RepoRouting {
+		client := h.gitClientFor(route.PathPrefix)
+		checker, ok := client.(AccessChecker)
+		if !ok || client == h.gitClient {
+			continue
+		}
+		if err := checker.CheckAccess(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("git provider (%s/%s): %w", route.Owner, route.Repo, err))
+		}
+	}
+	return errors.Join(errs...)
 }
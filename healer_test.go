@@ -0,0 +1,254 @@
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestInitializeWithContext_StopsWhenParentCancelled(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.LogLevel = "error"
+
+	parent, cancel := context.WithCancel(context.Background())
+	h, err := InitializeWithContext(parent, config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+
+	select {
+	case <-h.ctx.Done():
+		t.Fatal("healer stopped before its parent context was cancelled")
+	default:
+	}
+
+	cancel()
+	select {
+	case <-h.ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("healer did not stop after its parent context was cancelled")
+	}
+
+	// Stopping again after the context did is a no-op
+	if err := h.Stop(); err != nil {
+		t.Errorf("Stop after cancellation failed: %v", err)
+	}
+}
+
+func TestHealer_HealNowOpensPullRequest(t *testing.T) {
+	fix, _ := json.Marshal(map[string]any{
+		"proposed_fix": "if user == nil {\n\treturn nil\n}",
+		"explanation":  "Guard against a nil user",
+		"confidence":   0.9,
+	})
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"response": string(fix), "done": true})
+	}))
+	defer ollama.Close()
+
+	config := DefaultConfig()
+	config.AIProvider = "ollama"
+	config.OllamaBaseURL = ollama.URL
+	config.GitHubToken = "test-token"
+	config.RepoOwner = "acme"
+	config.RepoName = "service"
+	config.BatchByFile = true
+	config.LogLevel = "error"
+
+	h, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	defer h.Stop()
+	git := &recordingGitClient{}
+	h.gitClient = git
+
+	result, err := h.HealNow(context.Background(), PanicEvent{
+		Error:      "runtime error: invalid memory address or nil pointer dereference",
+		SourceFile: "/app/user.go",
+		LineNumber: 12,
+	})
+	if err != nil {
+		t.Fatalf("HealNow failed: %v", err)
+	}
+	if !result.Success || result.PRResult == nil || result.PRResult.URL != "https://example.com/pr" {
+		t.Errorf("Expected the pull request in the result, got %+v", result)
+	}
+	if result.FixResponse == nil || result.FixResponse.Provider != "ollama" {
+		t.Errorf("Expected the generated fix in the result, got %+v", result.FixResponse)
+	}
+	if len(git.requests) != 1 {
+		t.Errorf("Expected the pull request to be opened without batching, got %d requests", len(git.requests))
+	}
+}
+
+// accessCheckingGitClient is a Git client whose CheckAccess returns err
+type accessCheckingGitClient struct {
+	recordingGitClient
+	err error
+}
+
+func (c *accessCheckingGitClient) CheckAccess(ctx context.Context) error {
+	return c.err
+}
+
+func TestHealer_ValidateConnectivity(t *testing.T) {
+	status := http.StatusOK
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("Expected the model list to be requested, got %s", r.URL.Path)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(`{"models":[]}`))
+	}))
+	defer ollama.Close()
+
+	config := DefaultConfig()
+	config.AIProvider = "ollama"
+	config.OllamaBaseURL = ollama.URL
+	config.GitHubToken = "test-token"
+	config.RepoOwner = "acme"
+	config.RepoName = "service"
+	config.LogLevel = "error"
+
+	h, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	defer h.Stop()
+	git := &accessCheckingGitClient{}
+	h.gitClient = git
+
+	if err := h.ValidateConnectivity(context.Background()); err != nil {
+		t.Fatalf("Expected connectivity to validate, got %v", err)
+	}
+
+	// Both failures are reported
+	status = http.StatusUnauthorized
+	git.err = errors.New("token cannot push to acme/service")
+	err = h.ValidateConnectivity(context.Background())
+	if err == nil {
+		t.Fatal("Expected connectivity validation to fail")
+	}
+	for _, want := range []string{"ollama: credentials rejected with status 401", "git provider: token cannot push"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in the error, got %v", want, err)
+		}
+	}
+}
+
+func TestDisabled_WholeAPIIsInert(t *testing.T) {
+	previous := GetGlobalHealer()
+	defer SetGlobalHealer(previous)
+
+	h := Disabled()
+	SetGlobalHealer(h)
+	h.InstallPanicHandler()
+
+	if err := h.Start(); err != nil {
+		t.Errorf("Start failed: %v", err)
+	}
+	h.Pause()
+	h.Resume()
+	if err := h.SetWorkerCount(4); err == nil {
+		t.Error("Expected SetWorkerCount to fail without a worker pool")
+	}
+	if err := h.ReloadConfig(DefaultConfig()); err == nil {
+		t.Error("Expected ReloadConfig to fail for a disabled healer")
+	}
+	h.RegisterHook(NoopHook{})
+	h.ResetCircuitBreaker()
+	_ = h.GetQueueStats()
+	_ = h.GetStatus()
+	_ = h.GetProviderStatus()
+	_ = h.GetFailedEvents()
+	if h.GetQueueManager() != nil {
+		t.Error("Expected no queue manager")
+	}
+	if h.CreateAISession() != nil {
+		t.Error("Expected no AI session")
+	}
+	if _, err := h.HealNow(context.Background(), PanicEvent{Error: "boom"}); err == nil {
+		t.Error("Expected HealNow to fail")
+	}
+	if _, err := h.ProcessErrorWithSession(context.Background(), PanicEvent{Error: "boom"}); err == nil {
+		t.Error("Expected ProcessErrorWithSession to fail")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = h.EventStream(ctx)
+	cancel()
+	metrics := make(chan prometheus.Metric, 100)
+	h.MetricsCollector().Collect(metrics)
+
+	for _, handler := range []http.Handler{h.StatusHandler(), h.HealthzHandler()} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", rec.Code)
+		}
+	}
+
+	// Global helpers recover without capturing anything
+	func() {
+		defer RecoverAndHandle()
+		panic("ignored")
+	}()
+	WrapFunctionWithRecovery(func() { panic("ignored") })()
+	CaptureWithTags("ignored", map[string]string{"env": "test"})
+
+	done := make(chan struct{})
+	SafeGoroutine(func() {
+		defer close(done)
+		panic("ignored")
+	})
+	<-done
+
+	rec := httptest.NewRecorder()
+	WrapHTTPHandler(func(w http.ResponseWriter, r *http.Request) { panic("ignored") })(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rec.Code)
+	}
+
+	if h.queue.len() != 0 {
+		t.Errorf("Expected nothing queued, queue length is %d", h.queue.len())
+	}
+	if err := h.Stop(); err != nil {
+		t.Errorf("Stop failed: %v", err)
+	}
+	if GetGlobalHealer() != h {
+		t.Error("Expected the disabled healer to leave the global healer alone")
+	}
+}
+
+func TestGlobalHelpers_DoNotAllocateWithoutPanics(t *testing.T) {
+	previous := GetGlobalHealer()
+	defer SetGlobalHealer(previous)
+
+	noop := func() {}
+	wrapped := WrapFunction(noop)
+	recovering := WrapFunctionWithRecovery(noop)
+	for name, h := range map[string]*Healer{"no healer": nil, "disabled healer": Disabled()} {
+		SetGlobalHealer(h)
+		allocs := testing.AllocsPerRun(100, func() {
+			func() {
+				defer HandlePanic()
+			}()
+			func() {
+				defer RecoverAndHandle()
+			}()
+			wrapped()
+			recovering()
+		})
+		if allocs != 0 {
+			t.Errorf("Expected no allocations with %s installed, got %.1f per run", name, allocs)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package healertest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	healer "github.com/ajeet-kumar1087/go-code-healer"
+)
+
+// ErrNoResponse is returned by MockAIClient once its scripted responses are used up
+var ErrNoResponse = errors.New("healertest: no scripted AI response left")
+
+// scriptedFix is a response queued on MockAIClient
+type scriptedFix struct {
+	fix *healer.FixResponse
+	err error
+}
+
+// MockAIClient is an AI client that returns scripted fixes in order and
+// records every request. It is safe for concurrent use.
+type MockAIClient struct {
+	mu       sync.Mutex
+	script   []scriptedFix
+	requests []healer.FixRequest
+}
+
+// NewMockAIClient creates an AI client with no scripted responses
+func NewMockAIClient() *MockAIClient {
+	return &MockAIClient{}
+}
+
+// Respond queues fix as the response to the next unanswered request
+func (m *MockAIClient) Respond(fix *healer.FixResponse) *MockAIClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.script = append(m.script, scriptedFix{fix: fix})
+	return m
+}
+
+// Fail queues err as the result of the next unanswered request
+func (m *MockAIClient) Fail(err error) *MockAIClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.script = append(m.script, scriptedFix{err: err})
+	return m
+}
+
+// GenerateFix records request and returns the next scripted response, or
+// ErrNoResponse when none is left. Responses are copied, so the healer
+// annotating them does not change the script.
+func (m *MockAIClient) GenerateFix(ctx context.Context, request healer.FixRequest) (*healer.FixResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, request)
+
+	if len(m.script) == 0 {
+		return nil, ErrNoResponse
+	}
+	next := m.script[0]
+	m.script = m.script[1:]
+	if next.err != nil || next.fix == nil {
+		return nil, next.err
+	}
+	fix := *next.fix
+	if fix.Provider == "" {
+		fix.Provider = m.GetProviderName()
+	}
+	return &fix, nil
+}
+
+// GetProviderName returns "mock"
+func (m *MockAIClient) GetProviderName() string {
+	return "mock"
+}
+
+// ValidateConfiguration always succeeds
+func (m *MockAIClient) ValidateConfiguration() error {
+	return nil
+}
+
+// Requests returns the fix requests received so far, oldest first
+func (m *MockAIClient) Requests() []healer.FixRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]healer.FixRequest(nil), m.requests...)
+}
+
+// Pending returns the number of scripted responses not used yet
+func (m *MockAIClient) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.script)
+}
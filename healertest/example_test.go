@@ -0,0 +1,55 @@
+package healertest_test
+
+import (
+	"testing"
+	"time"
+
+	healer "github.com/ajeet-kumar1087/go-code-healer"
+	"github.com/ajeet-kumar1087/go-code-healer/healertest"
+)
+
+// lookupUser panics like code under test would
+func lookupUser(users map[string]*struct{ Name string }, id string) string {
+	return users[id].Name
+}
+
+func TestExample_PanicOpensPullRequest(t *testing.T) {
+	ai := healertest.NewMockAIClient().Respond(&healer.FixResponse{
+		ProposedFix: "if users[id] == nil {\n\treturn \"\"\n}",
+		Explanation: "Return early for unknown users",
+		IsValid:     true,
+		Confidence:  0.9,
+	})
+	git := healertest.NewMockGitClient()
+
+	config := healertest.Config(ai, git)
+	config.HealExcludePaths = []string{} // the panic comes from this _test.go file
+	h, err := healer.InstallGlobalPanicHandler(config)
+	if err != nil {
+		t.Fatalf("Failed to install healer: %v", err)
+	}
+	defer h.Stop()
+
+	func() {
+		defer healer.RecoverAndHandle()
+		lookupUser(nil, "alice")
+	}()
+
+	select {
+	case result := <-h.Results():
+		if !result.Success || result.PRUrl != "https://git.example.com/pull/1" {
+			t.Errorf("Expected the fix to open a pull request, got %+v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the panic to be processed")
+	}
+
+	requests := ai.Requests()
+	if len(requests) != 1 || requests[0].Error == "" {
+		t.Errorf("Expected one fix request for the panic, got %+v", requests)
+	}
+	prs := git.PullRequests()
+	if len(prs) != 1 || prs[0].Changes[0].Content != "if users[id] == nil {\n\treturn \"\"\n}" {
+		t.Errorf("Expected one pull request with the scripted fix, got %+v", prs)
+	}
+}
@@ -0,0 +1,109 @@
+package healertest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	healer "github.com/ajeet-kumar1087/go-code-healer"
+)
+
+// MockGitClient is a Git client that records the pull requests and issues
+// it is asked to open instead of calling a Git provider. It is safe for
+// concurrent use.
+type MockGitClient struct {
+	mu            sync.Mutex
+	failures      []error
+	pullRequests  []healer.PRRequest
+	issues        []healer.IssueRequest
+	accessErr     error
+	createdNumber int
+}
+
+// NewMockGitClient creates a Git client that opens every request successfully
+func NewMockGitClient() *MockGitClient {
+	return &MockGitClient{}
+}
+
+// Fail makes the next unanswered pull request or issue fail with err
+func (m *MockGitClient) Fail(err error) *MockGitClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures = append(m.failures, err)
+	return m
+}
+
+// DenyAccess makes CheckAccess, and so Healer.ValidateConnectivity, fail with err
+func (m *MockGitClient) DenyAccess(err error) *MockGitClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.accessErr = err
+	return m
+}
+
+// CreatePullRequest records request and returns a pull request numbered in
+// order from 1, or the next scripted failure
+func (m *MockGitClient) CreatePullRequest(ctx context.Context, request healer.PRRequest) (*healer.PRResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pullRequests = append(m.pullRequests, request)
+	if err := m.nextFailure(); err != nil {
+		return nil, err
+	}
+	m.createdNumber++
+	return &healer.PRResult{
+		URL:    fmt.Sprintf("https://git.example.com/pull/%d", m.createdNumber),
+		Number: m.createdNumber,
+		Title:  request.Title,
+		Draft:  request.Draft,
+	}, nil
+}
+
+// CreateIssue records request and returns an issue numbered like pull
+// requests, or the next scripted failure
+func (m *MockGitClient) CreateIssue(ctx context.Context, request healer.IssueRequest) (*healer.IssueResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.issues = append(m.issues, request)
+	if err := m.nextFailure(); err != nil {
+		return nil, err
+	}
+	m.createdNumber++
+	return &healer.IssueResult{
+		URL:    fmt.Sprintf("https://git.example.com/issues/%d", m.createdNumber),
+		Number: m.createdNumber,
+		Title:  request.Title,
+	}, nil
+}
+
+// CheckAccess returns the error set by DenyAccess
+func (m *MockGitClient) CheckAccess(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.accessErr
+}
+
+// PullRequests returns the pull requests asked for so far, oldest first,
+// including failed ones
+func (m *MockGitClient) PullRequests() []healer.PRRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]healer.PRRequest(nil), m.pullRequests...)
+}
+
+// Issues returns the issues asked for so far, oldest first, including failed ones
+func (m *MockGitClient) Issues() []healer.IssueRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]healer.IssueRequest(nil), m.issues...)
+}
+
+// nextFailure pops the next scripted failure; m.mu must be held
+func (m *MockGitClient) nextFailure() error {
+	if len(m.failures) == 0 {
+		return nil
+	}
+	err := m.failures[0]
+	m.failures = m.failures[1:]
+	return err
+}
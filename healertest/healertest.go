@@ -0,0 +1,37 @@
+// Package healertest provides test doubles for code that uses go-code-healer.
+//
+// MockAIClient and MockGitClient record every call and return scripted
+// responses, so tests can drive the whole pipeline, from a captured panic to
+// a pull request, without network access or credentials:
+//
+//	ai := healertest.NewMockAIClient().Respond(&healer.FixResponse{
+//	    ProposedFix: "if user == nil { return }",
+//	    IsValid:     true,
+//	    Confidence:  0.9,
+//	})
+//	git := healertest.NewMockGitClient()
+//	h, err := healer.Initialize(healertest.Config(ai, git))
+package healertest
+
+import (
+	healer "github.com/ajeet-kumar1087/go-code-healer"
+)
+
+// Config returns an enabled healer configuration that generates fixes with
+// ai and opens pull requests with git. It needs no credentials, runs a single
+// worker, logs only errors and does not retry failed calls, so each scripted
+// response is used once. A nil git logs pull requests as a dry run instead.
+func Config(ai *MockAIClient, git *MockGitClient) healer.Config {
+	config := healer.DefaultConfig()
+	config.Enabled = true
+	config.WorkerCount = 1
+	config.RetryAttempts = 1
+	config.LogLevel = "error"
+	config.AIClient = ai
+	if git != nil {
+		config.GitClient = git
+	} else {
+		config.DryRun = true
+	}
+	return config
+}
@@ -0,0 +1,115 @@
+package healer
+
+import "time"
+
+// EventHook receives notifications as panic events move through the healer.
+// Hooks are invoked asynchronously in their own goroutine and any panic they
+// raise is recovered, so a slow or faulty hook never blocks a worker.
+type EventHook interface {
+	// OnPanicCaptured is called as soon as a panic has been captured,
+	// before any AI or GitHub work happens
+	OnPanicCaptured(event PanicEvent)
+
+	// OnFixGenerated is called after an AI provider has returned a fix
+	OnFixGenerated(event PanicEvent, fix *FixResponse)
+
+	// OnPRCreated is called after a pull request with the fix has been opened
+	OnPRCreated(event PanicEvent, result *PRResult)
+
+	// OnError is called when processing an event fails
+	OnError(event PanicEvent, err error)
+}
+
+// NoopHook implements EventHook with empty methods. Embed it to implement
+// only the callbacks you care about.
+type NoopHook struct{}
+
+// OnPanicCaptured implements EventHook
+func (NoopHook) OnPanicCaptured(PanicEvent) {}
+
+// OnFixGenerated implements EventHook
+func (NoopHook) OnFixGenerated(PanicEvent, *FixResponse) {}
+
+// OnPRCreated implements EventHook
+func (NoopHook) OnPRCreated(PanicEvent, *PRResult) {}
+
+// OnError implements EventHook
+func (NoopHook) OnError(PanicEvent, error) {}
+
+// hookNotifier is implemented by healers that dispatch lifecycle hooks
+type hookNotifier interface {
+	notifyPanicCaptured(event PanicEvent)
+}
+
+// RegisterHook adds a hook that is notified of panic lifecycle events
+func (h *Healer) RegisterHook(hook EventHook) {
+	if hook == nil {
+		return
+	}
+
+	h.hooksMu.Lock()
+	defer h.hooksMu.Unlock()
+	h.hooks = append(h.hooks, hook)
+}
+
+// dispatchHooks invokes fn for every registered hook in its own goroutine
+func (h *Healer) dispatchHooks(name string, fn func(EventHook)) {
+	h.hooksMu.RLock()
+	hooks := h.hooks
+	h.hooksMu.RUnlock()
+
+	for _, hook := range hooks {
+		go func(hook EventHook) {
+			defer func() {
+				if r := recover(); r != nil && h.logger != nil {
+					h.logger.Error("Event hook %s panicked: %v", name, r)
+				}
+			}()
+			fn(hook)
+		}(hook)
+	}
+}
+
+// notifyPanicCaptured dispatches OnPanicCaptured to all hooks
+func (h *Healer) notifyPanicCaptured(event PanicEvent) {
+	h.savePanic(event)
+	streamEvent := HealerEvent{Type: EventPanicCaptured, Time: time.Now(), Event: event}
+	h.events.publish(streamEvent)
+	h.notify(streamEvent)
+	h.dispatchHooks("OnPanicCaptured", func(hook EventHook) {
+		hook.OnPanicCaptured(event)
+	})
+}
+
+// notifyFixGenerated dispatches OnFixGenerated to all hooks
+func (h *Healer) notifyFixGenerated(event PanicEvent, fix *FixResponse) {
+	streamEvent := HealerEvent{Type: EventFixGenerated, Time: time.Now(), Event: event, Fix: fix}
+	h.events.publish(streamEvent)
+	h.notify(streamEvent)
+	h.dispatchHooks("OnFixGenerated", func(hook EventHook) {
+		hook.OnFixGenerated(event, fix)
+	})
+}
+
+// notifyPRCreated dispatches OnPRCreated to all hooks. Notifiers are told
+// once per pull request by its creator instead, since a batched pull request
+// is reported here for every event it fixes.
+func (h *Healer) notifyPRCreated(event PanicEvent, result *PRResult) {
+	h.events.publish(HealerEvent{Type: EventPRCreated, Time: time.Now(), Event: event, PR: result})
+	h.dispatchHooks("OnPRCreated", func(hook EventHook) {
+		hook.OnPRCreated(event, result)
+	})
+}
+
+// notifyError dispatches OnError to all hooks
+func (h *Healer) notifyError(event PanicEvent, err error) {
+	streamEvent := HealerEvent{Type: EventProcessingFailed, Time: time.Now(), Event: event}
+	if err != nil {
+		streamEvent.Error = err.Error()
+	}
+	h.events.publish(streamEvent)
+	h.notify(streamEvent)
+	h.dispatchHooks("OnError", func(hook EventHook) {
+		hook.OnError(event, err)
+	})
+}
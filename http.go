@@ -0,0 +1,109 @@
+package healer
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// trackingResponseWriter records whether headers have been sent so a recovered
+// panic does not trigger a superfluous WriteHeader call
+type trackingResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// WriteHeader implements http.ResponseWriter, ignoring repeated calls
+func (tw *trackingResponseWriter) WriteHeader(statusCode int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	tw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write implements http.ResponseWriter
+func (tw *trackingResponseWriter) Write(b []byte) (int, error) {
+	tw.wroteHeader = true
+	return tw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher when the underlying writer supports it
+func (tw *trackingResponseWriter) Flush() {
+	if flusher, ok := tw.ResponseWriter.(http.Flusher); ok {
+		tw.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (tw *trackingResponseWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
+
+// writePanicResponse sends the configured panic response, or a 500 JSON error,
+// unless the handler already started writing its own response
+func writePanicResponse(tw *trackingResponseWriter, r *http.Request, panicValue any) {
+	if tw.wroteHeader {
+		return
+	}
+
+	if h := globalHealer.Load(); h != nil {
+		if respond := h.getConfig().HTTPPanicResponse; respond != nil {
+			respond(tw, r, panicValue)
+			return
+		}
+	}
+
+	writeJSON(tw, http.StatusInternalServerError, map[string]string{
+		"error": http.StatusText(http.StatusInternalServerError),
+	})
+}
+
+// StatusHandler returns an HTTP handler that reports the healer status, queue
+// statistics and AI provider status as JSON.
+// Usage: mux.Handle("/healer/status", h.StatusHandler())
+func (h *Healer) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := h.GetStatus()
+		status["providers"] = h.GetProviderStatus()
+		writeJSON(w, http.StatusOK, status)
+	})
+}
+
+// HealthzHandler returns an HTTP handler suitable for liveness and readiness
+// probes. It responds 200 while the worker pool is running and the queue has
+// room, and 503 otherwise. A disabled healer always reports healthy so that
+// turning it off does not take the service out of rotation.
+func (h *Healer) HealthzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !h.getConfig().Enabled {
+			writeJSON(w, http.StatusOK, map[string]string{"status": "disabled"})
+			return
+		}
+
+		if h.workerPool == nil || !h.workerPool.IsRunning() {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+				"status": "unhealthy",
+				"reason": "worker pool is not running",
+			})
+			return
+		}
+
+		if h.queue.full() {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+				"status": "unhealthy",
+				"reason": "queue is full",
+			})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
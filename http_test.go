@@ -0,0 +1,99 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrapHTTPHandler_WritesDefault500(t *testing.T) {
+	handler := WrapHTTPHandler(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	if !strings.Contains(rec.Body.String(), "Internal Server Error") {
+		t.Errorf("unexpected body: %s", rec.Body.String())
+	}
+}
+
+func TestWrapHTTPHandler_KeepsStatusWrittenBeforePanic(t *testing.T) {
+	handler := WrapHTTPHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("partial"))
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/jobs", nil))
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if rec.Body.String() != "partial" {
+		t.Errorf("body = %q, want only the handler's output", rec.Body.String())
+	}
+}
+
+func TestHealthzHandler(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.MaxQueueSize = 1
+
+	h, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.HealthzHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("disabled healer: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	// Pretend the healer is enabled but its workers were never started
+	h.config.Enabled = true
+	rec = httptest.NewRecorder()
+	h.HealthzHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("stopped workers: status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if !strings.Contains(rec.Body.String(), "worker pool is not running") {
+		t.Errorf("unexpected body: %s", rec.Body.String())
+	}
+}
+
+func TestEventStream_DropsEventsForSlowConsumers(t *testing.T) {
+	h := &Healer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := h.EventStream(ctx)
+
+	// Nobody reads the stream, so publishing must not block once the buffer fills
+	for i := 0; i < eventStreamBuffer+10; i++ {
+		h.notifyPanicCaptured(PanicEvent{ID: fmt.Sprintf("evt-%d", i)})
+	}
+	if dropped := h.events.dropped.Load(); dropped != 10 {
+		t.Errorf("dropped = %d, want 10", dropped)
+	}
+
+	first := <-stream
+	if first.Type != EventPanicCaptured || first.Event.ID != "evt-0" {
+		t.Errorf("first event = %s %s, want panic_captured evt-0", first.Type, first.Event.ID)
+	}
+
+	cancel()
+	for range stream {
+		// Drain until the stream is closed
+	}
+}
@@ -0,0 +1,66 @@
+package healer
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// maxRememberedEvents bounds how many processed event IDs are remembered
+const maxRememberedEvents = 4096
+
+// eventClaims makes processing idempotent per event ID. A worker claims an
+// event before processing it and finishes it afterwards, so an event queued
+// twice, for example by a journal replay or a requeue, is processed by only
+// one worker and not again once done. Completion is made durable across
+// restarts by the queue journal, which never replays an event marked done.
+// The zero value is ready to use.
+type eventClaims struct {
+	mu        sync.Mutex
+	inFlight  map[string]struct{}
+	processed map[string]struct{}
+	order     []string // processed IDs, oldest first
+	skipped   atomic.Int64
+}
+
+// claim reports whether the event with the given ID may be processed, i.e.
+// it is neither being processed nor already processed. Events without an ID
+// are always processed.
+func (c *eventClaims) claim(id string) bool {
+	if id == "" {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.inFlight[id]; ok {
+		c.skipped.Add(1)
+		return false
+	}
+	if _, ok := c.processed[id]; ok {
+		c.skipped.Add(1)
+		return false
+	}
+	if c.inFlight == nil {
+		c.inFlight = make(map[string]struct{})
+		c.processed = make(map[string]struct{})
+	}
+	c.inFlight[id] = struct{}{}
+	return true
+}
+
+// finish records that the claimed event with the given ID was processed
+func (c *eventClaims) finish(id string) {
+	if id == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.inFlight, id)
+	if len(c.order) == maxRememberedEvents {
+		delete(c.processed, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.processed[id] = struct{}{}
+	c.order = append(c.order, id)
+}
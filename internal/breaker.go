@@ -0,0 +1,199 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerState represents the state of a circuit breaker
+type CircuitBreakerState int
+
+const (
+	CircuitBreakerClosed CircuitBreakerState = iota
+	CircuitBreakerOpen
+	CircuitBreakerHalfOpen
+)
+
+// String returns the string representation of the circuit breaker state
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitBreakerClosed:
+		return "CLOSED"
+	case CircuitBreakerOpen:
+		return "OPEN"
+	case CircuitBreakerHalfOpen:
+		return "HALF_OPEN"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// CircuitBreakerConfig holds configuration for circuit breaker
+type CircuitBreakerConfig struct {
+	FailureThreshold int
+	RecoveryTimeout  time.Duration
+	ResetTimeout     time.Duration
+}
+
+// DefaultCircuitBreakerConfig returns default circuit breaker configuration
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		FailureThreshold: 5,
+		RecoveryTimeout:  30 * time.Second,
+		ResetTimeout:     60 * time.Second,
+	}
+}
+
+// CircuitBreaker implements the circuit breaker pattern for external API failures
+type CircuitBreaker struct {
+	config       CircuitBreakerConfig
+	state        CircuitBreakerState
+	failures     int
+	lastFailTime time.Time
+	openUntil    time.Time // set by OpenUntil
+	logger       LoggerInterface
+	mu           sync.RWMutex
+}
+
+// NewCircuitBreaker creates a new circuit breaker
+func NewCircuitBreaker(config CircuitBreakerConfig, logger LoggerInterface) *CircuitBreaker {
+	return &CircuitBreaker{
+		config: config,
+		state:  CircuitBreakerClosed,
+		logger: logger,
+	}
+}
+
+// Execute executes a function through the circuit breaker
+func (cb *CircuitBreaker) Execute(ctx context.Context, operation string, fn func() error) error {
+	if !cb.Allow() {
+		return fmt.Errorf("circuit breaker is OPEN for %s", operation)
+	}
+
+	err := fn()
+	cb.RecordResult(operation, err)
+	return err
+}
+
+// Allow reports whether the circuit breaker lets a call through. It holds the
+// write lock throughout because it may move the breaker from OPEN to HALF_OPEN.
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitBreakerClosed, CircuitBreakerHalfOpen:
+		return true
+	case CircuitBreakerOpen:
+		// Transition to half-open once the time set by OpenUntil or, without
+		// one, the recovery timeout has elapsed
+		if time.Now().Before(cb.openUntil) {
+			return false
+		}
+		if time.Since(cb.lastFailTime) > cb.config.RecoveryTimeout || !cb.openUntil.IsZero() {
+			cb.openUntil = time.Time{}
+			cb.state = CircuitBreakerHalfOpen
+			if cb.logger != nil {
+				cb.logger.Info("Circuit breaker transitioning to HALF_OPEN")
+			}
+			return true
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// RecordResult records the result of an operation and updates circuit breaker state
+func (cb *CircuitBreaker) RecordResult(operation string, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err != nil {
+		cb.failures++
+		cb.lastFailTime = time.Now()
+
+		if cb.logger != nil {
+			cb.logger.Debug("Circuit breaker recorded failure for %s (failures: %d)", operation, cb.failures)
+		}
+
+		// Check if we should open the circuit
+		if cb.failures >= cb.config.FailureThreshold {
+			if cb.state != CircuitBreakerOpen {
+				cb.state = CircuitBreakerOpen
+				if cb.logger != nil {
+					cb.logger.Warn("Circuit breaker OPENED for %s after %d failures", operation, cb.failures)
+				}
+			}
+		} else if cb.state == CircuitBreakerHalfOpen {
+			// Failed in half-open state, go back to open
+			cb.state = CircuitBreakerOpen
+			if cb.logger != nil {
+				cb.logger.Warn("Circuit breaker returned to OPEN state for %s", operation)
+			}
+		}
+	} else {
+		// Success
+		if cb.logger != nil {
+			cb.logger.Debug("Circuit breaker recorded success for %s", operation)
+		}
+
+		if cb.state == CircuitBreakerHalfOpen {
+			// Success in half-open state, close the circuit
+			cb.state = CircuitBreakerClosed
+			cb.failures = 0
+			if cb.logger != nil {
+				cb.logger.Info("Circuit breaker CLOSED for %s after successful operation", operation)
+			}
+		} else if cb.state == CircuitBreakerClosed {
+			// Reset failure count on success
+			cb.failures = 0
+		}
+	}
+}
+
+// GetState returns the current state of the circuit breaker
+func (cb *CircuitBreaker) GetState() CircuitBreakerState {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.state
+}
+
+// GetFailureCount returns the current failure count
+func (cb *CircuitBreaker) GetFailureCount() int {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.failures
+}
+
+// Reset manually resets the circuit breaker to closed state
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.state = CircuitBreakerClosed
+	cb.failures = 0
+	cb.openUntil = time.Time{}
+
+	if cb.logger != nil {
+		cb.logger.Info("Circuit breaker manually reset to CLOSED state")
+	}
+}
+
+// OpenUntil opens the circuit breaker until t regardless of its failure
+// count, for dependencies that say when they accept calls again, such as a
+// rate-limited API. The breaker moves to HALF_OPEN once t has passed.
+func (cb *CircuitBreaker) OpenUntil(t time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if t.After(cb.openUntil) {
+		cb.openUntil = t
+	}
+	cb.state = CircuitBreakerOpen
+	if cb.logger != nil {
+		cb.logger.Warn("Circuit breaker OPENED until %s", t.Format(time.RFC3339))
+	}
+}
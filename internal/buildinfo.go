@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// healerModulePath is the module of the healer itself. Its own binaries, the
+// command-line tool and test binaries, heal other repositories, so their
+// build info says nothing about the repository to fix.
+const healerModulePath = "github.com/ajeet-kumar1087/go-code-healer"
+
+// appBuildInfo returns the build info of the running binary, or nil when it
+// is unavailable or the binary is one of the healer's own
+func appBuildInfo() *debug.BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Path == healerModulePath || strings.HasPrefix(info.Main.Path, healerModulePath+"/") {
+		return nil
+	}
+	return info
+}
+
+// BuildRevision returns the VCS revision go build stamped into the running
+// binary, or "" for binaries built without VCS information, such as test
+// binaries and builds outside a repository or with -buildvcs=false
+func BuildRevision() string {
+	info := appBuildInfo()
+	if info == nil {
+		return ""
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
+
+// BuildModulePath returns the path of the running binary's main module, such
+// as github.com/acme/service, which starts the source file paths of binaries
+// built with -trimpath, or "" when it is unknown
+func BuildModulePath() string {
+	info := appBuildInfo()
+	if info == nil {
+		return ""
+	}
+	return info.Main.Path
+}
+
+// BuildRepository returns the owner and name of the GitHub repository of the
+// running binary's main module, parsed from a module path such as
+// github.com/owner/repo/v2, or empty strings for modules hosted elsewhere
+func BuildRepository() (owner, repo string) {
+	info := appBuildInfo()
+	if info == nil {
+		return "", ""
+	}
+	return githubRepository(info.Main.Path)
+}
+
+// githubRepository parses the owner and repository from a github.com module path
+func githubRepository(modulePath string) (owner, repo string) {
+	parts := strings.Split(modulePath, "/")
+	if len(parts) < 3 || parts[0] != "github.com" || parts[1] == "" || parts[2] == "" {
+		return "", ""
+	}
+	return parts[1], parts[2]
+}
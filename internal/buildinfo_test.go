@@ -0,0 +1,32 @@
+package internal
+
+import "testing"
+
+func TestGitHubRepository(t *testing.T) {
+	for _, tc := range []struct {
+		modulePath, owner, repo string
+	}{
+		{"github.com/acme/billing", "acme", "billing"},
+		{"github.com/acme/billing/v2", "acme", "billing"},
+		{"github.com/acme/tools/cmd/migrate", "acme", "tools"},
+		{"github.com/acme", "", ""},
+		{"gitlab.com/acme/billing", "", ""},
+		{"example.com/billing", "", ""},
+		{"command-line-arguments", "", ""},
+	} {
+		owner, repo := githubRepository(tc.modulePath)
+		if owner != tc.owner || repo != tc.repo {
+			t.Errorf("githubRepository(%q) = %q, %q; want %q, %q", tc.modulePath, owner, repo, tc.owner, tc.repo)
+		}
+	}
+}
+
+func TestBuildInfo_IgnoresHealerBinaries(t *testing.T) {
+	// Test binaries of this module are built from the healer's own module
+	if owner, repo := BuildRepository(); owner != "" || repo != "" {
+		t.Errorf("Expected no repository from the healer's own build info, got %s/%s", owner, repo)
+	}
+	if revision := BuildRevision(); revision != "" {
+		t.Errorf("Expected no revision from the healer's own build info, got %s", revision)
+	}
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultCommitMessageTemplate is the message of fix commits unless
+// CommitMessageTemplate is set
+const DefaultCommitMessageTemplate = "Fix panic in {{.File}}\n\nAutomatically generated fix for runtime panic"
+
+// CommitMessageData holds the fields available to commit message templates
+type CommitMessageData struct {
+	File       string  // source file the panic happened in
+	Line       int     // line the panic happened on
+	Function   string  // function the panic happened in
+	Error      string  // panic message
+	ErrorType  string  // type of the panic value, e.g. runtime.boundsError
+	Severity   string  // "critical", "high" or "medium"
+	Confidence float64 // AI confidence in the fix, from 0 to 1
+	Fixes      int     // number of panics fixed by the commit; more than 1 for batched fixes
+}
+
+// CommitMessageTemplate renders the messages of fix commits
+type CommitMessageTemplate struct {
+	tmpl *template.Template
+}
+
+// NewCommitMessageTemplate parses text as a text/template rendered with
+// CommitMessageData, or DefaultCommitMessageTemplate if text is empty.
+// Templates referring to fields CommitMessageData does not have are rejected.
+func NewCommitMessageTemplate(text string) (*CommitMessageTemplate, error) {
+	if text == "" {
+		text = DefaultCommitMessageTemplate
+	}
+	tmpl, err := template.New("commit_message").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit message template: %w", err)
+	}
+	t := &CommitMessageTemplate{tmpl: tmpl}
+	if _, err := t.Render(CommitMessageData{}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Render returns the commit message for data
+func (t *CommitMessageTemplate) Render(data CommitMessageData) (string, error) {
+	var message strings.Builder
+	if err := t.tmpl.Execute(&message, data); err != nil {
+		return "", fmt.Errorf("invalid commit message template: %w", err)
+	}
+	if strings.TrimSpace(message.String()) == "" {
+		return "", fmt.Errorf("invalid commit message template: renders an empty message")
+	}
+	return message.String(), nil
+}
@@ -0,0 +1,36 @@
+package internal
+
+import "testing"
+
+func TestCommitMessageTemplate(t *testing.T) {
+	data := CommitMessageData{
+		File:       "app/user.go",
+		Function:   "main.processUser",
+		Error:      "runtime error: invalid memory address or nil pointer dereference",
+		Confidence: 0.87,
+		Fixes:      1,
+	}
+
+	tmpl, err := NewCommitMessageTemplate("")
+	if err != nil {
+		t.Fatalf("default template rejected: %v", err)
+	}
+	if got, _ := tmpl.Render(data); got != "Fix panic in app/user.go\n\nAutomatically generated fix for runtime panic" {
+		t.Errorf("default message = %q", got)
+	}
+
+	tmpl, err = NewCommitMessageTemplate(`fix: {{.Error}} in {{.Function}}{{if gt .Confidence 0.8}} (high confidence){{end}}`)
+	if err != nil {
+		t.Fatalf("template rejected: %v", err)
+	}
+	want := "fix: runtime error: invalid memory address or nil pointer dereference in main.processUser (high confidence)"
+	if got, _ := tmpl.Render(data); got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+
+	for _, text := range []string{"fix: {{.Error", "fix: {{.Issue}}", "{{/* nothing */}}"} {
+		if _, err := NewCommitMessageTemplate(text); err == nil {
+			t.Errorf("expected template %q to be rejected", text)
+		}
+	}
+}
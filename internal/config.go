@@ -4,16 +4,84 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
 )
 
+// AIGenerationParams tunes how AI providers sample fixes. Unset fields keep
+// the provider defaults: temperature 0.1, top_p 0.9 and 1500-2000 max tokens.
+type AIGenerationParams struct {
+	Temperature *float64 `json:"temperature,omitempty"` // 0-2; higher values make fixes less deterministic
+	MaxTokens   int      `json:"max_tokens,omitempty"`  // longest response in tokens
+	TopP        *float64 `json:"top_p,omitempty"`       // 0-1, nucleus sampling
+}
+
+// RepoRoute sends fixes for panics in source files under PathPrefix to the
+// Owner/Repo repository instead of the default one. PathPrefix is the
+// directory that holds the root of the repository in stack trace paths, such
+// as "/app/billing/"; a panic in /app/billing/invoice/tax.go is fixed in
+// invoice/tax.go of the repository.
+type RepoRoute struct {
+	PathPrefix string `json:"path_prefix"` // compared with the start of the panic's source file
+	Owner      string `json:"owner"`       // GitHub owner or GitLab group/namespace
+	Repo       string `json:"repo"`        // GitHub repository or GitLab project
+}
+
+// TemperatureOr returns the configured temperature or def
+func (p AIGenerationParams) TemperatureOr(def float64) float64 {
+	if p.Temperature == nil {
+		return def
+	}
+	return *p.Temperature
+}
+
+// MaxTokensOr returns the configured max tokens or def
+func (p AIGenerationParams) MaxTokensOr(def int) int {
+	if p.MaxTokens <= 0 {
+		return def
+	}
+	return p.MaxTokens
+}
+
+// TopPOr returns the configured top_p or def
+func (p AIGenerationParams) TopPOr(def float64) float64 {
+	if p.TopP == nil {
+		return def
+	}
+	return *p.TopP
+}
+
+// validate checks that the set parameters are in range
+func (p AIGenerationParams) validate() error {
+	if p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2, got %g", *p.Temperature)
+	}
+	if p.MaxTokens < 0 {
+		return fmt.Errorf("max tokens must be positive, got %d", p.MaxTokens)
+	}
+	if p.TopP != nil && (*p.TopP < 0 || *p.TopP > 1) {
+		return fmt.Errorf("top_p must be between 0 and 1, got %g", *p.TopP)
+	}
+	return nil
+}
+
 // MCPServerConfig represents configuration for an MCP server
 type MCPServerConfig struct {
 	Name      string            `json:"name"`
-	Endpoint  string            `json:"endpoint"`
+	Transport string            `json:"transport,omitempty"` // "http" (default) or "stdio"
+	Endpoint  string            `json:"endpoint,omitempty"`  // URL of an http server
+	Command   string            `json:"command,omitempty"`   // command that starts a stdio server
+	Args      []string          `json:"args,omitempty"`      // arguments for Command
+	Env       map[string]string `json:"env,omitempty"`       // environment added for Command
 	AuthType  string            `json:"auth_type,omitempty"` // "none", "bearer", "basic"
 	AuthToken string            `json:"auth_token,omitempty"`
 	Tools     []string          `json:"tools,omitempty"`    // specific tools to use
@@ -25,49 +93,463 @@ type MCPServerConfig struct {
 // This is a copy of the main package Config to avoid circular imports
 type Config struct {
 	// AI Provider Configuration
-	AIProvider   string `json:"ai_provider,omitempty"` // "openai", "claude", "codex"
-	OpenAIAPIKey string `json:"openai_api_key"`
-	OpenAIModel  string `json:"openai_model,omitempty"`
-	ClaudeAPIKey string `json:"claude_api_key,omitempty"`
-	ClaudeModel  string `json:"claude_model,omitempty"`
-	CodexAPIKey  string `json:"codex_api_key,omitempty"`
-	CodexModel   string `json:"codex_model,omitempty"`
+	AIProvider    string `json:"ai_provider,omitempty"` // "openai", "claude", "codex", "gemini", "ollama", "bedrock"
+	OpenAIAPIKey  string `json:"openai_api_key"`
+	OpenAIModel   string `json:"openai_model,omitempty"`
+	ClaudeAPIKey  string `json:"claude_api_key,omitempty"`
+	ClaudeModel   string `json:"claude_model,omitempty"`
+	CodexAPIKey   string `json:"codex_api_key,omitempty"`
+	CodexModel    string `json:"codex_model,omitempty"`
+	GeminiAPIKey  string `json:"gemini_api_key,omitempty"`
+	GeminiModel   string `json:"gemini_model,omitempty"`
+	OllamaBaseURL string `json:"ollama_base_url,omitempty"` // no API key needed, runs self-hosted
+	OllamaModel   string `json:"ollama_model,omitempty"`
+
+	// Amazon Bedrock Configuration. Bedrock runs Claude models with AWS
+	// credentials from the standard chain: environment variables, the shared
+	// credentials file, container credentials or EC2 instance metadata.
+	BedrockRegion  string `json:"bedrock_region,omitempty"`   // e.g. us-east-1
+	BedrockModelID string `json:"bedrock_model_id,omitempty"` // defaults to anthropic.claude-3-5-sonnet-20240620-v1:0
+
+	// Azure OpenAI Configuration. When AzureEndpoint is set the OpenAI provider
+	// sends requests to the Azure deployment using OpenAIAPIKey as the api-key.
+	AzureEndpoint   string `json:"azure_endpoint,omitempty"`    // e.g. https://my-resource.openai.azure.com
+	AzureDeployment string `json:"azure_deployment,omitempty"`  // deployment name
+	AzureAPIVersion string `json:"azure_api_version,omitempty"` // defaults to 2024-02-01
+
+	// OpenAI-compatible endpoints such as Mistral, Together, Groq or vLLM.
+	// When OpenAICompatibleBaseURL is set the OpenAI provider sends
+	// OpenAIModel requests there, authenticated with OpenAIAPIKey.
+	OpenAICompatibleBaseURL    string `json:"openai_compatible_base_url,omitempty"`    // e.g. https://api.mistral.ai/v1; defaults to https://api.openai.com/v1
+	OpenAICompatibleAuthHeader string `json:"openai_compatible_auth_header,omitempty"` // header carrying the API key; defaults to Authorization with a bearer token
+
+	// AI Request Timeouts
+	AITimeoutSeconds   int            `json:"ai_timeout_seconds,omitempty"`   // defaults to 60 seconds
+	AIProviderTimeouts map[string]int `json:"ai_provider_timeouts,omitempty"` // per-provider overrides in seconds, keyed by provider name
+
+	// AI Sampling. Higher temperatures and top_p values make fixes less
+	// deterministic, so the same panic may get a different fix each time.
+	AITemperature    *float64                      `json:"ai_temperature,omitempty"`     // 0-2, defaults to 0.1
+	AIMaxTokens      int                           `json:"ai_max_tokens,omitempty"`      // defaults to 2000 (1500 for codex)
+	AITopP           *float64                      `json:"ai_top_p,omitempty"`           // 0-1, defaults to 0.9
+	AIProviderParams map[string]AIGenerationParams `json:"ai_provider_params,omitempty"` // per-provider overrides, keyed by provider name
+
+	// ErrorPrompts overrides the guidance added to fix prompts for a kind of
+	// error, keyed by category: nil_pointer, bounds, nil_map, type_assertion
+	// or concurrency. An empty value leaves the guidance out.
+	ErrorPrompts map[string]string `json:"error_prompts,omitempty"`
+
+	// AI Cost Estimation
+	ModelPricing map[string]float64 `json:"model_pricing,omitempty"` // USD per 1K tokens, keyed by model or provider name
+
+	// FallbackProviders lists the providers tried, in order, when AIProvider
+	// fails. When set, only these providers are used; when empty, every other
+	// provider with credentials is a fallback.
+	FallbackProviders []string `json:"fallback_providers,omitempty"`
+
+	// QueryAllProviders asks every provider in parallel for each fix and keeps
+	// the most confident valid one instead of stopping at the first valid fix.
+	// It trades AI cost for fix quality.
+	QueryAllProviders bool `json:"query_all_providers,omitempty"`
+
+	// StreamResponses reads OpenAI and Claude responses as they are generated.
+	// The AI timeout then limits the wait between chunks rather than the whole
+	// response, so large fixes are not cut off while still being written.
+	StreamResponses bool `json:"stream_responses,omitempty"`
 
 	// MCP Configuration
 	MCPEnabled bool              `json:"mcp_enabled"`
 	MCPServers []MCPServerConfig `json:"mcp_servers,omitempty"`
 	MCPTimeout int               `json:"mcp_timeout,omitempty"` // defaults to 10 seconds
 
-	// GitHub Configuration
-	GitHubToken string `json:"github_token"`
-	RepoOwner   string `json:"repo_owner"`
-	RepoName    string `json:"repo_name"`
+	// MCPCacheTTL is how long, in seconds, context gathered for a source file
+	// and function is reused for later panics in the same place. Defaults to
+	// 300; negative disables caching.
+	MCPCacheTTL int `json:"mcp_cache_ttl,omitempty"`
+
+	// Git Provider Configuration
+	GitProvider       string `json:"git_provider,omitempty"` // "github" or "gitlab"
+	GitHubToken       string `json:"github_token"`
+	GitLabToken       string `json:"gitlab_token,omitempty"`
+	GitLabBaseURL     string `json:"gitlab_base_url,omitempty"`     // self-managed instances, defaults to gitlab.com
+	RepoOwner         string `json:"repo_owner"`                    // GitHub owner or GitLab group/namespace; detected from a github.com main module
+	RepoName          string `json:"repo_name"`                     // GitHub repository or GitLab project; detected like RepoOwner
+	GitTimeoutSeconds int    `json:"git_timeout_seconds,omitempty"` // defaults to 60 seconds
+
+	// RepoRouting picks the repository fixes are opened in by the source file
+	// of the panic, for binaries built from several repositories. The longest
+	// matching PathPrefix wins; other panics go to RepoOwner/RepoName.
+	RepoRouting []RepoRoute `json:"repo_routing,omitempty"`
+
+	// CommitSHA is the commit the running binary was built from. Fix branches
+	// start from it, and source missing on disk is read from the repository
+	// at it, so fixes apply to the deployed code. Defaults to the revision
+	// stamped by go build; the default branch is used when it is unknown or
+	// not in the repository.
+	CommitSHA string `json:"commit_sha,omitempty"`
+
+	// SourceRoot is the directory the binary was built in, which holds the
+	// root of the repository in stack trace paths. It is stripped from the
+	// source file of panics to name the file in the repository, e.g.
+	// /src/app/pkg/user.go is pkg/user.go under "/src/app". Defaults to the
+	// working directory.
+	SourceRoot string `json:"source_root,omitempty"`
+
+	// Pull Request Configuration
+	PRLabels      []string `json:"pr_labels,omitempty"`       // labels added to every fix PR
+	PRAssignees   []string `json:"pr_assignees,omitempty"`    // user logins assigned to every fix PR
+	PRReviewers   []string `json:"pr_reviewers,omitempty"`    // user logins, or "org/team-slug" on GitHub, asked to review every fix PR
+	CreateDraftPR *bool    `json:"create_draft_pr,omitempty"` // open fix PRs as drafts so they need a human before merging; unset means true
+
+	// CommitMessageTemplate is a text/template for the messages of fix
+	// commits, rendered with CommitMessageData, e.g.
+	// "fix: {{.Error}} in {{.Function}}". Defaults to DefaultCommitMessageTemplate.
+	CommitMessageTemplate string `json:"commit_message_template,omitempty"`
+
+	// PRTitleTemplate and PRBodyTemplate are text/templates for the title and
+	// description of fix PRs, rendered with the healer's PRTemplateData. The
+	// generated title and description are used when unset.
+	PRTitleTemplate string `json:"pr_title_template,omitempty"`
+	PRBodyTemplate  string `json:"pr_body_template,omitempty"`
+
+	// Redaction Configuration
+	RedactPatterns []string `json:"redact_patterns,omitempty"` // extra regular expressions scrubbed before data is sent to AI providers
+
+	// Ignore Configuration. Panics matching either list are logged at debug
+	// level and counted, but never queued for healing.
+	IgnorePatterns []string `json:"ignore_patterns,omitempty"` // regular expressions matched against the error message
+	IgnorePackages []string `json:"ignore_packages,omitempty"` // import paths matched against the top user stack frame's package, including subpackages
+
+	// Heal-only Configuration. When set, only panics whose top user frame is
+	// in one of these packages or paths are healed. Paths are slash-separated
+	// globs matched against the source file and its directories, see PathMatches.
+	HealOnlyPackages []string `json:"heal_only_packages,omitempty"`
+	HealOnlyPaths    []string `json:"heal_only_paths,omitempty"`
+	HealExcludePaths []string `json:"heal_exclude_paths"` // path globs never healed; defaults to DefaultHealExcludePaths, an empty list excludes nothing
+
+	// Outbound HTTP Configuration, applied to every AI provider, MCP server,
+	// Git provider and Slack call
+	HTTPProxy          string `json:"http_proxy,omitempty"`           // http://, https:// or socks5:// proxy URL; defaults to the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+	CACertFile         string `json:"ca_cert_file,omitempty"`         // PEM bundle trusted in addition to the system roots
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"` // skips TLS certificate verification; only for testing
+
+	// Notification Configuration
+	SlackWebhookURL   string `json:"slack_webhook_url,omitempty"`   // posts a message when a fix PR is opened
+	DiscordWebhookURL string `json:"discord_webhook_url,omitempty"` // posts an embed when a fix PR is opened
+
+	// WebhookURL receives a JSON POST when a panic is captured, a fix is
+	// generated and a PR is opened. With WebhookSecret set, the body is signed
+	// with HMAC-SHA256 in the X-Healer-Signature header as "sha256=<hex>".
+	WebhookURL     string            `json:"webhook_url,omitempty"`
+	WebhookSecret  string            `json:"webhook_secret,omitempty"`
+	WebhookHeaders map[string]string `json:"webhook_headers,omitempty"` // extra request headers, e.g. for authentication
 
 	// Processing Configuration
 	Enabled       bool   `json:"enabled"`
-	MaxQueueSize  int    `json:"max_queue_size,omitempty"`
+	MaxQueueSize  int    `json:"max_queue_size,omitempty"` // total across the severity bands
 	WorkerCount   int    `json:"worker_count,omitempty"`
 	RetryAttempts int    `json:"retry_attempts,omitempty"`
 	LogLevel      string `json:"log_level,omitempty"`
+	LogFormat     string `json:"log_format,omitempty"`   // "text" (default) or "json"
+	DryRun        bool   `json:"dry_run,omitempty"`      // generate and log fixes without creating branches or PRs
+	DedupWindow   int    `json:"dedup_window,omitempty"` // seconds, defaults to 300; negative disables deduplication
+
+	// DefaultTags are attached to every captured panic, e.g. the service,
+	// environment, version or region, so PRs and notifications identify where
+	// the panic came from. Tags passed to CaptureWithTags take precedence.
+	DefaultTags map[string]string `json:"default_tags,omitempty"`
+
+	// SampleRate is the fraction of repeated panics that are processed, from 0
+	// (exclusive) to 1. The first panic with a fingerprint is always processed;
+	// later ones are kept at random with this probability. Defaults to 1.
+	SampleRate float64 `json:"sample_rate,omitempty"`
+
+	// OverflowStrategy decides what happens when the queue is full: "drop_oldest"
+	// (default), "drop_newest" or "block". Blocking waits up to
+	// OverflowBlockTimeoutMs for space before dropping the new event, which can
+	// momentarily slow the goroutine that captured the panic.
+	OverflowStrategy       string `json:"overflow_strategy,omitempty"`
+	OverflowBlockTimeoutMs int    `json:"overflow_block_timeout_ms,omitempty"` // defaults to 100
+
+	// MaxPRsPerHour caps how many pull requests are opened per hour. Fixes over
+	// the limit are dead-lettered instead of opened. 0 means unlimited.
+	MaxPRsPerHour int `json:"max_prs_per_hour,omitempty"`
+
+	// BatchByFile holds fixes that target the same source file for
+	// BatchWindowSeconds and opens a single pull request for them. Fixes that
+	// edit overlapping lines are split back into separate pull requests.
+	BatchByFile        bool `json:"batch_by_file,omitempty"`
+	BatchWindowSeconds int  `json:"batch_window_seconds,omitempty"` // defaults to 10
+
+	// QueuePersistencePath is a JSON-lines file where queued events are journaled
+	// so they survive restarts. Empty keeps the queue in memory only.
+	QueuePersistencePath string `json:"queue_persistence_path,omitempty"`
+
+	// DeadLetterPath is a JSON-lines file that every event failing processing
+	// after all retries is appended to. The most recent failures are always
+	// available from Healer.GetFailedEvents.
+	DeadLetterPath string `json:"dead_letter_path,omitempty"`
+
+	// StorePath is a JSON-lines file recording every captured panic and its
+	// processing result, as an audit trail that can be queried with
+	// Healer.Store. Empty keeps the most recent 1000 events in memory.
+	StorePath string `json:"store_path,omitempty"`
+
+	// ShutdownDrainTimeout is how long, in seconds, Stop lets workers keep
+	// processing already-queued events before shutting them down. Events still
+	// queued afterwards stay in the persisted queue if there is one and are
+	// dead-lettered otherwise. Defaults to 30; negative skips draining.
+	ShutdownDrainTimeout int `json:"shutdown_drain_timeout,omitempty"`
+
+	// Logger, when set, is used instead of the built-in logger by the healer,
+	// worker pool, AI clients and Git client. LogLevel and LogFormat are then
+	// ignored at startup; filtering is left to the logger itself.
+	Logger LoggerInterface `json:"-"`
+
+	// Tracer, when set, records a span for each captured panic and for each
+	// processing phase. Use otelhealer.NewTracer to report to OpenTelemetry.
+	Tracer Tracer `json:"-"`
+
+	// HTTPTransport, when set, is used for every outbound call instead of the
+	// transport built from HTTPProxy, CACertFile and InsecureSkipVerify
+	HTTPTransport http.RoundTripper `json:"-"`
+
+	// HTTPPanicResponse writes the response when WrapHTTPHandler recovers a panic.
+	// Nil writes a 500 with a JSON error body. It is not called if the handler
+	// already wrote headers before panicking.
+	HTTPPanicResponse func(w http.ResponseWriter, r *http.Request, panicValue any) `json:"-"`
+
+	// Store, when set, must be a healer.Store. It records captured panics and
+	// their processing results instead of the store selected by StorePath.
+	Store any `json:"-"`
+
+	// AIClient, when set, must be an ai.Client. It generates every fix instead
+	// of the providers selected by AIProvider, whose credentials are then not
+	// required. Tests can use healertest.MockAIClient.
+	AIClient any `json:"-"`
+
+	// GitClient, when set, must be a healer.GitClient. It opens every pull
+	// request, and every issue if it can, instead of the GitHub or GitLab
+	// client, so Git tokens, RepoOwner, RepoName and RepoRouting are not
+	// used. Tests can use healertest.MockGitClient.
+	GitClient any `json:"-"`
+
+	// SourceContextLines is the number of lines read on each side of the panic line
+	SourceContextLines int `json:"source_context_lines,omitempty"`
+
+	// SourceResolver, when set, must be a healer.SourceResolver. It returns
+	// the source sent to the AI instead of reading it from the local
+	// filesystem, for binaries whose build paths do not exist where they run.
+	SourceResolver any `json:"-"`
+
+	// MaxStackFrames and MaxStackTraceBytes bound the stack trace captured for a
+	// panic, which is sent to the AI provider. Longer traces keep the frame that
+	// raised the panic and the top user frames. They default to 32 frames and 8 KiB.
+	MaxStackFrames     int `json:"max_stack_frames,omitempty"`
+	MaxStackTraceBytes int `json:"max_stack_trace_bytes,omitempty"`
+
+	// MinConfidenceForPR is the confidence a valid fix needs before a PR is opened.
+	// Nil defaults to 0.7; 0 opens a PR for every valid fix and 1 effectively
+	// disables PRs while still generating fixes for logging.
+	MinConfidenceForPR *float64 `json:"min_confidence_for_pr,omitempty"`
+
+	// MinConfidenceForIssue is the confidence a fix needs for an issue to be
+	// opened when it falls short of MinConfidenceForPR, so the panic is still
+	// tracked. Nil never opens issues. Only GitHub supports issues.
+	MinConfidenceForIssue *float64 `json:"min_confidence_for_issue,omitempty"`
+
+	// CompileCheck builds the package with each fix applied before a PR is
+	// opened and rejects fixes that do not compile. It needs the Go toolchain
+	// and the module source on the host; the check is skipped without them.
+	CompileCheck bool `json:"compile_check,omitempty"`
+
+	// FixCacheSize is the number of AI fixes kept in memory for recurring panics.
+	// Nil defaults to 100; 0 disables the cache.
+	FixCacheSize *int `json:"fix_cache_size,omitempty"`
+}
+
+// GetAITimeout returns the request timeout for an AI provider, applying any
+// per-provider override. Zero means the client should use its own default.
+func (c *Config) GetAITimeout(provider string) time.Duration {
+	if timeout, ok := c.AIProviderTimeouts[provider]; ok && timeout > 0 {
+		return time.Duration(timeout) * time.Second
+	}
+	return time.Duration(c.AITimeoutSeconds) * time.Second
+}
+
+// GetAIGenerationParams returns the sampling parameters for provider: its
+// overrides in AIProviderParams on top of the global settings
+func (c *Config) GetAIGenerationParams(provider string) AIGenerationParams {
+	params := AIGenerationParams{
+		Temperature: c.AITemperature,
+		MaxTokens:   c.AIMaxTokens,
+		TopP:        c.AITopP,
+	}
+	override := c.AIProviderParams[provider]
+	if override.Temperature != nil {
+		params.Temperature = override.Temperature
+	}
+	if override.MaxTokens > 0 {
+		params.MaxTokens = override.MaxTokens
+	}
+	if override.TopP != nil {
+		params.TopP = override.TopP
+	}
+	return params
+}
+
+// GetAIPhaseTimeout returns how long fix generation may take for one event,
+// which is the longest timeout of any provider
+func (c *Config) GetAIPhaseTimeout() time.Duration {
+	timeout := c.GetAITimeout("")
+	for provider := range c.AIProviderTimeouts {
+		timeout = max(timeout, c.GetAITimeout(provider))
+	}
+	if timeout <= 0 {
+		return 60 * time.Second
+	}
+	return timeout
+}
+
+// GetGitTimeout returns the timeout for creating a pull request
+func (c *Config) GetGitTimeout() time.Duration {
+	if c.GitTimeoutSeconds <= 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(c.GitTimeoutSeconds) * time.Second
+}
+
+// GetMCPCacheTTL returns how long gathered MCP context is reused, or 0 when caching is disabled
+func (c *Config) GetMCPCacheTTL() time.Duration {
+	if c.MCPCacheTTL <= 0 {
+		return 0
+	}
+	return time.Duration(c.MCPCacheTTL) * time.Second
+}
+
+// GetShutdownDrainTimeout returns how long Stop waits for queued events to be
+// processed, or 0 when draining is disabled
+func (c *Config) GetShutdownDrainTimeout() time.Duration {
+	if c.ShutdownDrainTimeout <= 0 {
+		return 0
+	}
+	return time.Duration(c.ShutdownDrainTimeout) * time.Second
+}
+
+// WantsDraftPR reports whether fix PRs are opened as drafts, which they are
+// unless CreateDraftPR is set to false
+func (c *Config) WantsDraftPR() bool {
+	return c.CreateDraftPR == nil || *c.CreateDraftPR
+}
+
+// GetBatchWindow returns how long fixes for the same file are held before a pull request is opened
+func (c *Config) GetBatchWindow() time.Duration {
+	return time.Duration(c.BatchWindowSeconds) * time.Second
+}
+
+// DefaultMinConfidenceForPR is the confidence threshold used when none is configured
+const DefaultMinConfidenceForPR = 0.7
+
+// GetMinConfidenceForPR returns the configured PR confidence threshold or the default
+func (c *Config) GetMinConfidenceForPR() float64 {
+	if c.MinConfidenceForPR == nil {
+		return DefaultMinConfidenceForPR
+	}
+	return *c.MinConfidenceForPR
+}
+
+// WantsIssue reports whether a fix with the given confidence should be
+// tracked as an issue instead of opened as a pull request
+func (c *Config) WantsIssue(confidence float64) bool {
+	return c.MinConfidenceForIssue != nil &&
+		confidence >= *c.MinConfidenceForIssue &&
+		confidence < c.GetMinConfidenceForPR()
+}
+
+// DefaultFixCacheSize is the fix cache capacity used when none is configured
+const DefaultFixCacheSize = 100
+
+// GetFixCacheSize returns the configured fix cache capacity or the default
+func (c *Config) GetFixCacheSize() int {
+	if c.FixCacheSize == nil {
+		return DefaultFixCacheSize
+	}
+	return *c.FixCacheSize
 }
 
+// Default stack trace limits, used when none are configured
+const (
+	DefaultMaxStackFrames     = 32
+	DefaultMaxStackTraceBytes = 8 * 1024
+)
+
+// GetStackLimits returns the configured stack trace frame and byte limits or the defaults
+func (c *Config) GetStackLimits() (frames, bytes int) {
+	frames, bytes = c.MaxStackFrames, c.MaxStackTraceBytes
+	if frames <= 0 {
+		frames = DefaultMaxStackFrames
+	}
+	if bytes <= 0 {
+		bytes = DefaultMaxStackTraceBytes
+	}
+	return frames, bytes
+}
+
+// MaxWorkerCount is the largest worker pool size the healer accepts
+const MaxWorkerCount = 50
+
 // DefaultConfig returns a Config with default values
 func DefaultConfig() Config {
 	return Config{
-		AIProvider:    "openai",
-		OpenAIModel:   "gpt-4",
-		ClaudeModel:   "claude-3-sonnet-20240229",
-		CodexModel:    "code-davinci-002",
-		MCPEnabled:    false,
-		MCPTimeout:    10,
-		Enabled:       true,
-		MaxQueueSize:  100,
-		WorkerCount:   2,
-		RetryAttempts: 3,
-		LogLevel:      "info",
+		AIProvider:        "openai",
+		OpenAIModel:       "gpt-4",
+		ClaudeModel:       "claude-3-sonnet-20240229",
+		CodexModel:        "code-davinci-002",
+		GeminiModel:       "gemini-1.5-pro",
+		OllamaBaseURL:     "http://localhost:11434/api/generate",
+		OllamaModel:       "codellama",
+		BedrockModelID:    "anthropic.claude-3-5-sonnet-20240620-v1:0",
+		AITimeoutSeconds:  60,
+		GitTimeoutSeconds: 60,
+		GitProvider:       "github",
+		GitLabBaseURL:     "https://gitlab.com/api/v4",
+		MCPEnabled:        false,
+		MCPTimeout:        10,
+		MCPCacheTTL:       300,
+		Enabled:           true,
+		MaxQueueSize:      100,
+		WorkerCount:       2,
+		RetryAttempts:     3,
+		LogLevel:          "info",
+		LogFormat:         "text",
+		DedupWindow:       300,
+		SampleRate:        1.0,
+
+		OverflowStrategy:       "drop_oldest",
+		OverflowBlockTimeoutMs: 100,
+
+		BatchWindowSeconds:   10,
+		ShutdownDrainTimeout: 30,
+
+		SourceContextLines: 15,
+		MaxStackFrames:     DefaultMaxStackFrames,
+		MaxStackTraceBytes: DefaultMaxStackTraceBytes,
+		MinConfidenceForPR: floatPtr(DefaultMinConfidenceForPR),
+		FixCacheSize:       intPtr(DefaultFixCacheSize),
+		HealExcludePaths:   slices.Clone(DefaultHealExcludePaths),
 	}
 }
 
+// floatPtr returns a pointer to v
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+// intPtr returns a pointer to v
+func intPtr(v int) *int {
+	return &v
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	var errs []error
@@ -79,15 +561,18 @@ func (c *Config) Validate() error {
 			errs = append(errs, err)
 		}
 
-		if c.GitHubToken == "" {
-			errs = append(errs, errors.New("GitHub token is required when healer is enabled"))
+		// Validate Git provider configuration (dry runs never write to Git)
+		if !c.DryRun {
+			if err := c.validateGitProvider(); err != nil {
+				errs = append(errs, err)
+			}
 		}
 
-		if c.RepoOwner == "" {
+		if c.RepoOwner == "" && c.GitClient == nil {
 			errs = append(errs, errors.New("repository owner is required when healer is enabled"))
 		}
 
-		if c.RepoName == "" {
+		if c.RepoName == "" && c.GitClient == nil {
 			errs = append(errs, errors.New("repository name is required when healer is enabled"))
 		}
 
@@ -112,12 +597,140 @@ func (c *Config) Validate() error {
 		errs = append(errs, errors.New("retry attempts cannot be negative"))
 	}
 
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		errs = append(errs, errors.New("sample rate must be greater than 0 and at most 1"))
+	}
+
+	validOverflowStrategies := []string{"drop_oldest", "drop_newest", "block"}
+	if c.OverflowStrategy != "" && !slices.Contains(validOverflowStrategies, c.OverflowStrategy) {
+		errs = append(errs, fmt.Errorf("invalid overflow strategy '%s', must be one of: %v", c.OverflowStrategy, validOverflowStrategies))
+	}
+
+	if c.OverflowStrategy == "block" && c.OverflowBlockTimeoutMs <= 0 {
+		errs = append(errs, errors.New("overflow block timeout must be greater than 0"))
+	}
+
+	if c.MaxPRsPerHour < 0 {
+		errs = append(errs, errors.New("max PRs per hour cannot be negative"))
+	}
+
+	if c.BatchByFile && c.BatchWindowSeconds <= 0 {
+		errs = append(errs, errors.New("batch window must be greater than 0"))
+	}
+
 	// Validate log level
 	validLogLevels := []string{"debug", "info", "warn", "error"}
 	if !slices.Contains(validLogLevels, c.LogLevel) {
 		errs = append(errs, fmt.Errorf("invalid log level '%s', must be one of: %v", c.LogLevel, validLogLevels))
 	}
 
+	validLogFormats := []string{"text", "json"}
+	if c.LogFormat != "" && !slices.Contains(validLogFormats, c.LogFormat) {
+		errs = append(errs, fmt.Errorf("invalid log format '%s', must be one of: %v", c.LogFormat, validLogFormats))
+	}
+
+	if c.SourceContextLines < 0 {
+		errs = append(errs, errors.New("source context lines cannot be negative"))
+	}
+
+	if c.MaxStackFrames < 0 {
+		errs = append(errs, errors.New("max stack frames cannot be negative"))
+	}
+
+	if c.MaxStackTraceBytes < 0 {
+		errs = append(errs, errors.New("max stack trace bytes cannot be negative"))
+	}
+
+	if c.SlackWebhookURL != "" && !strings.HasPrefix(c.SlackWebhookURL, "https://") {
+		errs = append(errs, errors.New("Slack webhook URL must use https"))
+	}
+
+	if c.DiscordWebhookURL != "" && !strings.HasPrefix(c.DiscordWebhookURL, "https://") {
+		errs = append(errs, errors.New("Discord webhook URL must use https"))
+	}
+
+	if c.WebhookURL != "" {
+		if u, err := url.Parse(c.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid webhook URL '%s': must be an http or https URL", c.WebhookURL))
+		}
+	}
+	for name := range c.WebhookHeaders {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			errs = append(errs, fmt.Errorf("invalid webhook header name '%s'", name))
+		}
+	}
+
+	if err := c.validateAzure(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := c.validateOpenAICompatible(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := NewRedactor(c.RedactPatterns); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := NewCaptureFilter(c); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := NewCommitMessageTemplate(c.CommitMessageTemplate); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Field references are checked by the healer, which owns the template data
+	if _, err := template.New("pr_title").Parse(c.PRTitleTemplate); err != nil {
+		errs = append(errs, fmt.Errorf("invalid PR title template: %w", err))
+	}
+	if _, err := template.New("pr_body").Parse(c.PRBodyTemplate); err != nil {
+		errs = append(errs, fmt.Errorf("invalid PR body template: %w", err))
+	}
+
+	if c.HTTPTransport == nil {
+		if _, err := NewHTTPTransport(c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Validate request timeouts
+	if c.AITimeoutSeconds <= 0 {
+		errs = append(errs, errors.New("AI timeout must be greater than 0"))
+	}
+
+	for provider, timeout := range c.AIProviderTimeouts {
+		if timeout <= 0 {
+			errs = append(errs, fmt.Errorf("AI timeout for provider %s must be greater than 0", provider))
+		}
+	}
+
+	if c.GitTimeoutSeconds <= 0 {
+		errs = append(errs, errors.New("Git timeout must be greater than 0"))
+	}
+
+	global := AIGenerationParams{Temperature: c.AITemperature, MaxTokens: c.AIMaxTokens, TopP: c.AITopP}
+	if err := global.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid AI sampling settings: %w", err))
+	}
+	for provider, params := range c.AIProviderParams {
+		if err := params.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid AI sampling settings for provider %s: %w", provider, err))
+		}
+	}
+
+	for category := range c.ErrorPrompts {
+		if !slices.Contains(ErrorCategories, category) {
+			errs = append(errs, fmt.Errorf("unknown error_prompts category '%s', must be one of: %v", category, ErrorCategories))
+		}
+	}
+
+	for name, price := range c.ModelPricing {
+		if price < 0 {
+			errs = append(errs, fmt.Errorf("model pricing for %s cannot be negative", name))
+		}
+	}
+
 	// Validate MCP timeout
 	if c.MCPTimeout < 0 {
 		errs = append(errs, errors.New("MCP timeout cannot be negative"))
@@ -130,9 +743,44 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// validateAzure checks that Azure OpenAI mode has both an endpoint and a deployment
+func (c *Config) validateAzure() error {
+	if c.AzureEndpoint == "" && c.AzureDeployment == "" {
+		return nil
+	}
+	if c.AzureEndpoint == "" || c.AzureDeployment == "" {
+		return errors.New("Azure OpenAI requires both azure_endpoint and azure_deployment")
+	}
+	if !strings.HasPrefix(c.AzureEndpoint, "https://") {
+		return errors.New("Azure OpenAI endpoint must use https")
+	}
+	return nil
+}
+
+// validateOpenAICompatible checks the OpenAI-compatible endpoint settings
+func (c *Config) validateOpenAICompatible() error {
+	if c.OpenAICompatibleBaseURL == "" {
+		if c.OpenAICompatibleAuthHeader != "" {
+			return errors.New("openai_compatible_auth_header requires openai_compatible_base_url")
+		}
+		return nil
+	}
+	if c.AzureEndpoint != "" {
+		return errors.New("openai_compatible_base_url cannot be combined with azure_endpoint")
+	}
+	u, err := url.Parse(c.OpenAICompatibleBaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid OpenAI-compatible base URL '%s': must be an http or https URL", c.OpenAICompatibleBaseURL)
+	}
+	if c.OpenAICompatibleAuthHeader != "" && strings.ContainsAny(c.OpenAICompatibleAuthHeader, " :\r\n") {
+		return fmt.Errorf("invalid OpenAI-compatible auth header name '%s'", c.OpenAICompatibleAuthHeader)
+	}
+	return nil
+}
+
 // validateAIProvider validates the AI provider configuration
 func (c *Config) validateAIProvider() error {
-	validProviders := []string{"openai", "claude", "codex"}
+	validProviders := []string{"openai", "claude", "codex", "gemini", "ollama", "bedrock"}
 	if c.AIProvider == "" {
 		c.AIProvider = "openai" // default to OpenAI
 	}
@@ -141,8 +789,31 @@ func (c *Config) validateAIProvider() error {
 		return fmt.Errorf("invalid AI provider '%s', must be one of: %v", c.AIProvider, validProviders)
 	}
 
-	// Check that the required API key is provided for the selected provider
-	switch c.AIProvider {
+	if err := c.checkProviderCredentials(c.AIProvider); err != nil {
+		return err
+	}
+
+	// Every explicitly listed fallback must be usable
+	for _, provider := range c.FallbackProviders {
+		if !slices.Contains(validProviders, provider) {
+			return fmt.Errorf("invalid fallback provider '%s', must be one of: %v", provider, validProviders)
+		}
+		if err := c.checkProviderCredentials(provider); err != nil {
+			return fmt.Errorf("fallback provider %s: %w", provider, err)
+		}
+	}
+
+	return nil
+}
+
+// checkProviderCredentials checks that the API key or endpoint the named
+// provider needs is configured
+func (c *Config) checkProviderCredentials(provider string) error {
+	if c.AIClient != nil {
+		return nil // the injected client replaces the provider
+	}
+
+	switch provider {
 	case "openai":
 		if c.OpenAIAPIKey == "" {
 			return errors.New("OpenAI API key is required when using OpenAI provider")
@@ -155,6 +826,50 @@ func (c *Config) validateAIProvider() error {
 		if c.CodexAPIKey == "" {
 			return errors.New("Codex API key is required when using Codex provider")
 		}
+	case "gemini":
+		if c.GeminiAPIKey == "" {
+			return errors.New("Gemini API key is required when using Gemini provider")
+		}
+	case "ollama":
+		// Ollama is self-hosted and does not require an API key
+		if c.OllamaBaseURL == "" {
+			return errors.New("Ollama base URL is required when using Ollama provider")
+		}
+	case "bedrock":
+		// AWS credentials come from the standard chain and are checked on first use
+		if c.BedrockRegion == "" {
+			return errors.New("Bedrock region is required when using Bedrock provider")
+		}
+	}
+
+	return nil
+}
+
+// validateGitProvider validates the Git provider configuration
+func (c *Config) validateGitProvider() error {
+	validProviders := []string{"github", "gitlab"}
+	if c.GitProvider == "" {
+		c.GitProvider = "github" // default to GitHub
+	}
+
+	if !slices.Contains(validProviders, c.GitProvider) {
+		return fmt.Errorf("invalid Git provider '%s', must be one of: %v", c.GitProvider, validProviders)
+	}
+
+	// Check that the token for the selected provider is provided, unless a
+	// client was injected
+	if c.GitClient != nil {
+		return nil
+	}
+	switch c.GitProvider {
+	case "github":
+		if c.GitHubToken == "" {
+			return errors.New("GitHub token is required when healer is enabled")
+		}
+	case "gitlab":
+		if c.GitLabToken == "" {
+			return errors.New("GitLab token is required when using GitLab provider")
+		}
 	}
 
 	return nil
@@ -170,8 +885,17 @@ func (c *Config) validateMCPConfig() error {
 		if server.Name == "" {
 			return fmt.Errorf("MCP server %d: name is required", i)
 		}
-		if server.Endpoint == "" {
-			return fmt.Errorf("MCP server %s: endpoint is required", server.Name)
+		switch server.Transport {
+		case "", "http":
+			if server.Endpoint == "" {
+				return fmt.Errorf("MCP server %s: endpoint is required", server.Name)
+			}
+		case "stdio":
+			if server.Command == "" {
+				return fmt.Errorf("MCP server %s: command is required for the stdio transport", server.Name)
+			}
+		default:
+			return fmt.Errorf("MCP server %s: invalid transport '%s'", server.Name, server.Transport)
 		}
 		if server.AuthType != "" && !slices.Contains([]string{"none", "bearer", "basic"}, server.AuthType) {
 			return fmt.Errorf("MCP server %s: invalid auth type '%s'", server.Name, server.AuthType)
@@ -202,10 +926,51 @@ func (c *Config) ApplyDefaults() {
 		c.CodexModel = "code-davinci-002"
 	}
 
+	if c.GeminiModel == "" {
+		c.GeminiModel = "gemini-1.5-pro"
+	}
+
+	if c.OllamaBaseURL == "" {
+		c.OllamaBaseURL = "http://localhost:11434/api/generate"
+	}
+
+	if c.OllamaModel == "" {
+		c.OllamaModel = "codellama"
+	}
+
+	if c.BedrockModelID == "" {
+		c.BedrockModelID = "anthropic.claude-3-5-sonnet-20240620-v1:0"
+	}
+
+	if c.GitProvider == "" {
+		c.GitProvider = "github"
+	}
+
+	if c.GitLabBaseURL == "" {
+		c.GitLabBaseURL = "https://gitlab.com/api/v4"
+	}
+
+	if c.AITimeoutSeconds == 0 {
+		c.AITimeoutSeconds = 60
+	}
+
+	if c.GitTimeoutSeconds == 0 {
+		c.GitTimeoutSeconds = 60
+	}
+
 	if c.MCPTimeout == 0 {
 		c.MCPTimeout = 10
 	}
 
+	if c.MCPCacheTTL == 0 {
+		c.MCPCacheTTL = 300
+	}
+
+	// An explicitly empty list turns the default exclusions off
+	if c.HealExcludePaths == nil {
+		c.HealExcludePaths = slices.Clone(DefaultHealExcludePaths)
+	}
+
 	if c.MaxQueueSize == 0 {
 		c.MaxQueueSize = 100
 	}
@@ -221,6 +986,63 @@ func (c *Config) ApplyDefaults() {
 	if c.LogLevel == "" {
 		c.LogLevel = "info"
 	}
+
+	if c.LogFormat == "" {
+		c.LogFormat = "text"
+	}
+
+	if c.DedupWindow == 0 {
+		c.DedupWindow = 300
+	}
+
+	if c.SampleRate == 0 {
+		c.SampleRate = 1.0
+	}
+
+	if c.OverflowStrategy == "" {
+		c.OverflowStrategy = "drop_oldest"
+	}
+
+	if c.OverflowBlockTimeoutMs == 0 {
+		c.OverflowBlockTimeoutMs = 100
+	}
+
+	if c.BatchWindowSeconds == 0 {
+		c.BatchWindowSeconds = 10
+	}
+
+	if c.ShutdownDrainTimeout == 0 {
+		c.ShutdownDrainTimeout = 30
+	}
+
+	if c.SourceContextLines == 0 {
+		c.SourceContextLines = 15
+	}
+
+	if c.MaxStackFrames == 0 {
+		c.MaxStackFrames = DefaultMaxStackFrames
+	}
+
+	if c.MaxStackTraceBytes == 0 {
+		c.MaxStackTraceBytes = DefaultMaxStackTraceBytes
+	}
+
+	if c.MinConfidenceForPR == nil {
+		c.MinConfidenceForPR = floatPtr(DefaultMinConfidenceForPR)
+	}
+
+	if c.CommitSHA == "" {
+		c.CommitSHA = BuildRevision()
+	}
+
+	// GitHub-hosted modules name their repository in the module path
+	if c.RepoOwner == "" && c.RepoName == "" && (c.GitProvider == "" || c.GitProvider == "github") {
+		c.RepoOwner, c.RepoName = BuildRepository()
+	}
+
+	if c.FixCacheSize == nil {
+		c.FixCacheSize = intPtr(DefaultFixCacheSize)
+	}
 }
 
 // LoadFromEnv loads configuration values from environment variables
@@ -235,6 +1057,21 @@ func (c *Config) LoadFromEnv() error {
 	if val := os.Getenv("HEALER_OPENAI_MODEL"); val != "" {
 		c.OpenAIModel = val
 	}
+	if val := os.Getenv("HEALER_AZURE_ENDPOINT"); val != "" {
+		c.AzureEndpoint = val
+	}
+	if val := os.Getenv("HEALER_AZURE_DEPLOYMENT"); val != "" {
+		c.AzureDeployment = val
+	}
+	if val := os.Getenv("HEALER_AZURE_API_VERSION"); val != "" {
+		c.AzureAPIVersion = val
+	}
+	if val := os.Getenv("HEALER_OPENAI_COMPATIBLE_BASE_URL"); val != "" {
+		c.OpenAICompatibleBaseURL = val
+	}
+	if val := os.Getenv("HEALER_OPENAI_COMPATIBLE_AUTH_HEADER"); val != "" {
+		c.OpenAICompatibleAuthHeader = val
+	}
 	if val := os.Getenv("HEALER_CLAUDE_API_KEY"); val != "" {
 		c.ClaudeAPIKey = val
 	}
@@ -247,23 +1084,104 @@ func (c *Config) LoadFromEnv() error {
 	if val := os.Getenv("HEALER_CODEX_MODEL"); val != "" {
 		c.CodexModel = val
 	}
+	if val := os.Getenv("HEALER_GEMINI_API_KEY"); val != "" {
+		c.GeminiAPIKey = val
+	}
+	if val := os.Getenv("HEALER_GEMINI_MODEL"); val != "" {
+		c.GeminiModel = val
+	}
+	if val := os.Getenv("HEALER_OLLAMA_BASE_URL"); val != "" {
+		c.OllamaBaseURL = val
+	}
+	if val := os.Getenv("HEALER_OLLAMA_MODEL"); val != "" {
+		c.OllamaModel = val
+	}
+	if val := os.Getenv("HEALER_BEDROCK_REGION"); val != "" {
+		c.BedrockRegion = val
+	} else if val := os.Getenv("AWS_REGION"); val != "" && c.BedrockRegion == "" {
+		c.BedrockRegion = val
+	}
+	if val := os.Getenv("HEALER_BEDROCK_MODEL_ID"); val != "" {
+		c.BedrockModelID = val
+	}
 
-	// Load GitHub configuration
+	// Load Git provider configuration
+	if val := os.Getenv("HEALER_GIT_PROVIDER"); val != "" {
+		c.GitProvider = val
+	}
 	if val := os.Getenv("HEALER_GITHUB_TOKEN"); val != "" {
 		c.GitHubToken = val
 	}
+	if val := os.Getenv("HEALER_GITLAB_TOKEN"); val != "" {
+		c.GitLabToken = val
+	}
+	if val := os.Getenv("HEALER_GITLAB_BASE_URL"); val != "" {
+		c.GitLabBaseURL = val
+	}
 	if val := os.Getenv("HEALER_REPO_OWNER"); val != "" {
 		c.RepoOwner = val
 	}
 	if val := os.Getenv("HEALER_REPO_NAME"); val != "" {
 		c.RepoName = val
 	}
+	if val := os.Getenv("HEALER_COMMIT_SHA"); val != "" {
+		c.CommitSHA = val
+	}
+	if val := os.Getenv("HEALER_SOURCE_ROOT"); val != "" {
+		c.SourceRoot = val
+	}
+	if val := os.Getenv("HEALER_REPO_ROUTING"); val != "" {
+		var routes []RepoRoute
+		for _, item := range splitList(val) {
+			// GitLab owners may include subgroups, so the project follows the last '/'
+			prefix, repo, _ := strings.Cut(item, "=")
+			slash := strings.LastIndex(repo, "/")
+			if slash < 0 {
+				return fmt.Errorf("invalid HEALER_REPO_ROUTING value '%s': must be comma-separated prefix=owner/repo pairs", val)
+			}
+			routes = append(routes, RepoRoute{
+				PathPrefix: strings.TrimSpace(prefix),
+				Owner:      strings.TrimSpace(repo[:slash]),
+				Repo:       strings.TrimSpace(repo[slash+1:]),
+			})
+		}
+		c.RepoRouting = routes
+	}
+
+	// Load notification configuration
+	if val := os.Getenv("HEALER_SLACK_WEBHOOK_URL"); val != "" {
+		c.SlackWebhookURL = val
+	}
+	if val := os.Getenv("HEALER_DISCORD_WEBHOOK_URL"); val != "" {
+		c.DiscordWebhookURL = val
+	}
+	if val := os.Getenv("HEALER_WEBHOOK_URL"); val != "" {
+		c.WebhookURL = val
+	}
+	if val := os.Getenv("HEALER_WEBHOOK_SECRET"); val != "" {
+		c.WebhookSecret = val
+	}
+	if val := os.Getenv("HEALER_WEBHOOK_HEADERS"); val != "" {
+		headers := make(map[string]string)
+		for _, item := range splitList(val) {
+			name, value, ok := strings.Cut(item, "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				return fmt.Errorf("invalid HEALER_WEBHOOK_HEADERS value '%s': must be comma-separated name=value pairs", val)
+			}
+			headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+		c.WebhookHeaders = headers
+	}
 
 	// Load general configuration
 	if val := os.Getenv("HEALER_LOG_LEVEL"); val != "" {
 		c.LogLevel = val
 	}
 
+	if val := os.Getenv("HEALER_LOG_FORMAT"); val != "" {
+		c.LogFormat = val
+	}
+
 	// Load boolean values
 	if val := os.Getenv("HEALER_ENABLED"); val != "" {
 		enabled, err := strconv.ParseBool(val)
@@ -273,6 +1191,46 @@ func (c *Config) LoadFromEnv() error {
 		c.Enabled = enabled
 	}
 
+	if val := os.Getenv("HEALER_DRY_RUN"); val != "" {
+		dryRun, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_DRY_RUN value '%s': must be true or false", val)
+		}
+		c.DryRun = dryRun
+	}
+
+	if val := os.Getenv("HEALER_CREATE_DRAFT_PR"); val != "" {
+		draft, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_CREATE_DRAFT_PR value '%s': must be true or false", val)
+		}
+		c.CreateDraftPR = &draft
+	}
+
+	if val := os.Getenv("HEALER_COMPILE_CHECK"); val != "" {
+		compileCheck, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_COMPILE_CHECK value '%s': must be true or false", val)
+		}
+		c.CompileCheck = compileCheck
+	}
+
+	if val := os.Getenv("HEALER_QUERY_ALL_PROVIDERS"); val != "" {
+		queryAll, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_QUERY_ALL_PROVIDERS value '%s': must be true or false", val)
+		}
+		c.QueryAllProviders = queryAll
+	}
+
+	if val := os.Getenv("HEALER_STREAM_RESPONSES"); val != "" {
+		stream, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_STREAM_RESPONSES value '%s': must be true or false", val)
+		}
+		c.StreamResponses = stream
+	}
+
 	if val := os.Getenv("HEALER_MCP_ENABLED"); val != "" {
 		mcpEnabled, err := strconv.ParseBool(val)
 		if err != nil {
@@ -306,6 +1264,167 @@ func (c *Config) LoadFromEnv() error {
 		c.RetryAttempts = attempts
 	}
 
+	if val := os.Getenv("HEALER_DEDUP_WINDOW"); val != "" {
+		window, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_DEDUP_WINDOW value '%s': must be a number", val)
+		}
+		c.DedupWindow = window
+	}
+
+	if val := os.Getenv("HEALER_SAMPLE_RATE"); val != "" {
+		rate, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_SAMPLE_RATE value '%s': must be a number", val)
+		}
+		c.SampleRate = rate
+	}
+
+	if val := os.Getenv("HEALER_OVERFLOW_STRATEGY"); val != "" {
+		c.OverflowStrategy = val
+	}
+
+	if val := os.Getenv("HEALER_OVERFLOW_BLOCK_TIMEOUT_MS"); val != "" {
+		timeout, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_OVERFLOW_BLOCK_TIMEOUT_MS value '%s': must be a number", val)
+		}
+		c.OverflowBlockTimeoutMs = timeout
+	}
+
+	if val := os.Getenv("HEALER_MAX_PRS_PER_HOUR"); val != "" {
+		limit, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_MAX_PRS_PER_HOUR value '%s': must be a number", val)
+		}
+		c.MaxPRsPerHour = limit
+	}
+
+	if val := os.Getenv("HEALER_BATCH_BY_FILE"); val != "" {
+		batch, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_BATCH_BY_FILE value '%s': must be true or false", val)
+		}
+		c.BatchByFile = batch
+	}
+
+	if val := os.Getenv("HEALER_BATCH_WINDOW_SECONDS"); val != "" {
+		window, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_BATCH_WINDOW_SECONDS value '%s': must be a number", val)
+		}
+		c.BatchWindowSeconds = window
+	}
+
+	if val := os.Getenv("HEALER_QUEUE_PERSISTENCE_PATH"); val != "" {
+		c.QueuePersistencePath = val
+	}
+
+	if val := os.Getenv("HEALER_DEAD_LETTER_PATH"); val != "" {
+		c.DeadLetterPath = val
+	}
+
+	if val := os.Getenv("HEALER_STORE_PATH"); val != "" {
+		c.StorePath = val
+	}
+
+	if val := os.Getenv("HEALER_SHUTDOWN_DRAIN_TIMEOUT"); val != "" {
+		timeout, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_SHUTDOWN_DRAIN_TIMEOUT value '%s': must be a number", val)
+		}
+		c.ShutdownDrainTimeout = timeout
+	}
+
+	if val := os.Getenv("HEALER_SOURCE_CONTEXT_LINES"); val != "" {
+		lines, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_SOURCE_CONTEXT_LINES value '%s': must be a number", val)
+		}
+		c.SourceContextLines = lines
+	}
+
+	if val := os.Getenv("HEALER_MAX_STACK_FRAMES"); val != "" {
+		frames, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_MAX_STACK_FRAMES value '%s': must be a number", val)
+		}
+		c.MaxStackFrames = frames
+	}
+
+	if val := os.Getenv("HEALER_MAX_STACK_TRACE_BYTES"); val != "" {
+		size, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_MAX_STACK_TRACE_BYTES value '%s': must be a number", val)
+		}
+		c.MaxStackTraceBytes = size
+	}
+
+	if val := os.Getenv("HEALER_FIX_CACHE_SIZE"); val != "" {
+		size, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_FIX_CACHE_SIZE value '%s': must be a number", val)
+		}
+		c.FixCacheSize = &size
+	}
+
+	// Load float values
+	if val := os.Getenv("HEALER_MIN_CONFIDENCE_FOR_PR"); val != "" {
+		confidence, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_MIN_CONFIDENCE_FOR_PR value '%s': must be a number", val)
+		}
+		c.MinConfidenceForPR = &confidence
+	}
+
+	if val := os.Getenv("HEALER_MIN_CONFIDENCE_FOR_ISSUE"); val != "" {
+		confidence, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_MIN_CONFIDENCE_FOR_ISSUE value '%s': must be a number", val)
+		}
+		c.MinConfidenceForIssue = &confidence
+	}
+
+	if val := os.Getenv("HEALER_AI_TEMPERATURE"); val != "" {
+		temperature, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_AI_TEMPERATURE value '%s': must be a number", val)
+		}
+		c.AITemperature = &temperature
+	}
+
+	if val := os.Getenv("HEALER_AI_MAX_TOKENS"); val != "" {
+		maxTokens, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_AI_MAX_TOKENS value '%s': must be a number", val)
+		}
+		c.AIMaxTokens = maxTokens
+	}
+
+	if val := os.Getenv("HEALER_AI_TOP_P"); val != "" {
+		topP, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_AI_TOP_P value '%s': must be a number", val)
+		}
+		c.AITopP = &topP
+	}
+
+	if val := os.Getenv("HEALER_AI_TIMEOUT_SECONDS"); val != "" {
+		timeout, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_AI_TIMEOUT_SECONDS value '%s': must be a number", val)
+		}
+		c.AITimeoutSeconds = timeout
+	}
+
+	if val := os.Getenv("HEALER_GIT_TIMEOUT_SECONDS"); val != "" {
+		timeout, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_GIT_TIMEOUT_SECONDS value '%s': must be a number", val)
+		}
+		c.GitTimeoutSeconds = timeout
+	}
+
 	if val := os.Getenv("HEALER_MCP_TIMEOUT"); val != "" {
 		timeout, err := strconv.Atoi(val)
 		if err != nil {
@@ -314,16 +1433,157 @@ func (c *Config) LoadFromEnv() error {
 		c.MCPTimeout = timeout
 	}
 
+	if val := os.Getenv("HEALER_MCP_CACHE_TTL"); val != "" {
+		ttl, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_MCP_CACHE_TTL value '%s': must be a number", val)
+		}
+		c.MCPCacheTTL = ttl
+	}
+
+	if val := os.Getenv("HEALER_FALLBACK_PROVIDERS"); val != "" {
+		c.FallbackProviders = splitList(val)
+	}
+
+	if val := os.Getenv("HEALER_HTTP_PROXY"); val != "" {
+		c.HTTPProxy = val
+	}
+
+	if val := os.Getenv("HEALER_CA_CERT_FILE"); val != "" {
+		c.CACertFile = val
+	}
+
+	if val := os.Getenv("HEALER_INSECURE_SKIP_VERIFY"); val != "" {
+		insecure, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid HEALER_INSECURE_SKIP_VERIFY value '%s': must be true or false", val)
+		}
+		c.InsecureSkipVerify = insecure
+	}
+
+	if val := os.Getenv("HEALER_IGNORE_PACKAGES"); val != "" {
+		c.IgnorePackages = splitList(val)
+	}
+
+	if val := os.Getenv("HEALER_HEAL_ONLY_PACKAGES"); val != "" {
+		c.HealOnlyPackages = splitList(val)
+	}
+
+	if val := os.Getenv("HEALER_HEAL_ONLY_PATHS"); val != "" {
+		c.HealOnlyPaths = splitList(val)
+	}
+
+	if val := os.Getenv("HEALER_PR_LABELS"); val != "" {
+		c.PRLabels = splitList(val)
+	}
+
+	if val := os.Getenv("HEALER_PR_ASSIGNEES"); val != "" {
+		c.PRAssignees = splitList(val)
+	}
+
+	if val := os.Getenv("HEALER_PR_REVIEWERS"); val != "" {
+		c.PRReviewers = splitList(val)
+	}
+
+	if val := os.Getenv("HEALER_COMMIT_MESSAGE_TEMPLATE"); val != "" {
+		c.CommitMessageTemplate = val
+	}
+
+	if val := os.Getenv("HEALER_PR_TITLE_TEMPLATE"); val != "" {
+		c.PRTitleTemplate = val
+	}
+
+	if val := os.Getenv("HEALER_PR_BODY_TEMPLATE"); val != "" {
+		c.PRBodyTemplate = val
+	}
+
+	if val := os.Getenv("HEALER_DEFAULT_TAGS"); val != "" {
+		tags := make(map[string]string)
+		for _, item := range splitList(val) {
+			key, value, ok := strings.Cut(item, "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				return fmt.Errorf("invalid HEALER_DEFAULT_TAGS value '%s': must be comma-separated key=value pairs", val)
+			}
+			tags[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		c.DefaultTags = tags
+	}
+
+	// Secrets mounted as files override the inline variables
+	return c.loadSecretFiles()
+}
+
+// loadSecretFiles reads secrets from the files named by HEALER_*_FILE
+// variables, which is how Docker and Kubernetes mount secrets. A file takes
+// precedence over the inline variable of the same secret. Trailing newlines
+// are trimmed.
+func (c *Config) loadSecretFiles() error {
+	secrets := []struct {
+		env   string
+		field *string
+	}{
+		{"HEALER_OPENAI_API_KEY", &c.OpenAIAPIKey},
+		{"HEALER_CLAUDE_API_KEY", &c.ClaudeAPIKey},
+		{"HEALER_CODEX_API_KEY", &c.CodexAPIKey},
+		{"HEALER_GEMINI_API_KEY", &c.GeminiAPIKey},
+		{"HEALER_GITHUB_TOKEN", &c.GitHubToken},
+		{"HEALER_GITLAB_TOKEN", &c.GitLabToken},
+		{"HEALER_SLACK_WEBHOOK_URL", &c.SlackWebhookURL},
+		{"HEALER_DISCORD_WEBHOOK_URL", &c.DiscordWebhookURL},
+		{"HEALER_WEBHOOK_SECRET", &c.WebhookSecret},
+	}
+
+	for _, secret := range secrets {
+		path := os.Getenv(secret.env + "_FILE")
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s_FILE: %w", secret.env, err)
+		}
+		value := strings.TrimRight(string(data), "\r\n")
+		if value == "" {
+			return fmt.Errorf("invalid %s_FILE: %s is empty", secret.env, path)
+		}
+		*secret.field = value
+	}
 	return nil
 }
 
+// isCommitSHA reports whether s looks like a full or abbreviated Git commit SHA
+func isCommitSHA(s string) bool {
+	if len(s) < 4 || len(s) > 64 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitList splits a comma-separated environment value, dropping empty entries
+func splitList(val string) []string {
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // ValidateAPIKeys validates API keys and repository settings
 func (c *Config) ValidateAPIKeys() error {
 	var errs []error
 
 	if c.Enabled {
-		// Validate OpenAI API key format (should start with sk-)
-		if c.OpenAIAPIKey != "" && !strings.HasPrefix(c.OpenAIAPIKey, "sk-") {
+		// Validate OpenAI API key format (should start with sk-); Azure and
+		// OpenAI-compatible endpoints issue keys in their own formats
+		if c.OpenAIAPIKey != "" && c.AzureEndpoint == "" && c.OpenAICompatibleBaseURL == "" &&
+			!strings.HasPrefix(c.OpenAIAPIKey, "sk-") {
 			errs = append(errs, errors.New("OpenAI API key should start with 'sk-'"))
 		}
 
@@ -332,8 +1592,12 @@ func (c *Config) ValidateAPIKeys() error {
 			errs = append(errs, errors.New("GitHub token appears to be too short"))
 		}
 
-		// Validate repository settings format
-		if c.RepoOwner != "" && (strings.Contains(c.RepoOwner, "/") || strings.Contains(c.RepoOwner, " ")) {
+		// Validate repository settings format (GitLab namespaces may include subgroups)
+		if c.GitProvider == "gitlab" {
+			if c.RepoOwner != "" && strings.Contains(c.RepoOwner, " ") {
+				errs = append(errs, errors.New("repository owner should not contain spaces"))
+			}
+		} else if c.RepoOwner != "" && (strings.Contains(c.RepoOwner, "/") || strings.Contains(c.RepoOwner, " ")) {
 			errs = append(errs, errors.New("repository owner should not contain '/' or spaces"))
 		}
 
@@ -349,7 +1613,7 @@ func (c *Config) ValidateAPIKeys() error {
 	return nil
 }
 
-// LoadConfig loads configuration from JSON file and environment variables
+// LoadConfig loads configuration from a JSON or TOML file and environment variables
 func LoadConfig(configPath string) (*Config, error) {
 	config := DefaultConfig()
 
@@ -376,7 +1640,8 @@ func LoadConfig(configPath string) (*Config, error) {
 	return &config, nil
 }
 
-// LoadFromFile loads configuration from a JSON file
+// LoadFromFile loads configuration from a JSON file, or from a TOML file with
+// the same keys when the file name ends in .toml
 func (c *Config) LoadFromFile(filePath string) error {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -386,6 +1651,22 @@ func (c *Config) LoadFromFile(filePath string) error {
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	// TOML files use the same keys as JSON files, so they are decoded by
+	// converting them to JSON
+	if strings.EqualFold(filepath.Ext(filePath), ".toml") {
+		var doc map[string]any
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse TOML config: %w", err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("failed to parse TOML config: %w", err)
+		}
+		if err := json.Unmarshal(data, c); err != nil {
+			return fmt.Errorf("failed to parse TOML config: %w", err)
+		}
+		return nil
+	}
+
 	if err := json.Unmarshal(data, c); err != nil {
 		return fmt.Errorf("failed to parse JSON config: %w", err)
 	}
@@ -410,36 +1691,64 @@ func (c *Config) ValidateComplete() error {
 	// Additional comprehensive validation
 	if c.Enabled {
 		// Check for required fields with specific error messages
-		if c.OpenAIAPIKey == "" {
+		if c.AIProvider == "openai" && c.OpenAIAPIKey == "" && c.AIClient == nil {
 			errs = append(errs, errors.New("OpenAI API key is required when healer is enabled. Set HEALER_OPENAI_API_KEY environment variable or provide in config file"))
 		}
 
-		if c.GitHubToken == "" {
+		if !c.DryRun && c.GitProvider == "github" && c.GitHubToken == "" && c.GitClient == nil {
 			errs = append(errs, errors.New("GitHub token is required when healer is enabled. Set HEALER_GITHUB_TOKEN environment variable or provide in config file"))
 		}
 
-		if c.RepoOwner == "" {
+		if !c.DryRun && c.GitProvider == "gitlab" && c.GitLabToken == "" && c.GitClient == nil {
+			errs = append(errs, errors.New("GitLab token is required when using GitLab provider. Set HEALER_GITLAB_TOKEN environment variable or provide in config file"))
+		}
+
+		if c.RepoOwner == "" && c.GitClient == nil {
 			errs = append(errs, errors.New("repository owner is required when healer is enabled. Set HEALER_REPO_OWNER environment variable or provide in config file"))
 		}
 
-		if c.RepoName == "" {
+		if c.RepoName == "" && c.GitClient == nil {
 			errs = append(errs, errors.New("repository name is required when healer is enabled. Set HEALER_REPO_NAME environment variable or provide in config file"))
 		}
 	}
 
+	for i, route := range c.RepoRouting {
+		if route.PathPrefix == "" || route.Owner == "" || route.Repo == "" {
+			errs = append(errs, fmt.Errorf("repo_routing entry %d needs a path_prefix, owner and repo", i+1))
+		}
+	}
+
+	if c.CommitSHA != "" && !isCommitSHA(c.CommitSHA) {
+		errs = append(errs, fmt.Errorf("commit SHA '%s' must be 4 to 64 hexadecimal characters", c.CommitSHA))
+	}
+
 	// Validate ranges with helpful messages
 	if c.MaxQueueSize > 10000 {
 		errs = append(errs, errors.New("max queue size should not exceed 10000 to prevent excessive memory usage"))
 	}
 
-	if c.WorkerCount > 50 {
-		errs = append(errs, errors.New("worker count should not exceed 50 to prevent resource exhaustion"))
+	if c.WorkerCount > MaxWorkerCount {
+		errs = append(errs, fmt.Errorf("worker count should not exceed %d to prevent resource exhaustion", MaxWorkerCount))
 	}
 
 	if c.RetryAttempts > 10 {
 		errs = append(errs, errors.New("retry attempts should not exceed 10 to prevent excessive delays"))
 	}
 
+	if threshold := c.GetMinConfidenceForPR(); threshold < 0 || threshold > 1 {
+		errs = append(errs, fmt.Errorf("minimum confidence for PR must be between 0 and 1, got %.2f", threshold))
+	}
+
+	if c.MinConfidenceForIssue != nil {
+		if floor := *c.MinConfidenceForIssue; floor < 0 || floor > c.GetMinConfidenceForPR() {
+			errs = append(errs, fmt.Errorf("minimum confidence for issue must be between 0 and the PR threshold, got %.2f", floor))
+		}
+	}
+
+	if c.GetFixCacheSize() < 0 {
+		errs = append(errs, errors.New("fix cache size cannot be negative"))
+	}
+
 	if len(errs) > 0 {
 		var errorMessages []string
 		for _, err := range errs {
@@ -462,7 +1771,7 @@ func GetFallbackConfig() *Config {
 	config.ApplyDefaults()
 
 	// Disable if required settings are missing
-	if config.OpenAIAPIKey == "" || config.GitHubToken == "" || config.RepoOwner == "" || config.RepoName == "" {
+	if config.validateAIProvider() != nil || config.validateGitProvider() != nil || config.RepoOwner == "" || config.RepoName == "" {
 		config.Enabled = false
 	}
 
@@ -476,13 +1785,31 @@ func (c *Config) LogConfigStatus() []string {
 	if c.Enabled {
 		status = append(status, "Healer is ENABLED")
 
+		if c.DryRun {
+			status = append(status, "Dry-run mode: fixes are logged, no branches or PRs are created")
+		}
+
 		if c.OpenAIAPIKey != "" {
 			status = append(status, "✓ OpenAI API key configured")
 		} else {
 			status = append(status, "✗ OpenAI API key missing")
 		}
 
-		if c.GitHubToken != "" {
+		if c.AzureEndpoint != "" {
+			status = append(status, fmt.Sprintf("OpenAI requests routed to Azure deployment %s", c.AzureDeployment))
+		}
+
+		if c.OpenAICompatibleBaseURL != "" {
+			status = append(status, fmt.Sprintf("OpenAI requests routed to OpenAI-compatible endpoint %s", c.OpenAICompatibleBaseURL))
+		}
+
+		if c.GitProvider == "gitlab" {
+			if c.GitLabToken != "" {
+				status = append(status, "✓ GitLab token configured")
+			} else {
+				status = append(status, "✗ GitLab token missing")
+			}
+		} else if c.GitHubToken != "" {
 			status = append(status, "✓ GitHub token configured")
 		} else {
 			status = append(status, "✗ GitHub token missing")
@@ -495,6 +1822,7 @@ func (c *Config) LogConfigStatus() []string {
 		}
 
 		status = append(status, fmt.Sprintf("Queue size: %d, Workers: %d, Retries: %d", c.MaxQueueSize, c.WorkerCount, c.RetryAttempts))
+		status = append(status, fmt.Sprintf("Minimum confidence for PR: %.2f", c.GetMinConfidenceForPR()))
 	} else {
 		status = append(status, "Healer is DISABLED - will only log panics")
 	}
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadFromFile_TOMLMatchesJSON(t *testing.T) {
+	const jsonConfig = `{
+  "enabled": true,
+  "openai_api_key": "sk-test",
+  "github_token": "ghp_0123456789abcdef",
+  "repo_owner": "acme",
+  "repo_name": "billing",
+  "worker_count": 4,
+  "sample_rate": 0.5,
+  "ai_temperature": 0.2,
+  "ignore_patterns": ["^shutdown: ", "context canceled"],
+  "ai_provider_timeouts": {"ollama": 180},
+  "default_tags": {"service": "billing", "env": "production"},
+  "ai_provider_params": {"ollama": {"max_tokens": 4000, "top_p": 0.8}},
+  "mcp_servers": [{"name": "docs", "endpoint": "https://mcp.example.com", "tools": ["search"]}]
+}`
+	const tomlConfig = `
+enabled = true
+openai_api_key = "sk-test"
+github_token = "ghp_0123456789abcdef"
+repo_owner = "acme"
+repo_name = "billing"
+worker_count = 4
+sample_rate = 0.5
+ai_temperature = 0.2
+ignore_patterns = ["^shutdown: ", "context canceled"]
+
+[ai_provider_timeouts]
+ollama = 180
+
+[default_tags]
+service = "billing"
+env = "production"
+
+[ai_provider_params.ollama]
+max_tokens = 4000
+top_p = 0.8
+
+[[mcp_servers]]
+name = "docs"
+endpoint = "https://mcp.example.com"
+tools = ["search"]
+`
+	dir := t.TempDir()
+	load := func(name, content string) Config {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		config := DefaultConfig()
+		if err := config.LoadFromFile(path); err != nil {
+			t.Fatalf("LoadFromFile(%s) failed: %v", name, err)
+		}
+		config.ApplyDefaults()
+		if err := config.ValidateComplete(); err != nil {
+			t.Fatalf("ValidateComplete(%s) failed: %v", name, err)
+		}
+		return config
+	}
+
+	fromJSON := load("healer.json", jsonConfig)
+	fromTOML := load("healer.toml", tomlConfig)
+	if !reflect.DeepEqual(fromJSON, fromTOML) {
+		t.Errorf("TOML config differs from JSON config:\nJSON: %+v\nTOML: %+v", fromJSON, fromTOML)
+	}
+	if fromTOML.WorkerCount != 4 || fromTOML.DefaultTags["env"] != "production" || len(fromTOML.MCPServers) != 1 {
+		t.Errorf("TOML values not applied: %+v", fromTOML)
+	}
+
+	path := filepath.Join(dir, "invalid.toml")
+	if err := os.WriteFile(path, []byte("enabled = "), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := DefaultConfig()
+	if err := config.LoadFromFile(path); err == nil {
+		t.Error("Expected an error for invalid TOML")
+	}
+}
+
+func TestLoadFromEnv_ReadsSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "github-token")
+	if err := os.WriteFile(path, []byte("ghp_fromfile0123456789\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HEALER_GITHUB_TOKEN", "ghp_inline")
+	t.Setenv("HEALER_GITHUB_TOKEN_FILE", path)
+	t.Setenv("HEALER_OPENAI_API_KEY", "sk-inline")
+
+	config := DefaultConfig()
+	if err := config.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if config.GitHubToken != "ghp_fromfile0123456789" {
+		t.Errorf("GitHubToken = %q, want the file contents without the newline", config.GitHubToken)
+	}
+	if config.OpenAIAPIKey != "sk-inline" {
+		t.Errorf("OpenAIAPIKey = %q, want the inline value", config.OpenAIAPIKey)
+	}
+
+	t.Setenv("HEALER_OPENAI_API_KEY_FILE", filepath.Join(dir, "missing"))
+	if err := config.LoadFromEnv(); err == nil {
+		t.Error("Expected an error for a missing secret file")
+	}
+}
@@ -0,0 +1,53 @@
+package internal
+
+// Error categories that select a specialized section of fix prompts
+const (
+	ErrorCategoryNilPointer    = "nil_pointer"
+	ErrorCategoryBounds        = "bounds"
+	ErrorCategoryNilMap        = "nil_map"
+	ErrorCategoryTypeAssertion = "type_assertion"
+	ErrorCategoryConcurrency   = "concurrency"
+)
+
+// ErrorCategories lists the error categories in the order they are matched
+var ErrorCategories = []string{
+	ErrorCategoryConcurrency,
+	ErrorCategoryTypeAssertion,
+	ErrorCategoryNilMap,
+	ErrorCategoryNilPointer,
+	ErrorCategoryBounds,
+}
+
+// DefaultErrorPrompts is the guidance added to fix prompts for each error
+// category unless Config.ErrorPrompts overrides it
+var DefaultErrorPrompts = map[string]string{
+	ErrorCategoryNilPointer: "This is a nil pointer dereference. Find where the nil value comes from and prefer fixing " +
+		"the constructor or caller that produced it, or returning an error, over silently skipping work. Only add a " +
+		"nil check right before the dereference when nil is a valid state, and keep the function's contract for callers.",
+	ErrorCategoryBounds: "This is an out-of-range index or slice expression. Check lengths before indexing, look for " +
+		"off-by-one errors in loop bounds and slice expressions, and handle empty slices. Return an error or zero value " +
+		"rather than clamping indexes in a way that hides the bug.",
+	ErrorCategoryNilMap: "This is a write to a nil map. Initialize the map with make where its owner is created, such " +
+		"as in the constructor or the zero-value path, rather than at every write site.",
+	ErrorCategoryTypeAssertion: "This is a failed type assertion. Use the two-value form v, ok := x.(T) or a type " +
+		"switch, and handle unexpected types explicitly, typically by returning an error naming the type received.",
+	ErrorCategoryConcurrency: "This is a concurrency error: a data race, concurrent map access, deadlock or channel " +
+		"misuse. Guard shared state with a sync.Mutex or sync.RWMutex held across the whole read-modify-write, and " +
+		"keep lock ordering consistent. A channel must be closed exactly once, by its sender, and never sent on " +
+		"afterwards; use select with a context or timeout instead of blocking forever. Lower your confidence when " +
+		"the fix depends on goroutines you cannot see.",
+}
+
+// GetErrorPrompts returns the guidance for each error category: the
+// overrides in ErrorPrompts on top of DefaultErrorPrompts. Categories
+// overridden with an empty string get no guidance.
+func (c *Config) GetErrorPrompts() map[string]string {
+	prompts := make(map[string]string, len(DefaultErrorPrompts))
+	for category, prompt := range DefaultErrorPrompts {
+		prompts[category] = prompt
+	}
+	for category, prompt := range c.ErrorPrompts {
+		prompts[category] = prompt
+	}
+	return prompts
+}
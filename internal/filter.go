@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultHealExcludePaths keeps test, generated protobuf and vendored files
+// out of healing unless the configuration says otherwise
+var DefaultHealExcludePaths = []string{"*_test.go", "*.pb.go", "vendor"}
+
+// CaptureFilter decides which captured panics are skipped instead of healed
+type CaptureFilter struct {
+	ignorePatterns   []*regexp.Regexp
+	ignorePackages   []string
+	healOnlyPackages []string
+	healOnlyPaths    []string
+	excludePaths     []string
+}
+
+// NewCaptureFilter returns a filter for the ignore, heal-only and exclude
+// settings of c
+func NewCaptureFilter(c *Config) (*CaptureFilter, error) {
+	filter := &CaptureFilter{
+		ignorePackages:   c.IgnorePackages,
+		healOnlyPackages: c.HealOnlyPackages,
+		healOnlyPaths:    c.HealOnlyPaths,
+		excludePaths:     c.HealExcludePaths,
+	}
+	for _, pattern := range c.IgnorePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern '%s': %w", pattern, err)
+		}
+		filter.ignorePatterns = append(filter.ignorePatterns, re)
+	}
+	for _, pattern := range slices.Concat(c.HealOnlyPaths, c.HealExcludePaths) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid path pattern '%s': %w", pattern, err)
+		}
+	}
+	return filter, nil
+}
+
+// SkipReason returns why a panic with errorMessage raised in function in
+// sourceFile should not be healed, or "" if it should be
+func (f *CaptureFilter) SkipReason(errorMessage, function, sourceFile string) string {
+	if f == nil {
+		return ""
+	}
+
+	for _, re := range f.ignorePatterns {
+		if re.MatchString(errorMessage) {
+			return fmt.Sprintf("error matches ignore pattern '%s'", re.String())
+		}
+	}
+
+	pkg := FunctionPackage(function)
+	for _, ignored := range f.ignorePackages {
+		if inPackage(pkg, ignored) {
+			return fmt.Sprintf("package %s is ignored", pkg)
+		}
+	}
+
+	for _, pattern := range f.excludePaths {
+		if PathMatches(sourceFile, pattern) {
+			return fmt.Sprintf("source file %s is excluded by '%s'", sourceFile, pattern)
+		}
+	}
+
+	if len(f.healOnlyPackages) > 0 && !slices.ContainsFunc(f.healOnlyPackages, func(allowed string) bool { return inPackage(pkg, allowed) }) {
+		return fmt.Sprintf("package %s is not in heal_only_packages", pkg)
+	}
+
+	if len(f.healOnlyPaths) > 0 && !slices.ContainsFunc(f.healOnlyPaths, func(pattern string) bool { return PathMatches(sourceFile, pattern) }) {
+		return fmt.Sprintf("source file %s is not in heal_only_paths", sourceFile)
+	}
+
+	return ""
+}
+
+// PathMatches reports whether pattern, a slash-separated glob, matches file
+// or one of the directories containing it, comparing the pattern against
+// every run of consecutive path elements. "vendor" matches any file below a
+// vendor directory, "*_test.go" any test file and "internal/api/*.go" the
+// Go files directly in any internal/api directory.
+func PathMatches(file, pattern string) bool {
+	if file == "" {
+		return false
+	}
+
+	elements := strings.Split(strings.Trim(filepath.ToSlash(file), "/"), "/")
+	depth := strings.Count(strings.Trim(pattern, "/"), "/") + 1
+	for start := 0; start+depth <= len(elements); start++ {
+		candidate := strings.Join(elements[start:start+depth], "/")
+		if ok, _ := path.Match(strings.Trim(pattern, "/"), candidate); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FunctionPackage returns the import path of the package a function, as
+// reported by runtime.Frame, belongs to, e.g. "github.com/org/app/api" for
+// "github.com/org/app/api.(*Server).Handle". The runtime escapes dots in the
+// last path element, as in "gopkg.in/yaml%2ev3.unmarshal", so the first
+// dot after the last slash ends the package path.
+func FunctionPackage(function string) string {
+	pkg := function
+	lastSlash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[lastSlash+1:], "."); dot >= 0 {
+		pkg = function[:lastSlash+1+dot]
+	}
+	return strings.ReplaceAll(pkg, "%2e", ".")
+}
+
+// inPackage reports whether pkg is parent or one of the packages below it
+func inPackage(pkg, parent string) bool {
+	return pkg != "" && (pkg == parent || strings.HasPrefix(pkg, parent+"/"))
+}
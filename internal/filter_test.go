@@ -0,0 +1,86 @@
+package internal
+
+import "testing"
+
+func TestCaptureFilter_SkipReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		function string
+		file     string
+		wantSkip bool
+	}{
+		{
+			name:     "default excludes test files",
+			config:   DefaultConfig(),
+			function: "github.com/org/app/api.TestHandler",
+			file:     "/src/app/api/handler_test.go",
+			wantSkip: true,
+		},
+		{
+			name:     "default excludes vendored code",
+			config:   DefaultConfig(),
+			function: "github.com/lib/pq.(*conn).Close",
+			file:     "/src/app/vendor/github.com/lib/pq/conn.go",
+			wantSkip: true,
+		},
+		{
+			name:     "default excludes generated protobuf code",
+			config:   DefaultConfig(),
+			function: "github.com/org/app/proto.(*Request).GetID",
+			file:     "/src/app/proto/request.pb.go",
+			wantSkip: true,
+		},
+		{
+			name:     "default heals hand-written code",
+			config:   DefaultConfig(),
+			function: "github.com/org/app/api.(*Server).Handle",
+			file:     "/src/app/api/server.go",
+		},
+		{
+			name:     "empty exclude list heals test files",
+			config:   Config{HealExcludePaths: []string{}},
+			function: "github.com/org/app/api.TestHandler",
+			file:     "/src/app/api/handler_test.go",
+		},
+		{
+			name:     "heal-only package includes subpackages",
+			config:   Config{HealOnlyPackages: []string{"github.com/org/app"}},
+			function: "github.com/org/app/api.(*Server).Handle",
+			file:     "/src/app/api/server.go",
+		},
+		{
+			name:     "heal-only package skips other packages",
+			config:   Config{HealOnlyPackages: []string{"github.com/org/app"}},
+			function: "github.com/org/application.Run",
+			file:     "/src/application/run.go",
+			wantSkip: true,
+		},
+		{
+			name:     "heal-only path matches a directory",
+			config:   Config{HealOnlyPaths: []string{"internal/api"}},
+			function: "github.com/org/app/internal/api.Handle",
+			file:     "/src/app/internal/api/handle.go",
+		},
+		{
+			name:     "heal-only path skips other directories",
+			config:   Config{HealOnlyPaths: []string{"internal/api/*.go"}},
+			function: "github.com/org/app/internal/db.Query",
+			file:     "/src/app/internal/db/query.go",
+			wantSkip: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewCaptureFilter(&tt.config)
+			if err != nil {
+				t.Fatalf("NewCaptureFilter failed: %v", err)
+			}
+			reason := filter.SkipReason("boom", tt.function, tt.file)
+			if (reason != "") != tt.wantSkip {
+				t.Errorf("SkipReason = %q, want skip %v", reason, tt.wantSkip)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// JSONLogger writes one JSON object per line with ts, level, msg and any
+// structured fields, for log pipelines that cannot parse text lines
+type JSONLogger struct {
+	level  *atomic.Int32 // LogLevel, shared with loggers derived by WithFields
+	mu     *sync.Mutex   // serializes writes across derived loggers
+	out    io.Writer
+	fields Fields
+}
+
+// NewJSONLogger creates a JSON logger writing to stdout
+func NewJSONLogger(levelStr string) LoggerInterface {
+	return NewJSONLoggerWithWriter(levelStr, os.Stdout)
+}
+
+// NewJSONLoggerWithWriter creates a JSON logger writing to out
+func NewJSONLoggerWithWriter(levelStr string, out io.Writer) LoggerInterface {
+	logger := &JSONLogger{
+		level: new(atomic.Int32),
+		mu:    new(sync.Mutex),
+		out:   out,
+	}
+	logger.SetLevel(ParseLogLevel(levelStr))
+	return logger
+}
+
+// Debug logs a debug message
+func (l *JSONLogger) Debug(msg string, args ...any) {
+	l.log(LogLevelDebug, msg, args...)
+}
+
+// Info logs an info message
+func (l *JSONLogger) Info(msg string, args ...any) {
+	l.log(LogLevelInfo, msg, args...)
+}
+
+// Warn logs a warning message
+func (l *JSONLogger) Warn(msg string, args ...any) {
+	l.log(LogLevelWarn, msg, args...)
+}
+
+// Error logs an error message
+func (l *JSONLogger) Error(msg string, args ...any) {
+	l.log(LogLevelError, msg, args...)
+}
+
+// SetLevel sets the logging level
+func (l *JSONLogger) SetLevel(level LogLevel) {
+	l.level.Store(int32(level))
+}
+
+// WithFields implements FieldLogger
+func (l *JSONLogger) WithFields(fields Fields) LoggerInterface {
+	return &JSONLogger{level: l.level, mu: l.mu, out: l.out, fields: mergeFields(l.fields, fields)}
+}
+
+// log writes a single JSON line. Fields never override ts, level or msg.
+func (l *JSONLogger) log(level LogLevel, msg string, args ...any) {
+	if LogLevel(l.level.Load()) > level {
+		return
+	}
+
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+
+	entry := make(map[string]any, len(l.fields)+3)
+	for key, value := range l.fields {
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		entry[key] = value
+	}
+	entry["ts"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = strings.ToLower(level.String())
+	entry["msg"] = msg
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		line, _ = json.Marshal(map[string]string{
+			"ts":    entry["ts"].(string),
+			"level": entry["level"].(string),
+			"msg":   msg,
+			"error": fmt.Sprintf("failed to encode log fields: %v", err),
+		})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(append(line, '\n'))
+}
@@ -3,8 +3,11 @@ package internal
 import (
 	"fmt"
 	"log"
+	"maps"
 	"os"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -43,23 +46,120 @@ type LoggerInterface interface {
 	SetLevel(level LogLevel)
 }
 
+// Fields are structured key/value pairs attached to every line a logger writes
+type Fields map[string]any
+
+// FieldLogger is implemented by loggers that support structured fields
+type FieldLogger interface {
+	WithFields(fields Fields) LoggerInterface
+}
+
+// WithFields returns a logger that attaches fields to every line. Loggers that
+// do not implement FieldLogger get the fields appended to the message as
+// key=value pairs. A nil logger stays nil.
+func WithFields(logger LoggerInterface, fields Fields) LoggerInterface {
+	if logger == nil {
+		return nil
+	}
+	if fl, ok := logger.(FieldLogger); ok {
+		return fl.WithFields(fields)
+	}
+	return &fieldSuffixLogger{LoggerInterface: logger, fields: fields}
+}
+
+// fieldSuffixLogger adds fields to loggers that only understand messages
+type fieldSuffixLogger struct {
+	LoggerInterface
+	fields Fields
+}
+
+func (l *fieldSuffixLogger) Debug(msg string, args ...any) {
+	l.LoggerInterface.Debug("%s", l.format(msg, args))
+}
+
+func (l *fieldSuffixLogger) Info(msg string, args ...any) {
+	l.LoggerInterface.Info("%s", l.format(msg, args))
+}
+
+func (l *fieldSuffixLogger) Warn(msg string, args ...any) {
+	l.LoggerInterface.Warn("%s", l.format(msg, args))
+}
+
+func (l *fieldSuffixLogger) Error(msg string, args ...any) {
+	l.LoggerInterface.Error("%s", l.format(msg, args))
+}
+
+// WithFields implements FieldLogger
+func (l *fieldSuffixLogger) WithFields(fields Fields) LoggerInterface {
+	return &fieldSuffixLogger{LoggerInterface: l.LoggerInterface, fields: mergeFields(l.fields, fields)}
+}
+
+func (l *fieldSuffixLogger) format(msg string, args []any) string {
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	return msg + formatFields(l.fields)
+}
+
+// mergeFields returns a new map holding base overridden by extra
+func mergeFields(base, extra Fields) Fields {
+	merged := make(Fields, len(base)+len(extra))
+	maps.Copy(merged, base)
+	maps.Copy(merged, extra)
+	return merged
+}
+
+// formatFields renders fields as " key=value" pairs in key order
+func formatFields(fields Fields) string {
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		fmt.Fprintf(&b, " %s=%v", key, fields[key])
+	}
+	return b.String()
+}
+
 // DefaultLogger is a basic implementation of the Logger interface
 type DefaultLogger struct {
-	level  LogLevel
+	level  *atomic.Int32 // LogLevel, shared with loggers derived by WithFields
 	logger *log.Logger
+	fields Fields
 }
 
 // NewDefaultLogger creates a new default logger with the specified level
 func NewDefaultLogger(levelStr string) LoggerInterface {
-	level := parseLogLevel(levelStr)
-	return &DefaultLogger{
-		level:  level,
+	logger := &DefaultLogger{
+		level:  new(atomic.Int32),
 		logger: log.New(os.Stdout, "[HEALER] ", log.LstdFlags),
 	}
+	logger.SetLevel(ParseLogLevel(levelStr))
+	return logger
+}
+
+// nopLogger discards every message
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...any) {}
+func (nopLogger) Info(msg string, args ...any)  {}
+func (nopLogger) Warn(msg string, args ...any)  {}
+func (nopLogger) Error(msg string, args ...any) {}
+func (nopLogger) SetLevel(level LogLevel)       {}
+
+// NewNopLogger creates a logger that discards every message
+func NewNopLogger() LoggerInterface {
+	return nopLogger{}
+}
+
+// NewLogger creates a logger writing in the given format, "text" (the
+// default) or "json"
+func NewLogger(levelStr, format string) LoggerInterface {
+	if format == "json" {
+		return NewJSONLogger(levelStr)
+	}
+	return NewDefaultLogger(levelStr)
 }
 
-// parseLogLevel converts a string to LogLevel
-func parseLogLevel(levelStr string) LogLevel {
+// ParseLogLevel converts a string to LogLevel
+func ParseLogLevel(levelStr string) LogLevel {
 	switch strings.ToUpper(levelStr) {
 	case "DEBUG":
 		return LogLevelDebug
@@ -76,35 +176,45 @@ func parseLogLevel(levelStr string) LogLevel {
 
 // Debug logs a debug message
 func (l *DefaultLogger) Debug(msg string, args ...any) {
-	if l.level <= LogLevelDebug {
+	if l.enabled(LogLevelDebug) {
 		l.log(LogLevelDebug, msg, args...)
 	}
 }
 
 // Info logs an info message
 func (l *DefaultLogger) Info(msg string, args ...any) {
-	if l.level <= LogLevelInfo {
+	if l.enabled(LogLevelInfo) {
 		l.log(LogLevelInfo, msg, args...)
 	}
 }
 
 // Warn logs a warning message
 func (l *DefaultLogger) Warn(msg string, args ...any) {
-	if l.level <= LogLevelWarn {
+	if l.enabled(LogLevelWarn) {
 		l.log(LogLevelWarn, msg, args...)
 	}
 }
 
 // Error logs an error message
 func (l *DefaultLogger) Error(msg string, args ...any) {
-	if l.level <= LogLevelError {
+	if l.enabled(LogLevelError) {
 		l.log(LogLevelError, msg, args...)
 	}
 }
 
 // SetLevel sets the logging level
 func (l *DefaultLogger) SetLevel(level LogLevel) {
-	l.level = level
+	l.level.Store(int32(level))
+}
+
+// WithFields implements FieldLogger
+func (l *DefaultLogger) WithFields(fields Fields) LoggerInterface {
+	return &DefaultLogger{level: l.level, logger: l.logger, fields: mergeFields(l.fields, fields)}
+}
+
+// enabled reports whether messages at level should be logged
+func (l *DefaultLogger) enabled(level LogLevel) bool {
+	return LogLevel(l.level.Load()) <= level
 }
 
 // log is the internal logging method
@@ -116,5 +226,5 @@ func (l *DefaultLogger) log(level LogLevel, msg string, args ...any) {
 		msg = fmt.Sprintf(msg, args...)
 	}
 
-	l.logger.Printf("%s [%s] %s", timestamp, levelStr, msg)
+	l.logger.Printf("%s [%s] %s%s", timestamp, levelStr, msg, formatFields(l.fields))
 }
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestJSONLogger_WritesStructuredLines(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewJSONLoggerWithWriter("info", &buf)
+
+	logger.Debug("filtered out")
+	WithFields(logger, Fields{"event_id": "abc123", "worker_id": 2}).Info("Processed %d events", 3)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 line, got %d: %q", len(lines), buf.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Line is not valid JSON: %v", err)
+	}
+	if entry["level"] != "info" || entry["msg"] != "Processed 3 events" || entry["event_id"] != "abc123" {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+	if _, ok := entry["ts"]; !ok {
+		t.Error("Expected a ts field")
+	}
+}
+
+func TestSlogLogger_FiltersLevels(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := NewSlogLogger(slog.New(handler))
+
+	logger.Debug("debug dropped by handler")
+	WithFields(logger, Fields{"event_id": "abc123"}).Info("info %s", "kept")
+
+	logger.SetLevel(LogLevelError)
+	logger.Warn("warn dropped by SetLevel")
+	logger.Error("error kept")
+
+	out := buf.String()
+	for _, dropped := range []string{"debug dropped", "warn dropped"} {
+		if strings.Contains(out, dropped) {
+			t.Errorf("Expected %q to be filtered, got %q", dropped, out)
+		}
+	}
+	for _, kept := range []string{"level=INFO msg=\"info kept\" event_id=abc123", "level=ERROR msg=\"error kept\""} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("Expected output to contain %q, got %q", kept, out)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// redactRule replaces every match of pattern with replacement
+type redactRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// builtinRedactRules scrub the secrets and PII most commonly found in panic messages.
+// Credential assignments run before the generic patterns so the key name is kept.
+var builtinRedactRules = []redactRule{
+	{regexp.MustCompile(`(?i)\b(password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key)(\s*[=:]\s*)[^\s&;,"']+`), "${1}${2}[REDACTED]"},
+	{regexp.MustCompile(`://[^/\s:@]+:[^/\s@]+@`), "://[REDACTED]@"},
+	{regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`), "[REDACTED_API_KEY]"},
+	{regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,})`), "[REDACTED_GITHUB_TOKEN]"},
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), "[REDACTED_JWT]"},
+	{regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`), "[REDACTED_EMAIL]"},
+	{regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), "[REDACTED_IP]"},
+}
+
+// Redactor scrubs secrets and PII from text before it leaves the process
+type Redactor struct {
+	rules []redactRule
+}
+
+// DefaultRedactor returns a redactor using only the built-in patterns
+func DefaultRedactor() *Redactor {
+	return &Redactor{rules: builtinRedactRules}
+}
+
+// NewRedactor returns a redactor using the built-in patterns plus the given
+// regular expressions, whose matches are replaced with [REDACTED]
+func NewRedactor(patterns []string) (*Redactor, error) {
+	rules := make([]redactRule, 0, len(builtinRedactRules)+len(patterns))
+	rules = append(rules, builtinRedactRules...)
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern '%s': %w", pattern, err)
+		}
+		rules = append(rules, redactRule{pattern: re, replacement: "[REDACTED]"})
+	}
+
+	return &Redactor{rules: rules}, nil
+}
+
+// Redact returns s with every sensitive match replaced
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, rule := range r.rules {
+		s = rule.pattern.ReplaceAllString(s, rule.replacement)
+	}
+	return s
+}
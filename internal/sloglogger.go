@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync/atomic"
+)
+
+// SlogLogger forwards healer logging to a *slog.Logger. Messages are
+// formatted before being passed on, and structured fields become slog
+// attributes. Level filtering is done by the slog handler; SetLevel adds
+// a further minimum on top of it.
+type SlogLogger struct {
+	logger *slog.Logger
+	level  *atomic.Int32 // LogLevel, shared with loggers derived by WithFields
+}
+
+// NewSlogLogger returns a LoggerInterface that writes to logger
+func NewSlogLogger(logger *slog.Logger) LoggerInterface {
+	return &SlogLogger{
+		logger: logger,
+		level:  new(atomic.Int32), // LogLevelDebug, so the handler decides
+	}
+}
+
+// Debug logs a debug message
+func (l *SlogLogger) Debug(msg string, args ...any) {
+	l.log(LogLevelDebug, slog.LevelDebug, msg, args...)
+}
+
+// Info logs an info message
+func (l *SlogLogger) Info(msg string, args ...any) {
+	l.log(LogLevelInfo, slog.LevelInfo, msg, args...)
+}
+
+// Warn logs a warning message
+func (l *SlogLogger) Warn(msg string, args ...any) {
+	l.log(LogLevelWarn, slog.LevelWarn, msg, args...)
+}
+
+// Error logs an error message
+func (l *SlogLogger) Error(msg string, args ...any) {
+	l.log(LogLevelError, slog.LevelError, msg, args...)
+}
+
+// SetLevel sets the minimum level forwarded to slog
+func (l *SlogLogger) SetLevel(level LogLevel) {
+	l.level.Store(int32(level))
+}
+
+// WithFields implements FieldLogger
+func (l *SlogLogger) WithFields(fields Fields) LoggerInterface {
+	attrs := make([]any, 0, len(fields))
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+	return &SlogLogger{logger: l.logger.With(attrs...), level: l.level}
+}
+
+// log formats the message and hands it to slog if both levels allow it
+func (l *SlogLogger) log(level LogLevel, slogLevel slog.Level, msg string, args ...any) {
+	if LogLevel(l.level.Load()) > level {
+		return
+	}
+
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, slogLevel) {
+		return
+	}
+
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	l.logger.Log(ctx, slogLevel, msg)
+}
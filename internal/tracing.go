@@ -0,0 +1,43 @@
+package internal
+
+import "context"
+
+// Tracer starts spans around healer work. The otelhealer module adapts an
+// OpenTelemetry TracerProvider to this interface, which keeps the OpenTelemetry
+// dependency out of the core module.
+type Tracer interface {
+	// Start starts a span as a child of any span in ctx and returns a context
+	// carrying the new span
+	Start(ctx context.Context, name string) (context.Context, Span)
+
+	// TraceParent returns the W3C traceparent of the span in ctx, or "" if
+	// there is none. It is stored on queued events so processing spans can be
+	// correlated with the capture span.
+	TraceParent(ctx context.Context) string
+
+	// WithTraceParent returns ctx with the span described by traceParent as
+	// the remote parent of spans started from it
+	WithTraceParent(ctx context.Context, traceParent string) context.Context
+}
+
+// Span is a single traced operation
+type Span interface {
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+// StartSpan starts a span with tracer, or a no-op span when tracer is nil
+func StartSpan(ctx context.Context, tracer Tracer, name string) (context.Context, Span) {
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return tracer.Start(ctx, name)
+}
+
+// noopSpan discards everything recorded on it
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, any) {}
+func (noopSpan) RecordError(error)        {}
+func (noopSpan) End()                     {}
@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+)
+
+// Connection pool settings of the shared transport. Outbound calls go to a
+// handful of API hosts, so more idle connections are kept per host than the
+// standard library's default of two.
+const (
+	maxIdleConns        = 100
+	maxIdleConnsPerHost = 10
+	idleConnTimeout     = 90 * time.Second
+	keepAliveInterval   = 30 * time.Second
+)
+
+// HTTPClientFactory creates the HTTP clients of the AI providers, MCP servers,
+// Git providers and notifiers. Every client it creates shares one transport,
+// so they reuse pooled connections and all apply the same proxy and TLS
+// settings, while each keeps its own timeout.
+type HTTPClientFactory struct {
+	transport http.RoundTripper
+}
+
+// NewHTTPClientFactory returns a factory whose clients use c.HTTPTransport,
+// or a transport built from the proxy and TLS settings of c if none is set
+func NewHTTPClientFactory(c *Config) (*HTTPClientFactory, error) {
+	transport := c.HTTPTransport
+	if transport == nil {
+		built, err := NewHTTPTransport(c)
+		if err != nil {
+			return nil, err
+		}
+		transport = built
+	}
+	return &HTTPClientFactory{transport: transport}, nil
+}
+
+// Transport returns the transport shared by the factory's clients
+func (f *HTTPClientFactory) Transport() http.RoundTripper {
+	return f.transport
+}
+
+// Client returns a new client with timeout that sends its requests through
+// the shared transport. Clients are not shared between callers because the
+// AI clients adjust their timeout when streaming.
+func (f *HTTPClientFactory) Client(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: f.transport, Timeout: timeout}
+}
+
+// NewHTTPTransport returns the transport shared by every outbound call: a copy
+// of http.DefaultTransport with a larger connection pool and the proxy and
+// TLS settings of c applied.
+// Without HTTPProxy the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables are honored.
+func NewHTTPTransport(c *Config) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: keepAliveInterval}).DialContext
+	transport.MaxIdleConns = maxIdleConns
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.IdleConnTimeout = idleConnTimeout
+
+	if c.HTTPProxy != "" {
+		proxyURL, err := url.Parse(c.HTTPProxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid HTTP proxy '%s': must be an absolute URL", c.HTTPProxy)
+		}
+		if !slices.Contains([]string{"http", "https", "socks5", "socks5h"}, proxyURL.Scheme) {
+			return nil, fmt.Errorf("invalid HTTP proxy '%s': scheme must be http, https, socks5 or socks5h", c.HTTPProxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if c.CACertFile != "" || c.InsecureSkipVerify {
+		tlsConfig := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: c.InsecureSkipVerify,
+		}
+		if c.CACertFile != "" {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			pem, err := os.ReadFile(c.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("CA certificate file %s contains no PEM certificates", c.CACertFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return transport, nil
+}
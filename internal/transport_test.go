@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPTransport(t *testing.T) {
+	transport, err := NewHTTPTransport(&Config{HTTPProxy: "socks5://proxy.internal:1080"})
+	if err != nil {
+		t.Fatalf("NewHTTPTransport failed: %v", err)
+	}
+	request, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos", nil)
+	proxyURL, err := transport.Proxy(request)
+	if err != nil || proxyURL == nil || proxyURL.Host != "proxy.internal:1080" {
+		t.Errorf("expected requests to go through the configured proxy, got %v (%v)", proxyURL, err)
+	}
+
+	for _, proxy := range []string{"ftp://proxy.internal", "proxy.internal:8080"} {
+		if _, err := NewHTTPTransport(&Config{HTTPProxy: proxy}); err == nil {
+			t.Errorf("expected proxy %q to be rejected", proxy)
+		}
+	}
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(notPEM, []byte("not a certificate"), 0o600)
+	if _, err := NewHTTPTransport(&Config{CACertFile: notPEM}); err == nil {
+		t.Error("expected a CA file without certificates to be rejected")
+	}
+}
+
+func TestHTTPClientFactory_SharesTransport(t *testing.T) {
+	clients, err := NewHTTPClientFactory(&Config{})
+	if err != nil {
+		t.Fatalf("NewHTTPClientFactory failed: %v", err)
+	}
+	ai, git := clients.Client(time.Minute), clients.Client(30*time.Second)
+	if ai == git || ai.Transport != git.Transport {
+		t.Error("expected separate clients sharing one transport")
+	}
+	if ai.Timeout != time.Minute || git.Timeout != 30*time.Second {
+		t.Errorf("expected each client to keep its own timeout, got %v and %v", ai.Timeout, git.Timeout)
+	}
+}
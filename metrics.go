@@ -0,0 +1,191 @@
+package healer
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsNamespace prefixes every metric exported by the healer
+const metricsNamespace = "healer"
+
+// Collector exposes healer runtime statistics as Prometheus metrics.
+// Values are read from the healer on every scrape, so registering the
+// collector adds no overhead to the panic recovery path.
+type Collector struct {
+	healer *Healer
+
+	queueLength         *prometheus.Desc
+	queueCapacity       *prometheus.Desc
+	droppedEvents       *prometheus.Desc
+	deduplicatedEvents  *prometheus.Desc
+	prRateLimited       *prometheus.Desc
+	workers             *prometheus.Desc
+	circuitBreakerState *prometheus.Desc
+	providerBreakers    *prometheus.Desc
+	processedEvents     *prometheus.Desc
+	failedEvents        *prometheus.Desc
+	providerFixes       *prometheus.Desc
+	providerTokens      *prometheus.Desc
+}
+
+// NewCollector creates a Prometheus collector for the given healer. The
+// healer's Config.DefaultTags are added as constant labels to every metric,
+// so metrics of several services can be told apart.
+func NewCollector(h *Healer) *Collector {
+	var labels prometheus.Labels
+	if h != nil {
+		labels = metricLabels(h.getConfig().DefaultTags)
+	}
+	return &Collector{
+		healer: h,
+		queueLength: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "queue_length"),
+			"Number of panic events waiting in the queue.",
+			nil, labels,
+		),
+		queueCapacity: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "queue_capacity"),
+			"Maximum number of panic events the queue can hold.",
+			nil, labels,
+		),
+		droppedEvents: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "dropped_events_total"),
+			"Total number of panic events dropped because the queue was full.",
+			nil, labels,
+		),
+		deduplicatedEvents: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "deduplicated_events_total"),
+			"Total number of panic events skipped as duplicates.",
+			nil, labels,
+		),
+		prRateLimited: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "pr_rate_limited_total"),
+			"Total number of fixes dead-lettered because the PR rate limit was reached.",
+			nil, labels,
+		),
+		workers: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "workers"),
+			"Number of active background workers.",
+			nil, labels,
+		),
+		circuitBreakerState: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "circuit_breaker_state"),
+			"Git client circuit breaker state (0 = closed, 1 = open, 2 = half-open).",
+			nil, labels,
+		),
+		providerBreakers: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "provider_circuit_breaker_state"),
+			"AI provider circuit breaker state (0 = closed, 1 = open, 2 = half-open).",
+			[]string{"provider"}, labels,
+		),
+		processedEvents: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "processed_events_total"),
+			"Total number of panic events processed by workers.",
+			nil, labels,
+		),
+		failedEvents: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "failed_events_total"),
+			"Total number of panic events that failed processing.",
+			nil, labels,
+		),
+		providerFixes: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "provider_fixes_total"),
+			"Total number of fix generation attempts per AI provider and result.",
+			[]string{"provider", "result"}, labels,
+		),
+		providerTokens: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "provider_tokens_total"),
+			"Total number of tokens consumed per AI provider and token type.",
+			[]string{"provider", "type"}, labels,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.queueLength
+	ch <- c.queueCapacity
+	ch <- c.droppedEvents
+	ch <- c.deduplicatedEvents
+	ch <- c.prRateLimited
+	ch <- c.workers
+	ch <- c.circuitBreakerState
+	ch <- c.providerBreakers
+	ch <- c.processedEvents
+	ch <- c.failedEvents
+	ch <- c.providerFixes
+	ch <- c.providerTokens
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	h := c.healer
+	if h == nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.queueLength, prometheus.GaugeValue, float64(h.queue.len()))
+	ch <- prometheus.MustNewConstMetric(c.queueCapacity, prometheus.GaugeValue, float64(h.queue.capacity()))
+
+	if h.queueManager != nil {
+		ch <- prometheus.MustNewConstMetric(c.droppedEvents, prometheus.CounterValue, float64(h.queueManager.GetDroppedCount()))
+		ch <- prometheus.MustNewConstMetric(c.deduplicatedEvents, prometheus.CounterValue, float64(h.queueManager.GetDedupCount()))
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.prRateLimited, prometheus.CounterValue, float64(h.prLimiter.limitedCount()))
+
+	if h.workerPool != nil {
+		ch <- prometheus.MustNewConstMetric(c.workers, prometheus.GaugeValue, float64(h.workerPool.GetWorkerCount()))
+		ch <- prometheus.MustNewConstMetric(c.processedEvents, prometheus.CounterValue, float64(h.workerPool.GetProcessedCount()))
+		ch <- prometheus.MustNewConstMetric(c.failedEvents, prometheus.CounterValue, float64(h.workerPool.GetFailedCount()))
+	}
+
+	if h.gitBreaker != nil {
+		ch <- prometheus.MustNewConstMetric(c.circuitBreakerState, prometheus.GaugeValue, float64(h.gitBreaker.GetState()))
+	}
+
+	if pm := h.getProviderManager(); pm != nil {
+		for provider, stats := range pm.GetProviderStats() {
+			ch <- prometheus.MustNewConstMetric(c.providerFixes, prometheus.CounterValue, float64(stats.Successes), provider, "success")
+			ch <- prometheus.MustNewConstMetric(c.providerFixes, prometheus.CounterValue, float64(stats.Failures), provider, "failure")
+			ch <- prometheus.MustNewConstMetric(c.providerTokens, prometheus.CounterValue, float64(stats.PromptTokens), provider, "prompt")
+			ch <- prometheus.MustNewConstMetric(c.providerTokens, prometheus.CounterValue, float64(stats.CompletionTokens), provider, "completion")
+		}
+		for provider, state := range pm.GetCircuitBreakerStates() {
+			ch <- prometheus.MustNewConstMetric(c.providerBreakers, prometheus.GaugeValue, float64(state), provider)
+		}
+	}
+}
+
+// MetricsCollector returns a Prometheus collector for this healer.
+// Register it with prometheus.MustRegister to expose healer metrics.
+func (h *Healer) MetricsCollector() *Collector {
+	return NewCollector(h)
+}
+
+// metricLabels turns tags into Prometheus labels, replacing characters that
+// are not allowed in label names with underscores
+func metricLabels(tags map[string]string) prometheus.Labels {
+	if len(tags) == 0 {
+		return nil
+	}
+	labels := make(prometheus.Labels, len(tags))
+	for key, value := range tags {
+		name := strings.Map(func(r rune) rune {
+			if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+				return r
+			}
+			return '_'
+		}, key)
+		if name == "" || name[0] >= '0' && name[0] <= '9' {
+			name = "_" + name
+		}
+		// Names starting with __ are reserved; provider, result and type are variable labels
+		if strings.HasPrefix(name, "__") || name == "provider" || name == "result" || name == "type" {
+			continue
+		}
+		labels[name] = value
+	}
+	return labels
+}
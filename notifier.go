@@ -0,0 +1,54 @@
+package healer
+
+import (
+	"context"
+	"time"
+)
+
+// Notifier delivers lifecycle events to an external service, such as Slack,
+// Discord or a webhook. Notifiers return nil for event types they do not
+// handle. Every pull request is delivered once as EventPRCreated with its
+// Fix and PR set, even when it fixes a batch of panics.
+type Notifier interface {
+	Notify(ctx context.Context, event HealerEvent) error
+}
+
+// notifierTimeout bounds a single notification, including retries
+const notifierTimeout = 30 * time.Second
+
+// AddNotifier adds a notifier that is sent every lifecycle event. The
+// notifiers for Config.SlackWebhookURL, Config.DiscordWebhookURL and
+// Config.WebhookURL are added by Initialize.
+func (h *Healer) AddNotifier(notifier Notifier) {
+	if notifier == nil {
+		return
+	}
+
+	h.hooksMu.Lock()
+	defer h.hooksMu.Unlock()
+	h.notifiers = append(h.notifiers, notifier)
+}
+
+// notify sends event to every notifier in its own goroutine. Failures and
+// panics are logged and swallowed so notifications never affect healing.
+func (h *Healer) notify(event HealerEvent) {
+	h.hooksMu.RLock()
+	notifiers := h.notifiers
+	h.hooksMu.RUnlock()
+
+	for _, notifier := range notifiers {
+		go func() {
+			defer func() {
+				if r := recover(); r != nil && h.logger != nil {
+					h.logger.Error("Notifier %T panicked: %v", notifier, r)
+				}
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), notifierTimeout)
+			defer cancel()
+			if err := notifier.Notify(ctx, event); err != nil && h.logger != nil {
+				h.logger.Warn("Failed to send %s notification for event %s with %T: %v", event.Type, event.Event.ID, notifier, err)
+			}
+		}()
+	}
+}
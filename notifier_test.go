@@ -0,0 +1,118 @@
+package healer
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier_SignsAndRetries(t *testing.T) {
+	var attempts atomic.Int32
+	received := make(chan WebhookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if got, want := r.Header.Get(WebhookSignatureHeader), SignWebhookPayload("s3cret", body); !hmac.Equal([]byte(got), []byte(want)) {
+			t.Errorf("Expected signature %s, got %s", want, got)
+		}
+		if r.Header.Get("Authorization") != "Bearer internal" {
+			t.Errorf("Expected the configured headers, got %v", r.Header)
+		}
+		var payload WebhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("Invalid payload: %v", err)
+		}
+		received <- payload
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, "s3cret", map[string]string{"Authorization": "Bearer internal"}, nil)
+	notifier.retryDelay = time.Millisecond
+	event := HealerEvent{Type: EventPRCreated, Event: PanicEvent{ID: "evt-1", Error: "boom"}, PR: &PRResult{Number: 7, URL: "https://example.com/pr/7"}}
+	if err := notifier.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	select {
+	case payload := <-received:
+		if payload.Event != EventPRCreated || payload.Panic.ID != "evt-1" || payload.PR == nil || payload.PR.Number != 7 {
+			t.Errorf("Unexpected payload %+v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the webhook to be delivered")
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("Expected the failed delivery to be retried once, got %d attempts", attempts.Load())
+	}
+
+	// Client errors are not retried
+	attempts.Store(-10)
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	if err := notifier.Send(context.Background(), WebhookPayload{Event: EventPanicCaptured}); err == nil {
+		t.Error("Expected a 400 response to fail")
+	}
+	if attempts.Load() != -9 {
+		t.Errorf("Expected a single attempt, got %d", attempts.Load()+10)
+	}
+}
+
+func TestDiscordNotifier_PostsEmbedForCreatedPRs(t *testing.T) {
+	received := make(chan discordMessage, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message discordMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("Invalid payload: %v", err)
+		}
+		received <- message
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewDiscordNotifier(server.URL, nil)
+	if err := notifier.Notify(context.Background(), HealerEvent{Type: EventPanicCaptured}); err != nil || len(received) > 0 {
+		t.Fatalf("Expected captured panics to be ignored, got %v", err)
+	}
+
+	err := notifier.Notify(context.Background(), HealerEvent{
+		Type:  EventPRCreated,
+		Time:  time.Now(),
+		Event: PanicEvent{Error: "nil map", SourceFile: "/app/cache.go", LineNumber: 34, Function: "main.updateCache"},
+		Fix:   &FixResponse{Confidence: 0.85, Provider: "claude"},
+		PR:    &PRResult{Number: 12, Title: "Fix nil map", URL: "https://example.com/pr/12"},
+	})
+	if err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	embed := (<-received).Embeds[0]
+	if embed.URL != "https://example.com/pr/12" || embed.Description != "nil map" {
+		t.Errorf("Unexpected embed %+v", embed)
+	}
+	want := map[string]string{
+		"Location":     "`/app/cache.go:34` in `main.updateCache`",
+		"Confidence":   "85%",
+		"Provider":     "claude",
+		"Pull Request": "[#12 Fix nil map](https://example.com/pr/12)",
+	}
+	for _, field := range embed.Fields {
+		if want[field.Name] != field.Value {
+			t.Errorf("Expected field %s to be %q, got %q", field.Name, want[field.Name], field.Value)
+		}
+		delete(want, field.Name)
+	}
+	if len(want) > 0 {
+		t.Errorf("Missing fields %v", want)
+	}
+}
@@ -0,0 +1,98 @@
+// Package otelhealer reports go-code-healer spans to OpenTelemetry.
+//
+// It lives in its own module so applications that do not use OpenTelemetry
+// never pull in the otel dependency:
+//
+//	config := healer.DefaultConfig()
+//	config.Tracer = otelhealer.NewTracer(otel.GetTracerProvider())
+package otelhealer
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	healer "github.com/ajeet-kumar1087/go-code-healer"
+)
+
+// instrumentationName identifies healer spans in the tracer provider
+const instrumentationName = "github.com/ajeet-kumar1087/go-code-healer"
+
+// traceParentHeader is the W3C trace context carrier key
+const traceParentHeader = "traceparent"
+
+// Tracer adapts an OpenTelemetry TracerProvider to healer.Tracer
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TraceContext
+}
+
+// NewTracer returns a healer.Tracer that starts spans with provider. A nil
+// provider disables tracing.
+func NewTracer(provider trace.TracerProvider) healer.Tracer {
+	if provider == nil {
+		return nil
+	}
+	return &Tracer{tracer: provider.Tracer(instrumentationName)}
+}
+
+// Start implements healer.Tracer
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, healer.Span) {
+	ctx, span := t.tracer.Start(ctx, name)
+	return ctx, &Span{span: span}
+}
+
+// TraceParent implements healer.Tracer
+func (t *Tracer) TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	t.propagator.Inject(ctx, carrier)
+	return carrier.Get(traceParentHeader)
+}
+
+// WithTraceParent implements healer.Tracer
+func (t *Tracer) WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	return t.propagator.Extract(ctx, propagation.MapCarrier{traceParentHeader: traceParent})
+}
+
+// Span adapts an OpenTelemetry span to healer.Span
+type Span struct {
+	span trace.Span
+}
+
+// SetAttribute implements healer.Span
+func (s *Span) SetAttribute(key string, value any) {
+	s.span.SetAttributes(toAttribute(key, value))
+}
+
+// RecordError implements healer.Span and marks the span as failed
+func (s *Span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+// End implements healer.Span
+func (s *Span) End() {
+	s.span.End()
+}
+
+// toAttribute converts a healer attribute value to its OpenTelemetry type
+func toAttribute(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
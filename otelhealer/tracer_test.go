@@ -0,0 +1,66 @@
+package otelhealer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	healer "github.com/ajeet-kumar1087/go-code-healer"
+)
+
+func TestTracerCorrelatesCaptureWithRequestSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	config := healer.DefaultConfig()
+	config.Enabled = false // queue events without starting workers
+	config.Tracer = NewTracer(provider)
+
+	h, err := healer.Initialize(config)
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	h.InstallPanicHandler()
+	defer h.RestorePanicHandler()
+
+	ctx, request := provider.Tracer("test").Start(context.Background(), "request")
+	healer.CapturePanicCtx(ctx, "boom")
+	request.End()
+
+	var event healer.PanicEvent
+	select {
+	case event = <-h.GetErrorQueue():
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+
+	var capture sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "healer.capture_panic" {
+			capture = span
+		}
+	}
+	if capture == nil {
+		t.Fatal("capture span not recorded")
+	}
+	if capture.Parent().SpanID() != request.SpanContext().SpanID() {
+		t.Errorf("capture span parent = %s, want request span %s", capture.Parent().SpanID(), request.SpanContext().SpanID())
+	}
+
+	// Processing spans continue the same trace from the persisted traceparent
+	tracer := config.Tracer
+	processCtx, span := tracer.Start(tracer.WithTraceParent(context.Background(), event.TraceParent), "process")
+	span.End()
+	if got := tracer.TraceParent(processCtx); got == "" || got[3:35] != request.SpanContext().TraceID().String() {
+		t.Errorf("processing traceparent %q is not in trace %s", got, request.SpanContext().TraceID())
+	}
+}
+
+func TestNewTracerNilProvider(t *testing.T) {
+	if NewTracer(nil) != nil {
+		t.Error("expected a nil provider to disable tracing")
+	}
+}
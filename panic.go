@@ -1,30 +1,83 @@
 package healer
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/ajeet-kumar1087/go-code-healer/ai"
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
 )
 
+// maxGoroutineDumpSize caps the all-goroutine dump captured for concurrency panics
+const maxGoroutineDumpSize = 32 * 1024
+
 // PanicEvent represents a captured panic with context
 type PanicEvent struct {
-	ID          string     `json:"id"`
-	Timestamp   time.Time  `json:"timestamp"`
-	Error       string     `json:"error"`
-	StackTrace  string     `json:"stack_trace"`
-	SourceFile  string     `json:"source_file"`
-	LineNumber  int        `json:"line_number"`
-	Function    string     `json:"function"`
-	ProcessedAt *time.Time `json:"processed_at,omitempty"`
-	Status      string     `json:"status"` // "queued", "processing", "completed", "failed"
-}
-
-// NewPanicEvent creates a new PanicEvent from a panic value
+	ID           string     `json:"id"`
+	Timestamp    time.Time  `json:"timestamp"`
+	Error        string     `json:"error"`
+	ErrorType    string     `json:"error_type,omitempty"`    // concrete Go type of the panic value, e.g. "runtime.boundsError"
+	ErrorMessage string     `json:"error_message,omitempty"` // Error() of the panic value when it implements error
+	StackTrace   string     `json:"stack_trace"`             // the user's call chain, without runtime and healer frames
+	SourceFile   string     `json:"source_file"`
+	LineNumber   int        `json:"line_number"`
+	Function     string     `json:"function"`
+	ProcessedAt  *time.Time `json:"processed_at,omitempty"`
+	Status       string     `json:"status"`                 // "queued", "processing", "completed", "failed"
+	Fingerprint  string     `json:"fingerprint,omitempty"`  // stable hash of normalized error + top user frame
+	LastError    string     `json:"last_error,omitempty"`   // why processing failed, set on dead-lettered events
+	Severity     string     `json:"severity,omitempty"`     // "critical", "high" or "medium", see ClassifySeverity
+	TraceParent  string     `json:"trace_parent,omitempty"` // W3C traceparent of the capture span when tracing is enabled
+
+	// RawStackTrace holds the stack trace as captured, including the runtime
+	// and healer frames dropped from StackTrace, for debugging
+	RawStackTrace string `json:"raw_stack_trace,omitempty"`
+
+	// AllGoroutines holds the stacks of every goroutine, captured only for
+	// concurrency-related panics where a single stack is not enough
+	AllGoroutines string `json:"all_goroutines,omitempty"`
+
+	// Metadata holds request-scoped details such as the HTTP method and path
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Tags holds user metadata such as the service, environment, version or
+	// region: Config.DefaultTags merged with tags passed to CaptureWithTags
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// original keeps the unredacted values in memory; it is never serialized
+	original *unredactedFields
+}
+
+// unredactedFields holds the values of a PanicEvent before secrets were scrubbed
+type unredactedFields struct {
+	Error         string
+	ErrorMessage  string
+	StackTrace    string
+	RawStackTrace string
+	AllGoroutines string
+	Metadata      map[string]string
+}
+
+// NewPanicEvent creates a new PanicEvent from a panic value, with the stack
+// trace bounded by the default limits
 func NewPanicEvent(panicValue any) *PanicEvent {
+	return newPanicEvent(panicValue, internal.DefaultMaxStackFrames, internal.DefaultMaxStackTraceBytes)
+}
+
+// newPanicEvent creates a PanicEvent whose stack trace keeps at most
+// maxFrames frames and maxBytes bytes
+func newPanicEvent(panicValue any, maxFrames, maxBytes int) *PanicEvent {
 	event := &PanicEvent{
 		ID:        generateID(),
 		Timestamp: time.Now(),
@@ -32,49 +85,244 @@ func NewPanicEvent(panicValue any) *PanicEvent {
 		Status:    "queued",
 	}
 
+	// Preserve type information for typed errors and non-string panic values
+	if t := reflect.TypeOf(panicValue); t != nil {
+		event.ErrorType = t.String()
+	}
+	if err, ok := panicValue.(error); ok {
+		event.ErrorMessage = err.Error()
+	}
+
 	// Extract stack trace and source location
-	event.extractStackTrace()
+	event.extractStackTrace(maxFrames, maxBytes)
+	event.Fingerprint = event.computeFingerprint()
+	event.Severity = ClassifySeverity(*event)
+
+	if event.isConcurrencyRelated() {
+		event.AllGoroutines = captureGoroutineDump()
+	}
 	return event
 }
 
-// extractStackTrace captures the current stack trace and extracts source location
-func (pe *PanicEvent) extractStackTrace() {
-	// Get stack trace with up to 32 frames, skipping the first 3 frames
-	// (runtime.Callers, extractStackTrace, NewPanicEvent)
-	pc := make([]uintptr, 32)
-	n := runtime.Callers(3, pc)
+// isConcurrencyRelated reports whether the panic is classified as complex,
+// which covers deadlocks, channel misuse and other cross-goroutine failures
+func (pe *PanicEvent) isConcurrencyRelated() bool {
+	complexity := ai.NewCodeValidator(nil).AssessErrorComplexity(ai.FixRequest{
+		Error:      pe.Error,
+		StackTrace: pe.rawStackTrace(),
+	})
+	return complexity == "complex"
+}
+
+// ClassifySeverity maps a panic to a severity for triage: fatal runtime errors
+// such as concurrent map writes and deadlocks are "critical", nil pointer,
+// bounds and channel errors are "high", and failed type assertions and
+// application panics are "medium"
+func ClassifySeverity(event PanicEvent) string {
+	return ai.NewCodeValidator(nil).AssessErrorSeverity(ai.FixRequest{
+		Error:      event.Error,
+		StackTrace: event.rawStackTrace(),
+	})
+}
+
+// rawStackTrace returns the full stack trace, falling back to StackTrace for
+// events built without one
+func (pe *PanicEvent) rawStackTrace() string {
+	if pe.RawStackTrace != "" {
+		return pe.RawStackTrace
+	}
+	return pe.StackTrace
+}
+
+// captureGoroutineDump returns the stacks of all goroutines, truncated to maxGoroutineDumpSize
+func captureGoroutineDump() string {
+	buf := make([]byte, maxGoroutineDumpSize)
+	n := runtime.Stack(buf, true)
+	dump := string(buf[:n])
+	if n == len(buf) {
+		dump += "\n... (truncated)"
+	}
+	return dump
+}
+
+// extractStackTrace captures the current stack trace, bounded to maxFrames
+// frames and maxBytes bytes, and extracts the source location
+func (pe *PanicEvent) extractStackTrace(maxFrames, maxBytes int) {
+	// Skip only runtime.Callers; healer frames are filtered below by package,
+	// since the call depth differs between HandlePanic, CapturePanic, the
+	// wrappers and the framework adapters. Leave room for those frames and
+	// the runtime frames above the panic site.
+	pc := make([]uintptr, max(64, maxFrames+32))
+	n := runtime.Callers(1, pc)
+	complete := n < len(pc)
 	pc = pc[:n]
 
 	frames := runtime.CallersFrames(pc)
-	var stackLines []string
-	var firstUserFrame *runtime.Frame
+	var stack []runtime.Frame
+	firstUser := -1
 
 	for {
 		frame, more := frames.Next()
 
-		// Skip runtime and healer package frames to find the first user frame
-		if firstUserFrame == nil && !strings.Contains(frame.File, "runtime/") &&
-			!strings.Contains(frame.File, "/healer/") {
-			firstUserFrame = &frame
+		// Skip runtime and healer frames to find the first user frame
+		if firstUser < 0 && !isRuntimeFrame(frame) && !isHealerFrame(frame) {
+			firstUser = len(stack)
 		}
-
-		// Format stack trace line
-		stackLine := fmt.Sprintf("%s:%d %s", frame.File, frame.Line, frame.Function)
-		stackLines = append(stackLines, stackLine)
+		stack = append(stack, frame)
 
 		if !more {
 			break
 		}
 	}
 
-	pe.StackTrace = strings.Join(stackLines, "\n")
+	pe.RawStackTrace = formatStack(stack, firstUser, complete, maxFrames, maxBytes)
+	pe.StackTrace = cleanStackTrace(pe.RawStackTrace)
 
 	// Set source location from the first user frame
-	if firstUserFrame != nil {
-		pe.SourceFile = firstUserFrame.File
-		pe.LineNumber = firstUserFrame.Line
-		pe.Function = firstUserFrame.Function
+	if firstUser >= 0 {
+		pe.SourceFile = stack[firstUser].File
+		pe.LineNumber = stack[firstUser].Line
+		pe.Function = stack[firstUser].Function
+	}
+}
+
+// formatStack renders stack one frame per line. A stack over maxFrames frames
+// or maxBytes bytes is trimmed rather than cut: the healer and runtime frames
+// above the panic site are dropped except the runtime frame that raised the
+// panic, such as runtime.panicIndex, followed by as many frames as fit from
+// the first user frame (index firstUser) down. complete is false when frames
+// beyond stack were not captured.
+func formatStack(stack []runtime.Frame, firstUser int, complete bool, maxFrames, maxBytes int) string {
+	lines := make([]string, len(stack))
+	size := 0
+	for i, frame := range stack {
+		lines[i] = fmt.Sprintf("%s:%d %s", frame.File, frame.Line, frame.Function)
+		size += len(lines[i]) + 1
+	}
+	if complete && len(lines) <= maxFrames && size-1 <= maxBytes {
+		return strings.Join(lines, "\n")
+	}
+
+	// Frames in order of relevance
+	var candidates []int
+	if firstUser < 0 {
+		firstUser = 0
+	} else if firstUser > 0 && isRuntimeFrame(stack[firstUser-1]) {
+		candidates = append(candidates, firstUser-1)
+	}
+	for i := firstUser; i < len(stack); i++ {
+		candidates = append(candidates, i)
+	}
+
+	// Reserve room for the marker noting omitted frames
+	const markerReserve = len("... (NNNN+ frames omitted)")
+	var kept []string
+	used := 0
+	for _, i := range candidates {
+		if len(kept) == maxFrames || (len(kept) > 0 && used+len(lines[i])+markerReserve > maxBytes) {
+			break
+		}
+		kept = append(kept, lines[i])
+		used += len(lines[i]) + 1
+	}
+
+	omitted := fmt.Sprintf("%d", len(lines)-len(kept))
+	if !complete {
+		omitted += "+"
+	}
+	if omitted != "0" {
+		kept = append(kept, fmt.Sprintf("... (%s frames omitted)", omitted))
+	}
+	return strings.Join(kept, "\n")
+}
+
+// healerModulePath is the import path of this module, e.g.
+// github.com/ajeet-kumar1087/go-code-healer
+var healerModulePath = reflect.TypeOf(PanicEvent{}).PkgPath()
+
+// isHealerFrame reports whether frame belongs to this module or one of its
+// adapter packages. Matching on the function's package rather than the file
+// path keeps user code in a directory named "healer" from being skipped.
+func isHealerFrame(frame runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, healerModulePath+".") ||
+		strings.HasPrefix(frame.Function, healerModulePath+"/")
+}
+
+// isRuntimeFrame reports whether frame is inside the Go runtime, such as
+// runtime.gopanic or the bounds-check helpers that raise runtime errors
+func isRuntimeFrame(frame runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, "runtime.") ||
+		strings.HasPrefix(frame.Function, "runtime/")
+}
+
+// Patterns stripped from error messages so that the same bug hashes identically
+// regardless of addresses, indices, or lengths embedded in the message
+var (
+	hexAddressPattern = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+	numberPattern     = regexp.MustCompile(`\d+`)
+)
+
+// normalizeErrorMessage removes volatile values from an error message
+func normalizeErrorMessage(msg string) string {
+	msg = hexAddressPattern.ReplaceAllString(msg, "0x?")
+	msg = numberPattern.ReplaceAllString(msg, "N")
+	return strings.TrimSpace(msg)
+}
+
+// computeFingerprint hashes the normalized error message together with the
+// top user stack frame, identifying repeated occurrences of the same panic
+func (pe *PanicEvent) computeFingerprint() string {
+	h := sha256.New()
+	h.Write([]byte(normalizeErrorMessage(pe.Error)))
+	h.Write([]byte{0})
+	h.Write([]byte(fmt.Sprintf("%s:%d %s", pe.SourceFile, pe.LineNumber, pe.Function)))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// redact scrubs secrets and PII from the error and stack fields, keeping the
+// original values in memory. Source location fields are left untouched so
+// fixes still target the right code.
+func (pe *PanicEvent) redact(r *internal.Redactor) {
+	if r == nil || pe.original != nil {
+		return
+	}
+
+	pe.original = &unredactedFields{
+		Error:         pe.Error,
+		ErrorMessage:  pe.ErrorMessage,
+		StackTrace:    pe.StackTrace,
+		RawStackTrace: pe.RawStackTrace,
+		AllGoroutines: pe.AllGoroutines,
+		Metadata:      pe.Metadata,
+	}
+	pe.Error = r.Redact(pe.Error)
+	pe.ErrorMessage = r.Redact(pe.ErrorMessage)
+	pe.StackTrace = r.Redact(pe.StackTrace)
+	pe.RawStackTrace = r.Redact(pe.RawStackTrace)
+	pe.AllGoroutines = r.Redact(pe.AllGoroutines)
+
+	if pe.Metadata != nil {
+		redacted := make(map[string]string, len(pe.Metadata))
+		for key, value := range pe.Metadata {
+			redacted[key] = r.Redact(value)
+		}
+		pe.Metadata = redacted
+	}
+}
+
+// Unredacted returns a copy of the event with the values captured before
+// redaction restored. It is intended for local debugging only.
+func (pe PanicEvent) Unredacted() PanicEvent {
+	if pe.original != nil {
+		pe.Error = pe.original.Error
+		pe.ErrorMessage = pe.original.ErrorMessage
+		pe.StackTrace = pe.original.StackTrace
+		pe.RawStackTrace = pe.original.RawStackTrace
+		pe.AllGoroutines = pe.original.AllGoroutines
+		pe.Metadata = pe.original.Metadata
+		pe.original = nil
 	}
+	return pe
 }
 
 // ToJSON serializes the PanicEvent to JSON for logging and API calls
@@ -102,15 +350,50 @@ func (pe *PanicEvent) GetContext() string {
 	var context strings.Builder
 
 	context.WriteString(fmt.Sprintf("Error: %s\n", pe.Error))
+	if pe.ErrorType != "" {
+		context.WriteString(fmt.Sprintf("Error Type: %s\n", pe.ErrorType))
+	}
+	if pe.ErrorMessage != "" && pe.ErrorMessage != pe.Error {
+		context.WriteString(fmt.Sprintf("Error Message: %s\n", pe.ErrorMessage))
+	}
+	if pe.Severity != "" {
+		context.WriteString(fmt.Sprintf("Severity: %s\n", pe.Severity))
+	}
 	context.WriteString(fmt.Sprintf("Location: %s:%d\n", pe.SourceFile, pe.LineNumber))
 	context.WriteString(fmt.Sprintf("Function: %s\n", pe.Function))
 	context.WriteString(fmt.Sprintf("Timestamp: %s\n", pe.Timestamp.Format(time.RFC3339)))
+	if len(pe.Metadata) > 0 {
+		context.WriteString("Request Context:\n")
+		for _, key := range sortedKeys(pe.Metadata) {
+			context.WriteString(fmt.Sprintf("  %s: %s\n", key, pe.Metadata[key]))
+		}
+	}
+	if len(pe.Tags) > 0 {
+		context.WriteString("Tags:\n")
+		for _, key := range sortedKeys(pe.Tags) {
+			context.WriteString(fmt.Sprintf("  %s: %s\n", key, pe.Tags[key]))
+		}
+	}
 	context.WriteString("Stack Trace:\n")
 	context.WriteString(pe.StackTrace)
+	if pe.AllGoroutines != "" {
+		context.WriteString("\n\nAll Goroutines:\n")
+		context.WriteString(pe.AllGoroutines)
+	}
 
 	return context.String()
 }
 
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // generateID creates a unique identifier for the panic event
 func generateID() string {
 	bytes := make([]byte, 8)
@@ -130,6 +413,22 @@ type ProcessingResult struct {
 	ProcessedAt time.Time `json:"processed_at"`
 }
 
+// NewProcessingResult builds the result for an event from the created PR, if any, and the processing error
+func NewProcessingResult(event PanicEvent, prResult *PRResult, err error) ProcessingResult {
+	result := ProcessingResult{
+		PanicID:     event.ID,
+		Success:     err == nil,
+		ProcessedAt: time.Now(),
+	}
+	if prResult != nil {
+		result.PRUrl = prResult.URL
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
 // PanicCapture handles the interception of panics
 type PanicCapture struct {
 	healer HealerInterface
@@ -142,6 +441,36 @@ type HealerInterface interface {
 	GetErrorQueue() chan PanicEvent
 }
 
+// eventRedactor is implemented by healers that scrub secrets from captured events
+type eventRedactor interface {
+	redactEvent(event *PanicEvent)
+}
+
+// stackLimiter is implemented by healers that configure how much of the stack is captured
+type stackLimiter interface {
+	stackLimits() (frames, bytes int)
+}
+
+// eventTagger is implemented by healers that attach default tags to captured events
+type eventTagger interface {
+	applyDefaultTags(event *PanicEvent)
+}
+
+// eventFilter is implemented by healers that skip some captured panics
+type eventFilter interface {
+	skipReason(event PanicEvent) string
+}
+
+// eventSampler is implemented by healers that process only a sample of repeated panics
+type eventSampler interface {
+	sampleEvent(event PanicEvent) bool
+}
+
+// eventTracer is implemented by healers that trace captured events
+type eventTracer interface {
+	getTracer() Tracer
+}
+
 // QueueManagerInterface defines the interface for queue management
 type QueueManagerInterface interface {
 	EnqueueEvent(event PanicEvent) bool
@@ -182,33 +511,110 @@ func (pc *PanicCapture) setupPanicHandler() {
 
 // CapturePanic processes a panic and queues it for background processing
 func (pc *PanicCapture) CapturePanic(panicValue any) {
+	pc.capture(context.Background(), panicValue, nil, nil)
+}
+
+// CapturePanicWithMetadata processes a panic with request-scoped metadata
+// attached and queues it for background processing
+func (pc *PanicCapture) CapturePanicWithMetadata(panicValue any, metadata map[string]string) {
+	pc.capture(context.Background(), panicValue, metadata, nil)
+}
+
+// CaptureWithTags processes a panic with tags such as the service or
+// environment attached and queues it for background processing. The tags
+// take precedence over Config.DefaultTags.
+func (pc *PanicCapture) CaptureWithTags(panicValue any, tags map[string]string) {
+	pc.capture(context.Background(), panicValue, nil, tags)
+}
+
+// CapturePanicCtx processes a panic with the metadata stored on ctx attached.
+// The capture span, when tracing is enabled, is a child of any span in ctx.
+func (pc *PanicCapture) CapturePanicCtx(ctx context.Context, panicValue any) {
+	pc.capture(ctx, panicValue, MetadataFromContext(ctx), nil)
+}
+
+// capture builds the event for a panic and hands it to the healer
+func (pc *PanicCapture) capture(ctx context.Context, panicValue any, metadata, tags map[string]string) {
 	// Create panic event immediately
-	event := NewPanicEvent(panicValue)
+	maxFrames, maxBytes := internal.DefaultMaxStackFrames, internal.DefaultMaxStackTraceBytes
+	if limiter, ok := pc.healer.(stackLimiter); ok {
+		maxFrames, maxBytes = limiter.stackLimits()
+	}
+	event := newPanicEvent(panicValue, maxFrames, maxBytes)
+	if len(metadata) > 0 {
+		event.Metadata = maps.Clone(metadata)
+	}
+	if len(tags) > 0 {
+		event.Tags = maps.Clone(tags)
+	}
+	if tagger, ok := pc.healer.(eventTagger); ok {
+		tagger.applyDefaultTags(event)
+	}
+
+	// Skip panics the configuration says not to heal before any work is done
+	if filter, ok := pc.healer.(eventFilter); ok {
+		if reason := filter.skipReason(*event); reason != "" {
+			if pc.logger != nil {
+				pc.logger.Debug("Ignoring panic at %s:%d: %s", event.SourceFile, event.LineNumber, reason)
+			}
+			return
+		}
+	}
+	if sampler, ok := pc.healer.(eventSampler); ok && !sampler.sampleEvent(*event) {
+		if pc.logger != nil {
+			pc.logger.Debug("Panic at %s:%d sampled out", event.SourceFile, event.LineNumber)
+		}
+		return
+	}
+
+	var tracer Tracer
+	if t, ok := pc.healer.(eventTracer); ok {
+		tracer = t.getTracer()
+	}
+	ctx, span := internal.StartSpan(ctx, tracer, "healer.capture_panic")
+	defer span.End()
+	span.SetAttribute("healer.event_id", event.ID)
+	span.SetAttribute("healer.severity", event.Severity)
+	span.SetAttribute("healer.error_type", event.ErrorType)
+	if tracer != nil {
+		event.TraceParent = tracer.TraceParent(ctx)
+	}
+
+	// Scrub secrets before the event reaches hooks, the queue or any AI provider
+	if redactor, ok := pc.healer.(eventRedactor); ok {
+		redactor.redactEvent(event)
+	}
 
 	// Log the panic immediately for debugging
-	if pc.logger != nil {
-		pc.logger.Error("Panic captured: %s", event.GetSummary())
-		pc.logger.Debug("Panic details: %s", event.GetContext())
+	logger := internal.WithFields(pc.logger, internal.Fields{"event_id": event.ID})
+	if logger != nil {
+		logger.Error("Panic captured: %s", event.GetSummary())
+		logger.Debug("Panic details: %s", event.GetContext())
+	}
+
+	// Notify hooks before any AI or GitHub work happens
+	if notifier, ok := pc.healer.(hookNotifier); ok {
+		notifier.notifyPanicCaptured(*event)
 	}
 
 	// Queue the event for background processing using queue manager
 	if pc.healer != nil && pc.healer.GetQueueManager() != nil {
 		success := pc.healer.GetQueueManager().EnqueueEvent(*event)
-		if !success && pc.logger != nil {
-			pc.logger.Error("Failed to enqueue panic event: %s", event.ID)
+		if !success && logger != nil {
+			logger.Error("Failed to enqueue panic event")
 		}
 	} else {
 		// Fallback to direct queue access if queue manager is not available
 		if pc.healer != nil && pc.healer.GetErrorQueue() != nil {
 			select {
 			case pc.healer.GetErrorQueue() <- *event:
-				if pc.logger != nil {
-					pc.logger.Debug("Panic event queued for processing: %s", event.ID)
+				if logger != nil {
+					logger.Debug("Panic event queued for processing")
 				}
 			default:
 				// Queue is full, log the issue but don't block
-				if pc.logger != nil {
-					pc.logger.Warn("Panic event queue is full, dropping event: %s", event.ID)
+				if logger != nil {
+					logger.Warn("Panic event queue is full, dropping event")
 				}
 			}
 		}
@@ -0,0 +1,268 @@
+package healer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Journal record operations
+const (
+	journalOpEnqueue = "enqueue"
+	journalOpDone    = "done"
+)
+
+// journalRecord is one line of the queue journal
+type journalRecord struct {
+	Op     string      `json:"op"`
+	Event  *PanicEvent `json:"event,omitempty"`  // set for enqueue records
+	ID     string      `json:"id,omitempty"`     // set for done records
+	Status string      `json:"status,omitempty"` // "completed", "failed" or "dropped"
+}
+
+// journalCompactThreshold is how many done records are appended before the
+// journal is rewritten to hold only pending events, once they outnumber them
+const journalCompactThreshold = 1000
+
+// queueJournal is an append-only JSON-lines write-ahead log of queued events.
+// Events are appended when enqueued and marked done once a worker finishes
+// with them, so events still pending when the process exits are replayed on
+// the next Initialize. The journal is compacted at startup and whenever done
+// records dominate it. All methods are safe to call on a nil journal.
+type queueJournal struct {
+	path   string
+	logger Logger
+	mu     sync.Mutex
+	file   *os.File
+
+	// Pending events, kept to compact the journal without reading it back
+	live  map[string]PanicEvent
+	order []string // IDs in enqueue order; may hold done IDs until compaction
+	done  int      // done records appended since the last compaction
+}
+
+// openQueueJournal opens (creating if needed) the journal at path
+func openQueueJournal(path string, logger Logger) (*queueJournal, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create queue journal directory: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open queue journal: %w", err)
+	}
+
+	return &queueJournal{path: path, logger: logger, file: file, live: make(map[string]PanicEvent)}, nil
+}
+
+// recordEnqueued appends an enqueue record for event
+func (j *queueJournal) recordEnqueued(event PanicEvent) {
+	if j == nil {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.appendLocked(journalRecord{Op: journalOpEnqueue, Event: &event})
+	if _, seen := j.live[event.ID]; !seen {
+		j.order = append(j.order, event.ID)
+	}
+	j.live[event.ID] = event
+}
+
+// recordDone marks the event with the given ID as no longer pending
+func (j *queueJournal) recordDone(id, status string) {
+	if j == nil {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.appendLocked(journalRecord{Op: journalOpDone, ID: id, Status: status})
+	delete(j.live, id)
+	j.done++
+
+	// Keep a long-running process from growing the journal without bound
+	if j.done >= journalCompactThreshold && j.done > len(j.live) && j.file != nil {
+		if err := j.compactLocked(j.liveEventsLocked()); err != nil {
+			j.warn("Failed to compact queue journal: %v", err)
+		}
+	}
+}
+
+// liveEventsLocked returns the pending events in enqueue order. Callers must hold j.mu.
+func (j *queueJournal) liveEventsLocked() []PanicEvent {
+	events := make([]PanicEvent, 0, len(j.live))
+	for _, id := range j.order {
+		if event, ok := j.live[id]; ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// appendLocked writes a record and syncs it so it survives an immediate
+// crash. Callers must hold j.mu.
+func (j *queueJournal) appendLocked(record journalRecord) {
+	if j.file == nil {
+		return
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		j.warn("Failed to encode queue journal record: %v", err)
+		return
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		j.warn("Failed to write queue journal: %v", err)
+		return
+	}
+	if err := j.file.Sync(); err != nil {
+		j.warn("Failed to sync queue journal: %v", err)
+	}
+}
+
+// pending reads the journal and returns events that were enqueued but never
+// marked done, in enqueue order. Corrupt lines are skipped. The journal is
+// then compacted so it only contains the pending events.
+func (j *queueJournal) pending() ([]PanicEvent, error) {
+	if j == nil {
+		return nil, nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("failed to read queue journal: %w", err)
+	}
+
+	var order []string
+	events := make(map[string]PanicEvent)
+	skipped := 0
+
+	scanner := bufio.NewScanner(j.file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var record journalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			skipped++
+			continue
+		}
+
+		switch {
+		case record.Op == journalOpEnqueue && record.Event != nil && record.Event.ID != "":
+			if _, seen := events[record.Event.ID]; !seen {
+				order = append(order, record.Event.ID)
+			}
+			events[record.Event.ID] = *record.Event
+		case record.Op == journalOpDone:
+			delete(events, record.ID)
+		default:
+			skipped++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		// A truncated final line is expected after a crash; keep what was read
+		j.warn("Queue journal read stopped early: %v", err)
+	}
+	if skipped > 0 {
+		j.warn("Skipped %d corrupt queue journal lines", skipped)
+	}
+
+	var result []PanicEvent
+	for _, id := range order {
+		if event, ok := events[id]; ok {
+			result = append(result, event)
+		}
+	}
+
+	if err := j.compactLocked(result); err != nil {
+		j.warn("Failed to compact queue journal: %v", err)
+	}
+
+	return result, nil
+}
+
+// compactLocked rewrites the journal so it only holds enqueue records for
+// events, which become the pending events. Callers must hold j.mu.
+func (j *queueJournal) compactLocked(events []PanicEvent) error {
+	j.live = make(map[string]PanicEvent, len(events))
+	j.order = make([]string, 0, len(events))
+	for _, event := range events {
+		j.live[event.ID] = event
+		j.order = append(j.order, event.ID)
+	}
+	j.done = 0
+
+	tmpPath := j.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(tmp)
+	for i := range events {
+		line, err := json.Marshal(journalRecord{Op: journalOpEnqueue, Event: &events[i]})
+		if err != nil {
+			continue
+		}
+		writer.Write(line)
+		writer.WriteByte('\n')
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		return err
+	}
+
+	// Reopen so subsequent appends go to the compacted file
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+	j.file.Close()
+	j.file = file
+	return nil
+}
+
+// close closes the journal file
+func (j *queueJournal) close() error {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// warn logs a journal problem without failing the caller
+func (j *queueJournal) warn(msg string, args ...any) {
+	if j.logger != nil {
+		j.logger.Warn(msg, args...)
+	}
+}
@@ -0,0 +1,127 @@
+package healer
+
+import "context"
+
+// severityBands lists the queue priority bands, highest first. Events with an
+// unknown or empty severity go to the last band.
+var severityBands = []string{SeverityCritical, SeverityHigh, SeverityMedium}
+
+// priorityQueue holds queued events in one buffered channel per severity band
+// so that workers always drain higher-severity events first. The queue
+// capacity is split between the bands, each with its own overflow handling,
+// so a flood of medium-severity panics can never push out a critical one.
+type priorityQueue struct {
+	bands []chan PanicEvent // indexed like severityBands
+}
+
+// newPriorityQueue creates a queue holding up to capacity events in total.
+// Each band gets an equal share, higher bands taking any remainder, and at
+// least one slot when capacity is positive.
+func newPriorityQueue(capacity int) *priorityQueue {
+	pq := &priorityQueue{bands: make([]chan PanicEvent, len(severityBands))}
+	for i := range pq.bands {
+		size := capacity / len(pq.bands)
+		if i < capacity%len(pq.bands) {
+			size++
+		}
+		if capacity > 0 {
+			size = max(size, 1)
+		}
+		pq.bands[i] = make(chan PanicEvent, size)
+	}
+	return pq
+}
+
+// band returns the channel that events with the given severity are queued on
+func (pq *priorityQueue) band(severity string) chan PanicEvent {
+	for i, name := range severityBands {
+		if name == severity {
+			return pq.bands[i]
+		}
+	}
+	return pq.bands[len(pq.bands)-1]
+}
+
+// next returns the highest-priority queued event, waiting for one if all
+// bands are empty. It returns false once ctx is done or stop or pause is
+// closed, even if events are queued, and once drain is closed and the queue
+// is empty.
+func (pq *priorityQueue) next(ctx context.Context, stop, drain, pause <-chan struct{}) (PanicEvent, bool) {
+	select {
+	case <-stop:
+		return PanicEvent{}, false
+	default:
+	}
+
+	if event, ok := pq.take(); ok {
+		return event, true
+	}
+
+	select {
+	case <-ctx.Done():
+		return PanicEvent{}, false
+	case <-stop:
+		return PanicEvent{}, false
+	case <-pause:
+		return PanicEvent{}, false
+	case <-drain:
+		// An event may have been queued since the queue was last checked
+		return pq.take()
+	case event := <-pq.bands[0]:
+		return event, true
+	case event := <-pq.bands[1]:
+		return event, true
+	case event := <-pq.bands[2]:
+		return event, true
+	}
+}
+
+// take returns the highest-priority queued event without waiting
+func (pq *priorityQueue) take() (PanicEvent, bool) {
+	for _, band := range pq.bands {
+		select {
+		case event := <-band:
+			return event, true
+		default:
+		}
+	}
+	return PanicEvent{}, false
+}
+
+// len returns the number of queued events across all bands
+func (pq *priorityQueue) len() int {
+	total := 0
+	for _, band := range pq.bands {
+		total += len(band)
+	}
+	return total
+}
+
+// capacity returns the total capacity across all bands
+func (pq *priorityQueue) capacity() int {
+	total := 0
+	for _, band := range pq.bands {
+		total += cap(band)
+	}
+	return total
+}
+
+// lengths returns the number of queued events in each band, keyed by severity
+func (pq *priorityQueue) lengths() map[string]int {
+	lengths := make(map[string]int, len(pq.bands))
+	for i, name := range severityBands {
+		lengths[name] = len(pq.bands[i])
+	}
+	return lengths
+}
+
+// full reports whether any band has no room left, so new events of its
+// severity overflow
+func (pq *priorityQueue) full() bool {
+	for _, band := range pq.bands {
+		if len(band) >= cap(band) {
+			return true
+		}
+	}
+	return false
+}
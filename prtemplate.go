@@ -0,0 +1,83 @@
+package healer
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// PRTemplateData holds the fields available to Config.PRTitleTemplate and
+// Config.PRBodyTemplate
+type PRTemplateData struct {
+	Event PanicEvent   // the panic the pull request fixes; the first one for batched fixes
+	Fix   *FixResponse // the AI-generated fix for Event
+
+	// The title and description generated when no template is set, so
+	// templates can extend them, e.g. with a checklist
+	DefaultTitle string
+	DefaultBody  string
+}
+
+// validatePRTemplates checks that the PR templates of config render, so
+// mistakes such as unknown fields are reported when the config is loaded
+func validatePRTemplates(config Config) error {
+	data := PRTemplateData{Fix: &FixResponse{}}
+	if _, err := renderPRTemplate("PR title", config.PRTitleTemplate, data); err != nil {
+		return err
+	}
+	_, err := renderPRTemplate("PR body", config.PRBodyTemplate, data)
+	return err
+}
+
+// applyPRTemplates replaces the title and description of prRequest with the
+// configured templates rendered for event and fix. The generated text is kept
+// when a template is unset or fails to render.
+func (h *Healer) applyPRTemplates(prRequest *PRRequest, event PanicEvent, fix *FixResponse) {
+	config := h.getConfig()
+	data := PRTemplateData{
+		Event:        event,
+		Fix:          fix,
+		DefaultTitle: prRequest.Title,
+		DefaultBody:  prRequest.Description,
+	}
+
+	if config.PRTitleTemplate != "" {
+		// Titles are a single line
+		title, err := renderPRTemplate("PR title", config.PRTitleTemplate, data)
+		title, _, _ = strings.Cut(strings.TrimSpace(title), "\n")
+		if err == nil && strings.TrimSpace(title) == "" {
+			err = fmt.Errorf("PR title template rendered an empty title")
+		}
+		if err != nil && h.logger != nil {
+			h.logger.Error("Using the default PR title for event %s: %v", event.ID, err)
+		} else if err == nil {
+			prRequest.Title = strings.TrimSpace(title)
+		}
+	}
+	if config.PRBodyTemplate != "" {
+		body, err := renderPRTemplate("PR body", config.PRBodyTemplate, data)
+		if err != nil && h.logger != nil {
+			h.logger.Error("Using the default PR description for event %s: %v", event.ID, err)
+		} else if err == nil {
+			prRequest.Description = body
+		}
+	}
+}
+
+// renderPRTemplate renders the text/template text with data. An empty text
+// renders nothing.
+func renderPRTemplate(name, text string, data PRTemplateData) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return out.String(), nil
+}
@@ -3,16 +3,25 @@ package healer
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
 	"sync"
 	"time"
+
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
 )
 
+// maxTrackedFingerprints bounds the dedup map before expired entries are swept
+const maxTrackedFingerprints = 1024
+
 // QueueManager handles queue overflow and management
 type QueueManager struct {
 	healer       *Healer
 	logger       Logger
 	mu           sync.RWMutex
 	droppedCount int64
+	dedupCount   int64
+	seen         map[string]time.Time // fingerprint -> last enqueue time
+	journal      *queueJournal        // nil unless queue persistence is configured
 }
 
 // NewQueueManager creates a new queue manager
@@ -20,39 +29,163 @@ func NewQueueManager(healer *Healer, logger Logger) *QueueManager {
 	return &QueueManager{
 		healer: healer,
 		logger: logger,
+		seen:   make(map[string]time.Time),
 	}
 }
 
 // EnqueueEvent attempts to enqueue a panic event with overflow handling
 func (qm *QueueManager) EnqueueEvent(event PanicEvent) bool {
+	if qm.isDuplicate(event) {
+		if qm.logger != nil {
+			qm.logger.Debug("Event %s suppressed as duplicate (fingerprint %s)", event.ID, event.Fingerprint)
+		}
+		qm.saveSkipped(event, "suppressed as duplicate")
+		return true
+	}
+
+	// Journal before handing off so a worker can never finish the event first
+	qm.journal.recordEnqueued(event)
+
 	select {
-	case qm.healer.errorQueue <- event:
+	case qm.healer.queue.band(event.Severity) <- event:
 		if qm.logger != nil {
 			qm.logger.Debug("Event %s enqueued successfully", event.ID)
 		}
 		return true
 	default:
 		// Queue is full, handle overflow
-		return qm.handleQueueOverflow(event)
+		if !qm.handleQueueOverflow(event) {
+			qm.journal.recordDone(event.ID, "dropped")
+			qm.saveSkipped(event, "dropped on queue overflow")
+			return false
+		}
+		return true
+	}
+}
+
+// MarkProcessed records that a worker has finished with an event so it is
+// not replayed from the persisted queue after a restart
+func (qm *QueueManager) MarkProcessed(event PanicEvent) {
+	qm.journal.recordDone(event.ID, event.Status)
+}
+
+// persistsQueue reports whether queued events are journaled to be replayed
+// after a restart
+func (h *Healer) persistsQueue() bool {
+	return h.queueManager != nil && h.queueManager.journal != nil
+}
+
+// markProcessed records that event is finished, so it is not replayed from
+// the persisted queue after a restart
+func (h *Healer) markProcessed(event PanicEvent) {
+	if h.queueManager != nil {
+		h.queueManager.MarkProcessed(event)
 	}
 }
 
-// handleQueueOverflow implements oldest-item dropping strategy
+// replayJournal re-queues events persisted by a previous process that were
+// never processed. Events that do not fit in the queue stay in the journal.
+func (qm *QueueManager) replayJournal() {
+	events, err := qm.journal.pending()
+	if err != nil {
+		if qm.logger != nil {
+			qm.logger.Warn("Failed to replay persisted queue: %v", err)
+		}
+		return
+	}
+
+	replayed := 0
+	for _, event := range events {
+		event.Status = "queued"
+		select {
+		case qm.healer.queue.band(event.Severity) <- event:
+			replayed++
+		default:
+			if qm.logger != nil {
+				qm.logger.Warn("Queue full, %d persisted events left for a later restart", len(events)-replayed)
+			}
+			return
+		}
+	}
+
+	if replayed > 0 && qm.logger != nil {
+		qm.logger.Info("Replayed %d persisted panic events", replayed)
+	}
+}
+
+// handleQueueOverflow applies the configured overflow strategy to an event
+// that did not fit in the queue
 func (qm *QueueManager) handleQueueOverflow(newEvent PanicEvent) bool {
+	switch qm.healer.getConfig().OverflowStrategy {
+	case "drop_newest":
+		qm.mu.Lock()
+		qm.droppedCount++
+		qm.mu.Unlock()
+		if qm.logger != nil {
+			qm.logger.Warn("Queue overflow: dropped newest event %s", newEvent.ID)
+		}
+		return false
+	case "block":
+		return qm.blockUntilQueued(newEvent)
+	default:
+		return qm.dropOldest(newEvent)
+	}
+}
+
+// blockUntilQueued waits up to the configured block timeout for room in the
+// queue, dropping the event if none frees up. The lock is not held while
+// waiting so other producers are not serialized behind this one.
+func (qm *QueueManager) blockUntilQueued(newEvent PanicEvent) bool {
+	timeoutMs := qm.healer.getConfig().OverflowBlockTimeoutMs
+	timer := time.NewTimer(time.Duration(timeoutMs) * time.Millisecond)
+	defer timer.Stop()
+
+	select {
+	case qm.healer.queue.band(newEvent.Severity) <- newEvent:
+		if qm.logger != nil {
+			qm.logger.Debug("Event %s enqueued after waiting for queue space", newEvent.ID)
+		}
+		return true
+	case <-timer.C:
+		qm.mu.Lock()
+		qm.droppedCount++
+		qm.mu.Unlock()
+		if qm.logger != nil {
+			qm.logger.Warn("Queue overflow: dropped event %s after waiting %dms for space", newEvent.ID, timeoutMs)
+		}
+		return false
+	}
+}
+
+// dropOldest drops the oldest queued event of the same severity band to make
+// room for newEvent, so lower-severity floods never evict critical events
+func (qm *QueueManager) dropOldest(newEvent PanicEvent) bool {
+	// The dropped event is stored after unlocking, as the store may be slow
+	var dropped *PanicEvent
+	defer func() {
+		if dropped != nil {
+			qm.saveSkipped(*dropped, "dropped on queue overflow")
+		}
+	}()
+
 	qm.mu.Lock()
 	defer qm.mu.Unlock()
 
+	band := qm.healer.queue.band(newEvent.Severity)
+
 	// Try to drop the oldest item and add the new one
 	select {
-	case oldEvent := <-qm.healer.errorQueue:
+	case oldEvent := <-band:
 		qm.droppedCount++
+		qm.journal.recordDone(oldEvent.ID, "dropped")
+		dropped = &oldEvent
 		if qm.logger != nil {
 			qm.logger.Warn("Queue overflow: dropped oldest event %s to make room for %s", oldEvent.ID, newEvent.ID)
 		}
 
 		// Now try to add the new event
 		select {
-		case qm.healer.errorQueue <- newEvent:
+		case band <- newEvent:
 			if qm.logger != nil {
 				qm.logger.Debug("New event %s enqueued after dropping oldest", newEvent.ID)
 			}
@@ -67,7 +200,7 @@ func (qm *QueueManager) handleQueueOverflow(newEvent PanicEvent) bool {
 	default:
 		// Queue became empty while we were waiting, try again
 		select {
-		case qm.healer.errorQueue <- newEvent:
+		case band <- newEvent:
 			if qm.logger != nil {
 				qm.logger.Debug("Event %s enqueued on retry", newEvent.ID)
 			}
@@ -82,6 +215,49 @@ func (qm *QueueManager) handleQueueOverflow(newEvent PanicEvent) bool {
 	}
 }
 
+// saveSkipped records in the store that event will never be processed, so it
+// is not reported as pending forever
+func (qm *QueueManager) saveSkipped(event PanicEvent, reason string) {
+	qm.healer.saveResult(ProcessingResult{PanicID: event.ID, Error: reason, ProcessedAt: time.Now()})
+}
+
+// isDuplicate reports whether an event with the same fingerprint was enqueued
+// within the dedup window, recording the event otherwise
+func (qm *QueueManager) isDuplicate(event PanicEvent) bool {
+	window := time.Duration(qm.healer.getConfig().DedupWindow) * time.Second
+	if event.Fingerprint == "" || window <= 0 {
+		return false
+	}
+
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+
+	now := time.Now()
+	if lastSeen, ok := qm.seen[event.Fingerprint]; ok && now.Sub(lastSeen) < window {
+		qm.dedupCount++
+		return true
+	}
+
+	// Sweep expired fingerprints so the map doesn't grow without bound
+	if len(qm.seen) >= maxTrackedFingerprints {
+		for fingerprint, lastSeen := range qm.seen {
+			if now.Sub(lastSeen) >= window {
+				delete(qm.seen, fingerprint)
+			}
+		}
+	}
+
+	qm.seen[event.Fingerprint] = now
+	return false
+}
+
+// GetDedupCount returns the number of events suppressed as duplicates
+func (qm *QueueManager) GetDedupCount() int64 {
+	qm.mu.RLock()
+	defer qm.mu.RUnlock()
+	return qm.dedupCount
+}
+
 // GetDroppedCount returns the number of events dropped due to queue overflow
 func (qm *QueueManager) GetDroppedCount() int64 {
 	qm.mu.RLock()
@@ -89,12 +265,24 @@ func (qm *QueueManager) GetDroppedCount() int64 {
 	return qm.droppedCount
 }
 
+// RetryJitter randomizes retry delays so that operations failing at the same
+// time do not all retry at the same time
+type RetryJitter string
+
+// Retry jitter modes
+const (
+	JitterNone  RetryJitter = ""      // wait exactly the exponential backoff delay
+	JitterFull  RetryJitter = "full"  // wait a random delay between 0 and the backoff delay
+	JitterEqual RetryJitter = "equal" // wait half the backoff delay plus a random delay up to the other half
+)
+
 // RetryConfig holds configuration for retry logic
 type RetryConfig struct {
 	MaxAttempts   int
 	InitialDelay  time.Duration
 	MaxDelay      time.Duration
 	BackoffFactor float64
+	Jitter        RetryJitter
 }
 
 // DefaultRetryConfig returns default retry configuration
@@ -121,10 +309,35 @@ func NewRetryManager(config RetryConfig, logger Logger) *RetryManager {
 	}
 }
 
+// backoff returns how long to wait after the given failed attempt (starting
+// at 1). The exponential delay is capped at MaxDelay before jitter is applied,
+// so jitter never waits longer than MaxDelay.
+func (rm *RetryManager) backoff(attempt int) time.Duration {
+	delay := rm.config.InitialDelay
+	for i := 1; i < attempt && delay < rm.config.MaxDelay; i++ {
+		delay = time.Duration(float64(delay) * rm.config.BackoffFactor)
+	}
+	if delay > rm.config.MaxDelay {
+		delay = rm.config.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	switch rm.config.Jitter {
+	case JitterFull:
+		return rand.N(delay + 1)
+	case JitterEqual:
+		half := delay / 2
+		return delay - half + rand.N(half+1)
+	default:
+		return delay
+	}
+}
+
 // ExecuteWithRetry executes a function with exponential backoff retry
 func (rm *RetryManager) ExecuteWithRetry(ctx context.Context, operation string, fn func() error) error {
 	var lastErr error
-	delay := rm.config.InitialDelay
 
 	for attempt := 1; attempt <= rm.config.MaxAttempts; attempt++ {
 		if rm.logger != nil {
@@ -153,190 +366,35 @@ func (rm *RetryManager) ExecuteWithRetry(ctx context.Context, operation string,
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf("%s cancelled: %w", operation, ctx.Err())
-		case <-time.After(delay):
-			// Calculate next delay with exponential backoff
-			delay = time.Duration(float64(delay) * rm.config.BackoffFactor)
-			if delay > rm.config.MaxDelay {
-				delay = rm.config.MaxDelay
-			}
+		case <-time.After(rm.backoff(attempt)):
 		}
 	}
 
 	return fmt.Errorf("%s failed after %d attempts, last error: %w", operation, rm.config.MaxAttempts, lastErr)
 }
 
-// CircuitBreakerState represents the state of a circuit breaker
-type CircuitBreakerState int
+// CircuitBreakerState is an alias to internal.CircuitBreakerState
+type CircuitBreakerState = internal.CircuitBreakerState
 
+// Circuit breaker states
 const (
-	CircuitBreakerClosed CircuitBreakerState = iota
-	CircuitBreakerOpen
-	CircuitBreakerHalfOpen
+	CircuitBreakerClosed   = internal.CircuitBreakerClosed
+	CircuitBreakerOpen     = internal.CircuitBreakerOpen
+	CircuitBreakerHalfOpen = internal.CircuitBreakerHalfOpen
 )
 
-// String returns the string representation of the circuit breaker state
-func (s CircuitBreakerState) String() string {
-	switch s {
-	case CircuitBreakerClosed:
-		return "CLOSED"
-	case CircuitBreakerOpen:
-		return "OPEN"
-	case CircuitBreakerHalfOpen:
-		return "HALF_OPEN"
-	default:
-		return "UNKNOWN"
-	}
-}
+// CircuitBreakerConfig is an alias to internal.CircuitBreakerConfig
+type CircuitBreakerConfig = internal.CircuitBreakerConfig
 
-// CircuitBreakerConfig holds configuration for circuit breaker
-type CircuitBreakerConfig struct {
-	FailureThreshold int
-	RecoveryTimeout  time.Duration
-	ResetTimeout     time.Duration
-}
+// CircuitBreaker is an alias to internal.CircuitBreaker
+type CircuitBreaker = internal.CircuitBreaker
 
 // DefaultCircuitBreakerConfig returns default circuit breaker configuration
 func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
-	return CircuitBreakerConfig{
-		FailureThreshold: 5,
-		RecoveryTimeout:  30 * time.Second,
-		ResetTimeout:     60 * time.Second,
-	}
-}
-
-// CircuitBreaker implements the circuit breaker pattern for external API failures
-type CircuitBreaker struct {
-	config       CircuitBreakerConfig
-	state        CircuitBreakerState
-	failures     int
-	lastFailTime time.Time
-	logger       Logger
-	mu           sync.RWMutex
+	return internal.DefaultCircuitBreakerConfig()
 }
 
 // NewCircuitBreaker creates a new circuit breaker
 func NewCircuitBreaker(config CircuitBreakerConfig, logger Logger) *CircuitBreaker {
-	return &CircuitBreaker{
-		config: config,
-		state:  CircuitBreakerClosed,
-		logger: logger,
-	}
-}
-
-// Execute executes a function through the circuit breaker
-func (cb *CircuitBreaker) Execute(ctx context.Context, operation string, fn func() error) error {
-	if !cb.canExecute() {
-		return fmt.Errorf("circuit breaker is OPEN for %s", operation)
-	}
-
-	err := fn()
-	cb.recordResult(operation, err)
-	return err
-}
-
-// canExecute checks if the circuit breaker allows execution
-func (cb *CircuitBreaker) canExecute() bool {
-	cb.mu.RLock()
-	defer cb.mu.RUnlock()
-
-	switch cb.state {
-	case CircuitBreakerClosed:
-		return true
-	case CircuitBreakerOpen:
-		// Check if we should transition to half-open
-		if time.Since(cb.lastFailTime) > cb.config.RecoveryTimeout {
-			cb.mu.RUnlock()
-			cb.mu.Lock()
-			// Double-check after acquiring write lock
-			if cb.state == CircuitBreakerOpen && time.Since(cb.lastFailTime) > cb.config.RecoveryTimeout {
-				cb.state = CircuitBreakerHalfOpen
-				if cb.logger != nil {
-					cb.logger.Info("Circuit breaker transitioning to HALF_OPEN")
-				}
-			}
-			cb.mu.Unlock()
-			cb.mu.RLock()
-			return cb.state == CircuitBreakerHalfOpen
-		}
-		return false
-	case CircuitBreakerHalfOpen:
-		return true
-	default:
-		return false
-	}
-}
-
-// recordResult records the result of an operation and updates circuit breaker state
-func (cb *CircuitBreaker) recordResult(operation string, err error) {
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
-
-	if err != nil {
-		cb.failures++
-		cb.lastFailTime = time.Now()
-
-		if cb.logger != nil {
-			cb.logger.Debug("Circuit breaker recorded failure for %s (failures: %d)", operation, cb.failures)
-		}
-
-		// Check if we should open the circuit
-		if cb.failures >= cb.config.FailureThreshold {
-			if cb.state != CircuitBreakerOpen {
-				cb.state = CircuitBreakerOpen
-				if cb.logger != nil {
-					cb.logger.Warn("Circuit breaker OPENED for %s after %d failures", operation, cb.failures)
-				}
-			}
-		} else if cb.state == CircuitBreakerHalfOpen {
-			// Failed in half-open state, go back to open
-			cb.state = CircuitBreakerOpen
-			if cb.logger != nil {
-				cb.logger.Warn("Circuit breaker returned to OPEN state for %s", operation)
-			}
-		}
-	} else {
-		// Success
-		if cb.logger != nil {
-			cb.logger.Debug("Circuit breaker recorded success for %s", operation)
-		}
-
-		if cb.state == CircuitBreakerHalfOpen {
-			// Success in half-open state, close the circuit
-			cb.state = CircuitBreakerClosed
-			cb.failures = 0
-			if cb.logger != nil {
-				cb.logger.Info("Circuit breaker CLOSED for %s after successful operation", operation)
-			}
-		} else if cb.state == CircuitBreakerClosed {
-			// Reset failure count on success
-			cb.failures = 0
-		}
-	}
-}
-
-// GetState returns the current state of the circuit breaker
-func (cb *CircuitBreaker) GetState() CircuitBreakerState {
-	cb.mu.RLock()
-	defer cb.mu.RUnlock()
-	return cb.state
-}
-
-// GetFailureCount returns the current failure count
-func (cb *CircuitBreaker) GetFailureCount() int {
-	cb.mu.RLock()
-	defer cb.mu.RUnlock()
-	return cb.failures
-}
-
-// Reset manually resets the circuit breaker to closed state
-func (cb *CircuitBreaker) Reset() {
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
-
-	cb.state = CircuitBreakerClosed
-	cb.failures = 0
-
-	if cb.logger != nil {
-		cb.logger.Info("Circuit breaker manually reset to CLOSED state")
-	}
+	return internal.NewCircuitBreaker(config, logger)
 }
@@ -2,14 +2,23 @@ package healer
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
 func TestQueueManager_EnqueueEvent(t *testing.T) {
-	// Create a small queue for testing overflow
+	// Create a small queue for testing overflow, with 2 slots per band
 	config := DefaultConfig()
-	config.MaxQueueSize = 2
+	config.MaxQueueSize = 6
 	config.LogLevel = "debug"
 	config.Enabled = false // Disable to avoid API key requirements
 
@@ -46,6 +55,37 @@ func TestQueueManager_EnqueueEvent(t *testing.T) {
 	}
 }
 
+func TestQueueManager_OverflowStrategies(t *testing.T) {
+	for _, strategy := range []string{"drop_newest", "block"} {
+		t.Run(strategy, func(t *testing.T) {
+			config := DefaultConfig()
+			config.MaxQueueSize = 1
+			config.Enabled = false
+			config.OverflowStrategy = strategy
+			config.OverflowBlockTimeoutMs = 10
+
+			healer, err := Initialize(config)
+			if err != nil {
+				t.Fatalf("Failed to initialize healer: %v", err)
+			}
+
+			if !healer.queueManager.EnqueueEvent(PanicEvent{ID: "first"}) {
+				t.Fatal("Expected first event to be enqueued")
+			}
+			if healer.queueManager.EnqueueEvent(PanicEvent{ID: "second"}) {
+				t.Error("Expected second event to be rejected while the queue is full")
+			}
+
+			if got := healer.queueManager.GetDroppedCount(); got != 1 {
+				t.Errorf("Expected 1 dropped event, got %d", got)
+			}
+			if event := <-healer.queue.band(SeverityMedium); event.ID != "first" {
+				t.Errorf("Expected the first event to be kept, got %s", event.ID)
+			}
+		})
+	}
+}
+
 func TestRetryManager_ExecuteWithRetry(t *testing.T) {
 	logger := NewDefaultLogger("debug")
 	retryManager := NewRetryManager(DefaultRetryConfig(), logger)
@@ -71,6 +111,41 @@ func TestRetryManager_ExecuteWithRetry(t *testing.T) {
 	}
 }
 
+func TestRetryManager_BackoffJitterBounds(t *testing.T) {
+	config := RetryConfig{
+		MaxAttempts:   6,
+		InitialDelay:  100 * time.Millisecond,
+		MaxDelay:      time.Second,
+		BackoffFactor: 2.0,
+	}
+	// Exponential delays before jitter, capped at MaxDelay
+	expected := []time.Duration{100, 200, 400, 800, 1000, 1000}
+
+	for name, jitter := range map[string]RetryJitter{"none": JitterNone, "full": JitterFull, "equal": JitterEqual} {
+		t.Run(name, func(t *testing.T) {
+			config.Jitter = jitter
+			rm := NewRetryManager(config, nil)
+
+			for i, ms := range expected {
+				base := ms * time.Millisecond
+				low, high := base, base
+				switch jitter {
+				case JitterFull:
+					low = 0
+				case JitterEqual:
+					low = base - base/2
+				}
+
+				for range 100 {
+					if delay := rm.backoff(i + 1); delay < low || delay > high {
+						t.Fatalf("Attempt %d: delay %v outside [%v, %v]", i+1, delay, low, high)
+					}
+				}
+			}
+		})
+	}
+}
+
 func TestCircuitBreaker_Execute(t *testing.T) {
 	logger := NewDefaultLogger("debug")
 	config := CircuitBreakerConfig{
@@ -127,6 +202,54 @@ func TestCircuitBreaker_Execute(t *testing.T) {
 	}
 }
 
+func TestCircuitBreaker_ConcurrentExecute(t *testing.T) {
+	config := CircuitBreakerConfig{
+		FailureThreshold: 3,
+		RecoveryTimeout:  time.Millisecond,
+		ResetTimeout:     2 * time.Millisecond,
+	}
+	cb := NewCircuitBreaker(config, nil)
+	ctx := context.Background()
+
+	// Alternate failing and succeeding operations so the breaker keeps
+	// flipping between CLOSED, OPEN and HALF_OPEN while goroutines contend
+	var wg sync.WaitGroup
+	for g := 0; g < 32; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				_ = cb.Execute(ctx, "hammer", func() error {
+					if (g+i)%3 == 0 {
+						return &testError{"failure"}
+					}
+					return nil
+				})
+				if i%50 == 0 {
+					time.Sleep(config.RecoveryTimeout)
+				}
+				_ = cb.GetState()
+			}
+		}(g)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("concurrent Execute calls deadlocked")
+	}
+
+	if state := cb.GetState(); state != CircuitBreakerClosed && state != CircuitBreakerOpen && state != CircuitBreakerHalfOpen {
+		t.Errorf("unexpected final state %v", state)
+	}
+}
+
 // testError is a simple error type for testing
 type testError struct {
 	message string
@@ -135,3 +258,464 @@ type testError struct {
 func (e *testError) Error() string {
 	return e.message
 }
+
+func TestCircuitBreaker_OpenUntil(t *testing.T) {
+	cb := NewCircuitBreaker(DefaultCircuitBreakerConfig(), nil)
+	cb.OpenUntil(time.Now().Add(50 * time.Millisecond))
+
+	if cb.Allow() {
+		t.Error("Expected circuit breaker to reject calls before the reopening time")
+	}
+
+	// The reopening time replaces the much longer recovery timeout
+	time.Sleep(60 * time.Millisecond)
+	if !cb.Allow() || cb.GetState() != CircuitBreakerHalfOpen {
+		t.Errorf("Expected a HALF_OPEN circuit breaker after the reopening time, got %v", cb.GetState())
+	}
+}
+
+func TestQueueManager_DeduplicatesFingerprints(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxQueueSize = 10
+	config.Enabled = false
+
+	healer, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+
+	first := PanicEvent{ID: "first", Error: "index out of range [5] with length 3", SourceFile: "/app/handler.go", LineNumber: 42}
+	first.Fingerprint = first.computeFingerprint()
+
+	// Same bug with different indices must share the fingerprint
+	repeat := PanicEvent{ID: "repeat", Error: "index out of range [9] with length 2", SourceFile: "/app/handler.go", LineNumber: 42}
+	repeat.Fingerprint = repeat.computeFingerprint()
+	if first.Fingerprint != repeat.Fingerprint {
+		t.Fatalf("Expected identical fingerprints, got %s and %s", first.Fingerprint, repeat.Fingerprint)
+	}
+
+	other := PanicEvent{ID: "other", Error: "index out of range [5] with length 3", SourceFile: "/app/handler.go", LineNumber: 77}
+	other.Fingerprint = other.computeFingerprint()
+
+	for _, event := range []PanicEvent{first, repeat, other} {
+		if !healer.queueManager.EnqueueEvent(event) {
+			t.Errorf("Expected event %s to be accepted", event.ID)
+		}
+	}
+
+	if got := healer.queue.len(); got != 2 {
+		t.Errorf("Expected 2 queued events, got %d", got)
+	}
+
+	stats := healer.GetQueueStats()
+	if stats["deduplicated_events"] != int64(1) {
+		t.Errorf("Expected 1 deduplicated event, got %v", stats["deduplicated_events"])
+	}
+}
+
+func TestPriorityQueue_DrainsHigherSeverityFirst(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxQueueSize = 6
+	config.Enabled = false
+	config.DedupWindow = -1
+
+	healer, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+
+	// A full medium band must not evict or delay the critical event
+	for _, event := range []PanicEvent{
+		{ID: "medium-1", Severity: SeverityMedium},
+		{ID: "medium-2", Severity: SeverityMedium},
+		{ID: "medium-3", Severity: SeverityMedium},
+		{ID: "critical", Severity: SeverityCritical},
+		{ID: "high", Severity: SeverityHigh},
+	} {
+		healer.queueManager.EnqueueEvent(event)
+	}
+
+	lengths := healer.GetQueueStats()["queue_lengths"].(map[string]int)
+	if lengths[SeverityCritical] != 1 || lengths[SeverityHigh] != 1 || lengths[SeverityMedium] != 2 {
+		t.Errorf("Unexpected per-band lengths: %v", lengths)
+	}
+
+	var order []string
+	for healer.queue.len() > 0 {
+		event, _ := healer.queue.next(context.Background(), nil, nil, nil)
+		order = append(order, event.ID)
+	}
+	want := []string{"critical", "high", "medium-2", "medium-3"}
+	if !slices.Equal(order, want) {
+		t.Errorf("Expected dequeue order %v, got %v", want, order)
+	}
+
+	// The queue capacity is shared between the bands
+	if capacity := healer.GetQueueStats()["queue_capacity"]; capacity != 6 {
+		t.Errorf("Expected a total queue capacity of 6, got %v", capacity)
+	}
+	for capacity, want := range map[int][]int{100: {34, 33, 33}, 2: {1, 1, 1}, 0: {0, 0, 0}} {
+		pq := newPriorityQueue(capacity)
+		for i, band := range pq.bands {
+			if cap(band) != want[i] {
+				t.Errorf("newPriorityQueue(%d) band %s holds %d events, want %d", capacity, severityBands[i], cap(band), want[i])
+			}
+		}
+	}
+}
+
+func TestQueueJournal_ReplaysPendingEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+
+	journal, err := openQueueJournal(path, nil)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	journal.recordEnqueued(PanicEvent{ID: "done", Error: "first"})
+	journal.recordEnqueued(PanicEvent{ID: "pending", Error: "second"})
+	journal.recordDone("done", "completed")
+	journal.close()
+
+	// Simulate a crash mid-write
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("Failed to open journal file: %v", err)
+	}
+	file.WriteString("{\"op\":\"enqueue\",\"event\":{\"id\":\n")
+	file.Close()
+
+	config := DefaultConfig()
+	config.Enabled = false
+	config.QueuePersistencePath = path
+
+	healer, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	defer healer.queueManager.journal.close()
+
+	if got := healer.queue.len(); got != 1 {
+		t.Fatalf("Expected 1 replayed event, got %d", got)
+	}
+	if event := <-healer.queue.band(SeverityMedium); event.ID != "pending" {
+		t.Errorf("Expected event 'pending' to be replayed, got %s", event.ID)
+	}
+}
+
+func TestQueueJournal_CompactsWhileRunning(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+	journal, err := openQueueJournal(path, nil)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	defer journal.close()
+
+	journal.recordEnqueued(PanicEvent{ID: "pending"})
+	for i := range journalCompactThreshold {
+		id := fmt.Sprintf("event-%d", i)
+		journal.recordEnqueued(PanicEvent{ID: id})
+		journal.recordDone(id, "completed")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read journal: %v", err)
+	}
+	if lines := strings.Count(string(content), "\n"); lines != 1 {
+		t.Errorf("Expected the journal compacted to the pending event, got %d lines", lines)
+	}
+	if pending, _ := journal.pending(); len(pending) != 1 || pending[0].ID != "pending" {
+		t.Errorf("Expected only the pending event to remain, got %+v", pending)
+	}
+}
+
+func TestDeadLetterQueue_KeepsMostRecentFailures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	dlq, err := newDeadLetterQueue(path, nil)
+	if err != nil {
+		t.Fatalf("Failed to create dead-letter queue: %v", err)
+	}
+	defer dlq.close()
+
+	for i := 0; i < maxDeadLetterEvents+2; i++ {
+		dlq.add(PanicEvent{ID: fmt.Sprintf("event-%d", i), LastError: "AI fix generation failed"})
+	}
+
+	events := dlq.list()
+	if len(events) != maxDeadLetterEvents {
+		t.Fatalf("Expected %d buffered events, got %d", maxDeadLetterEvents, len(events))
+	}
+	if events[0].ID != "event-2" || events[len(events)-1].ID != fmt.Sprintf("event-%d", maxDeadLetterEvents+1) {
+		t.Errorf("Expected oldest events to be evicted, got %s..%s", events[0].ID, events[len(events)-1].ID)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read dead-letter file: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != maxDeadLetterEvents+2 {
+		t.Errorf("Expected every failure in the dead-letter file, got %d lines", lines)
+	}
+}
+
+func TestWorkerPool_PauseKeepsEventsQueued(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.DryRun = true
+
+	healer, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	if err := healer.workerPool.Start(); err != nil {
+		t.Fatalf("Failed to start worker pool: %v", err)
+	}
+	defer healer.workerPool.Stop()
+
+	healer.Pause()
+	if paused, _ := healer.GetStatus()["paused"].(bool); !paused {
+		t.Error("Expected status to report paused")
+	}
+
+	healer.queueManager.EnqueueEvent(PanicEvent{ID: "held", Error: "nil pointer dereference"})
+	time.Sleep(50 * time.Millisecond)
+	if got := healer.queue.len(); got != 1 {
+		t.Fatalf("Expected event to stay queued while paused, got queue length %d", got)
+	}
+
+	healer.Resume()
+	deadline := time.Now().Add(time.Second)
+	for healer.queue.len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := healer.queue.len(); got != 0 {
+		t.Errorf("Expected event to be processed after resume, got queue length %d", got)
+	}
+}
+
+func TestHealer_ShutdownDrainsQueuedEvents(t *testing.T) {
+	for _, paused := range []bool{false, true} {
+		config := DefaultConfig()
+		config.Enabled = false
+		config.DryRun = true
+		config.LogLevel = "error"
+
+		healer, err := Initialize(config)
+		if err != nil {
+			t.Fatalf("Failed to initialize healer: %v", err)
+		}
+		if err := healer.workerPool.Start(); err != nil {
+			t.Fatalf("Failed to start worker pool: %v", err)
+		}
+		if paused {
+			// A paused pool keeps its events queued through shutdown
+			healer.Pause()
+		}
+		for i := range 3 {
+			healer.queueManager.EnqueueEvent(PanicEvent{ID: fmt.Sprintf("event-%d", i), Error: fmt.Sprintf("panic %d", i)})
+		}
+
+		result, err := healer.Shutdown()
+		if err != nil {
+			t.Fatalf("Shutdown failed: %v", err)
+		}
+		if healer.queue.len() != 0 {
+			t.Errorf("Expected an empty queue after shutdown, got %d events", healer.queue.len())
+		}
+
+		if !paused {
+			if result.Dropped != 0 || healer.workerPool.GetProcessedCount() != 3 {
+				t.Errorf("Expected every queued event to be processed before stopping, got %+v with %d processed", result, healer.workerPool.GetProcessedCount())
+			}
+			continue
+		}
+		if result.Drained != 0 || result.Dropped != 3 {
+			t.Errorf("Shutdown() = %+v, want 3 dropped events", result)
+		}
+		if failed := healer.GetFailedEvents(); len(failed) != 3 || failed[0].LastError != "healer stopped before the event was processed" {
+			t.Errorf("Expected dropped events to be dead-lettered, got %+v", failed)
+		}
+	}
+}
+
+func TestWorkers_ProcessReplayedEventOnce(t *testing.T) {
+	fix, _ := json.Marshal(map[string]any{
+		"proposed_fix": "if user == nil {\n\treturn nil\n}",
+		"explanation":  "Guard against a nil user",
+		"confidence":   0.9,
+	})
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"response": string(fix), "done": true})
+	}))
+	defer ollama.Close()
+
+	config := DefaultConfig()
+	config.AIProvider = "ollama"
+	config.OllamaBaseURL = ollama.URL
+	config.GitHubToken = "test-token"
+	config.RepoOwner = "acme"
+	config.RepoName = "service"
+	config.WorkerCount = 2
+	config.LogLevel = "error"
+
+	healer, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	git := &recordingGitClient{}
+	healer.gitClient = git
+
+	// A journal replay queues an event that was also queued before the restart
+	event := PanicEvent{
+		ID:         "replayed",
+		Error:      "runtime error: invalid memory address or nil pointer dereference",
+		SourceFile: "/app/user.go",
+		LineNumber: 12,
+		Severity:   SeverityHigh,
+	}
+	healer.queue.band(event.Severity) <- event
+	healer.queue.band(event.Severity) <- event
+
+	if err := healer.Start(); err != nil {
+		t.Fatalf("Failed to start healer: %v", err)
+	}
+	if _, err := healer.Shutdown(); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if len(git.requests) != 1 {
+		t.Errorf("Expected a single pull request for the replayed event, got %d", len(git.requests))
+	}
+	if skipped := healer.GetQueueStats()["duplicate_deliveries"]; skipped != int64(1) {
+		t.Errorf("Expected 1 duplicate delivery, got %v", skipped)
+	}
+}
+
+func TestQueueJournal_KeepsBatchedEventsPendingUntilFlush(t *testing.T) {
+	fix, _ := json.Marshal(map[string]any{
+		"proposed_fix": "if user == nil {\n\treturn nil\n}",
+		"explanation":  "Guard against a nil user",
+		"confidence":   0.9,
+	})
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"response": string(fix), "done": true})
+	}))
+	defer ollama.Close()
+
+	config := DefaultConfig()
+	config.AIProvider = "ollama"
+	config.OllamaBaseURL = ollama.URL
+	config.GitHubToken = "test-token"
+	config.RepoOwner = "acme"
+	config.RepoName = "service"
+	config.BatchByFile = true
+	config.BatchWindowSeconds = 3600
+	config.QueuePersistencePath = filepath.Join(t.TempDir(), "queue.jsonl")
+	config.LogLevel = "error"
+
+	healer, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	defer healer.queueManager.journal.close()
+	git := &recordingGitClient{}
+	healer.gitClient = git
+
+	event := PanicEvent{
+		ID:         "batched",
+		Error:      "runtime error: invalid memory address or nil pointer dereference",
+		SourceFile: "/app/user.go",
+		LineNumber: 12,
+		Severity:   SeverityHigh,
+	}
+	healer.queueManager.journal.recordEnqueued(event)
+
+	worker := NewBackgroundWorker(1, healer, nil, nil)
+	worker.processEvent(context.Background(), event)
+
+	// A crash during the batch window must replay the held event
+	pending, err := healer.queueManager.journal.pending()
+	if err != nil {
+		t.Fatalf("Failed to read journal: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != "batched" {
+		t.Fatalf("Expected the batched event to stay pending, got %+v", pending)
+	}
+
+	healer.batcher.flushAll()
+	if len(git.requests) != 1 {
+		t.Fatalf("Expected a pull request for the batched event, got %d", len(git.requests))
+	}
+	if pending, _ := healer.queueManager.journal.pending(); len(pending) != 0 {
+		t.Errorf("Expected no pending events once the batch was flushed, got %+v", pending)
+	}
+}
+
+func TestHealer_ShutdownReplaysEventsCutOffByDrainTimeout(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+	defer ollama.Close()
+	defer close(release)
+
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+	config := DefaultConfig()
+	config.AIProvider = "ollama"
+	config.OllamaBaseURL = ollama.URL
+	config.GitHubToken = "test-token"
+	config.RepoOwner = "acme"
+	config.RepoName = "service"
+	config.WorkerCount = 1
+	config.ShutdownDrainTimeout = 1
+	config.QueuePersistencePath = path
+	config.LogLevel = "error"
+
+	healer, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	healer.gitClient = &recordingGitClient{}
+	if err := healer.workerPool.Start(); err != nil {
+		t.Fatalf("Failed to start worker pool: %v", err)
+	}
+	healer.queueManager.EnqueueEvent(PanicEvent{ID: "in-flight", Error: "runtime error: index out of range [3] with length 3"})
+	<-started
+
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		healer.Shutdown()
+	}()
+
+	// Stats stay readable while the drain waits on the in-flight event
+	time.Sleep(200 * time.Millisecond)
+	start := time.Now()
+	healer.GetQueueStats()
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected GetQueueStats not to wait for the drain, took %v", elapsed)
+	}
+	<-shutdown
+
+	if failed := healer.GetFailedEvents(); len(failed) != 0 {
+		t.Errorf("Expected the event cut off by shutdown not to be dead-lettered, got %+v", failed)
+	}
+	if failed := healer.workerPool.GetFailedCount(); failed != 0 {
+		t.Errorf("Expected the event cut off by shutdown not to count as failed, got %d", failed)
+	}
+	select {
+	case result := <-healer.Results():
+		t.Errorf("Expected no result for the event cut off by shutdown, got %+v", result)
+	default:
+	}
+	journal, err := openQueueJournal(path, nil)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	defer journal.close()
+	if pending, _ := journal.pending(); len(pending) != 1 || pending[0].ID != "in-flight" {
+		t.Errorf("Expected the event cut off by shutdown to be replayed, got %+v", pending)
+	}
+}
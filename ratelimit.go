@@ -0,0 +1,81 @@
+package healer
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
+)
+
+// errPRRateLimited is recorded on events dead-lettered because the PR rate limit was reached
+var errPRRateLimited = errors.New("pull request rate limit reached")
+
+// prRateLimiter is a token bucket limiting how many pull requests are opened
+// per hour. The bucket starts full, so a burst up to the hourly limit is
+// allowed, and refills continuously. The limit is passed on every call so
+// reloaded configuration takes effect immediately.
+type prRateLimiter struct {
+	mu      sync.Mutex
+	tokens  float64
+	limit   int // limit the bucket was last refilled for
+	last    time.Time
+	limited atomic.Int64 // pull requests refused because the bucket was empty
+}
+
+// allow takes a token if one is available. A limit of 0 or less never limits.
+func (l *prRateLimiter) allow(limit int) bool {
+	if limit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	switch {
+	case l.last.IsZero():
+		l.tokens = float64(limit)
+	case limit != l.limit:
+		l.tokens = min(l.tokens, float64(limit))
+	default:
+		l.tokens = min(float64(limit), l.tokens+now.Sub(l.last).Hours()*float64(limit))
+	}
+	l.limit = limit
+	l.last = now
+
+	if l.tokens < 1 {
+		l.limited.Add(1)
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// limitedCount returns how many pull requests were refused
+func (l *prRateLimiter) limitedCount() int64 {
+	return l.limited.Load()
+}
+
+// allowPR reports whether the configured PR rate limit leaves room for
+// another pull request
+func (h *Healer) allowPR() bool {
+	return h.prLimiter.allow(h.getConfig().MaxPRsPerHour)
+}
+
+// deadLetterRateLimited dead-letters an event whose fix was not opened as a
+// pull request because of the rate limit, so it can be handled manually
+func (h *Healer) deadLetterRateLimited(event PanicEvent) {
+	event.Status = "failed"
+	event.LastError = errPRRateLimited.Error()
+	h.deadLetters.add(event)
+	h.markProcessed(event)
+	h.notifyError(event, errPRRateLimited)
+	h.publishResult(NewProcessingResult(event, nil, errPRRateLimited))
+
+	logger := internal.WithFields(h.logger, internal.Fields{"event_id": event.ID})
+	if logger != nil {
+		logger.Warn("PR rate limit reached, event moved to dead-letter queue")
+	}
+}
@@ -0,0 +1,218 @@
+package healer
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/ajeet-kumar1087/go-code-healer/ai"
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
+)
+
+// ReloadConfig applies a new configuration to a running healer. The log level,
+// worker count, confidence threshold, dry-run mode, timeouts, dedup, sampling,
+// overflow, ignore, heal-only, repo routing and shutdown drain settings take effect
+// immediately. Changing AI provider settings replaces the provider manager,
+// which resets its statistics, fix cache and circuit breakers.
+// Changes that need a restart, such as the queue capacity or Git credentials,
+// are rejected and nothing is applied.
+func (h *Healer) ReloadConfig(newConfig Config) error {
+	// Injected AI and Git clients are fixed for the lifetime of the healer and
+	// stand in for credentials, so keep them before validating
+	startup := h.getConfig()
+	newConfig.AIClient = startup.AIClient
+	newConfig.GitClient = startup.GitClient
+
+	newConfig.ApplyDefaults()
+	if err := newConfig.ValidateComplete(); err != nil {
+		return err
+	}
+	if err := validatePRTemplates(newConfig); err != nil {
+		return err
+	}
+
+	if h.inert {
+		return errors.New("cannot reload the configuration of a healer created by Disabled")
+	}
+
+	// Serialize reloads so two callers can't interleave their changes
+	h.reloadMu.Lock()
+	defer h.reloadMu.Unlock()
+
+	current := h.getConfig()
+	if fields := restartRequiredChanges(current, newConfig); len(fields) > 0 {
+		return fmt.Errorf("cannot reload configuration: changes to %s require a restart", strings.Join(fields, ", "))
+	}
+
+	// Outbound calls keep going through the transport built at startup
+	newConfig.HTTPTransport = current.HTTPTransport
+
+	// Build the new provider manager before touching any state so a bad
+	// credential leaves the running configuration untouched
+	var providerManager *ProviderManager
+	if newConfig.Enabled && !reflect.DeepEqual(aiSettings(current), aiSettings(newConfig)) {
+		pm, err := ai.NewProviderManager(newConfig, h.logger)
+		if err != nil {
+			return fmt.Errorf("failed to create AI providers: %w", err)
+		}
+		if err := pm.ValidateProviders(); err != nil {
+			pm.Close()
+			return fmt.Errorf("AI provider validation failed: %w", err)
+		}
+		providerManager = pm
+	}
+
+	// Validated above, like the rest of the configuration
+	filter, err := internal.NewCaptureFilter(&newConfig)
+	if err != nil {
+		return err
+	}
+
+	// The logger, tracer, store and source resolver are fixed for the
+	// lifetime of the healer
+	newConfig.Logger = current.Logger
+	newConfig.Tracer = current.Tracer
+	newConfig.Store = current.Store
+	newConfig.SourceResolver = current.SourceResolver
+
+	h.configMu.Lock()
+	h.config = newConfig
+	h.filter = filter
+	previousManager := h.providerManager
+	if providerManager != nil {
+		h.providerManager = providerManager
+	}
+	h.configMu.Unlock()
+
+	// Stop stdio MCP servers started by the replaced provider manager
+	if providerManager != nil && previousManager != nil {
+		previousManager.Close()
+	}
+
+	if newConfig.LogLevel != current.LogLevel {
+		h.logger.SetLevel(internal.ParseLogLevel(newConfig.LogLevel))
+	}
+
+	if newConfig.WorkerCount != current.WorkerCount && h.workerPool != nil {
+		// A pool that is not running starts with the new count
+		if err := h.workerPool.Resize(newConfig.WorkerCount); err != nil && !errors.Is(err, errPoolNotRunning) {
+			return fmt.Errorf("failed to resize worker pool: %w", err)
+		}
+	}
+
+	h.logger.Info("Configuration reloaded")
+	return nil
+}
+
+// ReloadOnSIGHUP reloads the configuration from configPath and the environment,
+// as LoadConfig does, every time the process receives SIGHUP. Reload errors are
+// logged and the previous configuration stays in effect. Call the returned
+// function to stop listening.
+func (h *Healer) ReloadOnSIGHUP(configPath string) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				config, err := LoadConfig(configPath)
+				if err == nil {
+					err = h.ReloadConfig(*config)
+				}
+				if err != nil {
+					h.logger.Error("Failed to reload configuration on SIGHUP: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// restartRequiredChanges returns the names of settings that differ between
+// current and next but can only be applied by restarting the healer
+func restartRequiredChanges(current, next Config) []string {
+	var fields []string
+	check := func(name string, changed bool) {
+		if changed {
+			fields = append(fields, name)
+		}
+	}
+
+	check("enabled", current.Enabled != next.Enabled)
+	check("max_queue_size", current.MaxQueueSize != next.MaxQueueSize)
+	check("retry_attempts", current.RetryAttempts != next.RetryAttempts)
+	check("queue_persistence_path", current.QueuePersistencePath != next.QueuePersistencePath)
+	check("dead_letter_path", current.DeadLetterPath != next.DeadLetterPath)
+	check("store_path", current.StorePath != next.StorePath)
+	check("git_provider", current.GitProvider != next.GitProvider)
+	check("github_token", current.GitHubToken != next.GitHubToken)
+	check("gitlab_token", current.GitLabToken != next.GitLabToken)
+	check("gitlab_base_url", current.GitLabBaseURL != next.GitLabBaseURL)
+	check("repo_owner", current.RepoOwner != next.RepoOwner)
+	check("repo_name", current.RepoName != next.RepoName)
+	check("slack_webhook_url", current.SlackWebhookURL != next.SlackWebhookURL)
+	check("discord_webhook_url", current.DiscordWebhookURL != next.DiscordWebhookURL)
+	check("webhook_url", current.WebhookURL != next.WebhookURL)
+	check("webhook_secret", current.WebhookSecret != next.WebhookSecret)
+	check("webhook_headers", !maps.Equal(current.WebhookHeaders, next.WebhookHeaders))
+	check("http_proxy", current.HTTPProxy != next.HTTPProxy)
+	check("ca_cert_file", current.CACertFile != next.CACertFile)
+	check("insecure_skip_verify", current.InsecureSkipVerify != next.InsecureSkipVerify)
+	check("redact_patterns", !slices.Equal(current.RedactPatterns, next.RedactPatterns))
+
+	return fields
+}
+
+// aiSettings returns only the fields of c that the provider manager is built from
+func aiSettings(c Config) Config {
+	return Config{
+		AIProvider:                 c.AIProvider,
+		FallbackProviders:          c.FallbackProviders,
+		QueryAllProviders:          c.QueryAllProviders,
+		StreamResponses:            c.StreamResponses,
+		OpenAIAPIKey:               c.OpenAIAPIKey,
+		OpenAIModel:                c.OpenAIModel,
+		ClaudeAPIKey:               c.ClaudeAPIKey,
+		ClaudeModel:                c.ClaudeModel,
+		CodexAPIKey:                c.CodexAPIKey,
+		CodexModel:                 c.CodexModel,
+		GeminiAPIKey:               c.GeminiAPIKey,
+		GeminiModel:                c.GeminiModel,
+		OllamaBaseURL:              c.OllamaBaseURL,
+		OllamaModel:                c.OllamaModel,
+		BedrockRegion:              c.BedrockRegion,
+		BedrockModelID:             c.BedrockModelID,
+		AzureEndpoint:              c.AzureEndpoint,
+		OpenAICompatibleBaseURL:    c.OpenAICompatibleBaseURL,
+		OpenAICompatibleAuthHeader: c.OpenAICompatibleAuthHeader,
+		AzureDeployment:            c.AzureDeployment,
+		AzureAPIVersion:            c.AzureAPIVersion,
+		AITimeoutSeconds:           c.AITimeoutSeconds,
+		AIProviderTimeouts:         c.AIProviderTimeouts,
+		AITemperature:              c.AITemperature,
+		AIMaxTokens:                c.AIMaxTokens,
+		AITopP:                     c.AITopP,
+		AIProviderParams:           c.AIProviderParams,
+		ErrorPrompts:               c.ErrorPrompts,
+		ModelPricing:               c.ModelPricing,
+		MCPEnabled:                 c.MCPEnabled,
+		MCPServers:                 c.MCPServers,
+		MCPTimeout:                 c.MCPTimeout,
+		MCPCacheTTL:                c.MCPCacheTTL,
+		FixCacheSize:               c.FixCacheSize,
+	}
+}
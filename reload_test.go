@@ -0,0 +1,161 @@
+package healer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReloadConfig(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+
+	h, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+
+	updated := config
+	updated.LogLevel = "debug"
+	updated.WorkerCount = 4
+	if err := h.ReloadConfig(updated); err != nil {
+		t.Fatalf("Expected reload to succeed, got %v", err)
+	}
+	if got := h.getConfig(); got.LogLevel != "debug" || got.WorkerCount != 4 {
+		t.Errorf("Expected reloaded values, got log level %s and %d workers", got.LogLevel, got.WorkerCount)
+	}
+
+	resized := updated
+	resized.MaxQueueSize = 500
+	resized.LogLevel = "error"
+	err = h.ReloadConfig(resized)
+	if err == nil || !strings.Contains(err.Error(), "max_queue_size") {
+		t.Fatalf("Expected queue size change to be rejected, got %v", err)
+	}
+	if got := h.getConfig(); got.LogLevel != "debug" {
+		t.Errorf("Expected rejected reload to leave config untouched, got log level %s", got.LogLevel)
+	}
+}
+
+func TestWorkerPool_Resize(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.WorkerCount = 2
+
+	h, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	if err := h.workerPool.Start(); err != nil {
+		t.Fatalf("Failed to start worker pool: %v", err)
+	}
+	defer h.workerPool.Stop()
+
+	for _, n := range []int{5, 1} {
+		if err := h.SetWorkerCount(n); err != nil {
+			t.Fatalf("SetWorkerCount(%d) failed: %v", n, err)
+		}
+		if got := h.workerPool.GetWorkerCount(); got != n {
+			t.Errorf("Expected %d workers, got %d", n, got)
+		}
+	}
+
+	for _, n := range []int{0, 51} {
+		if err := h.SetWorkerCount(n); err == nil {
+			t.Errorf("Expected SetWorkerCount(%d) to be rejected", n)
+		}
+	}
+	if got := h.getConfig().WorkerCount; got != 1 {
+		t.Errorf("Expected configured worker count 1, got %d", got)
+	}
+
+	// A stopped pool cannot be resized, so the configured count stays true
+	h.workerPool.Stop()
+	if err := h.SetWorkerCount(3); !errors.Is(err, errPoolNotRunning) {
+		t.Errorf("Expected SetWorkerCount on a stopped pool to fail with errPoolNotRunning, got %v", err)
+	}
+	if got := h.getConfig().WorkerCount; got != 1 {
+		t.Errorf("Expected configured worker count to stay 1, got %d", got)
+	}
+}
+
+func TestWorkerPool_ResizeRetiresWorkersWithBacklog(t *testing.T) {
+	fix, _ := json.Marshal(map[string]any{
+		"proposed_fix": "if user == nil {\n\treturn nil\n}",
+		"explanation":  "Guard against a nil user",
+		"confidence":   0.9,
+	})
+	started := make(chan struct{}, 20)
+	release := make(chan struct{})
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		json.NewEncoder(w).Encode(map[string]any{"response": string(fix), "done": true})
+	}))
+	defer ollama.Close()
+
+	config := DefaultConfig()
+	config.AIProvider = "ollama"
+	config.OllamaBaseURL = ollama.URL
+	config.GitHubToken = "test-token"
+	config.RepoOwner = "acme"
+	config.RepoName = "service"
+	config.WorkerCount = 3
+	config.LogLevel = "error"
+
+	h, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	h.gitClient = &recordingGitClient{}
+
+	const events = 10
+	for i := range events {
+		h.queue.band(SeverityHigh) <- PanicEvent{
+			ID:         fmt.Sprintf("event-%d", i),
+			Error:      "runtime error: invalid memory address or nil pointer dereference",
+			SourceFile: "/app/user.go",
+			LineNumber: 12 + i,
+			Severity:   SeverityHigh,
+		}
+	}
+	if err := h.workerPool.Start(); err != nil {
+		t.Fatalf("Failed to start worker pool: %v", err)
+	}
+	defer h.workerPool.Stop()
+
+	// Shrink the pool while every worker is busy and the queue has a backlog
+	for range 3 {
+		<-started
+	}
+	if err := h.workerPool.Resize(1); err != nil {
+		t.Fatalf("Resize failed: %v", err)
+	}
+	close(release)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for h.workerPool.GetProcessedCount() < events && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := h.workerPool.GetProcessedCount(); got != events {
+		t.Fatalf("Expected %d events processed, got %d", events, got)
+	}
+
+	retired := 0
+	h.workerPool.mu.RLock()
+	for _, worker := range h.workerPool.retiring {
+		if got := worker.processedCount.Load(); got != 1 {
+			t.Errorf("Expected retired worker %d to only finish its in-flight event, processed %d", worker.id, got)
+		}
+		retired++
+	}
+	h.workerPool.mu.RUnlock()
+	if retired != 2 {
+		t.Errorf("Expected 2 retired workers, got %d", retired)
+	}
+}
@@ -0,0 +1,107 @@
+package healer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
+)
+
+// routedGitClients caches a Git client per repository of Config.RepoRouting,
+// so each repository keeps one client and its rate limit state
+type routedGitClients struct {
+	mu      sync.Mutex
+	factory func(owner, repo string) GitClient // nil when no Git client is configured
+	clients map[string]GitClient               // keyed by owner/repo
+}
+
+// get returns the cached client of owner/repo, creating it on first use
+func (r *routedGitClients) get(owner, repo string) GitClient {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := owner + "/" + repo
+	if client, ok := r.clients[key]; ok {
+		return client
+	}
+	if r.clients == nil {
+		r.clients = make(map[string]GitClient)
+	}
+	client := r.factory(owner, repo)
+	r.clients[key] = client
+	return client
+}
+
+// gitClientFor returns the Git client of the repository fixes for panics in
+// sourceFile are opened in: the longest matching route of Config.RepoRouting,
+// or the default repository
+func (h *Healer) gitClientFor(sourceFile string) GitClient {
+	config := h.getConfig()
+	route, ok := matchRepoRoute(config.RepoRouting, sourceFile)
+	if !ok || h.routed.factory == nil || (route.Owner == config.RepoOwner && route.Repo == config.RepoName) {
+		return h.gitClient
+	}
+	return h.routed.get(route.Owner, route.Repo)
+}
+
+// repoPath returns the path of sourceFile, as named in a stack trace, in the
+// repository fixes for it are opened in. A route's PathPrefix is the root of
+// its repository, so files under it are named relative to it; other files
+// are named relative to Config.SourceRoot, or to the main module path for
+// binaries built with -trimpath. Other files are returned unchanged.
+func (h *Healer) repoPath(sourceFile string) string {
+	config := h.getConfig()
+	if route, ok := matchRepoRoute(config.RepoRouting, sourceFile); ok && h.routed.factory != nil {
+		return strings.TrimLeft(strings.TrimPrefix(sourceFile, route.PathPrefix), "/")
+	}
+
+	root := config.SourceRoot
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+	if rel, ok := pathUnder(sourceFile, root); ok {
+		return rel
+	}
+	if rel, ok := pathUnder(sourceFile, internal.BuildModulePath()); ok {
+		return rel
+	}
+	return sourceFile
+}
+
+// pathUnder returns path relative to dir if it is inside dir. Both use
+// forward slashes, like stack trace paths.
+func pathUnder(path, dir string) (string, bool) {
+	dir = strings.TrimSuffix(filepath.ToSlash(dir), "/")
+	if dir == "" {
+		return "", false
+	}
+	rel, ok := strings.CutPrefix(filepath.ToSlash(path), dir+"/")
+	return rel, ok && rel != ""
+}
+
+// baseSHA returns the commit fixes for panics in sourceFile are made against:
+// Config.CommitSHA for the default repository, which the binary was built
+// from, and "" for routed repositories, whose commits are unknown
+func (h *Healer) baseSHA(sourceFile string) string {
+	if h.gitClientFor(sourceFile) != h.gitClient {
+		return ""
+	}
+	return h.getConfig().CommitSHA
+}
+
+// matchRepoRoute returns the route with the longest PathPrefix sourceFile starts with
+func matchRepoRoute(routes []RepoRoute, sourceFile string) (RepoRoute, bool) {
+	var best RepoRoute
+	found := false
+	for _, route := range routes {
+		if route.PathPrefix == "" || !strings.HasPrefix(sourceFile, route.PathPrefix) {
+			continue
+		}
+		if !found || len(route.PathPrefix) > len(best.PathPrefix) {
+			best, found = route, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,32 @@
+package healer
+
+import "errors"
+
+// resultsBuffer is how many processing results Results holds before further
+// results are dropped
+const resultsBuffer = 100
+
+// errHandedOff is returned by processEventWithGit when the outcome of an event
+// is reported elsewhere: its fix was held for a batched pull request or it
+// was dead-lettered by the PR rate limit
+var errHandedOff = errors.New("event outcome reported elsewhere")
+
+// Results returns the channel on which the outcome of every processed panic
+// event is delivered, with the URL of the pull request opened for it, if any.
+// All callers share the channel, so each result is received once. Delivery
+// never blocks a worker: results are dropped while 100 are waiting to be
+// received, and counted in GetQueueStats as dropped_results.
+func (h *Healer) Results() <-chan ProcessingResult {
+	return h.results
+}
+
+// publishResult records result in the store and delivers it on the Results
+// channel unless it is full
+func (h *Healer) publishResult(result ProcessingResult) {
+	h.saveResult(result)
+	select {
+	case h.results <- result:
+	default:
+		h.droppedResults.Add(1)
+	}
+}
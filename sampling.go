@@ -0,0 +1,45 @@
+package healer
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+)
+
+// panicSampler thins out repeated panics when a sample rate below 1 is
+// configured. The first panic with a fingerprint is always kept so a new bug
+// is never sampled away; later ones are kept with the sample rate and then
+// still go through deduplication in the queue manager.
+type panicSampler struct {
+	mu         sync.Mutex
+	seen       map[string]struct{} // fingerprints already kept once
+	sampledOut atomic.Int64
+}
+
+// keep reports whether a panic with fingerprint should be processed
+func (s *panicSampler) keep(fingerprint string, rate float64) bool {
+	if rate >= 1 || fingerprint == "" {
+		return true
+	}
+
+	s.mu.Lock()
+	if s.seen == nil || len(s.seen) >= maxTrackedFingerprints {
+		// Forgetting every fingerprint at once only lets each one through unsampled again
+		s.seen = make(map[string]struct{})
+	}
+	_, repeated := s.seen[fingerprint]
+	s.seen[fingerprint] = struct{}{}
+	s.mu.Unlock()
+
+	if !repeated || rand.Float64() < rate {
+		return true
+	}
+	s.sampledOut.Add(1)
+	return false
+}
+
+// sampleEvent reports whether a captured event should be processed under the
+// configured sample rate (implements eventSampler)
+func (h *Healer) sampleEvent(event PanicEvent) bool {
+	return h.sampler.keep(event.Fingerprint, h.getConfig().SampleRate)
+}
@@ -0,0 +1,101 @@
+package healer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SlackNotifier posts a message to a Slack incoming webhook when a fix PR is opened
+type SlackNotifier struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// slackTimeout bounds a single webhook post
+const slackTimeout = 10 * time.Second
+
+// NewSlackNotifier creates a Slack notifier for the given incoming webhook URL.
+// A nil httpClient creates one with a 10 second timeout.
+func NewSlackNotifier(webhookURL string, httpClient *http.Client) *SlackNotifier {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: slackTimeout}
+	}
+	return &SlackNotifier{
+		webhookURL: webhookURL,
+		httpClient: httpClient,
+	}
+}
+
+// slackMessage is the payload accepted by Slack incoming webhooks
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// NotifyPRCreated posts the error summary, location, confidence and PR link to Slack
+func (s *SlackNotifier) NotifyPRCreated(ctx context.Context, event PanicEvent, fixResponse *FixResponse, prResult *PRResult) error {
+	payload, err := json.Marshal(slackMessage{Text: formatSlackMessage(event, fixResponse, prResult)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.webhookURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Slack request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Slack webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Notify posts created pull requests to Slack (implements Notifier)
+func (s *SlackNotifier) Notify(ctx context.Context, event HealerEvent) error {
+	if event.Type != EventPRCreated {
+		return nil
+	}
+	return s.NotifyPRCreated(ctx, event.Event, event.Fix, event.PR)
+}
+
+// formatSlackMessage builds the mrkdwn text for a created PR
+func formatSlackMessage(event PanicEvent, fixResponse *FixResponse, prResult *PRResult) string {
+	var msg strings.Builder
+
+	msg.WriteString(":adhesive_bandage: *Healer opened a fix PR*\n")
+	msg.WriteString(fmt.Sprintf("*Error*: %s\n", event.Error))
+	if event.SourceFile != "" {
+		msg.WriteString(fmt.Sprintf("*Location*: `%s:%d` in `%s`\n", event.SourceFile, event.LineNumber, event.Function))
+	}
+	if len(event.Tags) > 0 {
+		tags := make([]string, 0, len(event.Tags))
+		for _, key := range sortedKeys(event.Tags) {
+			tags = append(tags, fmt.Sprintf("`%s=%s`", key, event.Tags[key]))
+		}
+		msg.WriteString(fmt.Sprintf("*Tags*: %s\n", strings.Join(tags, " ")))
+	}
+	if fixResponse != nil {
+		msg.WriteString(fmt.Sprintf("*Confidence*: %.0f%%\n", fixResponse.Confidence*100))
+	}
+	if prResult != nil {
+		if prResult.URL != "" {
+			msg.WriteString(fmt.Sprintf("*Pull Request*: <%s|#%d %s>", prResult.URL, prResult.Number, prResult.Title))
+		} else {
+			msg.WriteString(fmt.Sprintf("*Pull Request*: %s", prResult.Title))
+		}
+	}
+
+	return strings.TrimRight(msg.String(), "\n")
+}
@@ -0,0 +1,201 @@
+package healer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultSourceContextLines is the number of lines read on each side of the panic line
+const DefaultSourceContextLines = 15
+
+// SourceResolver returns the source around line of file, as named in a stack
+// trace, to send to the AI with the panic. Set Config.SourceResolver to read
+// source from elsewhere than the local filesystem, such as the repository at
+// the commit the binary was built from.
+type SourceResolver interface {
+	Resolve(file string, line int) (snippet string, err error)
+}
+
+// FileSourceResolver resolves source from the local filesystem, which is what
+// the healer does unless Config.SourceResolver is set; files it cannot read
+// are then read from the repository at Config.CommitSHA. Lines are numbered
+// and the requested one is marked with ">>"; files outside the working
+// directory are rejected.
+type FileSourceResolver struct {
+	ContextLines int // lines on each side of the requested line; negative uses DefaultSourceContextLines
+}
+
+// Resolve reads the lines around line from file
+func (r FileSourceResolver) Resolve(file string, line int) (string, error) {
+	return extractSourceWindow(file, line, r.ContextLines)
+}
+
+// gitSourceResolver resolves source from the repository at a commit, for
+// binaries whose source is not on disk where they run. Files are named by
+// their path in the repository, as returned by Healer.repoPath.
+type gitSourceResolver struct {
+	ctx          context.Context
+	reader       FileReader
+	ref          string
+	contextLines int
+}
+
+// Resolve reads file at the commit and returns the lines around line
+func (r gitSourceResolver) Resolve(file string, line int) (string, error) {
+	content, err := r.reader.ReadFile(r.ctx, file, r.ref)
+	if err != nil {
+		return "", err
+	}
+	return sourceWindow(strings.NewReader(content), file, line, r.contextLines)
+}
+
+// newSourceResolver returns the resolver injected through config, or nil to
+// read the local filesystem
+func newSourceResolver(config Config) (SourceResolver, error) {
+	if config.SourceResolver == nil {
+		return nil, nil
+	}
+	resolver, ok := config.SourceResolver.(SourceResolver)
+	if !ok {
+		return nil, fmt.Errorf("config SourceResolver of type %T does not implement healer.SourceResolver", config.SourceResolver)
+	}
+	return resolver, nil
+}
+
+// sourceCode returns the source around the panic site of event for the AI,
+// or a placeholder describing the location when it cannot be resolved
+func (h *Healer) sourceCode(event PanicEvent) string {
+	if event.SourceFile == "" || event.LineNumber == 0 {
+		return ""
+	}
+
+	config := h.getConfig()
+	resolver := h.resolver
+	if resolver == nil {
+		resolver = FileSourceResolver{ContextLines: config.SourceContextLines}
+	}
+	snippet, err := resolver.Resolve(event.SourceFile, event.LineNumber)
+
+	// Builds running away from their source read it from the repository at
+	// the commit they were built from
+	if err != nil && h.resolver == nil {
+		if reader, ok := h.gitClient.(FileReader); ok && h.baseSHA(event.SourceFile) != "" {
+			if h.logger != nil {
+				h.logger.Debug("Reading source for event %s at commit %s: %v", event.ID, config.CommitSHA, err)
+			}
+			ctx, cancel := context.WithTimeout(h.ctx, config.GetGitTimeout())
+			defer cancel()
+			resolver := gitSourceResolver{ctx: ctx, reader: reader, ref: config.CommitSHA, contextLines: config.SourceContextLines}
+			snippet, err = resolver.Resolve(h.repoPath(event.SourceFile), event.LineNumber)
+		}
+	}
+	if err == nil && strings.TrimSpace(snippet) == "" {
+		err = fmt.Errorf("no source for %s:%d", event.SourceFile, event.LineNumber)
+	}
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Debug("Could not read source for event %s, using placeholder: %v", event.ID, err)
+		}
+		return sourcePlaceholder(event.SourceFile, event.LineNumber, event.Function)
+	}
+	return snippet
+}
+
+// extractSourceWindow reads the lines surrounding lineNumber from sourceFile and
+// returns them with line numbers, marking the panic line with ">>".
+// Files outside the current working directory are rejected so the healer never
+// ships arbitrary files from the host to an AI provider.
+func extractSourceWindow(sourceFile string, lineNumber, window int) (string, error) {
+	if sourceFile == "" || lineNumber <= 0 {
+		return "", fmt.Errorf("no source location available")
+	}
+	if err := checkWithinWorkingDir(sourceFile); err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(sourceFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer file.Close()
+
+	return sourceWindow(file, sourceFile, lineNumber, window)
+}
+
+// sourceWindow formats the lines of source surrounding lineNumber like
+// extractSourceWindow. name identifies the source in errors.
+func sourceWindow(source io.Reader, name string, lineNumber, window int) (string, error) {
+	if window < 0 {
+		window = DefaultSourceContextLines
+	}
+
+	start := lineNumber - window
+	if start < 1 {
+		start = 1
+	}
+	end := lineNumber + window
+
+	var snippet strings.Builder
+	scanner := bufio.NewScanner(source)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	current := 0
+	found := false
+	for scanner.Scan() {
+		current++
+		if current < start {
+			continue
+		}
+		if current > end {
+			break
+		}
+
+		marker := "  "
+		if current == lineNumber {
+			marker = ">>"
+			found = true
+		}
+		snippet.WriteString(fmt.Sprintf("%s %4d | %s\n", marker, current, scanner.Text()))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read source file: %w", err)
+	}
+
+	if !found {
+		return "", fmt.Errorf("line %d is out of range for %s (%d lines)", lineNumber, name, current)
+	}
+
+	return strings.TrimRight(snippet.String(), "\n"), nil
+}
+
+// checkWithinWorkingDir returns an error if path resolves outside the working directory
+func checkWithinWorkingDir(path string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to determine working directory: %w", err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve source path: %w", err)
+	}
+
+	rel, err := filepath.Rel(wd, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("source file %s is outside the working directory", path)
+	}
+
+	return nil
+}
+
+// sourcePlaceholder describes the panic location when the real source cannot be read
+func sourcePlaceholder(sourceFile string, lineNumber int, function string) string {
+	return fmt.Sprintf("// Error occurred in file: %s at line %d in function: %s\n// Stack trace provides additional context",
+		sourceFile, lineNumber, function)
+}
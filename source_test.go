@@ -0,0 +1,183 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExtractSourceWindow(t *testing.T) {
+	dir, err := os.MkdirTemp(".", "source-test-")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var lines []string
+	for i := 1; i <= 50; i++ {
+		lines = append(lines, "line"+strings.Repeat("x", i%3))
+	}
+	path := filepath.Join(dir, "sample.go")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatalf("Failed to write sample file: %v", err)
+	}
+
+	snippet, err := extractSourceWindow(path, 25, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	snippetLines := strings.Split(snippet, "\n")
+	if len(snippetLines) != 7 {
+		t.Errorf("Expected 7 lines in window, got %d", len(snippetLines))
+	}
+	if !strings.HasPrefix(snippetLines[3], ">>   25 |") {
+		t.Errorf("Expected panic line to be marked, got %q", snippetLines[3])
+	}
+
+	// Line number out of range
+	if _, err := extractSourceWindow(path, 500, 3); err == nil {
+		t.Error("Expected error for out-of-range line number")
+	}
+
+	// Missing file
+	if _, err := extractSourceWindow(filepath.Join(dir, "missing.go"), 1, 3); err == nil {
+		t.Error("Expected error for missing file")
+	}
+
+	// File outside the working directory
+	outside, err := os.CreateTemp("", "outside-*.go")
+	if err != nil {
+		t.Fatalf("Failed to create outside file: %v", err)
+	}
+	outside.Close()
+	defer os.Remove(outside.Name())
+
+	if _, err := extractSourceWindow(outside.Name(), 1, 3); err == nil {
+		t.Error("Expected error for file outside the working directory")
+	}
+}
+
+// mapSourceResolver resolves source from memory, keyed by file
+type mapSourceResolver map[string]string
+
+func (r mapSourceResolver) Resolve(file string, line int) (string, error) {
+	if snippet, ok := r[file]; ok {
+		return snippet, nil
+	}
+	return "", os.ErrNotExist
+}
+
+func TestHealer_SourceCodeUsesResolver(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.SourceResolver = mapSourceResolver{"/build/app/user.go": ">>   12 | return user.Name"}
+
+	h, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	defer h.Stop()
+
+	if got := h.sourceCode(PanicEvent{SourceFile: "/build/app/user.go", LineNumber: 12}); got != ">>   12 | return user.Name" {
+		t.Errorf("Expected the resolved source, got %q", got)
+	}
+	if got := h.sourceCode(PanicEvent{SourceFile: "/build/app/order.go", LineNumber: 3, Function: "main.order"}); !strings.Contains(got, "/build/app/order.go at line 3") {
+		t.Errorf("Expected a placeholder when the resolver fails, got %q", got)
+	}
+
+	config.SourceResolver = "not a resolver"
+	if _, err := Initialize(config); err == nil {
+		t.Error("Expected a config SourceResolver that is not a SourceResolver to be rejected")
+	}
+}
+
+// revisionReader serves files of a repository at one commit and records the lookups
+type revisionReader struct {
+	recordingGitClient
+	ref   string
+	files map[string]string
+	reads []string
+}
+
+func (r *revisionReader) ReadFile(ctx context.Context, path, ref string) (string, error) {
+	r.reads = append(r.reads, path+"@"+ref)
+	if content, ok := r.files[path]; ok && ref == r.ref {
+		return content, nil
+	}
+	return "", os.ErrNotExist
+}
+
+func TestHealer_SourceCodeReadsRepositoryAtCommit(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.CommitSHA = "abc1234"
+	config.SourceRoot = "/home/app/svc"
+
+	h, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	defer h.Stop()
+	reader := &revisionReader{ref: "abc1234", files: map[string]string{"pkg/user.go": "package pkg\n\nreturn user.Name\n"}}
+	h.gitClient = reader
+
+	got := h.sourceCode(PanicEvent{SourceFile: "/home/app/svc/pkg/user.go", LineNumber: 3})
+	if !strings.Contains(got, ">>    3 | return user.Name") {
+		t.Errorf("Expected the source read at the commit, got %q (reads %v)", got, reader.reads)
+	}
+	if len(reader.reads) != 1 || reader.reads[0] != "pkg/user.go@abc1234" {
+		t.Errorf("Expected pkg/user.go to be read at abc1234, got %v", reader.reads)
+	}
+}
+
+func TestHealer_SourceCodeReadsRuntimeFrameAtBuildCommit(t *testing.T) {
+	_, file, line, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("Failed to get the caller frame")
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", file, err)
+	}
+	buildRoot, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.Enabled = false
+	config.CommitSHA = "abc1234"
+	config.SourceRoot = buildRoot
+
+	h, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	defer h.Stop()
+	reader := &revisionReader{ref: "abc1234", files: map[string]string{filepath.Base(file): string(content)}}
+	h.gitClient = reader
+
+	// Run away from the source, like a deployed binary
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer os.Chdir(buildRoot)
+
+	got := h.sourceCode(PanicEvent{SourceFile: file, LineNumber: line})
+	if want := fmt.Sprintf(">> %4d |", line); !strings.Contains(got, want) {
+		t.Errorf("Expected line %d read at the build commit, got %q (reads %v)", line, got, reader.reads)
+	}
+	if want := filepath.Base(file) + "@abc1234"; len(reader.reads) != 1 || reader.reads[0] != want {
+		t.Errorf("Expected %s, got reads %v", want, reader.reads)
+	}
+
+	// Binaries built with -trimpath name files by module path
+	if rel, ok := pathUnder("github.com/acme/service/pkg/user.go", "github.com/acme/service"); !ok || rel != "pkg/user.go" {
+		t.Errorf("Expected pkg/user.go under the module path, got %q", rel)
+	}
+}
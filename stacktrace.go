@@ -0,0 +1,171 @@
+package healer
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseStackTrace builds a PanicEvent from a Go panic dump collected elsewhere,
+// such as application logs or an error tracker, so it can be healed offline.
+// The dump must start with a "panic:" or "fatal error:" line followed by
+// goroutine stacks, as printed by the runtime. The source location is the top
+// frame of the first goroutine outside the runtime and this module. The whole
+// dump is kept as the raw stack trace and, without runtime and healer frames,
+// as the stack trace.
+func ParseStackTrace(raw string) (*PanicEvent, error) {
+	trace := strings.TrimSpace(strings.ReplaceAll(raw, "\r\n", "\n"))
+	lines := strings.Split(trace, "\n")
+
+	message := ""
+	start := -1
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if message == "" {
+			if msg, ok := strings.CutPrefix(line, "panic: "); ok {
+				message = strings.TrimSuffix(msg, " [recovered]")
+			} else if msg, ok := strings.CutPrefix(line, "fatal error: "); ok {
+				message = msg
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "goroutine ") && strings.HasSuffix(line, ":") {
+			start = i + 1
+			break
+		}
+	}
+	if message == "" {
+		return nil, errors.New("stack trace has no panic or fatal error message")
+	}
+	if start < 0 {
+		return nil, errors.New("stack trace has no goroutine stack")
+	}
+
+	event := &PanicEvent{
+		ID:            generateID(),
+		Timestamp:     time.Now(),
+		Error:         message,
+		StackTrace:    cleanStackTrace(trace),
+		RawStackTrace: trace,
+		Status:        "queued",
+	}
+
+	// Frames are a function line followed by an indented "file:line +0x..." line,
+	// up to the blank line that ends the goroutine
+	for i := start; i+1 < len(lines); i += 2 {
+		call := strings.TrimSpace(lines[i])
+		if call == "" || strings.HasPrefix(call, "created by ") {
+			break
+		}
+		file, line, ok := parseFrameLocation(lines[i+1])
+		if !ok {
+			break
+		}
+		frame := runtime.Frame{Function: frameFunction(call), File: file, Line: line}
+		if frame.Function == "panic" || isRuntimeFrame(frame) || isHealerFrame(frame) {
+			continue
+		}
+		event.SourceFile = frame.File
+		event.LineNumber = frame.Line
+		event.Function = frame.Function
+		break
+	}
+
+	event.Fingerprint = event.computeFingerprint()
+	event.Severity = ClassifySeverity(*event)
+	if event.isConcurrencyRelated() {
+		event.AllGoroutines = trace
+	}
+	return event, nil
+}
+
+// frameFunction returns the function name of a frame's call line, without
+// its argument list, e.g. "main.(*Server).handle" for
+// "main.(*Server).handle(0xc000010200, {0x4c6a83, 0x4})"
+func frameFunction(call string) string {
+	if i := strings.LastIndex(call, "("); i > 0 && strings.HasSuffix(call, ")") {
+		return call[:i]
+	}
+	return call
+}
+
+// parseFrameLocation parses the location line of a frame, e.g.
+// "\t/app/user.go:45 +0x1a fp=0xc000042f50"
+func parseFrameLocation(line string) (string, int, bool) {
+	if !strings.HasPrefix(line, "\t") {
+		return "", 0, false
+	}
+	location, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+	i := strings.LastIndex(location, ":")
+	if i <= 0 {
+		return "", 0, false
+	}
+	lineNumber, err := strconv.Atoi(location[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return location[:i], lineNumber, true
+}
+
+// cleanStackTrace removes the runtime and healer frames from trace, keeping
+// the user's call chain. It accepts both the "file:line function" lines
+// captured by the healer and runtime panic dumps, where a frame is a call
+// line followed by an indented location line. Other lines, such as goroutine
+// headers and omitted-frame markers, are kept. trace is returned unchanged if
+// it has no user frames.
+func cleanStackTrace(trace string) string {
+	var kept []string
+	dropping, userFrames := false, 0
+	for _, line := range strings.Split(trace, "\n") {
+		// Location lines belong to the call line before them
+		if strings.HasPrefix(line, "\t") {
+			if !dropping {
+				kept = append(kept, line)
+			}
+			continue
+		}
+
+		function, isFrame := traceLineFunction(line)
+		frame := runtime.Frame{Function: function}
+		dropping = isFrame && (function == "panic" || isRuntimeFrame(frame) || isHealerFrame(frame))
+		if dropping {
+			continue
+		}
+		if isFrame {
+			userFrames++
+		}
+		kept = append(kept, line)
+	}
+	if userFrames == 0 {
+		return trace
+	}
+	return strings.Join(kept, "\n")
+}
+
+// traceLineFunction returns the function of a stack trace line that starts a
+// frame: "file:line function", "function(args)" or "created by function"
+func traceLineFunction(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ") {
+		return "", false
+	}
+	if creator, ok := strings.CutPrefix(line, "created by "); ok {
+		creator, _, _ = strings.Cut(creator, " in goroutine ")
+		return creator, true
+	}
+	if location, function, ok := strings.Cut(line, " "); ok && !strings.Contains(function, " ") {
+		if i := strings.LastIndex(location, ":"); i > 0 {
+			if _, err := strconv.Atoi(location[i+1:]); err == nil {
+				return function, true
+			}
+		}
+	}
+	if strings.HasSuffix(line, ")") && !strings.HasPrefix(line, "...") {
+		if function := frameFunction(line); function != line {
+			return function, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,155 @@
+package healer
+
+import "testing"
+
+func TestParseStackTrace(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		error    string
+		file     string
+		line     int
+		function string
+		severity string
+	}{
+		{
+			name: "nil pointer",
+			raw: `panic: runtime error: invalid memory address or nil pointer dereference
+[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x47d2a6]
+
+goroutine 1 [running]:
+main.(*UserService).processUser(0x0)
+	/app/user.go:45 +0x1a
+main.main()
+	/app/main.go:12 +0x29
+exit status 2`,
+			error:    "runtime error: invalid memory address or nil pointer dereference",
+			file:     "/app/user.go",
+			line:     45,
+			function: "main.(*UserService).processUser",
+			severity: SeverityHigh,
+		},
+		{
+			name: "index out of range",
+			raw: `panic: runtime error: index out of range [5] with length 3 [recovered]
+	panic: runtime error: index out of range [5] with length 3
+
+goroutine 1 [running]:
+panic({0x4a1f60?, 0xc000014108?})
+	/usr/local/go/src/runtime/panic.go:770 +0x132
+main.processItems({0xc000010200, 0x3, 0x3})
+	/app/processor.go:23 +0x85
+main.main()
+	/app/main.go:15 +0x45`,
+			error:    "runtime error: index out of range [5] with length 3",
+			file:     "/app/processor.go",
+			line:     23,
+			function: "main.processItems",
+			severity: SeverityHigh,
+		},
+		{
+			name: "concurrent map writes",
+			raw: `fatal error: concurrent map writes
+
+goroutine 19 [running]:
+runtime.throw(0x4c7b85, 0x15)
+	/usr/local/go/src/runtime/panic.go:774 +0x72 fp=0xc000042f50 sp=0xc000042f20 pc=0x42cf42
+runtime.mapassign_faststr(0x4a3e20, 0xc000086000, 0x4c6a83, 0x4, 0x0)
+	/usr/local/go/src/runtime/map_faststr.go:211 +0x3f7 fp=0xc000042fb8 sp=0xc000042f50 pc=0x40f4a7
+main.updateCache(0xc000086000, 0x4c6a83, 0x4, 0x4c6a88, 0x5)
+	/app/cache.go:34 +0x5c fp=0xc000042fd8 sp=0xc000042fb8 pc=0x48a1bc
+created by main.main in goroutine 1
+	/app/main.go:20 +0x88
+
+goroutine 1 [sleep]:
+time.Sleep(0x3b9aca00)
+	/usr/local/go/src/runtime/time.go:195 +0x125
+main.main()
+	/app/main.go:24 +0xb2`,
+			error:    "concurrent map writes",
+			file:     "/app/cache.go",
+			line:     34,
+			function: "main.updateCache",
+			severity: SeverityCritical,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParseStackTrace(tt.raw)
+			if err != nil {
+				t.Fatalf("ParseStackTrace failed: %v", err)
+			}
+			if event.Error != tt.error {
+				t.Errorf("Expected error %q, got %q", tt.error, event.Error)
+			}
+			if event.SourceFile != tt.file || event.LineNumber != tt.line || event.Function != tt.function {
+				t.Errorf("Expected %s at %s:%d, got %s at %s:%d",
+					tt.function, tt.file, tt.line, event.Function, event.SourceFile, event.LineNumber)
+			}
+			if event.Severity != tt.severity {
+				t.Errorf("Expected severity %s, got %s", tt.severity, event.Severity)
+			}
+			if event.StackTrace == "" || event.Fingerprint == "" {
+				t.Error("Expected the stack trace and fingerprint to be set")
+			}
+		})
+	}
+
+	for _, raw := range []string{"", "goroutine 1 [running]:\nmain.main()\n\t/app/main.go:12 +0x29", "panic: boom"} {
+		if _, err := ParseStackTrace(raw); err == nil {
+			t.Errorf("Expected %q to be rejected", raw)
+		}
+	}
+}
+
+func TestCleanStackTrace(t *testing.T) {
+	tests := []struct {
+		name  string
+		trace string
+		want  string
+	}{
+		{
+			name: "captured frames",
+			trace: `/usr/local/go/src/runtime/panic.go:115 runtime.goPanicIndex
+/app/processor.go:23 main.processItems
+/go/pkg/mod/github.com/ajeet-kumar1087/go-code-healer/panic.go:80 github.com/ajeet-kumar1087/go-code-healer.WrapFunction.func1
+/app/main.go:15 main.main
+/usr/local/go/src/runtime/proc.go:272 runtime.main
+... (3 frames omitted)`,
+			want: `/app/processor.go:23 main.processItems
+/app/main.go:15 main.main
+... (3 frames omitted)`,
+		},
+		{
+			name: "panic dump",
+			trace: `panic: runtime error: index out of range [5] with length 3
+
+goroutine 1 [running]:
+panic({0x4a1f60?, 0xc000014108?})
+	/usr/local/go/src/runtime/panic.go:770 +0x132
+main.processItems({0xc000010200, 0x3, 0x3})
+	/app/processor.go:23 +0x85
+created by runtime.gcenable in goroutine 1
+	/usr/local/go/src/runtime/mgc.go:203 +0x66`,
+			want: `panic: runtime error: index out of range [5] with length 3
+
+goroutine 1 [running]:
+main.processItems({0xc000010200, 0x3, 0x3})
+	/app/processor.go:23 +0x85`,
+		},
+		{
+			name:  "no user frames",
+			trace: "/usr/local/go/src/runtime/proc.go:272 runtime.main",
+			want:  "/usr/local/go/src/runtime/proc.go:272 runtime.main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanStackTrace(tt.trace); got != tt.want {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.want, got)
+			}
+		})
+	}
+}
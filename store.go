@@ -0,0 +1,315 @@
+package healer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultMemoryStoreSize is how many events the default in-memory store keeps
+const defaultMemoryStoreSize = 1000
+
+// storeTimeout bounds every store write, since panics are stored on the
+// goroutine that panicked
+const storeTimeout = time.Second
+
+// Store records every captured panic and the result of processing it, as an
+// audit trail for reporting on healing over time. Set Config.Store to use a
+// custom backend, such as a database; Config.StorePath selects the built-in
+// JSON-lines file store and the most recent events are kept in memory
+// otherwise. Methods are called from the capturing goroutine and workers, so
+// they must be safe for concurrent use and give up once ctx is done; writes
+// get one second.
+type Store interface {
+	// SavePanic records a captured panic. An event saved again, such as a
+	// retried one, replaces the earlier record with the same ID.
+	SavePanic(ctx context.Context, event PanicEvent) error
+
+	// SaveResult records the outcome of processing the panic result.PanicID
+	SaveResult(ctx context.Context, result ProcessingResult) error
+
+	// Query returns the stored events matching query, newest first
+	Query(ctx context.Context, query StoreQuery) ([]StoredEvent, error)
+}
+
+// StoredEvent is a captured panic and, once processed, its result
+type StoredEvent struct {
+	Event  PanicEvent        `json:"event"`
+	Result *ProcessingResult `json:"result,omitempty"` // nil while the event is pending
+}
+
+// Outcomes matched by StoreQuery.Outcome
+const (
+	OutcomePending   = "pending"
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+)
+
+// StoreQuery selects stored events. Zero fields match every event.
+type StoreQuery struct {
+	Since       time.Time // events captured at or after Since
+	Until       time.Time // events captured before Until
+	Fingerprint string
+	SourceFile  string
+	Outcome     string // OutcomePending, OutcomeSucceeded or OutcomeFailed
+	Limit       int    // most events returned; 0 returns all
+}
+
+// Matches reports whether stored satisfies the query
+func (q StoreQuery) Matches(stored StoredEvent) bool {
+	event := stored.Event
+	switch {
+	case !q.Since.IsZero() && event.Timestamp.Before(q.Since),
+		!q.Until.IsZero() && !event.Timestamp.Before(q.Until),
+		q.Fingerprint != "" && event.Fingerprint != q.Fingerprint,
+		q.SourceFile != "" && event.SourceFile != q.SourceFile:
+		return false
+	}
+
+	switch q.Outcome {
+	case OutcomePending:
+		return stored.Result == nil
+	case OutcomeSucceeded:
+		return stored.Result != nil && stored.Result.Success
+	case OutcomeFailed:
+		return stored.Result != nil && !stored.Result.Success
+	}
+	return true
+}
+
+// Apply returns the events matching the query, newest first and at most Limit.
+// Stores can use it to implement Query over events they hold in memory.
+func (q StoreQuery) Apply(events []StoredEvent) []StoredEvent {
+	var matched []StoredEvent
+	for _, stored := range events {
+		if q.Matches(stored) {
+			matched = append(matched, stored)
+		}
+	}
+	slices.SortStableFunc(matched, func(a, b StoredEvent) int {
+		return b.Event.Timestamp.Compare(a.Event.Timestamp)
+	})
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	return matched
+}
+
+// MemoryStore keeps the most recent events in memory, evicting the oldest
+// once it is full. It is the default store.
+type MemoryStore struct {
+	mu     sync.Mutex
+	size   int
+	order  []string // event IDs, oldest first
+	events map[string]*StoredEvent
+}
+
+// NewMemoryStore creates a store holding up to size events, or 1000 if size is not positive
+func NewMemoryStore(size int) *MemoryStore {
+	if size <= 0 {
+		size = defaultMemoryStoreSize
+	}
+	return &MemoryStore{size: size, events: make(map[string]*StoredEvent)}
+}
+
+// SavePanic implements Store
+func (s *MemoryStore) SavePanic(ctx context.Context, event PanicEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(event.ID).Event = event
+	return nil
+}
+
+// SaveResult implements Store
+func (s *MemoryStore) SaveResult(ctx context.Context, result ProcessingResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(result.PanicID).Result = &result
+	return nil
+}
+
+// Query implements Store
+func (s *MemoryStore) Query(ctx context.Context, query StoreQuery) ([]StoredEvent, error) {
+	s.mu.Lock()
+	events := make([]StoredEvent, 0, len(s.order))
+	for _, id := range s.order {
+		events = append(events, *s.events[id])
+	}
+	s.mu.Unlock()
+	return query.Apply(events), nil
+}
+
+// record returns the record for id, adding it and evicting the oldest if needed
+func (s *MemoryStore) record(id string) *StoredEvent {
+	if stored, ok := s.events[id]; ok {
+		return stored
+	}
+	if len(s.order) == s.size {
+		delete(s.events, s.order[0])
+		s.order = s.order[1:]
+	}
+	stored := &StoredEvent{Event: PanicEvent{ID: id}}
+	s.events[id] = stored
+	s.order = append(s.order, id)
+	return stored
+}
+
+// storeRecord is one line of a FileStore
+type storeRecord struct {
+	Panic  *PanicEvent       `json:"panic,omitempty"`
+	Result *ProcessingResult `json:"result,omitempty"`
+}
+
+// FileStore appends every panic and result to a JSON-lines file, keeping the
+// full history across restarts. Query reads the whole file, so it suits
+// audit and reporting rather than frequent lookups.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStore creates a store appending to the file at path, creating it
+// and its directory if needed
+func NewFileStore(path string) (*FileStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create store directory: %w", err)
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	file.Close()
+	return &FileStore{path: path}, nil
+}
+
+// SavePanic implements Store
+func (s *FileStore) SavePanic(ctx context.Context, event PanicEvent) error {
+	return s.append(storeRecord{Panic: &event})
+}
+
+// SaveResult implements Store
+func (s *FileStore) SaveResult(ctx context.Context, result ProcessingResult) error {
+	return s.append(storeRecord{Result: &result})
+}
+
+// append writes record as one line
+func (s *FileStore) append(record storeRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open store: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write store: %w", err)
+	}
+	return file.Close()
+}
+
+// Query implements Store, replaying the file into one record per event
+func (s *FileStore) Query(ctx context.Context, query StoreQuery) ([]StoredEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	defer file.Close()
+
+	var order []string
+	events := make(map[string]*StoredEvent)
+	record := func(id string) *StoredEvent {
+		if stored, ok := events[id]; ok {
+			return stored
+		}
+		stored := &StoredEvent{Event: PanicEvent{ID: id}}
+		events[id] = stored
+		order = append(order, id)
+		return stored
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line storeRecord
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			// Skip lines torn by a crash mid-write
+			continue
+		}
+		switch {
+		case line.Panic != nil:
+			record(line.Panic.ID).Event = *line.Panic
+		case line.Result != nil:
+			record(line.Result.PanicID).Result = line.Result
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	}
+
+	stored := make([]StoredEvent, 0, len(order))
+	for _, id := range order {
+		stored = append(stored, *events[id])
+	}
+	return query.Apply(stored), nil
+}
+
+// Store returns the store recording captured panics and their results
+func (h *Healer) Store() Store {
+	return h.store
+}
+
+// newStore returns the store configured by config
+func newStore(config Config) (Store, error) {
+	switch {
+	case config.Store != nil:
+		store, ok := config.Store.(Store)
+		if !ok {
+			return nil, fmt.Errorf("config Store of type %T does not implement healer.Store", config.Store)
+		}
+		return store, nil
+	case config.StorePath != "":
+		return NewFileStore(config.StorePath)
+	default:
+		return NewMemoryStore(defaultMemoryStoreSize), nil
+	}
+}
+
+// savePanic records a captured event in the store, logging failures
+func (h *Healer) savePanic(event PanicEvent) {
+	if h.store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := h.store.SavePanic(ctx, event); err != nil && h.logger != nil {
+		h.logger.Warn("Failed to store event %s: %v", event.ID, err)
+	}
+}
+
+// saveResult records a processing result in the store, logging failures
+func (h *Healer) saveResult(result ProcessingResult) {
+	if h.store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := h.store.SaveResult(ctx, result); err != nil && h.logger != nil {
+		h.logger.Warn("Failed to store the result of event %s: %v", result.PanicID, err)
+	}
+}
@@ -0,0 +1,130 @@
+package healer
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStores_RecordPanicsAndResults(t *testing.T) {
+	fileStore, err := NewFileStore(filepath.Join(t.TempDir(), "audit", "events.jsonl"))
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	stores := map[string]Store{"memory": NewMemoryStore(10), "file": fileStore}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			for i, id := range []string{"a", "b", "c"} {
+				event := PanicEvent{ID: id, Timestamp: base.Add(time.Duration(i) * time.Hour), SourceFile: "/app/" + id + ".go"}
+				if err := store.SavePanic(ctx, event); err != nil {
+					t.Fatalf("SavePanic failed: %v", err)
+				}
+			}
+			store.SaveResult(ctx, NewProcessingResult(PanicEvent{ID: "a"}, &PRResult{URL: "https://example.com/pr/1"}, nil))
+			store.SaveResult(ctx, NewProcessingResult(PanicEvent{ID: "b"}, nil, errors.New("no fix")))
+
+			all, err := store.Query(ctx, StoreQuery{})
+			if err != nil || len(all) != 3 || all[0].Event.ID != "c" || all[2].Event.ID != "a" {
+				t.Fatalf("Expected all events newest first, got %+v (%v)", all, err)
+			}
+			if all[2].Result == nil || all[2].Result.PRUrl != "https://example.com/pr/1" {
+				t.Errorf("Expected the result stored with its event, got %+v", all[2].Result)
+			}
+
+			queries := map[string]struct {
+				query StoreQuery
+				want  []string
+			}{
+				"succeeded": {StoreQuery{Outcome: OutcomeSucceeded}, []string{"a"}},
+				"failed":    {StoreQuery{Outcome: OutcomeFailed}, []string{"b"}},
+				"pending":   {StoreQuery{Outcome: OutcomePending}, []string{"c"}},
+				"since":     {StoreQuery{Since: base.Add(time.Hour)}, []string{"c", "b"}},
+				"file":      {StoreQuery{SourceFile: "/app/b.go"}, []string{"b"}},
+				"limit":     {StoreQuery{Limit: 1}, []string{"c"}},
+			}
+			for queryName, tt := range queries {
+				got, _ := store.Query(ctx, tt.query)
+				var ids []string
+				for _, stored := range got {
+					ids = append(ids, stored.Event.ID)
+				}
+				if len(ids) != len(tt.want) || (len(ids) > 0 && ids[0] != tt.want[0]) {
+					t.Errorf("Query %s: expected %v, got %v", queryName, tt.want, ids)
+				}
+			}
+		})
+	}
+
+	// The memory store evicts the oldest events
+	small := NewMemoryStore(2)
+	for _, id := range []string{"a", "b", "c"} {
+		small.SavePanic(context.Background(), PanicEvent{ID: id, Timestamp: time.Now()})
+	}
+	if got, _ := small.Query(context.Background(), StoreQuery{}); len(got) != 2 {
+		t.Errorf("Expected 2 events kept, got %d", len(got))
+	}
+}
+
+func TestHealer_StoresCapturedPanics(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.LogLevel = "error"
+	config.StorePath = filepath.Join(t.TempDir(), "events.jsonl")
+
+	h, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	defer h.Stop()
+
+	(&PanicCapture{healer: h}).CapturePanic("boom")
+	h.publishResult(ProcessingResult{PanicID: "unrelated", Success: true})
+
+	pending, err := h.Store().Query(context.Background(), StoreQuery{Outcome: OutcomePending})
+	if err != nil || len(pending) != 1 || pending[0].Event.Error != "boom" {
+		t.Errorf("Expected the captured panic to be stored as pending, got %+v (%v)", pending, err)
+	}
+}
+
+// slowStore blocks every write until its context is done
+type slowStore struct {
+	*MemoryStore
+}
+
+func (s slowStore) SavePanic(ctx context.Context, event PanicEvent) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestHealer_BoundsStoreWritesAndRecordsSkippedPanics(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = false
+	config.LogLevel = "error"
+	config.Store = slowStore{NewMemoryStore(10)}
+
+	h, err := Initialize(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize healer: %v", err)
+	}
+	defer h.Stop()
+
+	start := time.Now()
+	h.savePanic(PanicEvent{ID: "slow"})
+	if elapsed := time.Since(start); elapsed > 2*storeTimeout {
+		t.Errorf("Expected a slow store to be given up on after %v, took %v", storeTimeout, elapsed)
+	}
+
+	// A duplicate is never processed, so its record gets a result right away
+	event := PanicEvent{ID: "dup", Fingerprint: "fp", Severity: "high"}
+	h.queueManager.EnqueueEvent(PanicEvent{ID: "first", Fingerprint: "fp", Severity: "high"})
+	h.queueManager.EnqueueEvent(event)
+	failed, _ := h.Store().Query(context.Background(), StoreQuery{Outcome: OutcomeFailed})
+	if len(failed) != 1 || failed[0].Result.PanicID != "dup" || failed[0].Result.Error != "suppressed as duplicate" {
+		t.Errorf("Expected the duplicate recorded as failed, got %+v", failed)
+	}
+}
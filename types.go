@@ -20,10 +20,44 @@ type FixResponse = ai.FixResponse
 // Git client types (directly from github module)
 type PRRequest = github.PRRequest
 type FileChange = github.FileChange
+type PRResult = github.PRResult
+type IssueRequest = github.IssueRequest
+type IssueResult = github.IssueResult
+
+// CommitMessageData holds the fields available to Config.CommitMessageTemplate
+type CommitMessageData = internal.CommitMessageData
+
+// AIGenerationParams holds per-provider overrides for Config.AIProviderParams
+type AIGenerationParams = internal.AIGenerationParams
+
+// RepoRoute sends fixes for panics under a path prefix to another
+// repository, see Config.RepoRouting
+type RepoRoute = internal.RepoRoute
+
+// RateLimitError is returned by the GitHub client while GitHub rate limits it
+type RateLimitError = github.RateLimitError
 
 // GitClient interface for Git operations and GitHub API calls
 type GitClient interface {
-	CreatePullRequest(ctx context.Context, request PRRequest) error
+	CreatePullRequest(ctx context.Context, request PRRequest) (*PRResult, error)
+}
+
+// IssueCreator is implemented by Git clients that can open issues. Low-confidence
+// fixes are tracked as issues only when the configured client implements it.
+type IssueCreator interface {
+	CreateIssue(ctx context.Context, request IssueRequest) (*IssueResult, error)
+}
+
+// FileReader is implemented by Git clients that can read a file of the
+// repository at a branch, tag or commit
+type FileReader interface {
+	ReadFile(ctx context.Context, path, ref string) (string, error)
+}
+
+// AccessChecker is implemented by Git clients that can confirm their token
+// may push to the repository. Healer.ValidateConnectivity uses it.
+type AccessChecker interface {
+	CheckAccess(ctx context.Context) error
 }
 
 // Worker interface for background processing
@@ -37,9 +71,20 @@ type LogLevel = internal.LogLevel
 type Logger = internal.LoggerInterface
 type LoggerInterface = internal.LoggerInterface
 type DefaultLogger = internal.DefaultLogger
+type JSONLogger = internal.JSONLogger
+type SlogLogger = internal.SlogLogger
+type LogFields = internal.Fields
+
+// Tracing types; see the otelhealer module for an OpenTelemetry adapter
+type Tracer = internal.Tracer
+type Span = internal.Span
 
 // Re-export constants
 const (
+	SeverityCritical = ai.SeverityCritical
+	SeverityHigh     = ai.SeverityHigh
+	SeverityMedium   = ai.SeverityMedium
+
 	LogLevelDebug = internal.LogLevelDebug
 	LogLevelInfo  = internal.LogLevelInfo
 	LogLevelWarn  = internal.LogLevelWarn
@@ -50,4 +95,10 @@ const (
 var (
 	// Panic functions are now defined directly in panic.go
 	NewDefaultLogger = internal.NewDefaultLogger
+	NewJSONLogger    = internal.NewJSONLogger
+	NewLogger        = internal.NewLogger
+	NewSlogLogger    = internal.NewSlogLogger
+
+	// WithLogFields returns a logger that attaches structured fields to every line
+	WithLogFields = internal.WithFields
 )
@@ -0,0 +1,134 @@
+package healer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 of the webhook body, as
+// "sha256=<hex>", when a webhook secret is configured
+const WebhookSignatureHeader = "X-Healer-Signature"
+
+// Webhook delivery limits: each attempt is bounded by webhookTimeout and
+// failed deliveries are retried after 1s and 2s
+const (
+	webhookTimeout     = 5 * time.Second
+	webhookMaxAttempts = 3
+)
+
+// WebhookNotifier posts a JSON payload to a generic HTTP endpoint when a
+// panic is captured, a fix is generated and a pull request is opened
+type WebhookNotifier struct {
+	url        string
+	secret     string
+	headers    map[string]string
+	httpClient *http.Client
+	retryDelay time.Duration // delay before the second attempt, doubled for later ones
+}
+
+// WebhookPayload is the JSON body posted by WebhookNotifier
+type WebhookPayload struct {
+	Event     HealerEventType `json:"event"` // panic_captured, fix_generated or pr_created
+	Timestamp time.Time       `json:"timestamp"`
+	Panic     PanicEvent      `json:"panic"`
+	Fix       *FixResponse    `json:"fix,omitempty"`
+	PR        *PRResult       `json:"pr,omitempty"`
+}
+
+// NewWebhookNotifier creates a notifier posting to url. A non-empty secret
+// signs every body in the X-Healer-Signature header, and headers are added
+// to every request. A nil httpClient creates one with a 5 second timeout.
+func NewWebhookNotifier(url, secret string, headers map[string]string, httpClient *http.Client) *WebhookNotifier {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: webhookTimeout}
+	}
+	return &WebhookNotifier{
+		url:        url,
+		secret:     secret,
+		headers:    headers,
+		httpClient: httpClient,
+		retryDelay: time.Second,
+	}
+}
+
+// Notify posts captured panics, generated fixes and created pull requests
+// (implements Notifier)
+func (w *WebhookNotifier) Notify(ctx context.Context, event HealerEvent) error {
+	switch event.Type {
+	case EventPanicCaptured, EventFixGenerated, EventPRCreated:
+		return w.Send(ctx, WebhookPayload{Event: event.Type, Timestamp: event.Time, Panic: event.Event, Fix: event.Fix, PR: event.PR})
+	}
+	return nil
+}
+
+// Send posts payload, retrying network errors, rate limits and server
+// errors up to three attempts in total
+func (w *WebhookNotifier) Send(ctx context.Context, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	delay := w.retryDelay
+	for attempt := 1; ; attempt++ {
+		retry, err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == webhookMaxAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post makes a single delivery attempt and reports whether a failure is worth retrying
+func (w *WebhookNotifier) post(ctx context.Context, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	for name, value := range w.headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(w.secret, body))
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("webhook request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+// SignWebhookPayload returns the X-Healer-Signature value for body: "sha256="
+// followed by the hex HMAC-SHA256 of body keyed with secret. Receivers
+// should compute it over the raw request body and compare with hmac.Equal.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
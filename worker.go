@@ -2,32 +2,46 @@ package healer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ajeet-kumar1087/go-code-healer/ai"
+	gh "github.com/ajeet-kumar1087/go-code-healer/github"
+	"github.com/ajeet-kumar1087/go-code-healer/internal"
 )
 
 // BackgroundWorker handles background processing of panic events
 type BackgroundWorker struct {
 	id        int
 	healer    *Healer
+	pool      *WorkerPool // nil for workers created outside a pool
+	unbatched bool        // open pull requests immediately even when BatchByFile is set
 	logger    Logger
 	stopCh    chan struct{}
+	drainCh   chan struct{} // closed by drain; the worker exits once the queue is empty
+	drainOnce sync.Once
 	wg        *sync.WaitGroup
 	isRunning bool
 	mu        sync.RWMutex
+
+	// Counters surfaced through metrics
+	processedCount atomic.Int64
+	failedCount    atomic.Int64
 }
 
 // NewBackgroundWorker creates a new background worker
 func NewBackgroundWorker(id int, healer *Healer, logger Logger, wg *sync.WaitGroup) *BackgroundWorker {
 	return &BackgroundWorker{
-		id:     id,
-		healer: healer,
-		logger: logger,
-		stopCh: make(chan struct{}),
-		wg:     wg,
+		id:      id,
+		healer:  healer,
+		logger:  logger,
+		stopCh:  make(chan struct{}),
+		drainCh: make(chan struct{}),
+		wg:      wg,
 	}
 }
 
@@ -70,6 +84,12 @@ func (w *BackgroundWorker) Stop() error {
 	return nil
 }
 
+// drain makes the worker keep taking queued events until the queue is empty
+// and then exit, rather than waiting for more
+func (w *BackgroundWorker) drain() {
+	w.drainOnce.Do(func() { close(w.drainCh) })
+}
+
 // run is the main worker loop
 func (w *BackgroundWorker) run(ctx context.Context) {
 	defer w.wg.Done()
@@ -79,77 +99,183 @@ func (w *BackgroundWorker) run(ctx context.Context) {
 	}
 
 	for {
+		paused, resumed := w.pool.pauseChannels()
 		select {
-		case <-ctx.Done():
-			if w.logger != nil {
-				w.logger.Debug("Worker %d stopped due to context cancellation", w.id)
+		case <-paused:
+			// Leave events queued until the pool is resumed
+			select {
+			case <-resumed:
+				continue
+			case <-ctx.Done():
+			case <-w.stopCh:
+			case <-w.drainCh:
+			}
+		default:
+			// A cancelled pool leaves the rest of the queue to Stop
+			if ctx.Err() != nil {
+				break
 			}
-			return
+			// next drains higher-severity bands first. A stopped worker takes
+			// no more events; a draining one takes them until the queue is empty.
+			event, ok := w.healer.queue.next(ctx, w.stopCh, w.drainCh, paused)
+			if ok {
+				w.processEvent(ctx, event)
+				continue
+			}
+			if ctx.Err() == nil && !w.stopped() && !w.draining() {
+				continue // paused while waiting
+			}
+		}
 
-		case <-w.stopCh:
-			if w.logger != nil {
+		if w.logger != nil {
+			if ctx.Err() != nil {
+				w.logger.Debug("Worker %d stopped due to context cancellation", w.id)
+			} else if !w.stopped() {
+				w.logger.Debug("Worker %d stopped after draining the queue", w.id)
+			} else {
 				w.logger.Debug("Worker %d stopped due to stop signal", w.id)
 			}
-			return
-
-		case event := <-w.healer.errorQueue:
-			w.processEvent(ctx, event)
 		}
+		return
 	}
 }
 
+// stopped reports whether Stop has been called on the worker
+func (w *BackgroundWorker) stopped() bool {
+	select {
+	case <-w.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// draining reports whether drain has been called on the worker
+func (w *BackgroundWorker) draining() bool {
+	select {
+	case <-w.drainCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// eventLogger returns the worker logger with the worker and event IDs attached
+func (w *BackgroundWorker) eventLogger(event PanicEvent) Logger {
+	return internal.WithFields(w.logger, internal.Fields{"worker_id": w.id, "event_id": event.ID})
+}
+
 // processEvent processes a single panic event
 func (w *BackgroundWorker) processEvent(ctx context.Context, event PanicEvent) {
-	if w.logger != nil {
-		w.logger.Debug("Worker %d processing event %s", w.id, event.ID)
+	logger := w.eventLogger(event)
+
+	// An event queued twice, e.g. by a journal replay, is processed only once
+	if !w.healer.claims.claim(event.ID) {
+		if logger != nil {
+			logger.Debug("Skipping event already processed or in progress")
+		}
+		return
+	}
+	defer w.healer.claims.finish(event.ID)
+
+	if logger != nil {
+		logger.Debug("Processing event")
 	}
 
+	ctx, span := w.startEventSpan(ctx, event)
+	defer span.End()
+
 	// Update event status
 	event.Status = "processing"
 	now := time.Now()
 	event.ProcessedAt = &now
 
 	// Process the event with retry logic and circuit breaker
-	err := w.processEventWithRetry(ctx, event)
+	prResult, handedOff, err := w.processEventWithRetry(ctx, event)
+	w.processedCount.Add(1)
+
+	// Events cut off by shutdown are replayed from the persisted queue, so
+	// they are neither failed nor reported until the replay finishes them
+	if err != nil && ctx.Err() != nil && w.healer.persistsQueue() {
+		span.RecordError(err)
+		if logger != nil {
+			logger.Warn("Processing cut off by shutdown, event kept in the persisted queue: %v", err)
+		}
+		return
+	}
+
 	if err != nil {
+		span.RecordError(err)
 		event.Status = "failed"
-		if w.logger != nil {
-			w.logger.Error("Worker %d failed to process event %s: %v", w.id, event.ID, err)
+		event.LastError = err.Error()
+		w.failedCount.Add(1)
+		w.healer.deadLetters.add(event)
+		w.healer.notifyError(event, err)
+		if logger != nil {
+			logger.Error("Failed to process event: %v", err)
 		}
 	} else {
 		event.Status = "completed"
-		if w.logger != nil {
-			w.logger.Info("Worker %d successfully processed event %s", w.id, event.ID)
+		if logger != nil {
+			logger.Info("Successfully processed event")
 		}
 	}
+
+	// Handed-off events are marked once their batched PR or dead letter exists
+	if !handedOff {
+		w.healer.markProcessed(event)
+		w.healer.publishResult(NewProcessingResult(event, prResult, err))
+	}
+}
+
+// startEventSpan starts the span covering all processing of event, as a child
+// of the span that captured it
+func (w *BackgroundWorker) startEventSpan(ctx context.Context, event PanicEvent) (context.Context, Span) {
+	tracer := w.healer.tracer
+	if tracer != nil && event.TraceParent != "" {
+		ctx = tracer.WithTraceParent(ctx, event.TraceParent)
+	}
+
+	ctx, span := internal.StartSpan(ctx, tracer, "healer.process_event")
+	span.SetAttribute("healer.event_id", event.ID)
+	span.SetAttribute("healer.severity", event.Severity)
+	span.SetAttribute("healer.worker_id", w.id)
+	return ctx, span
 }
 
-// processEventWithRetry processes an event with retry logic and circuit breaker
-func (w *BackgroundWorker) processEventWithRetry(ctx context.Context, event PanicEvent) error {
+// processEventWithRetry processes an event with retry logic. AI providers and
+// the Git client each sit behind their own circuit breaker. It returns the
+// pull request opened for the event, if any, and whether the outcome of the
+// event is reported elsewhere (see errHandedOff).
+func (w *BackgroundWorker) processEventWithRetry(ctx context.Context, event PanicEvent) (prResult *PRResult, handedOff bool, err error) {
 	// Use retry manager for processing
-	return w.healer.retryManager.ExecuteWithRetry(ctx, fmt.Sprintf("process-event-%s", event.ID), func() error {
-		// Use circuit breaker for external API calls
-		return w.healer.circuitBreaker.Execute(ctx, "event-processing", func() error {
-			// Use enhanced timeout management for different processing phases
-			return w.processEventWithTimeoutManagement(ctx, event)
-		})
+	err = w.healer.retryManager.ExecuteWithRetry(ctx, fmt.Sprintf("process-event-%s", event.ID), func() error {
+		// Use enhanced timeout management for different processing phases
+		var err error
+		prResult, handedOff, err = w.processEventWithTimeoutManagement(ctx, event)
+		return err
 	})
+	return prResult, handedOff, err
 }
 
 // processEventWithAI processes an event using AI fix generation
 func (w *BackgroundWorker) processEventWithAI(ctx context.Context, event PanicEvent) (*FixResponse, error) {
+	logger := w.eventLogger(event)
+
 	// Create timeout context for AI processing
-	aiCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	config := w.healer.getConfig()
+	aiCtx, cancel := context.WithTimeout(ctx, config.GetAIPhaseTimeout())
 	defer cancel()
 
-	if w.logger != nil {
-		w.logger.Debug("Worker %d starting AI processing for event %s", w.id, event.ID)
+	if logger != nil {
+		logger.Debug("Starting AI processing")
 	}
 
 	// Check if provider manager is available
-	if w.healer.providerManager == nil {
-		if w.logger != nil {
-			w.logger.Debug("Provider manager not available, skipping AI processing for event %s", event.ID)
+	providerManager := w.healer.getProviderManager()
+	if providerManager == nil {
+		if logger != nil {
+			logger.Debug("Provider manager not available, skipping AI processing")
 		}
 		return nil, nil // Not an error, just skip AI processing
 	}
@@ -158,12 +284,12 @@ func (w *BackgroundWorker) processEventWithAI(ctx context.Context, event PanicEv
 	fixRequest := ai.FixRequest{
 		Error:      event.Error,
 		StackTrace: event.StackTrace,
-		SourceCode: w.extractSourceCode(event),
+		SourceCode: w.healer.sourceCode(event),
 		Context:    event.GetContext(),
 	}
 
 	// Generate fix using provider manager with timeout management
-	fixResponse, err := w.healer.providerManager.GenerateFixWithFallback(aiCtx, fixRequest)
+	fixResponse, err := providerManager.GenerateFixWithFallback(aiCtx, fixRequest)
 	if err != nil {
 		// Check if it's a timeout or cancellation
 		if ctx.Err() != nil {
@@ -172,9 +298,8 @@ func (w *BackgroundWorker) processEventWithAI(ctx context.Context, event PanicEv
 		return nil, fmt.Errorf("AI fix generation failed: %w", err)
 	}
 
-	if w.logger != nil {
-		w.logger.Info("Worker %d generated AI fix for event %s (confidence: %.2f, valid: %v)",
-			w.id, event.ID, fixResponse.Confidence, fixResponse.IsValid)
+	if logger != nil {
+		logger.Info("Generated AI fix (confidence: %.2f, valid: %v)", fixResponse.Confidence, fixResponse.IsValid)
 	}
 
 	// Store the fix response for logging
@@ -183,40 +308,49 @@ func (w *BackgroundWorker) processEventWithAI(ctx context.Context, event PanicEv
 	return fixResponse, nil
 }
 
-// processEventWithGit processes an event using Git operations to create pull requests
-func (w *BackgroundWorker) processEventWithGit(ctx context.Context, event PanicEvent, fixResponse *FixResponse) error {
+// processEventWithGit processes an event using Git operations to create pull
+// requests. The result is nil when no pull request was needed.
+func (w *BackgroundWorker) processEventWithGit(ctx context.Context, event PanicEvent, fixResponse *FixResponse) (*PRResult, error) {
+	logger := w.eventLogger(event)
+
 	// Create timeout context for Git processing
-	gitCtx, cancel := context.WithTimeout(ctx, 90*time.Second)
+	config := w.healer.getConfig()
+	gitCtx, cancel := context.WithTimeout(ctx, config.GetGitTimeout())
 	defer cancel()
 
-	if w.logger != nil {
-		w.logger.Debug("Worker %d starting Git processing for event %s", w.id, event.ID)
+	if logger != nil {
+		logger.Debug("Starting Git processing")
 	}
 
-	// Check if Git client is available
-	if w.healer.gitClient == nil {
-		if w.logger != nil {
-			w.logger.Debug("Git client not available, skipping Git processing for event %s", event.ID)
+	// Check if Git client is available (dry runs never need one)
+	if w.healer.gitClient == nil && !config.DryRun {
+		if logger != nil {
+			logger.Debug("Git client not available, skipping Git processing")
 		}
-		return nil // Not an error, just skip Git processing
+		return nil, nil // Not an error, just skip Git processing
+	}
+
+	// Track fixes too uncertain for a pull request as issues instead
+	if fixResponse != nil && config.WantsIssue(fixResponse.Confidence) {
+		return nil, w.openIssue(gitCtx, event, fixResponse, config.DryRun)
 	}
 
 	// Skip Git processing if we don't have a valid AI fix
 	if fixResponse == nil || !fixResponse.IsValid || fixResponse.ProposedFix == "" {
-		if w.logger != nil {
-			w.logger.Debug("No valid AI fix available, skipping Git processing for event %s", event.ID)
+		if logger != nil {
+			logger.Debug("No valid AI fix available, skipping Git processing")
 		}
-		return nil
+		return nil, nil
 	}
 
 	// Check confidence threshold (only create PRs for high-confidence fixes)
-	confidenceThreshold := 0.7 // 70% confidence threshold
+	confidenceThreshold := config.GetMinConfidenceForPR()
 	if fixResponse.Confidence < confidenceThreshold {
-		if w.logger != nil {
-			w.logger.Debug("AI fix confidence (%.2f) below threshold (%.2f), skipping Git processing for event %s",
-				fixResponse.Confidence, confidenceThreshold, event.ID)
+		if logger != nil {
+			logger.Debug("AI fix confidence (%.2f) below threshold (%.2f), skipping Git processing",
+				fixResponse.Confidence, confidenceThreshold)
 		}
-		return nil
+		return nil, nil
 	}
 
 	// Generate branch name and PR details
@@ -227,79 +361,184 @@ func (w *BackgroundWorker) processEventWithGit(ctx context.Context, event PanicE
 	// Create file changes
 	changes := []FileChange{
 		{
-			FilePath: event.SourceFile,
-			Content:  fixResponse.ProposedFix,
+			FilePath:    w.healer.repoPath(event.SourceFile),
+			Content:     fixResponse.ProposedFix,
+			PatchFormat: fixResponse.PatchFormat,
+			StartLine:   fixResponse.StartLine,
+			EndLine:     fixResponse.EndLine,
 		},
 	}
 
 	// Create PR request
 	prRequest := PRRequest{
-		BranchName:  branchName,
-		Title:       prTitle,
-		Description: prDescription,
-		Changes:     changes,
+		BranchName:    branchName,
+		Title:         prTitle,
+		Description:   prDescription,
+		Changes:       changes,
+		Fingerprint:   event.Fingerprint,
+		CommitMessage: w.healer.commitMessage(event, fixResponse, 1),
+	}
+	w.healer.applyPRTemplates(&prRequest, event, fixResponse)
+
+	// Reject fixes that break the build before anyone has to review them
+	if config.CompileCheck {
+		// The check applies the fix to the source on disk
+		local := changes[0]
+		local.FilePath = event.SourceFile
+		if err := checkFixCompiles(ctx, local); errors.Is(err, errCompileCheckUnavailable) {
+			if logger != nil {
+				logger.Debug("Skipping compile check: %v", err)
+			}
+		} else if err != nil {
+			if logger != nil {
+				logger.Warn("Rejecting AI fix: %v", err)
+			}
+			return nil, nil
+		}
 	}
 
-	// Execute Git operations with retry logic
-	err := w.healer.retryManager.ExecuteWithRetry(gitCtx, fmt.Sprintf("git-pr-%s", event.ID), func() error {
-		return w.healer.gitClient.CreatePullRequest(gitCtx, prRequest)
-	})
+	// In dry-run mode, log what would have been created and stop before touching Git
+	if config.DryRun {
+		w.logDryRun(event, prRequest)
+		return nil, nil
+	}
+
+	// Hold the fix so other fixes to the same file can share its pull request
+	if config.BatchByFile && !w.unbatched {
+		w.healer.batcher.add(event, fixResponse, changes[0])
+		if logger != nil {
+			logger.Debug("Fix for %s held for batching", event.SourceFile)
+		}
+		return nil, errHandedOff
+	}
 
+	if !w.healer.allowPR() {
+		w.healer.deadLetterRateLimited(event)
+		return nil, errHandedOff
+	}
+
+	prResult, err := w.healer.submitPullRequest(gitCtx, event, prRequest)
 	if err != nil {
 		// Check if it's a timeout or cancellation
 		if ctx.Err() != nil {
-			return fmt.Errorf("Git processing cancelled: %w", ctx.Err())
+			return nil, fmt.Errorf("Git processing cancelled: %w", ctx.Err())
 		}
 
 		// Log the failure but don't fail the entire processing
-		if w.logger != nil {
-			w.logger.Error("Worker %d failed to create PR for event %s: %v", w.id, event.ID, err)
+		if logger != nil {
+			logger.Error("Failed to create PR: %v", err)
 		}
-		return fmt.Errorf("Git PR creation failed: %w", err)
+		return nil, fmt.Errorf("Git PR creation failed: %w", err)
 	}
 
-	if w.logger != nil {
-		w.logger.Info("Worker %d successfully created PR for event %s: %s", w.id, event.ID, prTitle)
+	if prResult == nil {
+		prResult = &PRResult{Title: prRequest.Title}
 	}
 
-	return nil
+	if prResult.AlreadyExisted {
+		if logger != nil {
+			logger.Info("Found existing PR, skipping creation: %s", prResult.URL)
+		}
+		return prResult, nil
+	}
+
+	if logger != nil {
+		logger.Info("Successfully created PR: %s %s", prResult.Title, prResult.URL)
+	}
+
+	w.healer.notifyPRCreated(event, prResult)
+	w.healer.notify(HealerEvent{Type: EventPRCreated, Time: time.Now(), Event: event, Fix: fixResponse, PR: prResult})
+
+	return prResult, nil
 }
 
-// extractSourceCode attempts to extract relevant source code context from the panic event
-func (w *BackgroundWorker) extractSourceCode(event PanicEvent) string {
-	// For now, we'll use the stack trace as context
-	// In a more sophisticated implementation, we could:
-	// 1. Read the actual source file at the panic location
-	// 2. Extract surrounding lines of code
-	// 3. Include relevant function definitions
+// openIssue opens an issue documenting a panic and its low-confidence fix
+func (w *BackgroundWorker) openIssue(ctx context.Context, event PanicEvent, fixResponse *FixResponse, dryRun bool) error {
+	logger := w.eventLogger(event)
+
+	githubEvent := toGitHubPanicEvent(event)
+	issueRequest := IssueRequest{
+		Title:       gh.GenerateIssueTitle(githubEvent),
+		Body:        gh.GenerateIssueBody(githubEvent, toGitHubFixResponse(fixResponse)),
+		Fingerprint: event.Fingerprint,
+	}
+
+	if dryRun {
+		if logger != nil {
+			logger.Info("[dry-run] Would create issue (confidence: %.2f)", fixResponse.Confidence)
+			logger.Info("[dry-run] Title: %s", issueRequest.Title)
+			logger.Info("[dry-run] Body:\n%s", issueRequest.Body)
+		}
+		return nil
+	}
+
+	issueResult, err := w.healer.submitIssue(ctx, event, issueRequest)
+	if err != nil {
+		if logger != nil {
+			logger.Error("Failed to create issue: %v", err)
+		}
+		return fmt.Errorf("Git issue creation failed: %w", err)
+	}
 
-	if event.SourceFile == "" || event.LineNumber == 0 {
-		return ""
+	switch {
+	case issueResult == nil:
+		if logger != nil {
+			logger.Debug("Git client cannot open issues, skipping low-confidence fix")
+		}
+	case issueResult.AlreadyExisted:
+		if logger != nil {
+			logger.Info("Found existing issue, skipping creation: %s", issueResult.URL)
+		}
+	default:
+		if logger != nil {
+			logger.Info("Created issue for low-confidence fix (confidence: %.2f): %s", fixResponse.Confidence, issueResult.URL)
+		}
 	}
+	return nil
+}
 
-	// Create a simple source context description
-	return fmt.Sprintf("// Error occurred in file: %s at line %d in function: %s\n// Stack trace provides additional context",
-		event.SourceFile, event.LineNumber, event.Function)
+// logDryRun logs the pull request that would have been created for an event
+func (w *BackgroundWorker) logDryRun(event PanicEvent, prRequest PRRequest) {
+	logger := w.eventLogger(event)
+	if logger == nil {
+		return
+	}
+
+	logger.Info("[dry-run] Would create PR")
+	logger.Info("[dry-run] Branch: %s", prRequest.BranchName)
+	logger.Info("[dry-run] Title: %s", prRequest.Title)
+	logger.Info("[dry-run] Description:\n%s", prRequest.Description)
+
+	for _, change := range prRequest.Changes {
+		switch format := gh.DetectPatchFormat(change); format {
+		case gh.PatchFormatLineRange:
+			logger.Info("[dry-run] Change to %s (lines %d-%d replaced):\n%s",
+				change.FilePath, change.StartLine, change.EndLine, change.Content)
+		default:
+			logger.Info("[dry-run] Change to %s (%s):\n%s", change.FilePath, format, change.Content)
+		}
+	}
 }
 
 // storeFixResponse stores the AI fix response for later use by Git processing
 func (w *BackgroundWorker) storeFixResponse(event PanicEvent, fixResponse *FixResponse) {
+	logger := w.eventLogger(event)
+
 	// For now, just log the fix response
 	// In a more complete implementation, this could:
 	// 1. Store in a database or cache
 	// 2. Queue for Git processing
 	// 3. Notify other components
 
-	if w.logger != nil {
-		w.logger.Debug("Storing fix response for event %s: confidence=%.2f, valid=%v",
-			event.ID, fixResponse.Confidence, fixResponse.IsValid)
+	if logger != nil {
+		logger.Debug("Storing fix response: confidence=%.2f, valid=%v", fixResponse.Confidence, fixResponse.IsValid)
 
 		if fixResponse.ProposedFix != "" {
-			w.logger.Debug("Proposed fix for event %s:\n%s", event.ID, fixResponse.ProposedFix)
+			logger.Debug("Proposed fix:\n%s", fixResponse.ProposedFix)
 		}
 
 		if fixResponse.Explanation != "" {
-			w.logger.Debug("Fix explanation for event %s: %s", event.ID, fixResponse.Explanation)
+			logger.Debug("Fix explanation: %s", fixResponse.Explanation)
 		}
 	}
 }
@@ -313,20 +552,49 @@ type WorkerPool struct {
 	ctx     context.Context
 	cancel  context.CancelFunc
 	mu      sync.RWMutex
+
+	nextID int // id of the most recently created worker
+
+	// paused is closed while the pool is paused and resumed while it is not,
+	// so workers can wait on whichever state change they need. They have their
+	// own lock because Stop holds mu while waiting for workers to exit.
+	pauseMu sync.RWMutex
+	paused  chan struct{}
+	resumed chan struct{}
+
+	// Totals from workers that have since been stopped
+	retiredProcessed int64
+	retiredFailed    int64
+	retiring         []*BackgroundWorker // removed by resize, may still be finishing an event
+
+	draining bool // set by Drain until Stop
 }
 
 // NewWorkerPool creates a new worker pool
 func NewWorkerPool(healer *Healer, logger Logger) *WorkerPool {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	resumed := make(chan struct{})
+	close(resumed)
+
 	return &WorkerPool{
-		healer: healer,
-		logger: logger,
-		ctx:    ctx,
-		cancel: cancel,
+		healer:  healer,
+		logger:  logger,
+		ctx:     ctx,
+		cancel:  cancel,
+		paused:  make(chan struct{}),
+		resumed: resumed,
 	}
 }
 
+// newWorker creates a worker belonging to the pool. Callers must hold wp.mu.
+func (wp *WorkerPool) newWorker() *BackgroundWorker {
+	wp.nextID++
+	worker := NewBackgroundWorker(wp.nextID, wp.healer, wp.logger, &wp.wg)
+	worker.pool = wp
+	return worker
+}
+
 // Start initializes and starts all workers in the pool
 func (wp *WorkerPool) Start() error {
 	wp.mu.Lock()
@@ -337,11 +605,11 @@ func (wp *WorkerPool) Start() error {
 	}
 
 	// Create workers based on configuration
-	workerCount := wp.healer.config.WorkerCount
+	workerCount := wp.healer.getConfig().WorkerCount
 	wp.workers = make([]*BackgroundWorker, workerCount)
 
 	for i := 0; i < workerCount; i++ {
-		worker := NewBackgroundWorker(i+1, wp.healer, wp.logger, &wp.wg)
+		worker := wp.newWorker()
 		wp.workers[i] = worker
 
 		if err := worker.Start(wp.ctx); err != nil {
@@ -358,6 +626,47 @@ func (wp *WorkerPool) Start() error {
 	return nil
 }
 
+// Drain stops all workers in the pool once the queue is empty, waiting up to
+// timeout for them to process the events already queued. It reports whether
+// every worker finished in time; Stop must still be called afterwards. A
+// paused pool does not drain.
+func (wp *WorkerPool) Drain(timeout time.Duration) bool {
+	wp.mu.Lock()
+	if len(wp.workers) == 0 {
+		wp.mu.Unlock()
+		return true
+	}
+
+	if wp.logger != nil {
+		wp.logger.Info("Draining %d queued events before stopping workers", wp.healer.queue.len())
+	}
+
+	// Draining workers keep taking events until the queue is empty. The lock
+	// is released while waiting so counts and stats stay readable; draining
+	// keeps Resize from starting workers meanwhile.
+	wp.draining = true
+	for _, worker := range wp.workers {
+		worker.drain()
+	}
+	wp.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		wp.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		if wp.logger != nil {
+			wp.logger.Warn("Timeout draining the queue after %v", timeout)
+		}
+		return false
+	}
+}
+
 // Stop gracefully stops all workers in the pool
 func (wp *WorkerPool) Stop() error {
 	wp.mu.Lock()
@@ -395,12 +704,134 @@ func (wp *WorkerPool) Stop() error {
 		}
 	}
 
+	// Keep counters from stopped workers so totals stay monotonic
+	for _, worker := range slices.Concat(wp.workers, wp.retiring) {
+		wp.retiredProcessed += worker.processedCount.Load()
+		wp.retiredFailed += worker.failedCount.Load()
+	}
+
 	// Clear workers slice
 	wp.workers = nil
+	wp.retiring = nil
+	wp.draining = false
 
 	return nil
 }
 
+// Errors returned by Resize when the pool has no workers to resize
+var (
+	errPoolNotRunning = errors.New("worker pool is not running")
+	errPoolDraining   = errors.New("worker pool is draining for shutdown")
+)
+
+// Resize starts or stops workers until the pool has n of them. Stopped
+// workers finish the event they are processing before exiting. n must be
+// between 1 and 50. It fails with errPoolNotRunning or errPoolDraining when
+// the pool is not running or is draining.
+func (wp *WorkerPool) Resize(n int) error {
+	if n < 1 || n > internal.MaxWorkerCount {
+		return fmt.Errorf("worker count must be between 1 and %d, got %d", internal.MaxWorkerCount, n)
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	current := len(wp.workers)
+	switch {
+	case current == 0:
+		return errPoolNotRunning
+	case wp.draining:
+		return errPoolDraining
+	case n == current:
+		return nil
+	}
+
+	for len(wp.workers) < n {
+		worker := wp.newWorker()
+		if err := worker.Start(wp.ctx); err != nil {
+			return err
+		}
+		wp.workers = append(wp.workers, worker)
+	}
+
+	for len(wp.workers) > n {
+		worker := wp.workers[len(wp.workers)-1]
+		wp.workers = wp.workers[:len(wp.workers)-1]
+		if err := worker.Stop(); err != nil && wp.logger != nil {
+			wp.logger.Error("Error stopping worker %d: %v", worker.id, err)
+		}
+		// Counters are read until Stop so an in-flight event is still counted
+		wp.retiring = append(wp.retiring, worker)
+	}
+
+	if wp.logger != nil {
+		wp.logger.Info("Worker pool resized from %d to %d workers", current, n)
+	}
+
+	return nil
+}
+
+// Pause stops workers from taking new events off the queue. Events being
+// processed are finished, and new events keep queuing up to the queue capacity.
+func (wp *WorkerPool) Pause() {
+	wp.pauseMu.Lock()
+	defer wp.pauseMu.Unlock()
+
+	if wp.isPausedLocked() {
+		return
+	}
+	wp.resumed = make(chan struct{})
+	close(wp.paused)
+
+	if wp.logger != nil {
+		wp.logger.Info("Worker pool paused")
+	}
+}
+
+// Resume lets workers take events off the queue again after Pause
+func (wp *WorkerPool) Resume() {
+	wp.pauseMu.Lock()
+	defer wp.pauseMu.Unlock()
+
+	if !wp.isPausedLocked() {
+		return
+	}
+	wp.paused = make(chan struct{})
+	close(wp.resumed)
+
+	if wp.logger != nil {
+		wp.logger.Info("Worker pool resumed")
+	}
+}
+
+// IsPaused returns true if the worker pool is paused
+func (wp *WorkerPool) IsPaused() bool {
+	wp.pauseMu.RLock()
+	defer wp.pauseMu.RUnlock()
+	return wp.isPausedLocked()
+}
+
+// isPausedLocked reports whether the pool is paused. Callers must hold wp.pauseMu.
+func (wp *WorkerPool) isPausedLocked() bool {
+	select {
+	case <-wp.paused:
+		return true
+	default:
+		return false
+	}
+}
+
+// pauseChannels returns the current paused and resumed channels. A nil pool is
+// never paused.
+func (wp *WorkerPool) pauseChannels() (paused, resumed <-chan struct{}) {
+	if wp == nil {
+		return nil, nil
+	}
+	wp.pauseMu.RLock()
+	defer wp.pauseMu.RUnlock()
+	return wp.paused, wp.resumed
+}
+
 // stopWorkers stops all workers without waiting
 func (wp *WorkerPool) stopWorkers() {
 	for _, worker := range wp.workers {
@@ -417,6 +848,30 @@ func (wp *WorkerPool) GetWorkerCount() int {
 	return len(wp.workers)
 }
 
+// GetProcessedCount returns the total number of events processed by the pool
+func (wp *WorkerPool) GetProcessedCount() int64 {
+	wp.mu.RLock()
+	defer wp.mu.RUnlock()
+
+	total := wp.retiredProcessed
+	for _, worker := range slices.Concat(wp.workers, wp.retiring) {
+		total += worker.processedCount.Load()
+	}
+	return total
+}
+
+// GetFailedCount returns the total number of events that failed processing
+func (wp *WorkerPool) GetFailedCount() int64 {
+	wp.mu.RLock()
+	defer wp.mu.RUnlock()
+
+	total := wp.retiredFailed
+	for _, worker := range slices.Concat(wp.workers, wp.retiring) {
+		total += worker.failedCount.Load()
+	}
+	return total
+}
+
 // IsRunning returns true if the worker pool is running
 func (wp *WorkerPool) IsRunning() bool {
 	wp.mu.RLock()
@@ -426,6 +881,8 @@ func (wp *WorkerPool) IsRunning() bool {
 
 // processEventAsync processes an event asynchronously with proper timeout management
 func (w *BackgroundWorker) processEventAsync(ctx context.Context, event PanicEvent) {
+	logger := w.eventLogger(event)
+
 	// Create a goroutine for async processing with timeout
 	go func() {
 		// Create a timeout context for the entire async operation
@@ -446,75 +903,103 @@ func (w *BackgroundWorker) processEventAsync(ctx context.Context, event PanicEve
 			}
 		}()
 
-		if w.logger != nil {
-			w.logger.Debug("Worker %d starting async processing for event %s", w.id, event.ID)
+		if logger != nil {
+			logger.Debug("Starting async processing")
 		}
 
 		// Process the event with full error handling
-		err := w.processEventWithRetry(combinedCtx, event)
+		_, _, err := w.processEventWithRetry(combinedCtx, event)
 		if err != nil {
-			if w.logger != nil {
-				w.logger.Error("Worker %d async processing failed for event %s: %v", w.id, event.ID, err)
+			if logger != nil {
+				logger.Error("Async processing failed: %v", err)
 			}
 		} else {
-			if w.logger != nil {
-				w.logger.Info("Worker %d async processing completed for event %s", w.id, event.ID)
+			if logger != nil {
+				logger.Info("Async processing completed")
 			}
 		}
 	}()
 }
 
 // processEventWithTimeoutManagement adds additional timeout management for AI and Git operations
-func (w *BackgroundWorker) processEventWithTimeoutManagement(ctx context.Context, event PanicEvent) error {
-	// Store fix response for Git processing
+func (w *BackgroundWorker) processEventWithTimeoutManagement(ctx context.Context, event PanicEvent) (*PRResult, bool, error) {
+	logger := w.eventLogger(event)
+
+	// Store fix response for Git processing, and the outcome of the Git phase
 	var fixResponse *FixResponse
+	var prResult *PRResult
+	handedOff := false
+	config := w.healer.getConfig()
 
 	// Create multiple timeout contexts for different phases
 	phases := []struct {
 		name    string
+		span    string
 		timeout time.Duration
-		fn      func(context.Context) error
+		fn      func(context.Context, Span) error
 	}{
 		{
 			name:    "ai-processing",
-			timeout: 45 * time.Second,
-			fn: func(phaseCtx context.Context) error {
+			span:    "healer.ai_phase",
+			timeout: config.GetAIPhaseTimeout(),
+			fn: func(phaseCtx context.Context, span Span) error {
 				var err error
 				fixResponse, err = w.processEventWithAI(phaseCtx, event)
+				if err == nil && fixResponse != nil {
+					span.SetAttribute("healer.ai.provider", fixResponse.Provider)
+					span.SetAttribute("healer.ai.model", fixResponse.Model)
+					span.SetAttribute("healer.ai.confidence", fixResponse.Confidence)
+					span.SetAttribute("healer.ai.used_mcp", fixResponse.UsedMCP)
+					w.healer.notifyFixGenerated(event, fixResponse)
+				}
 				return err
 			},
 		},
 		{
 			name:    "git-processing",
-			timeout: 60 * time.Second,
-			fn: func(phaseCtx context.Context) error {
-				return w.processEventWithGit(phaseCtx, event, fixResponse)
+			span:    "healer.git_phase",
+			timeout: config.GetGitTimeout(),
+			fn: func(phaseCtx context.Context, span Span) error {
+				var err error
+				prResult, err = w.processEventWithGit(phaseCtx, event, fixResponse)
+				if errors.Is(err, errHandedOff) {
+					handedOff = true
+					return nil
+				}
+				if prResult != nil {
+					span.SetAttribute("healer.pr.url", prResult.URL)
+				}
+				return err
 			},
 		},
 	}
 
 	for _, phase := range phases {
 		phaseCtx, cancel := context.WithTimeout(ctx, phase.timeout)
+		phaseCtx, span := internal.StartSpan(phaseCtx, w.healer.tracer, phase.span)
 
-		if w.logger != nil {
-			w.logger.Debug("Worker %d starting phase '%s' for event %s (timeout: %v)",
-				w.id, phase.name, event.ID, phase.timeout)
+		if logger != nil {
+			logger.Debug("Starting phase '%s' (timeout: %v)", phase.name, phase.timeout)
 		}
 
-		err := phase.fn(phaseCtx)
+		err := phase.fn(phaseCtx, span)
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
 		cancel()
 
 		if err != nil {
 			if phaseCtx.Err() == context.DeadlineExceeded {
-				return fmt.Errorf("phase '%s' timed out after %v: %w", phase.name, phase.timeout, err)
+				return nil, false, fmt.Errorf("phase '%s' timed out after %v: %w", phase.name, phase.timeout, err)
 			}
-			return fmt.Errorf("phase '%s' failed: %w", phase.name, err)
+			return nil, false, fmt.Errorf("phase '%s' failed: %w", phase.name, err)
 		}
 
-		if w.logger != nil {
-			w.logger.Debug("Worker %d completed phase '%s' for event %s", w.id, phase.name, event.ID)
+		if logger != nil {
+			logger.Debug("Completed phase '%s'", phase.name)
 		}
 	}
 
-	return nil
+	return prResult, handedOff, nil
 }
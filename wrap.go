@@ -0,0 +1,92 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+)
+
+// PanicError is returned by the WrapFn helpers when the wrapped function panics
+type PanicError struct {
+	Value any // the value passed to panic
+}
+
+// Error implements error
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value when it is itself an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// recoverToError reports a recovered panic to the global healer and stores a
+// PanicError in *errp. It must be called directly from a deferred function.
+func recoverToError(ctx context.Context, r any, errp *error) {
+	CapturePanicCtx(ctx, r)
+	if logger := globalLogger(); logger != nil {
+		logger.Error("Recovered from panic: %v", r)
+	}
+	*errp = &PanicError{Value: r}
+}
+
+// WrapFn wraps a function returning a value and an error. A panic is reported
+// to the healer and returned as a *PanicError alongside the zero value.
+func WrapFn[T any](fn func() (T, error)) func() (T, error) {
+	return func() (result T, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				var zero T
+				result = zero
+				recoverToError(context.Background(), r, &err)
+			}
+		}()
+		return fn()
+	}
+}
+
+// WrapFn2 is like WrapFn for functions returning two values and an error
+func WrapFn2[T, U any](fn func() (T, U, error)) func() (T, U, error) {
+	return func() (first T, second U, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				var zeroT T
+				var zeroU U
+				first, second = zeroT, zeroU
+				recoverToError(context.Background(), r, &err)
+			}
+		}()
+		return fn()
+	}
+}
+
+// WrapFnCtx is like WrapFn for context-aware functions. Metadata attached to
+// the context with WithContext is recorded on the captured panic.
+func WrapFnCtx[T any](fn func(ctx context.Context) (T, error)) func(ctx context.Context) (T, error) {
+	return func(ctx context.Context) (result T, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				var zero T
+				result = zero
+				recoverToError(ctx, r, &err)
+			}
+		}()
+		return fn(ctx)
+	}
+}
+
+// WrapFn2Ctx is like WrapFn2 for context-aware functions
+func WrapFn2Ctx[T, U any](fn func(ctx context.Context) (T, U, error)) func(ctx context.Context) (T, U, error) {
+	return func(ctx context.Context) (first T, second U, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				var zeroT T
+				var zeroU U
+				first, second = zeroT, zeroU
+				recoverToError(ctx, r, &err)
+			}
+		}()
+		return fn(ctx)
+	}
+}
@@ -0,0 +1,37 @@
+package healer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWrapFn_ConvertsPanicToError(t *testing.T) {
+	fn := WrapFn(func() (int, error) {
+		var values []int
+		return values[3], nil
+	})
+
+	got, err := fn()
+	if got != 0 {
+		t.Errorf("result = %d, want zero value", got)
+	}
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected *PanicError, got %v", err)
+	}
+	if panicErr.Unwrap() == nil {
+		t.Error("runtime error panic value should be unwrappable")
+	}
+}
+
+func TestWrapFn2_PassesThroughResults(t *testing.T) {
+	fn := WrapFn2(func() (string, int, error) {
+		return "ok", 7, nil
+	})
+
+	first, second, err := fn()
+	if first != "ok" || second != 7 || err != nil {
+		t.Errorf("got (%q, %d, %v), want (\"ok\", 7, nil)", first, second, err)
+	}
+}